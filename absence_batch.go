@@ -0,0 +1,110 @@
+package nmt
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"slices"
+	"sort"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ErrNamespaceFound indicates that a namespace whose absence was to be proven
+// has leaves in the tree.
+var ErrNamespaceFound = errors.New("namespace has leaves in the tree")
+
+// BatchAbsenceProof proves that none of a set of namespaces has leaves in a
+// tree. Namespaces falling into the same gap between two consecutive
+// namespaces of the tree share a single absence proof, whose adjacent nodes
+// witness the absence of all of them, and namespaces outside the namespace
+// range of the root need no proof at all.
+type BatchAbsenceProof struct {
+	// Proofs holds the absence proofs of the gaps containing the namespaces
+	// in the order of the leaves.
+	Proofs []Proof
+}
+
+// ProveAbsence returns a BatchAbsenceProof of the absence of the namespaces
+// nIDs, which may be given in any order. It returns an ErrNamespaceFound
+// error if any of them has leaves in the tree.
+func (n *NamespacedMerkleTree) ProveAbsence(nIDs []namespace.ID) (BatchAbsenceProof, error) {
+	nIDs = slices.Clone(nIDs)
+	sort.Slice(nIDs, func(i, j int) bool { return nIDs[i].Less(nIDs[j]) })
+	var p BatchAbsenceProof
+	for _, nID := range nIDs {
+		if last := len(p.Proofs) - 1; last >= 0 && nID.Less(MinNamespace(p.Proofs[last].leafHash, n.NamespaceSize())) {
+			// nID is in the gap of the previous namespace
+			continue
+		}
+		proof, err := n.ProveNamespace(nID)
+		if err != nil {
+			return BatchAbsenceProof{}, err
+		}
+		switch {
+		case proof.IsOfAbsence():
+			p.Proofs = append(p.Proofs, proof)
+		case !proof.IsEmptyProof():
+			return BatchAbsenceProof{}, fmt.Errorf("%w: %s", ErrNamespaceFound, nID)
+		}
+	}
+	return p, nil
+}
+
+// Verify checks that none of the namespaces nIDs has leaves in the tree with
+// the given root. The first namespace of each gap is verified as by
+// VerifyNamespace; for the other namespaces of the gap, only the nodes
+// adjacent to the gap are checked. h must be the base hash function of the
+// tree. Verify returns an error wrapping ErrInvalidProof if the verification
+// fails.
+func (p BatchAbsenceProof) Verify(h hash.Hash, nIDs []namespace.ID, root []byte) error {
+	if len(nIDs) == 0 {
+		return nil
+	}
+	nidSize := nIDs[0].Size()
+	for i, proof := range p.Proofs {
+		if !proof.IsOfAbsence() {
+			return fmt.Errorf("%w: proof %d is not an absence proof", ErrInvalidProof, i)
+		}
+		if i > 0 && proof.Start() <= p.Proofs[i-1].Start() {
+			return fmt.Errorf("%w: proof %d is not ordered after proof %d", ErrInvalidProof, i, i-1)
+		}
+		if err := proof.validateFormat(NewNmtHasher(h, nidSize, proof.IsMaxNamespaceIDIgnored()), root); err != nil {
+			return fmt.Errorf("%w: proof %d: %w", ErrInvalidProof, i, err)
+		}
+	}
+	verified := make([]bool, len(p.Proofs))
+	for _, nID := range nIDs {
+		if nID.Size() != nidSize {
+			return fmt.Errorf("%w: got: %v, want: %v", ErrMismatchedNamespaceSize, nID.Size(), nidSize)
+		}
+		// namespaces outside the namespace range of the root need no proof
+		if NewEmptyRangeProof(false).checkNamespaceRange(h, nID, nID, nil, root) == nil {
+			continue
+		}
+		// the gap of nID is covered by the first proof whose leaf hash lies
+		// after nID
+		i := sort.Search(len(p.Proofs), func(i int) bool {
+			return nID.Less(MinNamespace(p.Proofs[i].leafHash, nidSize))
+		})
+		if i == len(p.Proofs) {
+			return fmt.Errorf("%w: no proof for namespace %s", ErrInvalidProof, nID)
+		}
+		proof := p.Proofs[i]
+		if !verified[i] {
+			if err := proof.checkNamespaceRange(h, nID, nID, nil, root); err != nil {
+				return fmt.Errorf("%w: namespace %s: %w", ErrInvalidProof, nID, err)
+			}
+			verified[i] = true
+			continue
+		}
+		// the proof has been verified, hence it holds the nodes left of its
+		// leaf hash
+		leftSubtrees := proof.nodes[:leftSubtreeCount(proof.Start())]
+		nth := NewNmtHasher(h, nidSize, proof.IsMaxNamespaceIDIgnored())
+		if err := proof.checkAdjacentNodes(nth, nID, nID, leftSubtrees, proof.leafHash); err != nil {
+			return fmt.Errorf("%w: namespace %s: %w", ErrInvalidProof, nID, err)
+		}
+	}
+	return nil
+}
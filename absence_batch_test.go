@@ -0,0 +1,60 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestProveAbsence(t *testing.T) {
+	tree := exampleNMT(1, true, 2, 3, 3, 6, 7, 10, 10, 11)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	nIDs := []namespace.ID{{9}, {4}, {0}, {5}, {8}, {12}, {1}, {4}}
+
+	proof, err := tree.ProveAbsence(nIDs)
+	require.NoError(t, err)
+	// the gaps (3, 6), (7, 10) hold all namespaces within the range of the tree
+	require.Len(t, proof.Proofs, 2)
+	require.NoError(t, proof.Verify(sha256.New(), nIDs, root))
+	require.NoError(t, proof.Verify(sha256.New(), nil, root))
+
+	for _, nID := range []namespace.ID{{2}, {3}, {6}, {10}, {11}} {
+		assert.ErrorIs(t, proof.Verify(sha256.New(), append(nIDs, nID), root), ErrInvalidProof, "namespace %s", nID)
+		_, err := tree.ProveAbsence(append(nIDs, nID))
+		assert.ErrorIs(t, err, ErrNamespaceFound, "namespace %s", nID)
+	}
+	_, err = tree.ProveAbsence([]namespace.ID{{1, 2}})
+	assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+	assert.ErrorIs(t, proof.Verify(sha256.New(), []namespace.ID{{4}, {4, 4}}, root), ErrMismatchedNamespaceSize)
+
+	tests := []struct {
+		name  string
+		proof BatchAbsenceProof
+	}{
+		{"missing gap", BatchAbsenceProof{Proofs: proof.Proofs[1:]}},
+		{"unordered", BatchAbsenceProof{Proofs: []Proof{proof.Proofs[1], proof.Proofs[0]}}},
+		{"inclusion proof", BatchAbsenceProof{Proofs: []Proof{proof.Proofs[0], NewInclusionProof(5, 6, proof.Proofs[1].Nodes(), true)}}},
+		{"truncated leaf hash", BatchAbsenceProof{Proofs: []Proof{proof.Proofs[0], NewAbsenceProof(5, 6, proof.Proofs[1].Nodes(), proof.Proofs[1].LeafHash()[1:], true)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.proof.Verify(sha256.New(), nIDs, root), ErrInvalidProof)
+		})
+	}
+}
+
+func TestProveAbsence_EmptyTree(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1))
+	root, err := tree.Root()
+	require.NoError(t, err)
+	nIDs := []namespace.ID{{1}, {2}}
+	proof, err := tree.ProveAbsence(nIDs)
+	require.NoError(t, err)
+	assert.Empty(t, proof.Proofs)
+	assert.NoError(t, proof.Verify(sha256.New(), nIDs, root))
+}
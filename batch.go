@@ -0,0 +1,182 @@
+package nmt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+var (
+	// ErrBatchDone indicates that a Batch was used after it had been
+	// committed or rolled back.
+	ErrBatchDone = errors.New("batch already committed or rolled back")
+	// ErrBatchOpen indicates that Pop would remove a leaf that precedes an
+	// open Batch, which Rollback could not restore.
+	ErrBatchOpen = errors.New("leaf precedes an open batch")
+)
+
+// Batch groups pushes to a tree so that they take effect together or not at
+// all. Pushes through a Batch are applied to the tree immediately, i.e., they
+// are visible to all methods of the tree, and are undone by Rollback. A Batch
+// must not be used concurrently with other modifications of its tree.
+type Batch struct {
+	tree *NamespacedMerkleTree
+	// size is the number of leaves of the tree when the batch began, and
+	// frontier the state of frontier-only trees at that time.
+	size     int
+	frontier *frontierState
+	done     bool
+}
+
+// Begin starts a Batch of pushes to the tree. Leaves pushed to the tree
+// directly while the batch is open are considered part of the batch. Until
+// the batch is committed or rolled back, Pop only removes leaves pushed
+// during the batch.
+func (n *NamespacedMerkleTree) Begin() *Batch {
+	b := &Batch{tree: n, size: n.Size(), frontier: n.saveFrontier()}
+	n.batches = append(n.batches, b)
+	return b
+}
+
+// close marks the batch as committed or rolled back.
+func (b *Batch) close() {
+	b.done = true
+	b.tree.batches = slices.DeleteFunc(b.tree.batches, func(other *Batch) bool { return other == b })
+}
+
+// validatePop returns an ErrBatchOpen error if the last leaf precedes an open
+// batch.
+func (n *NamespacedMerkleTree) validatePop() error {
+	for _, b := range n.batches {
+		if n.Size() <= b.size {
+			return fmt.Errorf("%w: leaf %d, batch began at %d leaves", ErrBatchOpen, n.Size()-1, b.size)
+		}
+	}
+	return nil
+}
+
+// Push adds a namespaced data to the tree just like NamespacedMerkleTree.Push.
+// If it fails, the tree is left as before the call, and the batch remains
+// open so that the caller can decide whether to continue or roll back. It
+// returns an ErrBatchDone error if the batch was committed or rolled back.
+func (b *Batch) Push(namespacedData namespace.PrefixedData) error {
+	if b.done {
+		return ErrBatchDone
+	}
+	return b.tree.Push(namespacedData)
+}
+
+// Commit keeps the leaves pushed during the batch and closes it. If the tree
+// records its history (see History), Commit records a checkpoint. It returns
+// an ErrBatchDone error if the batch was committed or rolled back before.
+func (b *Batch) Commit() error {
+	if b.done {
+		return ErrBatchDone
+	}
+	b.close()
+	if b.tree.keepHistory {
+		if _, err := b.tree.Checkpoint(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rollback removes all leaves pushed since the batch began, restoring the
+// tree to its state before the batch, and closes the batch. The inner nodes
+// memoized for the leaves preceding the batch are kept. It returns an
+// ErrBatchDone error if the batch was committed or rolled back before.
+func (b *Batch) Rollback() error {
+	if b.done {
+		return ErrBatchDone
+	}
+	b.close()
+	if b.frontier != nil {
+		b.tree.restoreFrontier(b.frontier)
+	} else {
+		b.tree.truncate(b.size)
+	}
+	b.tree.checkInvariants("Rollback")
+	return nil
+}
+
+// PushAll pushes all leaves atomically: if any leaf is rejected, none of them
+// is added and the returned error identifies the offending leaf.
+func (n *NamespacedMerkleTree) PushAll(leaves [][]byte) error {
+	batch := n.Begin()
+	for i, leaf := range leaves {
+		if err := batch.Push(leaf); err != nil {
+			// rolling back an open batch cannot fail
+			_ = batch.Rollback()
+			return fmt.Errorf("leaf %d: %w", i, err)
+		}
+	}
+	return batch.Commit()
+}
+
+// truncate removes all leaves at index size or above and updates all caches
+// accordingly. It is a no-op if the tree has at most size leaves.
+func (n *NamespacedMerkleTree) truncate(size int) {
+	if size < 0 || size >= n.Size() {
+		return
+	}
+	nidSize := n.NamespaceSize()
+	n.ownNamespaceRanges()
+	for i := n.Size() - 1; i >= size; i-- {
+		leafHash := n.leafHashes[i]
+		if index, found := n.leafIndices[string(leafHash)]; found && index >= size {
+			delete(n.leafIndices, string(leafHash))
+		}
+		nsStr := string(leafHash[:nidSize])
+		if rng, found := n.namespaceRanges[nsStr]; found {
+			if rng.Start >= size {
+				delete(n.namespaceRanges, nsStr)
+			} else if rng.End > size {
+				n.namespaceRanges[nsStr] = LeafRange{Start: rng.Start, End: size}
+			}
+		}
+	}
+	// pending leaves are at the end of the tree
+	n.pending = maxInt(0, n.pending-(n.Size()-size))
+	if n.shared != nil {
+		// the removed leaves may be in use by snapshots, and the next push
+		// reallocates the arrays
+		n.leaves = n.leaves[:size:size]
+		n.leafHashes = n.leafHashes[:size:size]
+	} else {
+		clear(n.leaves[size:])
+		n.leaves = n.leaves[:size]
+		clear(n.leafHashes[size:])
+		n.leafHashes = n.leafHashes[:size]
+	}
+
+	// bloom filters do not support removal; removed namespaces remain in the
+	// filter as false positives until the tree is reset
+
+	// minNID and maxNID may alias removed leaves, hence they are replaced
+	n.minNID = bytes.Repeat([]byte{0xFF}, int(nidSize))
+	n.maxNID = bytes.Repeat([]byte{0x00}, int(nidSize))
+	if n.unordered {
+		for _, leafHash := range n.leafHashes {
+			n.updateMinMaxID(leafHash[:nidSize])
+		}
+	} else if size > 0 {
+		// the leaves are ordered by namespace
+		n.minNID = namespace.ID(n.leafHashes[0][:nidSize])
+		n.maxNID = namespace.ID(n.leafHashes[size-1][:nidSize])
+	}
+	n.rawRoot = nil
+	// the inner nodes of the remaining leaves are unaffected
+	maps.DeleteFunc(n.innerNodes, func(rng LeafRange, _ []byte) bool {
+		return rng.End > size
+	})
+	if n.padding == PadWithLastLeaf {
+		n.padNodes = nil
+	}
+	n.invalidateProofs()
+	n.truncateHistory(size)
+}
@@ -0,0 +1,160 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// requireSameState checks that got has the same leaves and derived state as
+// want.
+func requireSameState(t *testing.T, want, got *NamespacedMerkleTree) {
+	t.Helper()
+	require.Equal(t, want.leaves, got.leaves)
+	require.Equal(t, want.leafHashes, got.leafHashes)
+	require.Equal(t, want.namespaceRanges, got.namespaceRanges)
+	// the leaf indices are built on demand
+	want.IndexOf(nil)
+	got.IndexOf(nil)
+	require.Equal(t, want.leafIndices, got.leafIndices)
+	require.Equal(t, want.minNID, got.minNID)
+	require.Equal(t, want.maxNID, got.maxNID)
+	wantRoot, err := want.Root()
+	require.NoError(t, err)
+	gotRoot, err := got.Root()
+	require.NoError(t, err)
+	require.Equal(t, wantRoot, gotRoot)
+}
+
+func TestBatch_Rollback(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 2)
+	want := exampleNMT(1, true, 1, 2, 2)
+	_, err := tree.Root()
+	require.NoError(t, err)
+
+	batch := tree.Begin()
+	require.NoError(t, batch.Push([]byte{2, 'a'}))
+	require.NoError(t, batch.Push([]byte{3, 'b'}))
+	require.NoError(t, batch.Push([]byte{5, 'c'}))
+	assert.ErrorIs(t, batch.Push([]byte{4, 'd'}), ErrInvalidPushOrder)
+	assert.Equal(t, 6, tree.Size())
+
+	require.NoError(t, batch.Rollback())
+	requireSameState(t, want, tree)
+	assert.Empty(t, tree.Get(namespace.ID{3}))
+
+	assert.ErrorIs(t, batch.Push([]byte{6}), ErrBatchDone)
+	assert.ErrorIs(t, batch.Rollback(), ErrBatchDone)
+	assert.ErrorIs(t, batch.Commit(), ErrBatchDone)
+}
+
+func TestBatch_RollbackKeepsState(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), NamespaceFilter(8, 0.01))
+	want := New(sha256.New(), NamespaceIDSize(1))
+	for _, leaf := range [][]byte{{1, 'a'}, {2, 'b'}, {2, 'c'}, {4, 'd'}} {
+		require.NoError(t, tree.Push(leaf))
+		require.NoError(t, want.Push(leaf))
+	}
+
+	batch := tree.Begin()
+	require.NoError(t, batch.Push([]byte{5, 'e'}))
+	require.NoError(t, batch.Push([]byte{6, 'f'}))
+	_, err := tree.Root()
+	require.NoError(t, err)
+	require.NoError(t, batch.Rollback())
+
+	// the inner nodes of the leaves before the batch are kept
+	assert.Contains(t, tree.innerNodes, LeafRange{Start: 0, End: 4})
+	assert.NotContains(t, tree.innerNodes, LeafRange{Start: 4, End: 6})
+	requireSameState(t, want, tree)
+	// the namespaces of the batch may remain in the filter, but have no
+	// leaves
+	assert.Empty(t, tree.Get(namespace.ID{5}))
+	assert.Empty(t, tree.Get(namespace.ID{6}))
+}
+
+func TestBatch_Pop(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 2, 3)
+	want := exampleNMT(1, true, 0, 1, 2, 3)
+
+	// leaves preceding the batch cannot be popped, as Rollback could not
+	// restore them
+	batch := tree.Begin()
+	_, err := tree.Pop()
+	assert.ErrorIs(t, err, ErrBatchOpen)
+	require.NoError(t, batch.Push([]byte{3, 'Z'}))
+	leaf, err := tree.Pop()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{3, 'Z'}, leaf)
+	require.NoError(t, batch.Push([]byte{3, 'Z'}))
+	require.NoError(t, batch.Rollback())
+	requireSameState(t, want, tree)
+
+	// closed batches do not restrict Pop
+	_, err = tree.Pop()
+	require.NoError(t, err)
+	batch = tree.Begin()
+	require.NoError(t, batch.Commit())
+	_, err = tree.Pop()
+	require.NoError(t, err)
+}
+
+func TestBatch_Commit(t *testing.T) {
+	tree := exampleNMT(1, true, 1)
+	batch := tree.Begin()
+	require.NoError(t, batch.Push([]byte{2, 'a'}))
+	require.NoError(t, batch.Commit())
+	assert.Equal(t, 2, tree.Size())
+	assert.ErrorIs(t, batch.Rollback(), ErrBatchDone)
+	assert.Equal(t, 2, tree.Size())
+}
+
+func TestPushAll(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2)
+	want := exampleNMT(1, true, 1, 2)
+
+	err := tree.PushAll([][]byte{{2, 'a'}, {3, 'b'}, {1, 'c'}, {4, 'd'}})
+	assert.ErrorIs(t, err, ErrInvalidPushOrder)
+	assert.ErrorContains(t, err, "leaf 2")
+	requireSameState(t, want, tree)
+
+	require.NoError(t, tree.PushAll([][]byte{{2, 'a'}, {3, 'b'}}))
+	require.NoError(t, want.Push([]byte{2, 'a'}))
+	require.NoError(t, want.Push([]byte{3, 'b'}))
+	requireSameState(t, want, tree)
+}
+
+func TestTruncate(t *testing.T) {
+	nIDs := []byte{0, 1, 1, 2, 3, 3, 3, 7}
+	full := exampleNMT(1, true, nIDs...)
+	for size := 0; size <= len(nIDs); size++ {
+		tree := exampleNMT(1, true, nIDs...)
+		// memoize the inner nodes to ensure that they are invalidated
+		_, err := tree.ProveNamespace(namespace.ID{3})
+		require.NoError(t, err)
+		tree.truncate(size)
+		want := New(sha256.New(), NamespaceIDSize(1))
+		for _, leaf := range full.leaves[:size] {
+			require.NoError(t, want.Push(leaf))
+		}
+		requireSameState(t, want, tree)
+	}
+}
+
+func TestTruncate_Filter(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), NamespaceFilter(10, 0.01))
+	require.NoError(t, tree.Push([]byte{1, 'a'}))
+	require.NoError(t, tree.PushAll([][]byte{{2, 'b'}, {3, 'c'}}))
+	require.Error(t, tree.PushAll([][]byte{{4, 'b'}, {2, 'c'}}))
+	assert.True(t, tree.MayContainNamespace(namespace.ID{3}))
+
+	batch := tree.Begin()
+	require.NoError(t, batch.Push([]byte{5, 'd'}))
+	require.NoError(t, batch.Rollback())
+	assert.NotContains(t, tree.namespaceRanges, string([]byte{5}))
+	assert.True(t, tree.MayContainNamespace(namespace.ID{1}))
+}
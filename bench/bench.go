@@ -0,0 +1,315 @@
+// Package bench defines standardized workloads for namespaced Merkle trees
+// and measures the throughput of building trees, proving namespaces and
+// verifying namespace proofs on them. Workloads are generated
+// deterministically from a seed, so that measurements taken on different
+// releases or machines are comparable:
+//
+//	results, err := bench.RunAll(bench.Standard, time.Second)
+//	if err != nil {
+//		return err
+//	}
+//	return bench.WriteReport(os.Stdout, results)
+//
+// The same workloads are available as Go benchmarks, whose output can be
+// compared across releases with benchstat:
+//
+//	go test -run='^$' -bench=. ./bench
+package bench
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ErrInvalidWorkload indicates that the parameters of a workload are invalid.
+var ErrInvalidWorkload = errors.New("invalid workload")
+
+// Distribution determines how the leaves of a workload are distributed over
+// its namespaces.
+type Distribution int
+
+const (
+	// Uniform assigns each leaf to a namespace chosen uniformly at random.
+	Uniform Distribution = iota
+	// Zipf assigns leaves to namespaces following a Zipf distribution, so
+	// that few namespaces hold most of the leaves and most namespaces hold
+	// few leaves, if any.
+	Zipf
+)
+
+func (d Distribution) String() string {
+	switch d {
+	case Uniform:
+		return "uniform"
+	case Zipf:
+		return "zipf"
+	default:
+		return fmt.Sprintf("Distribution(%d)", int(d))
+	}
+}
+
+// MarshalText encodes d as its name.
+func (d Distribution) MarshalText() ([]byte, error) {
+	if d != Uniform && d != Zipf {
+		return nil, fmt.Errorf("unknown distribution %d", int(d))
+	}
+	return []byte(d.String()), nil
+}
+
+// zipfExponent is the exponent s of the Zipf distribution, see rand.NewZipf.
+const zipfExponent = 1.1
+
+// Workload describes a tree whose leaves are generated deterministically by
+// Generate.
+type Workload struct {
+	Name string `json:"name"`
+	// Leaves is the number of leaves of the tree.
+	Leaves int `json:"leaves"`
+	// Namespaces is the number of namespaces the leaves are distributed
+	// over. Namespaces that are assigned no leaves are absent from the tree,
+	// but are proven nonetheless.
+	Namespaces   int          `json:"namespaces"`
+	Distribution Distribution `json:"distribution"`
+	// NamespaceSize is the size of the namespace IDs in bytes.
+	NamespaceSize namespace.IDSize `json:"namespace_size"`
+	// LeafSize is the size of the leaf data in bytes, excluding the namespace
+	// ID.
+	LeafSize int `json:"leaf_size"`
+	// Seed seeds the generation of the leaves.
+	Seed int64 `json:"seed"`
+}
+
+// Standard holds the workloads used to track the performance of the module
+// across releases. Their parameters must not be changed; new workloads are
+// added instead.
+var Standard = []Workload{
+	{Name: "small", Leaves: 64, Namespaces: 8, Distribution: Uniform, NamespaceSize: 8, LeafSize: 256, Seed: 1},
+	// rows of a Celestia data square of 128 shares of 512 bytes
+	{Name: "row-uniform", Leaves: 128, Namespaces: 16, Distribution: Uniform, NamespaceSize: 29, LeafSize: 483, Seed: 1},
+	{Name: "row-zipf", Leaves: 128, Namespaces: 64, Distribution: Zipf, NamespaceSize: 29, LeafSize: 483, Seed: 1},
+	{Name: "single-namespace", Leaves: 1 << 12, Namespaces: 1, Distribution: Uniform, NamespaceSize: 8, LeafSize: 256, Seed: 1},
+	{Name: "large-uniform", Leaves: 1 << 14, Namespaces: 256, Distribution: Uniform, NamespaceSize: 8, LeafSize: 64, Seed: 1},
+	{Name: "large-zipf", Leaves: 1 << 14, Namespaces: 1024, Distribution: Zipf, NamespaceSize: 8, LeafSize: 64, Seed: 1},
+}
+
+// Validate returns an ErrInvalidWorkload error if the parameters of w are
+// invalid.
+func (w Workload) Validate() error {
+	switch {
+	case w.Leaves <= 0:
+		return fmt.Errorf("%w: %q has %d leaves", ErrInvalidWorkload, w.Name, w.Leaves)
+	case w.LeafSize < 0:
+		return fmt.Errorf("%w: %q has a negative leaf size", ErrInvalidWorkload, w.Name)
+	case w.NamespaceSize == 0 || w.NamespaceSize > namespace.IDMaxSize:
+		return fmt.Errorf("%w: %q has an invalid namespace size %d", ErrInvalidWorkload, w.Name, w.NamespaceSize)
+	case w.Distribution != Uniform && w.Distribution != Zipf:
+		return fmt.Errorf("%w: %q has an unknown distribution %d", ErrInvalidWorkload, w.Name, int(w.Distribution))
+	}
+	// namespace IDs are numbered from 1 so that neither the zero nor the
+	// maximum namespace ID is used
+	maxNamespaces := uint64(1)<<min(8*uint(w.NamespaceSize), 62) - 2
+	if w.Namespaces <= 0 || uint64(w.Namespaces) > maxNamespaces {
+		return fmt.Errorf("%w: %q has %d namespaces, must be within [1, %d]", ErrInvalidWorkload, w.Name, w.Namespaces, maxNamespaces)
+	}
+	return nil
+}
+
+// NamespaceIDs returns the namespace IDs of w in ascending order.
+func (w Workload) NamespaceIDs() []namespace.ID {
+	ids := make([]namespace.ID, w.Namespaces)
+	for i := range ids {
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], uint64(i)+1)
+		id := make(namespace.ID, w.NamespaceSize)
+		if len(id) >= len(buf) {
+			copy(id[len(id)-len(buf):], buf[:])
+		} else {
+			copy(id, buf[len(buf)-len(id):])
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+// Generate returns the namespace-prefixed leaves of w in namespace order. The
+// leaves only depend on the parameters of w.
+func (w Workload) Generate() ([][]byte, error) {
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+	ids := w.NamespaceIDs()
+	r := rand.New(rand.NewSource(w.Seed)) //nolint:gosec
+	var next func() int
+	switch w.Distribution {
+	case Uniform:
+		next = func() int { return r.Intn(len(ids)) }
+	case Zipf:
+		zipf := rand.NewZipf(r, zipfExponent, 1, uint64(len(ids)-1))
+		next = func() int { return int(zipf.Uint64()) }
+	}
+	indices := make([]int, w.Leaves)
+	for i := range indices {
+		indices[i] = next()
+	}
+	sort.Ints(indices)
+
+	leaves := make([][]byte, w.Leaves)
+	for i, index := range indices {
+		leaf := make([]byte, int(w.NamespaceSize)+w.LeafSize)
+		copy(leaf, ids[index])
+		_, _ = r.Read(leaf[w.NamespaceSize:])
+		leaves[i] = leaf
+	}
+	return leaves, nil
+}
+
+// Measurement is the number of operations performed in a period of time.
+type Measurement struct {
+	Ops     int           `json:"ops"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// PerSecond returns the number of operations per second.
+func (m Measurement) PerSecond() float64 {
+	if m.Elapsed <= 0 {
+		return 0
+	}
+	return float64(m.Ops) / m.Elapsed.Seconds()
+}
+
+// Result holds the throughput measured on a workload.
+type Result struct {
+	Workload Workload `json:"workload"`
+	// Build counts the leaves pushed to trees whose root was computed.
+	Build Measurement `json:"build"`
+	// Bytes is the total size of the leaves of the workload.
+	Bytes int `json:"bytes"`
+	// Prove counts the namespace proofs created, see
+	// NamespacedMerkleTree.ProveNamespace.
+	Prove Measurement `json:"prove"`
+	// Verify counts the namespace proofs verified, see Proof.VerifyNamespace.
+	Verify Measurement `json:"verify"`
+}
+
+// Run generates w and measures building its tree, proving each of its
+// namespaces and verifying the proofs. Each of them is repeated until it took
+// at least minTime in total, and performed at least once.
+func Run(w Workload, minTime time.Duration) (Result, error) {
+	leaves, err := w.Generate()
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{Workload: w}
+	for _, leaf := range leaves {
+		result.Bytes += len(leaf)
+	}
+
+	var tree *nmt.NamespacedMerkleTree
+	var root []byte
+	result.Build, err = measure(minTime, len(leaves), func() error {
+		tree, root, err = Build(w, leaves)
+		return err
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	ids := w.NamespaceIDs()
+	proofs := make([]nmt.Proof, len(ids))
+	result.Prove, err = measure(minTime, len(ids), func() error {
+		for i, id := range ids {
+			if proofs[i], err = tree.ProveNamespace(id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	data := make([][][]byte, len(ids))
+	for i, id := range ids {
+		data[i] = tree.Get(id)
+	}
+	h := sha256.New()
+	result.Verify, err = measure(minTime, len(ids), func() error {
+		for i, id := range ids {
+			if !proofs[i].VerifyNamespace(h, id, data[i], root) {
+				return fmt.Errorf("proof of namespace %x does not verify", id)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return result, nil
+}
+
+// RunAll runs each of the workloads, see Run.
+func RunAll(workloads []Workload, minTime time.Duration) ([]Result, error) {
+	results := make([]Result, 0, len(workloads))
+	for _, w := range workloads {
+		result, err := Run(w, minTime)
+		if err != nil {
+			return nil, fmt.Errorf("workload %q: %w", w.Name, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Build pushes the leaves generated for w to a new tree with SHA256 as the
+// underlying hash function and computes its root.
+func Build(w Workload, leaves [][]byte) (*nmt.NamespacedMerkleTree, []byte, error) {
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(int(w.NamespaceSize)), nmt.InitialCapacity(len(leaves)))
+	for _, leaf := range leaves {
+		if err := tree.Push(leaf); err != nil {
+			return nil, nil, err
+		}
+	}
+	root, err := tree.Root()
+	if err != nil {
+		return nil, nil, err
+	}
+	return tree, root, nil
+}
+
+// measure calls fn, which performs ops operations, until it took at least
+// minTime in total.
+func measure(minTime time.Duration, ops int, fn func() error) (Measurement, error) {
+	var m Measurement
+	start := time.Now()
+	for m.Ops == 0 || m.Elapsed < minTime {
+		if err := fn(); err != nil {
+			return Measurement{}, err
+		}
+		m.Ops += ops
+		m.Elapsed = time.Since(start)
+	}
+	return m, nil
+}
+
+// WriteReport writes the results as a table to w.
+func WriteReport(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "workload\tleaves\tnamespaces\tdistribution\tbuild leaves/s\tbuild MB/s\tprove proofs/s\tverify proofs/s\t")
+	for _, r := range results {
+		buildMBs := r.Build.PerSecond() * float64(r.Bytes) / float64(r.Workload.Leaves) / 1e6
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%.0f\t%.2f\t%.0f\t%.0f\t\n",
+			r.Workload.Name, r.Workload.Leaves, r.Workload.Namespaces, r.Workload.Distribution,
+			r.Build.PerSecond(), buildMBs, r.Prove.PerSecond(), r.Verify.PerSecond())
+	}
+	return tw.Flush()
+}
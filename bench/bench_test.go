@@ -0,0 +1,171 @@
+package bench
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestStandard_Reproducible(t *testing.T) {
+	// the leaves of the standard workloads must not change across releases,
+	// or measurements would not be comparable
+	want := map[string]string{
+		"small":            "1e44a1e23b9c1d5af4897e305a8599256562675caf2475349a6cb5898357710b",
+		"row-uniform":      "3e49baafd56afd6334b9cd92fe51dda89ac9f000f9d165a23696f69925501412",
+		"row-zipf":         "78414051622938c101e0c5e012ad592950df7436c88d014c244a5ac265aaff78",
+		"single-namespace": "8bf648870da75208ea661821a5186fa6978a992ddaf45868c0d81b3567e04e19",
+		"large-uniform":    "a938fa7a19bbf750465b9b99542a541de55e35807574304a29d96d6d27f48971",
+		"large-zipf":       "e74f3248f4b8b738f4ff0d9b7b84ab452d782445cc667016c591687d083a042b",
+	}
+	require.Len(t, Standard, len(want))
+	for _, w := range Standard {
+		leaves, err := w.Generate()
+		require.NoError(t, err)
+		require.Len(t, leaves, w.Leaves)
+		h := sha256.New()
+		for _, leaf := range leaves {
+			require.Len(t, leaf, int(w.NamespaceSize)+w.LeafSize)
+			h.Write(leaf)
+		}
+		assert.Equal(t, want[w.Name], hex.EncodeToString(h.Sum(nil)), w.Name)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	w := Workload{Name: "test", Leaves: 1000, Namespaces: 10, Distribution: Zipf, NamespaceSize: 2, LeafSize: 4, Seed: 7}
+	leaves, err := w.Generate()
+	require.NoError(t, err)
+	counts := make(map[string]int)
+	for i, leaf := range leaves {
+		if i > 0 {
+			require.LessOrEqual(t, bytes.Compare(leaves[i-1][:2], leaf[:2]), 0)
+		}
+		counts[namespace.ID(leaf[:2]).String()]++
+	}
+	// the first namespace is the most frequent one
+	assert.Greater(t, counts["0001"], counts["0002"])
+	assert.Greater(t, counts["0002"], counts["000a"])
+
+	again, err := w.Generate()
+	require.NoError(t, err)
+	assert.Equal(t, leaves, again)
+	w.Seed++
+	other, err := w.Generate()
+	require.NoError(t, err)
+	assert.NotEqual(t, leaves, other)
+}
+
+func TestValidate(t *testing.T) {
+	valid := Workload{Name: "test", Leaves: 1, Namespaces: 254, NamespaceSize: 1}
+	require.NoError(t, valid.Validate())
+	for _, modify := range []func(*Workload){
+		func(w *Workload) { w.Leaves = 0 },
+		func(w *Workload) { w.LeafSize = -1 },
+		func(w *Workload) { w.NamespaceSize = 0 },
+		func(w *Workload) { w.Namespaces = 0 },
+		// the maximum namespace ID is not used
+		func(w *Workload) { w.Namespaces = 255 },
+		func(w *Workload) { w.Distribution = Zipf + 1 },
+	} {
+		w := valid
+		modify(&w)
+		assert.ErrorIs(t, w.Validate(), ErrInvalidWorkload)
+		_, err := w.Generate()
+		assert.ErrorIs(t, err, ErrInvalidWorkload)
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	results, err := RunAll(Standard[:3], 0)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for i, r := range results {
+		w := Standard[i]
+		assert.Equal(t, w, r.Workload)
+		assert.Equal(t, w.Leaves, r.Build.Ops)
+		assert.Equal(t, w.Namespaces, r.Prove.Ops)
+		assert.Equal(t, w.Namespaces, r.Verify.Ops)
+		assert.Equal(t, w.Leaves*(int(w.NamespaceSize)+w.LeafSize), r.Bytes)
+	}
+
+	var report strings.Builder
+	require.NoError(t, WriteReport(&report, results))
+	lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[0], "build leaves/s")
+	assert.Contains(t, lines[3], "row-zipf")
+
+	encoded, err := json.Marshal(results[2])
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"distribution":"zipf"`)
+}
+
+func BenchmarkBuild(b *testing.B) {
+	for _, w := range Standard {
+		leaves, err := w.Generate()
+		require.NoError(b, err)
+		b.Run(w.Name, func(b *testing.B) {
+			var size int
+			for _, leaf := range leaves {
+				size += len(leaf)
+			}
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := Build(w, leaves); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.N*len(leaves))/b.Elapsed().Seconds(), "leaves/s")
+		})
+	}
+}
+
+func BenchmarkProve(b *testing.B) {
+	for _, w := range Standard {
+		leaves, err := w.Generate()
+		require.NoError(b, err)
+		tree, _, err := Build(w, leaves)
+		require.NoError(b, err)
+		ids := w.NamespaceIDs()
+		b.Run(w.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := tree.ProveNamespace(ids[i%len(ids)]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	for _, w := range Standard {
+		leaves, err := w.Generate()
+		require.NoError(b, err)
+		tree, root, err := Build(w, leaves)
+		require.NoError(b, err)
+		// the namespace holding the middle leaf
+		id := namespace.ID(leaves[len(leaves)/2][:w.NamespaceSize])
+		proof, err := tree.ProveNamespace(id)
+		require.NoError(b, err)
+		data := tree.Get(id)
+		h := sha256.New()
+		b.Run(w.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if !proof.VerifyNamespace(h, id, data, root) {
+					b.Fatal("verification failed")
+				}
+			}
+		})
+	}
+}
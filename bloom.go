@@ -0,0 +1,98 @@
+package nmt
+
+import (
+	"hash/maphash"
+	"math"
+	"slices"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// NamespaceFilter enables a bloom filter over the namespace IDs pushed to the
+// tree. The filter is sized for expectedNamespaces distinct namespace IDs at
+// the given false positive rate. It allows lookups of absent namespaces, e.g.,
+// by Get, ProveNamespace or MayContainNamespace, to be answered without
+// consulting the namespace index. The filter only speeds up negative lookups;
+// the results of all methods are unchanged.
+func NamespaceFilter(expectedNamespaces int, falsePositiveRate float64) Option {
+	if expectedNamespaces <= 0 {
+		panic("Got invalid number of expected namespaces. Expected a value greater than 0.")
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		panic("Got invalid false positive rate. Expected 0 < rate < 1.")
+	}
+	return func(opts *Options) {
+		opts.FilterNamespaces = expectedNamespaces
+		opts.FilterFalsePositiveRate = falsePositiveRate
+	}
+}
+
+// MayContainNamespace reports whether the tree may contain leaves of
+// namespace nID. A false result is definite. If the tree was created with the
+// NamespaceFilter option, a true result may be a false positive; otherwise it
+// is exact.
+func (n *NamespacedMerkleTree) MayContainNamespace(nID namespace.ID) bool {
+	if n.filter != nil {
+		return n.filter.mayContain(nID)
+	}
+	found, _, _ := n.foundInRange(nID)
+	return found
+}
+
+// bloomFilter is a bloom filter using double hashing to derive its k bit
+// positions from a single 64-bit hash of the key.
+type bloomFilter struct {
+	bits []uint64
+	k    uint64
+	seed maphash.Seed
+}
+
+// newBloomFilter returns a bloomFilter sized for n keys at a false positive
+// rate of p.
+func newBloomFilter(n int, p float64) *bloomFilter {
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / float64(n) * math.Ln2)
+	if k < 1 {
+		k = 1
+	}
+	return &bloomFilter{
+		bits: make([]uint64, (uint64(m)+63)/64),
+		k:    uint64(k),
+		seed: maphash.MakeSeed(),
+	}
+}
+
+func (f *bloomFilter) positions(key []byte, fn func(word int, mask uint64) bool) bool {
+	h := maphash.Bytes(f.seed, key)
+	h1, h2 := h&0xFFFFFFFF, (h>>32)|1
+	size := uint64(len(f.bits)) * 64
+	for i := uint64(0); i < f.k; i++ {
+		pos := (h1 + i*h2) % size
+		if !fn(int(pos/64), 1<<(pos%64)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *bloomFilter) add(key []byte) {
+	f.positions(key, func(word int, mask uint64) bool {
+		f.bits[word] |= mask
+		return true
+	})
+}
+
+func (f *bloomFilter) mayContain(key []byte) bool {
+	return f.positions(key, func(word int, mask uint64) bool {
+		return f.bits[word]&mask != 0
+	})
+}
+
+func (f *bloomFilter) reset() {
+	clear(f.bits)
+}
+
+// clone returns an independent copy of the filter.
+func (f *bloomFilter) clone() *bloomFilter {
+	return &bloomFilter{bits: slices.Clone(f.bits), k: f.k, seed: f.seed}
+}
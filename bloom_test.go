@@ -0,0 +1,73 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestBloomFilter(t *testing.T) {
+	const n = 1000
+	f := newBloomFilter(n, 0.01)
+	key := func(i int) []byte {
+		return binary.BigEndian.AppendUint64(nil, uint64(i))
+	}
+	for i := 0; i < n; i++ {
+		f.add(key(i))
+	}
+	// no false negatives
+	for i := 0; i < n; i++ {
+		assert.True(t, f.mayContain(key(i)))
+	}
+	falsePositives := 0
+	for i := n; i < 11*n; i++ {
+		if f.mayContain(key(i)) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 300)
+
+	f.reset()
+	assert.False(t, f.mayContain(key(0)))
+}
+
+func TestNamespaceFilter(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), NamespaceFilter(16, 0.01))
+	for _, nID := range []byte{1, 1, 3, 5, 5, 7} {
+		require.NoError(t, tree.Push([]byte{nID, 'd'}))
+	}
+	root, err := tree.Root()
+	require.NoError(t, err)
+
+	for _, nID := range []namespace.ID{{1}, {3}, {5}, {7}} {
+		assert.True(t, tree.MayContainNamespace(nID))
+		assert.NotEmpty(t, tree.Get(nID))
+	}
+	for _, nID := range []namespace.ID{{0}, {2}, {4}, {6}, {8}} {
+		assert.Empty(t, tree.Get(nID))
+		proof, err := tree.ProveNamespace(nID)
+		require.NoError(t, err)
+		assert.True(t, proof.VerifyNamespace(sha256.New(), nID, nil, root))
+	}
+
+	tree.Reset()
+	assert.False(t, tree.MayContainNamespace(namespace.ID{1}))
+	assert.Empty(t, tree.Get(namespace.ID{1}))
+}
+
+func TestMayContainNamespace_WithoutFilter(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 3)
+	assert.True(t, tree.MayContainNamespace(namespace.ID{1}))
+	assert.False(t, tree.MayContainNamespace(namespace.ID{2}))
+}
+
+func TestNamespaceFilter_Panics(t *testing.T) {
+	assert.Panics(t, func() { NamespaceFilter(0, 0.01) })
+	assert.Panics(t, func() { NamespaceFilter(10, 0) })
+	assert.Panics(t, func() { NamespaceFilter(10, 1) })
+}
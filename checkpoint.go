@@ -0,0 +1,186 @@
+package nmt
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// ErrInvalidCheckpoint indicates that a checkpoint of a RootComputer is
+// malformed, corrupted, or was taken with different options.
+var ErrInvalidCheckpoint = errors.New("invalid checkpoint")
+
+var (
+	_ encoding.BinaryMarshaler   = (*RootComputer)(nil)
+	_ encoding.BinaryUnmarshaler = (*RootComputer)(nil)
+)
+
+// checkpointMagic and checkpointVersion identify the encoding of checkpoints.
+const (
+	checkpointMagic   = "NMTC"
+	checkpointVersion = 1
+)
+
+// MarshalBinary returns a checkpoint of the RootComputer, i.e., its frontier
+// of perfect subtree roots together with the metadata needed to continue
+// pushing leaves. Its size is logarithmic in the number of leaves pushed. The
+// checkpoint ends with a CRC-32 checksum so that truncated or corrupted
+// checkpoints are detected when they are restored.
+func (c *RootComputer) MarshalBinary() ([]byte, error) {
+	nodeSize := len(c.treeHasher.EmptyRoot())
+	data := make([]byte, 0, len(checkpointMagic)+32+(len(c.peaks)+1)*nodeSize+crc32.Size)
+	data = append(data, checkpointMagic...)
+	data = append(data, checkpointVersion)
+	data = binary.AppendUvarint(data, uint64(c.treeHasher.NamespaceSize()))
+	if c.treeHasher.IsMaxNamespaceIDIgnored() {
+		data = binary.AppendUvarint(data, 1)
+	} else {
+		data = binary.AppendUvarint(data, 0)
+	}
+	data = binary.AppendUvarint(data, uint64(c.padding))
+	data = binary.AppendUvarint(data, uint64(c.fixedSize))
+	data = binary.AppendUvarint(data, uint64(nodeSize))
+	data = binary.AppendUvarint(data, uint64(c.size))
+	if c.size > 0 {
+		data = append(data, c.lastLeafHash...)
+	}
+	for _, peak := range c.peaks {
+		data = append(data, peak...)
+	}
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(data)), nil
+}
+
+// UnmarshalBinary restores a checkpoint returned by MarshalBinary, replacing
+// all leaves pushed to c so far. Pushing continues with leaf Size() of the
+// original input. c must have been created with the same base hash function
+// and options as the RootComputer the checkpoint was taken of; UnmarshalBinary
+// returns an ErrInvalidCheckpoint error if the options differ or the
+// checkpoint is malformed, in which case c is left unchanged.
+func (c *RootComputer) UnmarshalBinary(data []byte) error {
+	if len(data) < len(checkpointMagic)+1+crc32.Size || string(data[:len(checkpointMagic)]) != checkpointMagic {
+		return fmt.Errorf("%w: missing header", ErrInvalidCheckpoint)
+	}
+	body, sum := data[:len(data)-crc32.Size], binary.BigEndian.Uint32(data[len(data)-crc32.Size:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidCheckpoint)
+	}
+	if version := body[len(checkpointMagic)]; version != checkpointVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidCheckpoint, version)
+	}
+	r := bytes.NewReader(body[len(checkpointMagic)+1:])
+
+	var header [6]uint64
+	for i := range header {
+		var err error
+		if header[i], err = binary.ReadUvarint(r); err != nil {
+			return fmt.Errorf("%w: truncated header", ErrInvalidCheckpoint)
+		}
+	}
+	want := [5]uint64{uint64(c.treeHasher.NamespaceSize()), 0, uint64(c.padding), uint64(c.fixedSize), uint64(len(c.treeHasher.EmptyRoot()))}
+	if c.treeHasher.IsMaxNamespaceIDIgnored() {
+		want[1] = 1
+	}
+	if got := [5]uint64(header[:5]); got != want {
+		return fmt.Errorf("%w: taken with namespace size %d, ignoreMax %d, padding %d, fixed size %d and node size %d, want %d, %d, %d, %d and %d",
+			ErrInvalidCheckpoint, got[0], got[1], got[2], got[3], got[4], want[0], want[1], want[2], want[3], want[4])
+	}
+	nodeSize, size := int(header[4]), header[5]
+	if size > math.MaxInt || c.fixedSize > 0 && size > uint64(c.fixedSize) {
+		return fmt.Errorf("%w: size %d exceeds the capacity of the tree", ErrInvalidCheckpoint, size)
+	}
+
+	// the checkpoint holds the last leaf hash followed by the peaks
+	numNodes := bits.OnesCount64(size)
+	if size > 0 {
+		numNodes++
+	}
+	if r.Len() != numNodes*nodeSize {
+		return fmt.Errorf("%w: got %d bytes of nodes, want %d", ErrInvalidCheckpoint, r.Len(), numNodes*nodeSize)
+	}
+	rest := body[len(body)-r.Len():]
+	nodes := make([][]byte, numNodes)
+	for i := range nodes {
+		nodes[i] = bytes.Clone(rest[i*nodeSize : (i+1)*nodeSize])
+	}
+
+	c.Reset()
+	c.size = int(size)
+	if size > 0 {
+		c.lastLeafHash = nodes[0]
+		c.lastNID = append(c.lastNID[:0], MinNamespace(c.lastLeafHash, c.treeHasher.NamespaceSize())...)
+		nodes = nodes[1:]
+	}
+	c.peaks = append(c.peaks[:0], nodes...)
+	return nil
+}
+
+// SaveCheckpoint writes a checkpoint of the RootComputer (see MarshalBinary)
+// to the file at path. The checkpoint is written to a temporary file in the
+// same directory, synced and renamed to path, so that a crash while saving
+// leaves the previous checkpoint at path intact. The directory is synced after
+// the rename so that the new checkpoint survives a crash as well.
+func (c *RootComputer) SaveCheckpoint(path string) (err error) {
+	data, err := c.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir commits the entries of the directory at path, such as a file renamed
+// into it, to stable storage. Directories cannot be synced on Windows, where
+// renames are durable once they return.
+func syncDir(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		_ = d.Close()
+		return err
+	}
+	return d.Close()
+}
+
+// LoadCheckpoint restores the checkpoint saved to the file at path by
+// SaveCheckpoint, see UnmarshalBinary.
+func (c *RootComputer) LoadCheckpoint(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return c.UnmarshalBinary(data)
+}
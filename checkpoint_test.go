@@ -0,0 +1,129 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootComputer_Checkpoint(t *testing.T) {
+	for _, padding := range []PaddingStrategy{NoPadding, PadWithLastLeaf} {
+		opts := []Option{NamespaceIDSize(2), Padding(padding)}
+		leaves := make([][]byte, 40)
+		for i := range leaves {
+			leaves[i] = append([]byte{0, byte(i / 3)}, []byte(fmt.Sprintf("leaf_%d", i))...)
+		}
+		want := NewRootComputer(sha256.New(), opts...)
+		for _, leaf := range leaves {
+			require.NoError(t, want.Push(leaf))
+		}
+		wantRoot, err := want.Root()
+		require.NoError(t, err)
+
+		for _, stop := range []int{0, 1, 7, 16, 39} {
+			c := NewRootComputer(sha256.New(), opts...)
+			for _, leaf := range leaves[:stop] {
+				require.NoError(t, c.Push(leaf))
+			}
+			data, err := c.MarshalBinary()
+			require.NoError(t, err)
+
+			resumed := NewRootComputer(sha256.New(), opts...)
+			require.NoError(t, resumed.Push([]byte{0xFF, 0xFF}))
+			require.NoError(t, resumed.UnmarshalBinary(data))
+			assert.Equal(t, stop, resumed.Size())
+			if stop > 3 {
+				// the push order is restored as well
+				assert.ErrorIs(t, resumed.Push([]byte{0, 0}), ErrInvalidPushOrder)
+			}
+			for _, leaf := range leaves[stop:] {
+				require.NoError(t, resumed.Push(leaf))
+			}
+			got, err := resumed.Root()
+			require.NoError(t, err)
+			assert.Equal(t, wantRoot, got, "padding %d, stop %d", padding, stop)
+		}
+	}
+}
+
+func TestRootComputer_UnmarshalBinaryInvalid(t *testing.T) {
+	c := NewRootComputer(sha256.New(), NamespaceIDSize(1))
+	for _, leaf := range [][]byte{{1, 'a'}, {2, 'b'}, {3, 'c'}} {
+		require.NoError(t, c.Push(leaf))
+	}
+	data, err := c.MarshalBinary()
+	require.NoError(t, err)
+	wantRoot, err := c.Root()
+	require.NoError(t, err)
+
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)/2] ^= 1
+	tests := []struct {
+		name string
+		c    *RootComputer
+		data []byte
+	}{
+		{"empty", c, nil},
+		{"truncated", c, data[:len(data)-1]},
+		{"corrupted", c, corrupted},
+		{"namespace size", NewRootComputer(sha256.New(), NamespaceIDSize(2)), data},
+		{"ignore max", NewRootComputer(sha256.New(), NamespaceIDSize(1), IgnoreMaxNamespace(false)), data},
+		{"padding", NewRootComputer(sha256.New(), NamespaceIDSize(1), Padding(PadWithEmptyLeaves)), data},
+		{"fixed height", NewRootComputer(sha256.New(), NamespaceIDSize(1), FixedHeight(4)), data},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.c.UnmarshalBinary(tt.data), ErrInvalidCheckpoint)
+		})
+	}
+	// failed restores leave the computer unchanged
+	got, err := c.Root()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoot, got)
+}
+
+func TestRootComputer_CheckpointLargeSize(t *testing.T) {
+	if strconv.IntSize < 64 {
+		t.Skip("sizes beyond 2^31 do not fit into int")
+	}
+	c := NewRootComputer(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, c.Push([]byte{1, 'a'}))
+	// pretend that the single peak covers 2^32 leaves
+	size := uint64(1) << 32
+	c.size = int(size)
+	data, err := c.MarshalBinary()
+	require.NoError(t, err)
+
+	resumed := NewRootComputer(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, resumed.UnmarshalBinary(data))
+	assert.Equal(t, c.size, resumed.Size())
+}
+
+func TestRootComputer_SaveCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	c := NewRootComputer(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, c.Push([]byte{1, 'a'}))
+	require.NoError(t, c.SaveCheckpoint(path))
+	require.NoError(t, c.Push([]byte{2, 'b'}))
+	require.NoError(t, c.SaveCheckpoint(path))
+	want, err := c.Root()
+	require.NoError(t, err)
+
+	resumed := NewRootComputer(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, resumed.LoadCheckpoint(path))
+	got, err := resumed.Root()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.ErrorIs(t, resumed.LoadCheckpoint(path+".missing"), os.ErrNotExist)
+}
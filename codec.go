@@ -0,0 +1,170 @@
+package nmt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ErrInvalidEncoding indicates that a payload cannot be encoded into a leaf or
+// that a leaf is not a valid encoding.
+var ErrInvalidEncoding = errors.New("invalid leaf encoding")
+
+// LeafCodec translates between application payloads and the
+// namespace-prefixed leaves pushed to the tree. It allows applications with
+// structured leaf formats to encode and decode them consistently.
+type LeafCodec interface {
+	// Encode returns the namespace-prefixed leaf carrying payload in
+	// namespace nID.
+	Encode(nID namespace.ID, payload []byte) (namespace.PrefixedData, error)
+	// Decode returns the namespace ID and the payload of a leaf produced by
+	// Encode.
+	Decode(data namespace.PrefixedData) (nID namespace.ID, payload []byte, err error)
+}
+
+var (
+	_ LeafCodec = RawCodec{}
+	_ LeafCodec = ShareCodec{}
+)
+
+// RawCodec is the default LeafCodec, which prefixes the payload with the
+// namespace ID without any further structure.
+type RawCodec struct {
+	NamespaceSize namespace.IDSize
+}
+
+// Encode returns nID || payload.
+func (c RawCodec) Encode(nID namespace.ID, payload []byte) (namespace.PrefixedData, error) {
+	data, err := namespace.NewPrefixedDataFromParts(c.NamespaceSize, nID, payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMismatchedNamespaceSize, err)
+	}
+	return data, nil
+}
+
+// Decode splits data into its namespace ID and payload.
+func (c RawCodec) Decode(data namespace.PrefixedData) (namespace.ID, []byte, error) {
+	if len(data) < int(c.NamespaceSize) {
+		return nil, nil, fmt.Errorf("%w: got: %v, want >= %v", ErrInvalidLeafLen, len(data), c.NamespaceSize)
+	}
+	return data.NamespaceID(c.NamespaceSize), data.Data(c.NamespaceSize), nil
+}
+
+// shareInfoSize and shareSequenceLenSize are the sizes of the info byte and
+// the sequence length of a share.
+const (
+	shareInfoSize        = 1
+	shareSequenceLenSize = 4
+	// maxShareVersion is the largest version that fits into the upper seven
+	// bits of the info byte.
+	maxShareVersion = 127
+)
+
+// ShareCodec encodes payloads into fixed-size shares with the layout
+//
+//	nID || info byte || sequence length || payload || zero padding
+//
+// where the info byte holds Version in its upper seven bits and a set
+// sequence start flag in its lowest bit, and the sequence length is the
+// payload size as a big-endian uint32. Each payload must fit into a single
+// share.
+type ShareCodec struct {
+	NamespaceSize namespace.IDSize
+	// ShareSize is the size of each encoded leaf in bytes.
+	ShareSize int
+	// Version is the share version encoded into the info byte; it must be
+	// smaller than 128.
+	Version byte
+}
+
+// MaxPayloadSize returns the size of the largest payload that fits into a
+// share.
+func (c ShareCodec) MaxPayloadSize() int {
+	return c.ShareSize - int(c.NamespaceSize) - shareInfoSize - shareSequenceLenSize
+}
+
+// Encode returns the share carrying payload in namespace nID. It returns an
+// ErrInvalidEncoding error if the payload does not fit into a share or the
+// version of the codec does not fit into the info byte.
+func (c ShareCodec) Encode(nID namespace.ID, payload []byte) (namespace.PrefixedData, error) {
+	if len(nID) != int(c.NamespaceSize) {
+		return nil, fmt.Errorf("%w: got: %v, want: %v", ErrMismatchedNamespaceSize, len(nID), c.NamespaceSize)
+	}
+	if c.Version > maxShareVersion {
+		return nil, fmt.Errorf("%w: version %d exceeds the maximum of %d", ErrInvalidEncoding, c.Version, maxShareVersion)
+	}
+	if len(payload) > c.MaxPayloadSize() {
+		return nil, fmt.Errorf("%w: payload of %d bytes exceeds the maximum of %d", ErrInvalidEncoding, len(payload), c.MaxPayloadSize())
+	}
+	share := make([]byte, c.ShareSize)
+	offset := copy(share, nID)
+	share[offset] = c.Version<<1 | 1
+	offset += shareInfoSize
+	binary.BigEndian.PutUint32(share[offset:], uint32(len(payload)))
+	offset += shareSequenceLenSize
+	copy(share[offset:], payload)
+	return share, nil
+}
+
+// Decode returns the namespace ID and payload of a share, validating its
+// size, info byte, sequence length and padding.
+func (c ShareCodec) Decode(data namespace.PrefixedData) (namespace.ID, []byte, error) {
+	if len(data) != c.ShareSize {
+		return nil, nil, fmt.Errorf("%w: share of %d bytes, want %d", ErrInvalidEncoding, len(data), c.ShareSize)
+	}
+	if c.MaxPayloadSize() < 0 {
+		return nil, nil, fmt.Errorf("%w: share size %d is too small", ErrInvalidEncoding, c.ShareSize)
+	}
+	if c.Version > maxShareVersion {
+		return nil, nil, fmt.Errorf("%w: version %d exceeds the maximum of %d", ErrInvalidEncoding, c.Version, maxShareVersion)
+	}
+	nID := data.NamespaceID(c.NamespaceSize)
+	offset := int(c.NamespaceSize)
+	if info := data[offset]; info != c.Version<<1|1 {
+		return nil, nil, fmt.Errorf("%w: info byte %#x, want version %d with sequence start", ErrInvalidEncoding, info, c.Version)
+	}
+	offset += shareInfoSize
+	sequenceLen := binary.BigEndian.Uint32(data[offset:])
+	offset += shareSequenceLenSize
+	if uint64(sequenceLen) > uint64(c.MaxPayloadSize()) {
+		return nil, nil, fmt.Errorf("%w: sequence length %d exceeds the maximum of %d", ErrInvalidEncoding, sequenceLen, c.MaxPayloadSize())
+	}
+	payloadEnd := offset + int(sequenceLen)
+	for _, b := range data[payloadEnd:] {
+		if b != 0 {
+			return nil, nil, fmt.Errorf("%w: non-zero padding", ErrInvalidEncoding)
+		}
+	}
+	return nID, data[offset:payloadEnd], nil
+}
+
+// PushPayload encodes payload into a leaf of namespace nID using the tree's
+// LeafCodec (see the Codec option) and pushes it.
+func (n *NamespacedMerkleTree) PushPayload(nID namespace.ID, payload []byte) error {
+	leaf, err := n.codec.Encode(nID, payload)
+	if err != nil {
+		return err
+	}
+	return n.Push(leaf)
+}
+
+// GetPayloads returns the payloads of all leaves of namespace nID decoded
+// using the tree's LeafCodec. It returns an ErrLeafDataDropped error if the
+// tree drops leaf data.
+func (n *NamespacedMerkleTree) GetPayloads(nID namespace.ID) ([][]byte, error) {
+	if err := n.checkLeafData(); err != nil {
+		return nil, err
+	}
+	leaves := n.Get(nID)
+	payloads := make([][]byte, 0, len(leaves))
+	for i, leaf := range leaves {
+		_, payload, err := n.codec.Decode(leaf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode leaf %d of namespace %x: %w", i, nID, err)
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
@@ -0,0 +1,88 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestRawCodec(t *testing.T) {
+	c := RawCodec{NamespaceSize: 2}
+	leaf, err := c.Encode(namespace.ID{1, 2}, []byte("payload"))
+	require.NoError(t, err)
+	assert.Equal(t, namespace.PrefixedData("\x01\x02payload"), leaf)
+
+	nID, payload, err := c.Decode(leaf)
+	require.NoError(t, err)
+	assert.Equal(t, namespace.ID{1, 2}, nID)
+	assert.Equal(t, []byte("payload"), payload)
+
+	_, err = c.Encode(namespace.ID{1}, nil)
+	assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+	_, _, err = c.Decode([]byte{1})
+	assert.ErrorIs(t, err, ErrInvalidLeafLen)
+}
+
+func TestShareCodec(t *testing.T) {
+	c := ShareCodec{NamespaceSize: 2, ShareSize: 16, Version: 1}
+	assert.Equal(t, 9, c.MaxPayloadSize())
+
+	share, err := c.Encode(namespace.ID{0, 7}, []byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, namespace.PrefixedData{0, 7, 3, 0, 0, 0, 3, 'a', 'b', 'c', 0, 0, 0, 0, 0, 0}, share)
+	nID, payload, err := c.Decode(share)
+	require.NoError(t, err)
+	assert.Equal(t, namespace.ID{0, 7}, nID)
+	assert.Equal(t, []byte("abc"), payload)
+
+	_, err = c.Encode(namespace.ID{0, 7}, make([]byte, 10))
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+
+	// versions beyond seven bits would be truncated by the info byte
+	wide := ShareCodec{NamespaceSize: 2, ShareSize: 16, Version: 129}
+	_, err = wide.Encode(namespace.ID{0, 7}, []byte("abc"))
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+	_, _, err = wide.Decode(share)
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+
+	invalid := map[string]func(s []byte) []byte{
+		"wrong size":      func(s []byte) []byte { return s[:15] },
+		"wrong info byte": func(s []byte) []byte { s[2] = 2; return s },
+		"long sequence":   func(s []byte) []byte { s[6] = 10; return s },
+		"dirty padding":   func(s []byte) []byte { s[15] = 1; return s },
+	}
+	for name, modify := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := c.Decode(modify(append([]byte(nil), share...)))
+			assert.ErrorIs(t, err, ErrInvalidEncoding)
+		})
+	}
+}
+
+func TestPushPayload(t *testing.T) {
+	codec := ShareCodec{NamespaceSize: 1, ShareSize: 12}
+	tree := New(sha256.New(), NamespaceIDSize(1), Codec(codec))
+	require.NoError(t, tree.PushPayload(namespace.ID{1}, []byte("one")))
+	require.NoError(t, tree.PushPayload(namespace.ID{1}, []byte("two")))
+	require.NoError(t, tree.PushPayload(namespace.ID{2}, []byte("three")))
+	assert.ErrorIs(t, tree.PushPayload(namespace.ID{0}, nil), ErrInvalidPushOrder)
+
+	payloads, err := tree.GetPayloads(namespace.ID{1})
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("one"), []byte("two")}, payloads)
+	for _, leaf := range tree.Get(namespace.ID{1}) {
+		assert.Len(t, leaf, 12)
+	}
+
+	// the default codec only prefixes the namespace
+	tree = New(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, tree.PushPayload(namespace.ID{1}, []byte("one")))
+	assert.Equal(t, [][]byte{[]byte("\x01one")}, tree.Get(namespace.ID{1}))
+	payloads, err = tree.GetPayloads(namespace.ID{1})
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("one")}, payloads)
+}
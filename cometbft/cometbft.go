@@ -0,0 +1,171 @@
+// Package cometbft adapts namespaced Merkle trees to the Merkle tree and proof
+// interfaces of Tendermint/CometBFT's crypto/merkle package.
+// HashFromByteSlices and ProofsFromByteSlices mirror their CometBFT
+// counterparts, and NamespaceProofOp lets NMT namespace proofs travel inside
+// ProofOps.
+//
+// By default, the package does not depend on CometBFT: its ProofOp and
+// ProofOperator types only mirror the CometBFT ones and do not satisfy
+// CometBFT's interfaces. Built with the nmt_cometbft build tag, which requires
+// the main module to depend on github.com/cometbft/cometbft v0.38, the package
+// additionally provides MerkleProofOperator, which implements
+// merkle.ProofOperator, and RegisterOpDecoder, which registers the decoder of
+// NMT ProofOps with a merkle.ProofRuntime.
+package cometbft
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ProofOpNamespace is the type of the ProofOps carrying NMT namespace proofs.
+const ProofOpNamespace = "nmt:namespace"
+
+var (
+	// ErrInvalidProofOp indicates that a ProofOp cannot be decoded into a
+	// NamespaceProofOp.
+	ErrInvalidProofOp = errors.New("invalid NMT proof op")
+	// ErrVerificationFailed indicates that the leaves supplied to Run do not
+	// match the proof.
+	ErrVerificationFailed = errors.New("NMT proof op verification failed")
+)
+
+// ProofOp mirrors CometBFT's crypto.ProofOp. See MerkleProofOperator for the
+// conversion to the CometBFT type.
+type ProofOp struct {
+	Type string
+	Key  []byte
+	Data []byte
+}
+
+// ProofOperator mirrors CometBFT's merkle.ProofOperator, except that ProofOp
+// returns the mirrored ProofOp type.
+type ProofOperator interface {
+	Run([][]byte) ([][]byte, error)
+	GetKey() []byte
+	ProofOp() ProofOp
+}
+
+// HashFromByteSlices returns the NMT root of the namespace-prefixed items,
+// using SHA-256 like its CometBFT counterpart. The items must be sorted by
+// namespace ID.
+func HashFromByteSlices(items [][]byte, opts ...nmt.Option) ([]byte, error) {
+	tree, err := buildTree(items, opts)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Root()
+}
+
+// ProofsFromByteSlices returns the NMT root of the namespace-prefixed items
+// together with an inclusion proof for each item, using SHA-256.
+func ProofsFromByteSlices(items [][]byte, opts ...nmt.Option) (root []byte, proofs []nmt.Proof, err error) {
+	tree, err := buildTree(items, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if root, err = tree.Root(); err != nil {
+		return nil, nil, err
+	}
+	proofs = make([]nmt.Proof, len(items))
+	for i := range items {
+		if proofs[i], err = tree.Prove(i); err != nil {
+			return nil, nil, err
+		}
+	}
+	return root, proofs, nil
+}
+
+func buildTree(items [][]byte, opts []nmt.Option) (*nmt.NamespacedMerkleTree, error) {
+	tree := nmt.New(sha256.New(), opts...)
+	for i, item := range items {
+		if err := tree.Push(item); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	return tree, nil
+}
+
+// NamespaceProofOp is a ProofOperator for an NMT namespace proof. Its key is
+// the namespace ID. Run verifies that its arguments are the complete set of
+// namespace-prefixed leaves of the namespace in the tree with the root the op
+// was created for, and outputs that root. Chaining the op with
+// ProofOperators.Verify then checks the root against the trusted one.
+type NamespaceProofOp struct {
+	newHash func() hash.Hash
+	nID     namespace.ID
+	root    []byte
+	proof   nmt.Proof
+}
+
+var _ ProofOperator = NamespaceProofOp{}
+
+// NewNamespaceProofOp creates a NamespaceProofOp for the proof of namespace
+// nID in the tree with the given root. newHash must return the underlying
+// hash function of the tree.
+func NewNamespaceProofOp(newHash func() hash.Hash, nID namespace.ID, proof nmt.Proof, root []byte) NamespaceProofOp {
+	return NamespaceProofOp{newHash: newHash, nID: nID, root: root, proof: proof}
+}
+
+// Proof returns the wrapped namespace proof.
+func (op NamespaceProofOp) Proof() nmt.Proof {
+	return op.proof
+}
+
+// Run implements ProofOperator.
+func (op NamespaceProofOp) Run(leaves [][]byte) ([][]byte, error) {
+	if len(leaves) == 0 {
+		leaves = nil
+	}
+	if !op.proof.VerifyNamespace(op.newHash(), op.nID, leaves, op.root) {
+		return nil, fmt.Errorf("%w: namespace %x", ErrVerificationFailed, op.nID)
+	}
+	return [][]byte{op.root}, nil
+}
+
+// GetKey implements ProofOperator.
+func (op NamespaceProofOp) GetKey() []byte {
+	return op.nID
+}
+
+// ProofOp implements ProofOperator. The data of the returned ProofOp is the
+// uvarint length-prefixed root followed by the protobuf encoding of the proof.
+func (op NamespaceProofOp) ProofOp() ProofOp {
+	pbProof := op.proof.ToProto()
+	proof, err := pbProof.Marshal()
+	if err != nil { // encoding a proof into a buffer never fails
+		panic(err)
+	}
+	data := binary.AppendUvarint(nil, uint64(len(op.root)))
+	data = append(append(data, op.root...), proof...)
+	return ProofOp{Type: ProofOpNamespace, Key: op.nID, Data: data}
+}
+
+// NamespaceProofOpDecoder returns a decoder of ProofOps of type
+// ProofOpNamespace, e.g., for registration in a CometBFT ProofRuntime.
+func NamespaceProofOpDecoder(newHash func() hash.Hash) func(ProofOp) (ProofOperator, error) {
+	return func(pop ProofOp) (ProofOperator, error) {
+		if pop.Type != ProofOpNamespace {
+			return nil, fmt.Errorf("%w: unexpected type %q", ErrInvalidProofOp, pop.Type)
+		}
+		rootLen, n := binary.Uvarint(pop.Data)
+		if n <= 0 || rootLen > uint64(len(pop.Data)-n) {
+			return nil, fmt.Errorf("%w: malformed root", ErrInvalidProofOp)
+		}
+		root := bytes.Clone(pop.Data[n : n+int(rootLen)])
+		// GobDecode decodes the protobuf encoding of the proof and validates
+		// its range
+		var proof nmt.Proof
+		if err := proof.GobDecode(pop.Data[n+int(rootLen):]); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidProofOp, err)
+		}
+		return NewNamespaceProofOp(newHash, bytes.Clone(pop.Key), proof, root), nil
+	}
+}
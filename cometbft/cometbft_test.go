@@ -0,0 +1,99 @@
+package cometbft
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func exampleItems() [][]byte {
+	return [][]byte{{1, 'a'}, {1, 'b'}, {3, 'c'}, {5, 'd'}, {5, 'e'}}
+}
+
+func TestHashFromByteSlices(t *testing.T) {
+	items := exampleItems()
+	root, err := HashFromByteSlices(items, nmt.NamespaceIDSize(1))
+	require.NoError(t, err)
+
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(1))
+	for _, item := range items {
+		require.NoError(t, tree.Push(item))
+	}
+	want, err := tree.Root()
+	require.NoError(t, err)
+	assert.Equal(t, want, root)
+
+	_, err = HashFromByteSlices([][]byte{{2}, {1}}, nmt.NamespaceIDSize(1))
+	assert.ErrorIs(t, err, nmt.ErrInvalidPushOrder)
+}
+
+func TestProofsFromByteSlices(t *testing.T) {
+	items := exampleItems()
+	root, proofs, err := ProofsFromByteSlices(items, nmt.NamespaceIDSize(1))
+	require.NoError(t, err)
+	require.Len(t, proofs, len(items))
+	for i, proof := range proofs {
+		assert.True(t, proof.VerifyInclusion(sha256.New(), items[i][:1], [][]byte{items[i][1:]}, root))
+	}
+}
+
+// verify mirrors ProofOperators.Verify of CometBFT for a single operator.
+func verify(op ProofOperator, root []byte, args [][]byte) error {
+	out, err := op.Run(args)
+	if err != nil {
+		return err
+	}
+	if len(out) != 1 || string(out[0]) != string(root) {
+		return ErrVerificationFailed
+	}
+	return nil
+}
+
+func TestNamespaceProofOp(t *testing.T) {
+	items := exampleItems()
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(1))
+	for _, item := range items {
+		require.NoError(t, tree.Push(item))
+	}
+	root, err := tree.Root()
+	require.NoError(t, err)
+	decode := NamespaceProofOpDecoder(sha256.New)
+
+	for _, nID := range []namespace.ID{{1}, {2}, {5}} {
+		proof, err := tree.ProveNamespace(nID)
+		require.NoError(t, err)
+		op := NewNamespaceProofOp(sha256.New, nID, proof, root)
+
+		pop := op.ProofOp()
+		assert.Equal(t, ProofOpNamespace, pop.Type)
+		assert.Equal(t, []byte(nID), pop.Key)
+		decoded, err := decode(pop)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(nID), decoded.GetKey())
+
+		leaves := tree.Get(nID)
+		require.NoError(t, verify(decoded, root, leaves))
+		if len(leaves) > 0 {
+			assert.ErrorIs(t, verify(decoded, root, leaves[:len(leaves)-1]), ErrVerificationFailed)
+		}
+		// an op created for a different root does not verify against the
+		// trusted one
+		other := NewNamespaceProofOp(sha256.New, nID, proof, tree.Get(namespace.ID{1})[0])
+		assert.Error(t, verify(other, root, leaves))
+	}
+}
+
+func TestNamespaceProofOpDecoder_Invalid(t *testing.T) {
+	decode := NamespaceProofOpDecoder(sha256.New)
+	_, err := decode(ProofOp{Type: "simple:v"})
+	assert.ErrorIs(t, err, ErrInvalidProofOp)
+	_, err = decode(ProofOp{Type: ProofOpNamespace, Data: []byte{10, 1}})
+	assert.ErrorIs(t, err, ErrInvalidProofOp)
+	_, err = decode(ProofOp{Type: ProofOpNamespace, Data: []byte{1, 1, 0xFF}})
+	assert.ErrorIs(t, err, ErrInvalidProofOp)
+}
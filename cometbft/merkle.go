@@ -0,0 +1,45 @@
+//go:build nmt_cometbft
+
+package cometbft
+
+import (
+	"hash"
+
+	"github.com/cometbft/cometbft/crypto/merkle"
+	cmtcrypto "github.com/cometbft/cometbft/proto/tendermint/crypto"
+)
+
+// MerkleProofOperator wraps a NamespaceProofOp to implement CometBFT's
+// merkle.ProofOperator, whose ProofOp method returns CometBFT's own ProofOp
+// type. It is only built with the nmt_cometbft build tag.
+type MerkleProofOperator struct {
+	NamespaceProofOp
+}
+
+var _ merkle.ProofOperator = MerkleProofOperator{}
+
+// ProofOp implements merkle.ProofOperator.
+func (op MerkleProofOperator) ProofOp() cmtcrypto.ProofOp {
+	pop := op.NamespaceProofOp.ProofOp()
+	return cmtcrypto.ProofOp{Type: pop.Type, Key: pop.Key, Data: pop.Data}
+}
+
+// MerkleOpDecoder returns a merkle.OpDecoder of ProofOps of type
+// ProofOpNamespace, see NamespaceProofOpDecoder.
+func MerkleOpDecoder(newHash func() hash.Hash) merkle.OpDecoder {
+	decode := NamespaceProofOpDecoder(newHash)
+	return func(pop cmtcrypto.ProofOp) (merkle.ProofOperator, error) {
+		op, err := decode(ProofOp{Type: pop.Type, Key: pop.Key, Data: pop.Data})
+		if err != nil {
+			return nil, err
+		}
+		return MerkleProofOperator{op.(NamespaceProofOp)}, nil
+	}
+}
+
+// RegisterOpDecoder registers the decoder of ProofOps of type ProofOpNamespace
+// with prt, so that prt can verify ProofOps chains including NMT namespace
+// proofs.
+func RegisterOpDecoder(prt *merkle.ProofRuntime, newHash func() hash.Hash) {
+	prt.RegisterOpDecoder(ProofOpNamespace, MerkleOpDecoder(newHash))
+}
@@ -0,0 +1,42 @@
+//go:build nmt_cometbft
+
+package cometbft
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/cometbft/cometbft/crypto/merkle"
+	cmtcrypto "github.com/cometbft/cometbft/proto/tendermint/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestProofRuntime(t *testing.T) {
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(1))
+	for _, item := range exampleItems() {
+		require.NoError(t, tree.Push(item))
+	}
+	root, err := tree.Root()
+	require.NoError(t, err)
+	prt := merkle.NewProofRuntime()
+	RegisterOpDecoder(prt, sha256.New)
+
+	for _, nID := range []namespace.ID{{1}, {2}, {5}} {
+		proof, err := tree.ProveNamespace(nID)
+		require.NoError(t, err)
+		op := MerkleProofOperator{NewNamespaceProofOp(sha256.New, nID, proof, root)}
+		ops, err := prt.DecodeProof(&cmtcrypto.ProofOps{Ops: []cmtcrypto.ProofOp{op.ProofOp()}})
+		require.NoError(t, err)
+
+		keypath := merkle.KeyPath{}.AppendKey(nID, merkle.KeyEncodingHex).String()
+		leaves := tree.Get(nID)
+		require.NoError(t, ops.Verify(root, keypath, leaves))
+		if len(leaves) > 0 {
+			assert.Error(t, ops.Verify(root, keypath, leaves[:len(leaves)-1]))
+		}
+	}
+}
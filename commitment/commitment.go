@@ -0,0 +1,130 @@
+// Package commitment computes blob commitments from NMT subtree roots. The
+// shares of a blob are split into chunks of at most SubtreeWidth shares
+// following a Merkle mountain range, an NMT root is computed for each chunk,
+// and the commitment is the RFC 6962 Merkle root over these subtree roots.
+package commitment
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"math"
+	"math/bits"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+var (
+	// ErrNoShares indicates that a commitment was requested for zero shares.
+	ErrNoShares = errors.New("no shares")
+	// ErrInvalidThreshold indicates a non-positive subtree root threshold.
+	ErrInvalidThreshold = errors.New("invalid subtree root threshold")
+)
+
+// SubtreeWidth returns the maximum number of shares covered by a single
+// subtree root of a blob with shareCount shares: the number of shares per
+// root needed to stay within threshold roots, rounded up to a power of two,
+// but no larger than the width of the smallest square the blob fits into.
+func SubtreeWidth(shareCount, threshold int) int {
+	width := (shareCount + threshold - 1) / threshold
+	return min(roundUpPowerOfTwo(width), minSquareSize(shareCount))
+}
+
+// MountainRangeSizes splits total into a sequence of descending powers of two,
+// none of which exceeds maxTreeSize, that sum up to total.
+func MountainRangeSizes(total, maxTreeSize int) []int {
+	var sizes []int
+	for total > 0 {
+		size := min(1<<(bits.Len(uint(total))-1), maxTreeSize)
+		sizes = append(sizes, size)
+		total -= size
+	}
+	return sizes
+}
+
+// SubtreeRoots returns the NMT roots of the chunks of shares of namespace nID,
+// chunked according to MountainRangeSizes and SubtreeWidth. Each share is
+// prefixed with nID before being pushed to a tree created with newHash and
+// opts; the namespace size is set to the size of nID.
+func SubtreeRoots(newHash func() hash.Hash, nID namespace.ID, shares [][]byte, threshold int, opts ...nmt.Option) ([][]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrNoShares
+	}
+	if threshold <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidThreshold, threshold)
+	}
+	opts = append(opts[:len(opts):len(opts)], nmt.NamespaceIDSize(int(nID.Size())))
+
+	sizes := MountainRangeSizes(len(shares), SubtreeWidth(len(shares), threshold))
+	roots := make([][]byte, 0, len(sizes))
+	// a single tree (and hash state) is reused for all subtrees
+	tree := nmt.New(newHash(), opts...)
+	start := 0
+	for _, size := range sizes {
+		tree.Reset()
+		for _, share := range shares[start : start+size] {
+			leaf := make([]byte, 0, len(nID)+len(share))
+			leaf = append(append(leaf, nID...), share...)
+			if err := tree.Push(leaf); err != nil {
+				return nil, err
+			}
+		}
+		root, err := tree.Root()
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, root)
+		start += size
+	}
+	return roots, nil
+}
+
+// Create returns the commitment to the shares of namespace nID, i.e., the
+// RFC 6962 Merkle root (using newHash) over their SubtreeRoots.
+func Create(newHash func() hash.Hash, nID namespace.ID, shares [][]byte, threshold int, opts ...nmt.Option) ([]byte, error) {
+	roots, err := SubtreeRoots(newHash, nID, shares, threshold, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return merkleRoot(newHash(), roots), nil
+}
+
+// merkleRoot returns the RFC 6962 Merkle root of items. h is reset before
+// each use and shared by all nodes.
+func merkleRoot(h hash.Hash, items [][]byte) []byte {
+	switch len(items) {
+	case 0:
+		h.Reset()
+		return h.Sum(nil)
+	case 1:
+		h.Reset()
+		h.Write([]byte{nmt.LeafPrefix})
+		h.Write(items[0])
+		return h.Sum(nil)
+	default:
+		k := 1 << (bits.Len(uint(len(items)-1)) - 1)
+		left := merkleRoot(h, items[:k])
+		right := merkleRoot(h, items[k:])
+		h.Reset()
+		h.Write([]byte{nmt.NodePrefix})
+		h.Write(left)
+		h.Write(right)
+		return h.Sum(nil)
+	}
+}
+
+// minSquareSize returns the width of the smallest square with a power of two
+// width that fits shareCount shares.
+func minSquareSize(shareCount int) int {
+	return roundUpPowerOfTwo(int(math.Ceil(math.Sqrt(float64(shareCount)))))
+}
+
+// roundUpPowerOfTwo returns the smallest power of two that is greater than or
+// equal to n, and 1 for n <= 1.
+func roundUpPowerOfTwo(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
@@ -0,0 +1,97 @@
+package commitment
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestSubtreeWidth(t *testing.T) {
+	tests := []struct {
+		shareCount, threshold, want int
+	}{
+		{1, 64, 1},
+		{64, 64, 1},
+		{65, 64, 2},
+		{128, 64, 2},
+		{129, 64, 4},
+		{2, 1, 2},
+		{3, 1, 2},
+		{17, 1, 8},
+		{1000, 8, 32},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, SubtreeWidth(tt.shareCount, tt.threshold), "%d shares, threshold %d", tt.shareCount, tt.threshold)
+	}
+}
+
+func TestMountainRangeSizes(t *testing.T) {
+	assert.Equal(t, []int{4, 4, 2, 1}, MountainRangeSizes(11, 4))
+	assert.Equal(t, []int{8, 2, 1}, MountainRangeSizes(11, 8))
+	assert.Equal(t, []int{1, 1, 1}, MountainRangeSizes(3, 1))
+	assert.Empty(t, MountainRangeSizes(0, 4))
+}
+
+func exampleShares(count int) [][]byte {
+	shares := make([][]byte, count)
+	for i := range shares {
+		shares[i] = []byte(fmt.Sprintf("share_%d", i))
+	}
+	return shares
+}
+
+func TestSubtreeRoots(t *testing.T) {
+	nID := namespace.ID{0, 0, 7}
+	shares := exampleShares(11)
+	roots, err := SubtreeRoots(sha256.New, nID, shares, 2)
+	require.NoError(t, err)
+	// the subtree width is 4, hence the chunks [0, 4), [4, 8), [8, 10), [10, 11)
+	require.Len(t, roots, 4)
+
+	// the roots equal the corresponding subtree roots of a tree over all shares
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(3))
+	for _, share := range shares {
+		require.NoError(t, tree.Push(append(append([]byte{}, nID...), share...)))
+	}
+	for i, rng := range []nmt.LeafRange{{Start: 0, End: 4}, {Start: 4, End: 8}, {Start: 8, End: 10}, {Start: 10, End: 11}} {
+		want, err := tree.ComputeSubtreeRoot(rng.Start, rng.End)
+		require.NoError(t, err)
+		assert.Equal(t, want, roots[i], "range %v", rng)
+	}
+
+	_, err = SubtreeRoots(sha256.New, nID, nil, 2)
+	assert.ErrorIs(t, err, ErrNoShares)
+	_, err = SubtreeRoots(sha256.New, nID, shares, 0)
+	assert.ErrorIs(t, err, ErrInvalidThreshold)
+}
+
+func TestCreate(t *testing.T) {
+	nID := namespace.ID{0, 0, 7}
+	shares := exampleShares(11)
+	commitment, err := Create(sha256.New, nID, shares, 2)
+	require.NoError(t, err)
+	assert.Len(t, commitment, sha256.Size)
+
+	roots, err := SubtreeRoots(sha256.New, nID, shares, 2)
+	require.NoError(t, err)
+	assert.Equal(t, merkleRoot(sha256.New(), roots), commitment)
+
+	// a single subtree root is committed to as a single RFC 6962 leaf
+	h := sha256.New()
+	h.Write([]byte{nmt.LeafPrefix})
+	h.Write(roots[0])
+	assert.Equal(t, h.Sum(nil), merkleRoot(sha256.New(), roots[:1]))
+
+	other, err := Create(sha256.New, nID, exampleShares(12), 2)
+	require.NoError(t, err)
+	assert.NotEqual(t, commitment, other)
+	other, err = Create(sha256.New, namespace.ID{0, 0, 8}, shares, 2)
+	require.NoError(t, err)
+	assert.NotEqual(t, commitment, other)
+}
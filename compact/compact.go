@@ -0,0 +1,454 @@
+// Package compact implements a variant of the namespaced Merkle tree whose
+// inner nodes carry namespace ranges truncated to a prefix of the namespace
+// IDs, which shrinks proofs dominated by the 2*NamespaceSize bytes of flags per
+// node.
+//
+// Leaves are hashed with full namespace flags,
+//
+//	leaf  = nID || nID || h(0x10 || ndata)
+//	inner = min[:p] || max[:p] || h(0x11 || left || right)
+//
+// where p is the prefix size, min[:p] is the prefix of the minimum namespace ID
+// of the left child and max[:p] the prefix of the maximum namespace ID of the
+// right child. The tree has the shape of RFC 6962 trees like the NMT. A prefix
+// size of 0 removes the flags from inner nodes altogether, a prefix size equal
+// to the namespace size keeps them complete.
+//
+// Truncated ranges cannot always exclude a namespace: an inner node whose
+// prefix range contains the prefix of a namespace ID might still not contain
+// the namespace. Proofs therefore replace such nodes by their children, down
+// to the leaves if necessary, see Proof.
+//
+// The scheme is not compatible with the NMT: roots are prefixed with Version,
+// and the hashes use domain separation bytes distinct from nmt.LeafPrefix and
+// nmt.NodePrefix, so that nodes of one scheme are never mistaken for nodes of
+// the other. The maximum namespace ID is not treated specially.
+package compact
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"math/bits"
+	"sort"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// Version identifies the scheme and is the first byte of every root.
+const Version byte = 1
+
+const (
+	// LeafPrefix is the domain separation byte prepended to leaf data before
+	// hashing.
+	LeafPrefix = 0x10
+	// NodePrefix is the domain separation byte prepended to the concatenated
+	// children of an inner node before hashing.
+	NodePrefix = 0x11
+)
+
+// ErrInvalidRoot indicates that a root is not a root of this scheme.
+var ErrInvalidRoot = errors.New("invalid compact root")
+
+// Hasher computes the nodes of trees whose inner nodes carry namespace ranges
+// truncated to PrefixSize bytes. It is not safe for concurrent use.
+type Hasher struct {
+	baseHasher hash.Hash
+	nidSize    int
+	prefixSize int
+}
+
+// NewHasher returns a hasher for namespace IDs of size nidSize whose inner
+// nodes keep the first prefixSize bytes of their namespace range. It panics if
+// prefixSize is not within [0, nidSize].
+func NewHasher(h hash.Hash, nidSize namespace.IDSize, prefixSize int) *Hasher {
+	if prefixSize < 0 || prefixSize > int(nidSize) {
+		panic("Got invalid prefix size. Expected 0 <= prefixSize <= nidSize.")
+	}
+	return &Hasher{baseHasher: h, nidSize: int(nidSize), prefixSize: prefixSize}
+}
+
+// NamespaceSize returns the size of namespace IDs in bytes.
+func (h *Hasher) NamespaceSize() namespace.IDSize {
+	return namespace.IDSize(h.nidSize)
+}
+
+// PrefixSize returns the number of bytes of the namespace range kept by inner
+// nodes.
+func (h *Hasher) PrefixSize() int {
+	return h.prefixSize
+}
+
+// LeafSize returns the size of leaf nodes in bytes.
+func (h *Hasher) LeafSize() int {
+	return 2*h.nidSize + h.baseHasher.Size()
+}
+
+// InnerSize returns the size of inner nodes in bytes.
+func (h *Hasher) InnerSize() int {
+	return 2*h.prefixSize + h.baseHasher.Size()
+}
+
+// EmptyRoot returns the root of a tree without leaves.
+func (h *Hasher) EmptyRoot() []byte {
+	h.baseHasher.Reset()
+	root := append([]byte{Version}, make([]byte, 2*h.prefixSize)...)
+	return h.baseHasher.Sum(root)
+}
+
+// HashLeaf returns the leaf node of the namespace-prefixed data ndata. It
+// returns an nmt.ErrInvalidLeafLen error if ndata is shorter than the
+// namespace size.
+func (h *Hasher) HashLeaf(ndata []byte) ([]byte, error) {
+	if len(ndata) < h.nidSize {
+		return nil, fmt.Errorf("%w: got: %v, want >= %v", nmt.ErrInvalidLeafLen, len(ndata), h.nidSize)
+	}
+	nID := ndata[:h.nidSize]
+	h.baseHasher.Reset()
+	h.baseHasher.Write([]byte{LeafPrefix})
+	h.baseHasher.Write(ndata)
+	node := make([]byte, 0, h.LeafSize())
+	node = append(append(node, nID...), nID...)
+	return h.baseHasher.Sum(node), nil
+}
+
+// HashNode returns the inner node with the children left and right, each of
+// which may be a leaf or an inner node. It returns an nmt.ErrInvalidNodeLen
+// error if a child has neither the size of a leaf nor of an inner node, and an
+// nmt.ErrUnorderedSiblings error if the namespace range of left does not
+// precede the one of right.
+func (h *Hasher) HashNode(left, right []byte) ([]byte, error) {
+	if !h.isNode(left) || !h.isNode(right) {
+		return nil, fmt.Errorf("%w: got children of %d and %d bytes, want %d or %d", nmt.ErrInvalidNodeLen, len(left), len(right), h.LeafSize(), h.InnerSize())
+	}
+	leftMax, rightMin := h.maxOf(left), h.minOf(right)
+	if n := minInt(len(leftMax), len(rightMin)); bytes.Compare(leftMax[:n], rightMin[:n]) > 0 {
+		return nil, fmt.Errorf("%w: %x > %x", nmt.ErrUnorderedSiblings, leftMax, rightMin)
+	}
+	h.baseHasher.Reset()
+	h.baseHasher.Write([]byte{NodePrefix})
+	h.baseHasher.Write(left)
+	h.baseHasher.Write(right)
+	node := make([]byte, 0, h.InnerSize())
+	node = append(append(node, h.minOf(left)[:h.prefixSize]...), h.maxOf(right)[:h.prefixSize]...)
+	return h.baseHasher.Sum(node), nil
+}
+
+// isNode reports whether node has the size of a leaf or an inner node.
+func (h *Hasher) isNode(node []byte) bool {
+	return len(node) == h.LeafSize() || len(node) == h.InnerSize()
+}
+
+// isLeaf reports whether node, which must be a valid node, is a leaf. Leaves
+// and inner nodes are indistinguishable if the prefix size equals the
+// namespace size, which is fine as both carry complete namespace ranges then.
+func (h *Hasher) isLeaf(node []byte) bool {
+	return len(node) == h.LeafSize()
+}
+
+// minOf returns the (possibly truncated) minimum namespace ID of node.
+func (h *Hasher) minOf(node []byte) []byte {
+	if h.isLeaf(node) {
+		return node[:h.nidSize]
+	}
+	return node[:h.prefixSize]
+}
+
+// maxOf returns the (possibly truncated) maximum namespace ID of node.
+func (h *Hasher) maxOf(node []byte) []byte {
+	if h.isLeaf(node) {
+		return node[h.nidSize : 2*h.nidSize]
+	}
+	return node[h.prefixSize : 2*h.prefixSize]
+}
+
+// precedes reports whether the namespace range of node provably lies before
+// nID, i.e., whether its (truncated) maximum namespace ID is smaller than the
+// corresponding prefix of nID.
+func (h *Hasher) precedes(node []byte, nID namespace.ID) bool {
+	nodeMax := h.maxOf(node)
+	return bytes.Compare(nodeMax, nID[:len(nodeMax)]) < 0
+}
+
+// follows reports whether the namespace range of node provably lies after
+// nID.
+func (h *Hasher) follows(node []byte, nID namespace.ID) bool {
+	nodeMin := h.minOf(node)
+	return bytes.Compare(nodeMin, nID[:len(nodeMin)]) > 0
+}
+
+// Tree is a namespaced Merkle tree of the compact scheme. It is not safe for
+// concurrent use.
+type Tree struct {
+	treeHasher *Hasher
+	leaves     [][]byte
+	leafHashes [][]byte
+}
+
+// New returns an empty tree using the given hasher.
+func New(h *Hasher) *Tree {
+	return &Tree{treeHasher: h}
+}
+
+// Size returns the number of leaves in the tree.
+func (t *Tree) Size() int {
+	return len(t.leaves)
+}
+
+// Push adds the namespace-prefixed data as the next leaf. It returns an
+// nmt.ErrInvalidLeafLen error if the data is shorter than the namespace size
+// and an nmt.ErrInvalidPushOrder error if its namespace ID is smaller than the
+// one of the previous leaf.
+func (t *Tree) Push(namespacedData namespace.PrefixedData) error {
+	leafHash, err := t.treeHasher.HashLeaf(namespacedData)
+	if err != nil {
+		return err
+	}
+	nID := namespacedData.NamespaceID(t.treeHasher.NamespaceSize())
+	if size := t.Size(); size > 0 {
+		if last := t.leafNamespace(size - 1); nID.Less(last) {
+			return fmt.Errorf("%w: last namespace: %x, pushed: %x", nmt.ErrInvalidPushOrder, last, nID)
+		}
+	}
+	t.leaves = append(t.leaves, bytes.Clone(namespacedData))
+	t.leafHashes = append(t.leafHashes, leafHash)
+	return nil
+}
+
+// Root returns the root of the tree, i.e., Version followed by the node
+// covering all leaves.
+func (t *Tree) Root() ([]byte, error) {
+	if t.Size() == 0 {
+		return t.treeHasher.EmptyRoot(), nil
+	}
+	node, err := t.subtreeRoot(0, t.Size())
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{Version}, node...), nil
+}
+
+// Get returns the leaves of the namespace nID.
+func (t *Tree) Get(nID namespace.ID) [][]byte {
+	start, end := t.namespaceRange(nID)
+	return t.leaves[start:end]
+}
+
+// ProveNamespace returns a proof of the leaves of the namespace nID, or of
+// its absence if the tree has no such leaves. It returns an
+// nmt.ErrMismatchedNamespaceSize error if nID has the wrong size.
+func (t *Tree) ProveNamespace(nID namespace.ID) (Proof, error) {
+	if len(nID) != t.treeHasher.nidSize {
+		return Proof{}, fmt.Errorf("%w: got: %v, want: %v", nmt.ErrMismatchedNamespaceSize, len(nID), t.treeHasher.nidSize)
+	}
+	start, end := t.namespaceRange(nID)
+	proof := Proof{Size: t.Size(), Start: start, End: end}
+	if proof.Size == 0 {
+		return proof, nil
+	}
+	if err := t.prove(&proof, nID, 0, t.Size()); err != nil {
+		return Proof{}, err
+	}
+	return proof, nil
+}
+
+// namespaceRange returns the range of the leaves of the namespace nID, which
+// is empty and starts at the position nID would be inserted at if the tree
+// has no such leaves.
+func (t *Tree) namespaceRange(nID namespace.ID) (start, end int) {
+	start = sort.Search(t.Size(), func(i int) bool { return !t.leafNamespace(i).Less(nID) })
+	end = sort.Search(t.Size(), func(i int) bool { return nID.Less(t.leafNamespace(i)) })
+	return start, end
+}
+
+// prove adds the nodes proving the subtree [start, end) to proof, replacing
+// the subtrees outside [proof.Start, proof.End) whose truncated namespace
+// range does not exclude nID by their children.
+func (t *Tree) prove(proof *Proof, nID namespace.ID, start, end int) error {
+	if start >= proof.End || end <= proof.Start {
+		node, err := t.subtreeRoot(start, end)
+		if err != nil {
+			return err
+		}
+		if end-start > 1 {
+			expand := end <= proof.Start && !t.treeHasher.precedes(node, nID) ||
+				start >= proof.End && !t.treeHasher.follows(node, nID)
+			proof.Expanded = append(proof.Expanded, expand)
+			if !expand {
+				proof.Nodes = append(proof.Nodes, node)
+				return nil
+			}
+		} else {
+			proof.Nodes = append(proof.Nodes, node)
+			return nil
+		}
+	} else if end-start == 1 {
+		return nil
+	}
+	k := splitPoint(end - start)
+	if err := t.prove(proof, nID, start, start+k); err != nil {
+		return err
+	}
+	return t.prove(proof, nID, start+k, end)
+}
+
+// subtreeRoot returns the node covering the leaves [start, end).
+func (t *Tree) subtreeRoot(start, end int) ([]byte, error) {
+	if end-start == 1 {
+		return t.leafHashes[start], nil
+	}
+	k := splitPoint(end - start)
+	left, err := t.subtreeRoot(start, start+k)
+	if err != nil {
+		return nil, err
+	}
+	right, err := t.subtreeRoot(start+k, end)
+	if err != nil {
+		return nil, err
+	}
+	return t.treeHasher.HashNode(left, right)
+}
+
+func (t *Tree) leafNamespace(i int) namespace.ID {
+	return namespace.ID(t.leafHashes[i][:t.treeHasher.nidSize])
+}
+
+// Proof proves the leaves of a namespace, or its absence, in a tree of the
+// compact scheme.
+type Proof struct {
+	// Size is the number of leaves of the tree.
+	Size int
+	// Start and End denote the range [Start, End) of the leaves of the
+	// namespace. For proofs of absence, the range is empty and starts at the
+	// index the namespace would be inserted at.
+	Start int
+	End   int
+	// Nodes holds the nodes covering the leaves outside [Start, End), from
+	// left to right. Each node provably lies before or after the namespace.
+	Nodes [][]byte
+	// Expanded holds one bit per subtree of more than one leaf outside
+	// [Start, End) that is visited in depth-first order, telling whether the
+	// subtree is represented by its root in Nodes or, because its truncated
+	// namespace range does not exclude the namespace, by its two children.
+	Expanded []bool
+}
+
+// VerifyNamespace checks that leaves are all leaves of the namespace nID in
+// the tree with the given root, i.e., that the namespace is absent if leaves
+// is empty. h is the base hash function and prefixSize the prefix size of the
+// tree. Roots of another scheme or version fail verification.
+func (p Proof) VerifyNamespace(h hash.Hash, nID namespace.ID, prefixSize int, leaves [][]byte, root []byte) bool {
+	if prefixSize < 0 || prefixSize > len(nID) || len(nID) > namespace.IDMaxSize {
+		return false
+	}
+	nth := NewHasher(h, namespace.IDSize(len(nID)), prefixSize)
+	if p.Start < 0 || p.Start > p.End || p.End > p.Size || p.End-p.Start != len(leaves) {
+		return false
+	}
+	rootNode, err := ParseRoot(nth, root)
+	if err != nil {
+		return false
+	}
+	if p.Size == 0 {
+		return len(p.Nodes) == 0 && len(p.Expanded) == 0 && bytes.Equal(root, nth.EmptyRoot())
+	}
+	leafHashes := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		if len(leaf) < len(nID) || !nID.Equal(leaf[:len(nID)]) {
+			return false
+		}
+		leafHash, err := nth.HashLeaf(leaf)
+		if err != nil {
+			return false
+		}
+		leafHashes[i] = leafHash
+	}
+	v := verifier{proof: p, nth: nth, nID: nID, leafHashes: leafHashes}
+	node, err := v.subtreeRoot(0, p.Size)
+	if err != nil || len(v.proof.Nodes) != 0 || len(v.proof.Expanded) != 0 {
+		return false
+	}
+	return bytes.Equal(rootNode, node)
+}
+
+// ParseRoot splits root into the scheme version and the node covering all
+// leaves. It returns an ErrInvalidRoot error if root is not a root of the
+// current version of the scheme for the given hasher.
+func ParseRoot(h *Hasher, root []byte) ([]byte, error) {
+	if len(root) == 0 || root[0] != Version {
+		return nil, fmt.Errorf("%w: unknown version", ErrInvalidRoot)
+	}
+	if node := root[1:]; h.isNode(node) {
+		return node, nil
+	}
+	return nil, fmt.Errorf("%w: got %d bytes", ErrInvalidRoot, len(root))
+}
+
+// errInvalidProof is returned by the verifier for any malformed proof.
+var errInvalidProof = errors.New("invalid compact proof")
+
+// verifier recomputes the root of a tree from a proof, consuming its nodes
+// and expansion bits.
+type verifier struct {
+	proof      Proof
+	nth        *Hasher
+	nID        namespace.ID
+	leafHashes [][]byte
+}
+
+// subtreeRoot returns the node covering the leaves [start, end).
+func (v *verifier) subtreeRoot(start, end int) ([]byte, error) {
+	before, after := end <= v.proof.Start, start >= v.proof.End
+	switch {
+	case end-start == 1 && !before && !after:
+		return v.leafHashes[start-v.proof.Start], nil
+	case end-start == 1 || before || after:
+		if end-start > 1 {
+			if len(v.proof.Expanded) == 0 {
+				return nil, errInvalidProof
+			}
+			expand := v.proof.Expanded[0]
+			v.proof.Expanded = v.proof.Expanded[1:]
+			if expand {
+				break
+			}
+		}
+		if len(v.proof.Nodes) == 0 {
+			return nil, errInvalidProof
+		}
+		node := v.proof.Nodes[0]
+		v.proof.Nodes = v.proof.Nodes[1:]
+		if !v.nth.isNode(node) || end-start == 1 && !v.nth.isLeaf(node) {
+			return nil, errInvalidProof
+		}
+		// the node must exclude the namespace, see Proof.Nodes
+		if before && !v.nth.precedes(node, v.nID) || after && !v.nth.follows(node, v.nID) {
+			return nil, errInvalidProof
+		}
+		return node, nil
+	}
+	k := splitPoint(end - start)
+	left, err := v.subtreeRoot(start, start+k)
+	if err != nil {
+		return nil, err
+	}
+	right, err := v.subtreeRoot(start+k, end)
+	if err != nil {
+		return nil, err
+	}
+	return v.nth.HashNode(left, right)
+}
+
+// splitPoint returns the largest power of two smaller than n, see RFC 6962.
+func splitPoint(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,188 @@
+package compact
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+	"github.com/celestiaorg/nmt/nmttest"
+)
+
+func buildTree(t *testing.T, prefixSize int, leaves [][]byte) (*Tree, []byte) {
+	t.Helper()
+	tree := New(NewHasher(sha256.New(), 8, prefixSize))
+	for _, leaf := range leaves {
+		require.NoError(t, tree.Push(leaf))
+	}
+	root, err := tree.Root()
+	require.NoError(t, err)
+	return tree, root
+}
+
+func TestProveNamespace(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		gen := nmttest.New(seed, nmttest.NamespaceSize(8), nmttest.DuplicationRate(0.5))
+		leaves := gen.Leaves(1 + int(seed)*13)
+		nIDs := []namespace.ID{leaves[0][:8], leaves[len(leaves)-1][:8], gen.NamespaceID()}
+		if nID, found := gen.AbsentNamespaceID(leaves); found {
+			nIDs = append(nIDs, nID)
+		}
+		for _, prefixSize := range []int{0, 1, 2, 8} {
+			tree, root := buildTree(t, prefixSize, leaves)
+			for _, nID := range nIDs {
+				proof, err := tree.ProveNamespace(nID)
+				require.NoError(t, err)
+				data := tree.Get(nID)
+				assert.True(t, proof.VerifyNamespace(sha256.New(), nID, prefixSize, data, root), "seed %d, prefix size %d, namespace %s", seed, prefixSize, nID)
+				if len(data) > 0 {
+					assert.False(t, proof.VerifyNamespace(sha256.New(), nID, prefixSize, data[1:], root))
+				}
+				assert.False(t, proof.VerifyNamespace(sha256.New(), nID, prefixSize, data, append([]byte{Version + 1}, root[1:]...)))
+			}
+		}
+	}
+}
+
+func TestProveNamespace_Expansion(t *testing.T) {
+	// all namespace IDs share the prefix 01, so that truncated inner nodes
+	// cannot exclude any of them
+	var leaves [][]byte
+	for i := 0; i < 8; i++ {
+		leaves = append(leaves, []byte{1, 0, 0, 0, 0, 0, 0, byte(i), 'x'})
+	}
+	nID := namespace.ID{1, 0, 0, 0, 0, 0, 0, 3}
+
+	tree, root := buildTree(t, 8, leaves)
+	proof, err := tree.ProveNamespace(nID)
+	require.NoError(t, err)
+	assert.Len(t, proof.Nodes, 3)
+	assert.Equal(t, []bool{false, false}, proof.Expanded)
+	assert.True(t, proof.VerifyNamespace(sha256.New(), nID, 8, tree.Get(nID), root))
+
+	// with a single byte prefix, the proof reaches down to the leaves
+	tree, root = buildTree(t, 1, leaves)
+	proof, err = tree.ProveNamespace(nID)
+	require.NoError(t, err)
+	assert.Len(t, proof.Nodes, 7)
+	assert.Equal(t, []bool{true, true, true, true}, proof.Expanded)
+	assert.True(t, proof.VerifyNamespace(sha256.New(), nID, 1, tree.Get(nID), root))
+
+	// nodes that do not exclude the namespace are rejected
+	proof.Expanded = []bool{true, false}
+	proof.Nodes = append(proof.Nodes[:3:3], mustSubtreeRoot(t, tree, 4, 8))
+	assert.False(t, proof.VerifyNamespace(sha256.New(), nID, 1, tree.Get(nID), root))
+}
+
+func TestProofSize(t *testing.T) {
+	// namespace IDs differing in their first byte are separated by
+	// single-byte prefixes, so proofs keep their shape and shrink
+	var leaves [][]byte
+	for i := 0; i < 64; i++ {
+		leaves = append(leaves, []byte{byte(i), 0xAA, 0xBB, 0xCC, 0xDD, 0xEE, 0xFF, byte(i), 'x'})
+	}
+	nID := namespace.ID(leaves[20][:8])
+	full, _ := buildTree(t, 8, leaves)
+	truncated, _ := buildTree(t, 1, leaves)
+	fullProof, err := full.ProveNamespace(nID)
+	require.NoError(t, err)
+	truncatedProof, err := truncated.ProveNamespace(nID)
+	require.NoError(t, err)
+	assert.Len(t, truncatedProof.Nodes, len(fullProof.Nodes))
+	assert.Less(t, proofSize(truncatedProof), proofSize(fullProof))
+}
+
+func TestVerifyNamespace_Invalid(t *testing.T) {
+	leaves := [][]byte{
+		{0, 0, 0, 0, 0, 0, 0, 1, 'a'},
+		{0, 0, 0, 0, 0, 0, 0, 2, 'b'},
+		{0, 0, 0, 0, 0, 0, 0, 3, 'c'},
+	}
+	tree, root := buildTree(t, 2, leaves)
+	nID := namespace.ID(leaves[1][:8])
+	proof, err := tree.ProveNamespace(nID)
+	require.NoError(t, err)
+	require.True(t, proof.VerifyNamespace(sha256.New(), nID, 2, leaves[1:2], root))
+
+	for _, modify := range []func(p *Proof){
+		func(p *Proof) { p.Size++ },
+		func(p *Proof) { p.Start, p.End = 0, 1 },
+		func(p *Proof) { p.Nodes = p.Nodes[1:] },
+		func(p *Proof) { p.Nodes = append(p.Nodes, p.Nodes[0]) },
+		func(p *Proof) { p.Nodes[0] = p.Nodes[0][1:] },
+		func(p *Proof) { p.Expanded = append(p.Expanded, true) },
+	} {
+		p := proof
+		p.Nodes = append([][]byte(nil), proof.Nodes...)
+		modify(&p)
+		assert.False(t, p.VerifyNamespace(sha256.New(), nID, 2, leaves[1:2], root))
+	}
+	assert.False(t, proof.VerifyNamespace(sha256.New(), nID, 3, leaves[1:2], root))
+	assert.False(t, proof.VerifyNamespace(sha256.New(), nID, 9, leaves[1:2], root))
+	assert.False(t, proof.VerifyNamespace(sha256.New(), nID, 2, leaves[:1], root))
+
+	_, err = tree.ProveNamespace(namespace.ID{1})
+	assert.ErrorIs(t, err, nmt.ErrMismatchedNamespaceSize)
+}
+
+func TestEmptyTree(t *testing.T) {
+	h := NewHasher(sha256.New(), 8, 2)
+	tree := New(h)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	assert.Equal(t, h.EmptyRoot(), root)
+	_, err = ParseRoot(h, root)
+	require.NoError(t, err)
+
+	nID := namespace.ID{0, 0, 0, 0, 0, 0, 0, 1}
+	proof, err := tree.ProveNamespace(nID)
+	require.NoError(t, err)
+	assert.True(t, proof.VerifyNamespace(sha256.New(), nID, 2, nil, root))
+}
+
+func TestRoot_NotAnNMTRoot(t *testing.T) {
+	leaves := [][]byte{{0, 0, 0, 0, 0, 0, 0, 1, 'a'}, {0, 0, 0, 0, 0, 0, 0, 2, 'b'}}
+	_, root := buildTree(t, 8, leaves)
+	h := NewHasher(sha256.New(), 8, 8)
+	node, err := ParseRoot(h, root)
+	require.NoError(t, err)
+
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(8), nmt.IgnoreMaxNamespace(false))
+	for _, leaf := range leaves {
+		require.NoError(t, tree.Push(leaf))
+	}
+	nmtRoot, err := tree.Root()
+	require.NoError(t, err)
+	assert.NotEqual(t, nmtRoot, node)
+
+	_, err = ParseRoot(h, nmtRoot)
+	assert.ErrorIs(t, err, ErrInvalidRoot)
+}
+
+func TestPush_Invalid(t *testing.T) {
+	tree := New(NewHasher(sha256.New(), 2, 1))
+	assert.ErrorIs(t, tree.Push([]byte{1}), nmt.ErrInvalidLeafLen)
+	require.NoError(t, tree.Push([]byte{1, 2}))
+	assert.ErrorIs(t, tree.Push([]byte{1, 1}), nmt.ErrInvalidPushOrder)
+
+	assert.Panics(t, func() { NewHasher(sha256.New(), 2, 3) })
+	assert.Panics(t, func() { NewHasher(sha256.New(), 2, -1) })
+}
+
+func mustSubtreeRoot(t *testing.T, tree *Tree, start, end int) []byte {
+	t.Helper()
+	node, err := tree.subtreeRoot(start, end)
+	require.NoError(t, err)
+	return node
+}
+
+func proofSize(p Proof) int {
+	size := 0
+	for _, node := range p.Nodes {
+		size += len(node)
+	}
+	return size
+}
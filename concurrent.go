@@ -0,0 +1,127 @@
+package nmt
+
+import (
+	"hash"
+	"sync"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ConcurrentTree is a NamespacedMerkleTree that can be queried while further
+// leaves are pushed, e.g., to publish commitments to the leaves received so
+// far while their ingestion continues.
+//
+// Queries have snapshot semantics: each query is answered for the prefix of
+// leaves whose Push returned before the query started, as if no later leaf had
+// been pushed; leaves pushed concurrently with a query are not reflected in
+// its result. Queries do not block pushes, and pushes only wait for the
+// creation of a snapshot of the tree, which does not copy its leaves, not for
+// the queries themselves. Queries over the same prefix share the nodes they
+// compute, while the first query after a push computes the root of the new
+// prefix afresh.
+//
+// Pushes are serialized, and concurrent pushes are ordered arbitrarily, so a
+// single goroutine should push the leaves for their order to be defined. The
+// Metrics and Logger of the tree are used concurrently by pushes and queries
+// and must be safe for concurrent use.
+type ConcurrentTree struct {
+	newHash func() hash.Hash
+	// mtx guards tree and view.
+	mtx  sync.Mutex
+	tree *NamespacedMerkleTree
+	// view is the snapshot of tree queried until the next push, or nil if
+	// no query was made since the last push.
+	view *concurrentView
+}
+
+// concurrentView is a snapshot of the leaves pushed to a ConcurrentTree,
+// shared by the queries made until the next push.
+type concurrentView struct {
+	// mtx serializes the queries, which memoize nodes in tree.
+	mtx  sync.Mutex
+	tree *NamespacedMerkleTree
+}
+
+// NewConcurrent returns a ConcurrentTree configured by setters as for New.
+// Each snapshot queried hashes with a new instance of newHash; since hashers
+// cannot be shared by snapshots, NewConcurrent panics if setters include a
+// CustomHasher.
+func NewConcurrent(newHash func() hash.Hash, setters ...Option) *ConcurrentTree {
+	tree := New(newHash(), setters...)
+	if _, ok := tree.treeHasher.(*NmtHasher); !ok {
+		panic("Got CustomHasher. Expected the default hasher, which can be instantiated per snapshot.")
+	}
+	return &ConcurrentTree{newHash: newHash, tree: tree}
+}
+
+// Push adds a namespace-prefixed leaf as for NamespacedMerkleTree.Push. The
+// leaf is reflected in the queries started after Push returns.
+func (c *ConcurrentTree) Push(namespacedData namespace.PrefixedData) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if err := c.tree.Push(namespacedData); err != nil {
+		return err
+	}
+	c.view = nil
+	return nil
+}
+
+// Size returns the number of leaves pushed so far.
+func (c *ConcurrentTree) Size() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.tree.Size()
+}
+
+// Snapshot returns an independent snapshot of the leaves pushed so far (see
+// NamespacedMerkleTree.Snapshot), which the caller owns and may query or
+// extend without affecting c. Use it for several queries that must answer
+// for the same prefix of leaves.
+func (c *ConcurrentTree) Snapshot() *NamespacedMerkleTree {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.tree.Snapshot(c.newHash())
+}
+
+// currentView returns the snapshot of the leaves pushed so far shared by
+// queries.
+func (c *ConcurrentTree) currentView() *concurrentView {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.view == nil {
+		c.view = &concurrentView{tree: c.tree.Snapshot(c.newHash())}
+	}
+	return c.view
+}
+
+// Root returns the root of the leaves pushed so far, as
+// NamespacedMerkleTree.Root, together with the number of leaves it commits
+// to.
+func (c *ConcurrentTree) Root() ([]byte, int, error) {
+	v := c.currentView()
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	root, err := v.tree.Root()
+	return root, v.tree.Size(), err
+}
+
+// Get returns the leaves of the namespace nID among the leaves pushed so far,
+// as NamespacedMerkleTree.Get.
+func (c *ConcurrentTree) Get(nID namespace.ID) [][]byte {
+	v := c.currentView()
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	return v.tree.Get(nID)
+}
+
+// ProveNamespace proves the namespace nID among the leaves pushed so far, as
+// NamespacedMerkleTree.ProveNamespace. The proof verifies against the root
+// returned by Root for the same number of leaves, which is returned alongside
+// the proof.
+func (c *ConcurrentTree) ProveNamespace(nID namespace.ID) (Proof, int, error) {
+	v := c.currentView()
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	proof, err := v.tree.ProveNamespace(nID)
+	return proof, v.tree.Size(), err
+}
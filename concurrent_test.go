@@ -0,0 +1,128 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestConcurrentTree_Queries(t *testing.T) {
+	tree := NewConcurrent(sha256.New, NamespaceIDSize(1))
+	root, size, err := tree.Root()
+	require.NoError(t, err)
+	assert.Equal(t, 0, size)
+	assert.Equal(t, EmptyRoot(sha256.New(), 1), root)
+
+	require.NoError(t, tree.Push([]byte{1, 'a'}))
+	require.NoError(t, tree.Push([]byte{3, 'b'}))
+	snapshot := tree.Snapshot()
+	require.NoError(t, tree.Push([]byte{3, 'c'}))
+	assert.Error(t, tree.Push([]byte{2, 'd'}))
+
+	want := New(sha256.New(), NamespaceIDSize(1))
+	for _, leaf := range [][]byte{{1, 'a'}, {3, 'b'}, {3, 'c'}} {
+		require.NoError(t, want.Push(leaf))
+	}
+	wantRoot, err := want.Root()
+	require.NoError(t, err)
+
+	root, size, err = tree.Root()
+	require.NoError(t, err)
+	assert.Equal(t, 3, size)
+	assert.Equal(t, wantRoot, root)
+	assert.Equal(t, 3, tree.Size())
+	assert.Equal(t, [][]byte{{3, 'b'}, {3, 'c'}}, tree.Get(namespace.ID{3}))
+
+	proof, size, err := tree.ProveNamespace(namespace.ID{3})
+	require.NoError(t, err)
+	assert.Equal(t, 3, size)
+	assert.True(t, proof.VerifyNamespace(sha256.New(), namespace.ID{3}, [][]byte{{3, 'b'}, {3, 'c'}}, root))
+
+	// the snapshot is unaffected by later pushes
+	assert.Equal(t, 2, snapshot.Size())
+	assert.Equal(t, [][]byte{{3, 'b'}}, snapshot.Get(namespace.ID{3}))
+}
+
+func TestConcurrentTree_CustomHasher(t *testing.T) {
+	assert.Panics(t, func() {
+		NewConcurrent(sha256.New, CustomHasher(struct{ Hasher }{NewNmtHasher(sha256.New(), 1, true)}))
+	})
+}
+
+func TestConcurrentTree_PushWhileQuerying(t *testing.T) {
+	const leaves = 256
+	tree := NewConcurrent(sha256.New, NamespaceIDSize(1))
+	want := New(sha256.New(), NamespaceIDSize(1))
+	// wantRoots[i] is the root of the first i leaves
+	wantRoots := make([][]byte, leaves+1)
+	for i := 0; i <= leaves; i++ {
+		root, err := want.Root()
+		require.NoError(t, err)
+		wantRoots[i] = root
+		if i < leaves {
+			require.NoError(t, want.Push([]byte{byte(i / 16), byte(i)}))
+		}
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	errs := make(chan error, 4)
+	for q := 0; q < 4; q++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last := 0
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				root, size, err := tree.Root()
+				if err != nil {
+					errs <- err
+					return
+				}
+				// queries never go back to a shorter prefix
+				if !assert.GreaterOrEqual(t, size, last) || !assert.Equal(t, wantRoots[size], root) {
+					return
+				}
+				last = size
+				nID := namespace.ID{byte(size / 16)}
+				proof, size, err := tree.ProveNamespace(nID)
+				if err != nil {
+					errs <- err
+					return
+				}
+				var nsLeaves [][]byte
+				for i := 0; i < size; i++ {
+					if byte(i/16) == nID[0] {
+						nsLeaves = append(nsLeaves, []byte{nID[0], byte(i)})
+					}
+				}
+				if !assert.True(t, proof.VerifyNamespace(sha256.New(), nID, nsLeaves, wantRoots[size])) {
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < leaves; i++ {
+		require.NoError(t, tree.Push([]byte{byte(i / 16), byte(i)}))
+	}
+	close(done)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	root, size, err := tree.Root()
+	require.NoError(t, err)
+	assert.Equal(t, leaves, size)
+	assert.Equal(t, wantRoots[leaves], root)
+}
@@ -0,0 +1,288 @@
+// Package conformance contains the canonical NMT test vectors derived from the
+// LazyLedger/Celestia specification (see docs/spec/nmt.md), and a Run helper
+// that checks an nmt.Hasher implementation against them. Alternative hasher
+// implementations can use it to prove that they match the specification:
+//
+//	func TestConformance(t *testing.T) {
+//		conformance.Run(t, func(nIDSize namespace.IDSize, ignoreMaxNamespace bool) nmt.Hasher {
+//			return myhasher.New(nIDSize, ignoreMaxNamespace)
+//		})
+//	}
+//
+// All vectors use SHA256 as the underlying hash function.
+//
+// Applications can pin the behavior of their trees in fixture files of their
+// own, which are reviewed alongside changes to their protocol specification,
+// and check them with LoadVectors and RunVectors:
+//
+//	func TestFixtures(t *testing.T) {
+//		vectors, err := conformance.LoadVectors("testdata/vectors.json")
+//		require.NoError(t, err)
+//		conformance.RunVectors(t, vectors)
+//	}
+//
+// A vector file holds a JSON array of objects of the following form, see
+// schema.json for the JSON schema; all byte strings are hex-encoded:
+//
+//	{
+//	  "name": "spec example",          // unique name of the vector
+//	  "namespace_size": 1,             // size of namespace IDs in bytes
+//	  "ignore_max_namespace": true,    // see nmt.IgnoreMaxNamespace
+//	  "leaves": ["006c6561665f30"],    // namespace-prefixed leaves
+//	  "leaf_hashes": ["0000..."],      // optional expected leaf hashes
+//	  "root": "0000...",               // expected root
+//	  "proofs": [{                     // optional expected proofs
+//	    "namespace": "00",             // the proven namespace, or omitted
+//	    "start": 0,                    // proven range of leaves [start, end)
+//	    "end": 1,
+//	    "nodes": ["0000..."],          // expected proof nodes
+//	    "leaf_hash": "0000..."         // leaf hash of an absence proof
+//	  }]
+//	}
+//
+// A proof with a namespace is the proof returned by ProveNamespace, and any
+// other proof the one returned by ProveRange for its range.
+package conformance
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+//go:embed vectors.json
+var vectorsJSON []byte
+
+// HexBytes is a byte slice that is encoded as a hex string in JSON.
+type HexBytes []byte
+
+// MarshalJSON encodes b as a hex string.
+func (b HexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(b))
+}
+
+// UnmarshalJSON decodes a hex string into b.
+func (b *HexBytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	decoded, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+// Vector is a single conformance test vector: a tree configuration, the
+// namespace-prefixed leaves pushed to it in order, and the expected leaf
+// hashes and root.
+type Vector struct {
+	Name               string     `json:"name"`
+	NamespaceSize      int        `json:"namespace_size"`
+	IgnoreMaxNamespace bool       `json:"ignore_max_namespace"`
+	Leaves             []HexBytes `json:"leaves"`
+	// LeafHashes are not checked if they are empty.
+	LeafHashes []HexBytes    `json:"leaf_hashes"`
+	Root       HexBytes      `json:"root"`
+	Proofs     []ProofVector `json:"proofs,omitempty"`
+}
+
+// ProofVector is an expected proof of a Vector: the namespace proof of
+// Namespace if it is set, and the proof of the range of leaves [Start, End)
+// otherwise.
+type ProofVector struct {
+	Namespace HexBytes   `json:"namespace,omitempty"`
+	Start     int        `json:"start"`
+	End       int        `json:"end"`
+	Nodes     []HexBytes `json:"nodes"`
+	// LeafHash is the leaf hash of an absence proof.
+	LeafHash HexBytes `json:"leaf_hash,omitempty"`
+}
+
+// validate checks that the sizes within v are consistent, so that it can be
+// checked without running into the panics of nmt.New for invalid options.
+func (v Vector) validate() error {
+	if v.NamespaceSize < 0 || v.NamespaceSize > namespace.IDMaxSize {
+		return fmt.Errorf("namespace size %d not in [0, %d]", v.NamespaceSize, namespace.IDMaxSize)
+	}
+	if len(v.LeafHashes) != 0 && len(v.LeafHashes) != len(v.Leaves) {
+		return fmt.Errorf("%d leaf hashes for %d leaves", len(v.LeafHashes), len(v.Leaves))
+	}
+	for i, leaf := range v.Leaves {
+		if len(leaf) < v.NamespaceSize {
+			return fmt.Errorf("leaf %d of %d bytes is shorter than the namespace size %d", i, len(leaf), v.NamespaceSize)
+		}
+	}
+	for i, proof := range v.Proofs {
+		if proof.Namespace != nil && len(proof.Namespace) != v.NamespaceSize {
+			return fmt.Errorf("proof %d: namespace of %d bytes, want %d", i, len(proof.Namespace), v.NamespaceSize)
+		}
+	}
+	return nil
+}
+
+// HasherFactory creates the hasher under test for the given tree
+// configuration.
+type HasherFactory func(nIDSize namespace.IDSize, ignoreMaxNamespace bool) nmt.Hasher
+
+// Vectors returns the embedded conformance vectors. It panics if the embedded
+// vectors are malformed, which would be a bug in this package.
+func Vectors() []Vector {
+	var vectors []Vector
+	if err := json.Unmarshal(vectorsJSON, &vectors); err != nil {
+		panic(fmt.Sprintf("malformed embedded conformance vectors: %v", err))
+	}
+	return vectors
+}
+
+// LoadVectors reads the vectors from the JSON file at path, see the package
+// documentation for its format. It returns an error if the file is malformed,
+// contains unknown fields, or a vector is inconsistent, e.g., has unnamed or
+// duplicate names or a number of leaf hashes different from its number of
+// leaves.
+func LoadVectors(path string) ([]Vector, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var vectors []Vector
+	if err := dec.Decode(&vectors); err != nil {
+		return nil, fmt.Errorf("malformed vectors in %s: %w", path, err)
+	}
+	names := make(map[string]bool, len(vectors))
+	for i, v := range vectors {
+		switch {
+		case v.Name == "":
+			return nil, fmt.Errorf("vector %d in %s has no name", i, path)
+		case names[v.Name]:
+			return nil, fmt.Errorf("duplicate vector %q in %s", v.Name, path)
+		}
+		if err := v.validate(); err != nil {
+			return nil, fmt.Errorf("vector %q in %s: %w", v.Name, path, err)
+		}
+		names[v.Name] = true
+	}
+	return vectors, nil
+}
+
+// Run checks the hashers created by newHasher against all embedded vectors.
+// Each vector is run as a subtest of t.
+func Run(t *testing.T, newHasher HasherFactory) {
+	run(t, Vectors(), newHasher)
+}
+
+// RunVectors checks the default hasher against vectors, e.g., as returned by
+// LoadVectors. Each vector is run as a subtest of t.
+func RunVectors(t *testing.T, vectors []Vector) {
+	run(t, vectors, DefaultHasher)
+}
+
+func run(t *testing.T, vectors []Vector, newHasher HasherFactory) {
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			if err := Check(v, newHasher); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// DefaultHasher is the HasherFactory of the default nmt.NmtHasher using
+// SHA256.
+func DefaultHasher(nIDSize namespace.IDSize, ignoreMaxNamespace bool) nmt.Hasher {
+	return nmt.NewNmtHasher(sha256.New(), nIDSize, ignoreMaxNamespace)
+}
+
+// Check checks the hasher created by newHasher against a single vector and
+// returns an error describing the first mismatch. It returns an error if the
+// sizes within the vector are inconsistent, e.g., its namespace size does not
+// match the size of its leaves, or if the hasher does not use the namespace
+// size of the vector.
+func Check(v Vector, newHasher HasherFactory) error {
+	if err := v.validate(); err != nil {
+		return fmt.Errorf("invalid vector: %w", err)
+	}
+	hasher := newHasher(namespace.IDSize(v.NamespaceSize), v.IgnoreMaxNamespace)
+	if int(hasher.NamespaceSize()) != v.NamespaceSize {
+		return fmt.Errorf("hasher uses namespace size %d, want %d", hasher.NamespaceSize(), v.NamespaceSize)
+	}
+	if len(v.Leaves) == 0 {
+		if got := hasher.EmptyRoot(); !bytes.Equal(got, v.Root) {
+			return fmt.Errorf("empty root mismatch: got %x, want %x", got, v.Root)
+		}
+	}
+	for i, leaf := range v.LeafHashes {
+		got, err := hasher.HashLeaf(v.Leaves[i])
+		if err != nil {
+			return fmt.Errorf("failed to hash leaf %d: %w", i, err)
+		}
+		if !bytes.Equal(got, leaf) {
+			return fmt.Errorf("leaf hash %d mismatch: got %x, want %x", i, got, v.LeafHashes[i])
+		}
+	}
+
+	tree := nmt.New(sha256.New(),
+		nmt.NamespaceIDSize(v.NamespaceSize),
+		nmt.IgnoreMaxNamespace(v.IgnoreMaxNamespace),
+		nmt.CustomHasher(hasher),
+	)
+	for i, leaf := range v.Leaves {
+		if err := tree.Push(namespace.PrefixedData(leaf)); err != nil {
+			return fmt.Errorf("failed to push leaf %d: %w", i, err)
+		}
+	}
+	root, err := tree.Root()
+	if err != nil {
+		return fmt.Errorf("failed to compute root: %w", err)
+	}
+	if !bytes.Equal(root, v.Root) {
+		return fmt.Errorf("root mismatch: got %x, want %x", root, v.Root)
+	}
+	for i, want := range v.Proofs {
+		if err := checkProof(tree, want); err != nil {
+			return fmt.Errorf("proof %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// checkProof compares the proof generated by tree with the expected one.
+func checkProof(tree *nmt.NamespacedMerkleTree, want ProofVector) error {
+	var proof nmt.Proof
+	var err error
+	if want.Namespace != nil {
+		proof, err = tree.ProveNamespace(namespace.ID(want.Namespace))
+	} else {
+		proof, err = tree.ProveRange(want.Start, want.End)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate proof: %w", err)
+	}
+	if proof.Start() != want.Start || proof.End() != want.End {
+		return fmt.Errorf("range mismatch: got [%d, %d), want [%d, %d)", proof.Start(), proof.End(), want.Start, want.End)
+	}
+	if len(proof.Nodes()) != len(want.Nodes) {
+		return fmt.Errorf("got %d nodes, want %d", len(proof.Nodes()), len(want.Nodes))
+	}
+	for i, node := range proof.Nodes() {
+		if !bytes.Equal(node, want.Nodes[i]) {
+			return fmt.Errorf("node %d mismatch: got %x, want %x", i, node, want.Nodes[i])
+		}
+	}
+	if !bytes.Equal(proof.LeafHash(), want.LeafHash) {
+		return fmt.Errorf("leaf hash mismatch: got %x, want %x", proof.LeafHash(), want.LeafHash)
+	}
+	return nil
+}
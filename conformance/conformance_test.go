@@ -0,0 +1,116 @@
+package conformance
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestRun_DefaultHasher(t *testing.T) {
+	Run(t, DefaultHasher)
+}
+
+func TestVectors(t *testing.T) {
+	vectors := Vectors()
+	require.NotEmpty(t, vectors)
+	for _, v := range vectors {
+		assert.Len(t, v.LeafHashes, len(v.Leaves), v.Name)
+	}
+}
+
+// flippedHasher deviates from the specification by ignoring the configured
+// IgnoreMaxNamespace setting.
+type flippedHasher struct {
+	*nmt.NmtHasher
+}
+
+func TestCheck_DetectsDeviation(t *testing.T) {
+	deviating := func(nIDSize namespace.IDSize, ignoreMaxNamespace bool) nmt.Hasher {
+		return flippedHasher{nmt.NewNmtHasher(sha256.New(), nIDSize, !ignoreMaxNamespace)}
+	}
+	failures := 0
+	for _, v := range Vectors() {
+		if Check(v, deviating) != nil {
+			failures++
+		}
+	}
+	assert.Positive(t, failures)
+}
+
+func TestLoadVectors(t *testing.T) {
+	vectors, err := LoadVectors("vectors.json")
+	require.NoError(t, err)
+	assert.Equal(t, Vectors(), vectors)
+	RunVectors(t, vectors)
+}
+
+func TestLoadVectors_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"malformed", `[{"name": "a"`},
+		{"unknown field", `[{"name": "a", "root": "", "extra": 1}]`},
+		{"invalid hex", `[{"name": "a", "root": "0g"}]`},
+		{"unnamed", `[{"root": ""}]`},
+		{"duplicate name", `[{"name": "a", "root": ""}, {"name": "a", "root": ""}]`},
+		{"leaf hash count", `[{"name": "a", "leaves": ["00"], "leaf_hashes": ["00", "01"], "root": ""}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "vectors.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.data), 0o600))
+			_, err := LoadVectors(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCheck_InvalidSizes(t *testing.T) {
+	v := Vectors()[0]
+	tests := map[string]func(v *Vector){
+		"negative namespace size": func(v *Vector) { v.NamespaceSize = -1 },
+		"large namespace size":    func(v *Vector) { v.NamespaceSize = namespace.IDMaxSize + 1 },
+		"short leaf":              func(v *Vector) { v.Leaves = []HexBytes{{}} },
+		"leaf hash count":         func(v *Vector) { v.LeafHashes = append(slices.Clone(v.LeafHashes), HexBytes{0}) },
+		"proof namespace size":    func(v *Vector) { v.Proofs = []ProofVector{{Namespace: make(HexBytes, v.NamespaceSize+1)}} },
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			invalid := v
+			modify(&invalid)
+			assert.Error(t, Check(invalid, DefaultHasher))
+		})
+	}
+
+	// hashers disagreeing with the namespace size of the vector are rejected
+	// as well
+	wrongSize := func(nIDSize namespace.IDSize, ignoreMaxNamespace bool) nmt.Hasher {
+		return nmt.NewNmtHasher(sha256.New(), nIDSize+1, ignoreMaxNamespace)
+	}
+	assert.Error(t, Check(v, wrongSize))
+}
+
+func TestCheck_DetectsProofMismatch(t *testing.T) {
+	for _, v := range Vectors() {
+		for i := range v.Proofs {
+			tampered := v
+			tampered.Proofs = slices.Clone(v.Proofs)
+			tampered.Proofs[i].End++
+			assert.Error(t, Check(tampered, DefaultHasher), "%s: proof %d", v.Name, i)
+			if len(v.Proofs[i].Nodes) > 0 {
+				tampered.Proofs[i] = v.Proofs[i]
+				tampered.Proofs[i].Nodes = v.Proofs[i].Nodes[1:]
+				assert.Error(t, Check(tampered, DefaultHasher), "%s: proof %d", v.Name, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Package das packages the data availability sampling (DAS) pattern on top of
+// NMTs: a light node selects random leaf indices of a row, a full node answers
+// each of them with a Sample (the leaf and its inclusion proof), and the light
+// node verifies the samples against the row root.
+package das
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"math/bits"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ErrInvalidSampleCount indicates that more distinct samples were requested
+// than there are leaves.
+var ErrInvalidSampleCount = errors.New("invalid sample count")
+
+// Sample is a single leaf of a row together with the proof of its inclusion in
+// the row root.
+type Sample struct {
+	// Index is the index of the sampled leaf in the row.
+	Index int
+	// Leaf is the namespace-prefixed leaf data.
+	Leaf []byte
+	// Proof is the inclusion proof of Leaf at Index.
+	Proof nmt.Proof
+}
+
+// SelectIndices returns count distinct leaf indices chosen uniformly at random
+// from [0, size), in the order they were drawn. Randomness is read from rnd,
+// which defaults to crypto/rand.Reader if nil.
+func SelectIndices(size, count int, rnd io.Reader) ([]int, error) {
+	if count < 0 || count > size {
+		return nil, fmt.Errorf("%w: cannot select %d distinct indices out of %d", ErrInvalidSampleCount, count, size)
+	}
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	selected := make(map[int]bool, count)
+	indices := make([]int, 0, count)
+	bound := big.NewInt(int64(size))
+	for len(indices) < count {
+		v, err := rand.Int(rnd, bound)
+		if err != nil {
+			return nil, fmt.Errorf("failed to draw random index: %w", err)
+		}
+		index := int(v.Int64())
+		if selected[index] {
+			continue
+		}
+		selected[index] = true
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
+
+// NewSample returns the sample for the leaf at index of tree.
+func NewSample(tree *nmt.NamespacedMerkleTree, index int) (Sample, error) {
+	leaf, err := tree.Leaf(index)
+	if err != nil {
+		return Sample{}, err
+	}
+	proof, err := tree.Prove(index)
+	if err != nil {
+		return Sample{}, err
+	}
+	return Sample{Index: index, Leaf: leaf, Proof: proof}, nil
+}
+
+// NewSamples returns the samples for the leaves at the given indices of tree.
+func NewSamples(tree *nmt.NamespacedMerkleTree, indices []int) ([]Sample, error) {
+	samples := make([]Sample, 0, len(indices))
+	for _, index := range indices {
+		sample, err := NewSample(tree, index)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+// Verify checks that the sample's leaf is included in the row with the given
+// root at the sample's index. `h` MUST be the same as the underlying hash
+// function used to generate the proof and nIDSize must match the namespace size
+// of the row. size is the number of leaves of the row, including its padding
+// leaves if it is padded. Since inclusion proofs do not commit to the size of
+// the tree, the proof must consist of exactly the nodes on the path of the
+// index in a row of that size, so that it cannot prove another leaf at the
+// index.
+func (s Sample) Verify(h hash.Hash, nIDSize namespace.IDSize, size int, root []byte) bool {
+	if s.Index < 0 || s.Index >= size || s.Proof.Start() != s.Index || s.Proof.End() != s.Index+1 {
+		return false
+	}
+	if len(s.Proof.Nodes()) != pathLength(s.Index, size) {
+		return false
+	}
+	if len(s.Leaf) < int(nIDSize) {
+		return false
+	}
+	nID := namespace.ID(s.Leaf[:nIDSize])
+	return s.Proof.VerifyInclusion(h, nID, [][]byte{s.Leaf[nIDSize:]}, root)
+}
+
+// pathLength returns the number of siblings on the path from the leaf at
+// index to the root of a tree of size leaves.
+func pathLength(index, size int) int {
+	length := 0
+	for start, end := 0, size; end-start > 1; length++ {
+		// the left subtree holds the largest power of two smaller than the
+		// number of leaves
+		k := 1 << (bits.Len(uint(end-start-1)) - 1)
+		if index < start+k {
+			end = start + k
+		} else {
+			start += k
+		}
+	}
+	return length
+}
+
+// VerifySamples verifies all samples against root of a row of size leaves, see
+// Sample.Verify, and checks that they answer exactly the requested indices, in
+// order. newHash must return the underlying hash function used to generate
+// the proofs.
+func VerifySamples(newHash func() hash.Hash, nIDSize namespace.IDSize, size int, root []byte, indices []int, samples []Sample) error {
+	if len(samples) != len(indices) {
+		return fmt.Errorf("got %d samples for %d requested indices", len(samples), len(indices))
+	}
+	// the hash state is reset by the verification and can be shared by all
+	// samples
+	h := newHash()
+	for i, sample := range samples {
+		if sample.Index != indices[i] {
+			return fmt.Errorf("sample %d answers index %d, requested %d", i, sample.Index, indices[i])
+		}
+		if !sample.Verify(h, nIDSize, size, root) {
+			return fmt.Errorf("sample for index %d: %w", sample.Index, nmt.ErrInvalidProof)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package das
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+)
+
+func exampleRow(t *testing.T, size int) (*nmt.NamespacedMerkleTree, []byte) {
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(2))
+	for i := 0; i < size; i++ {
+		require.NoError(t, tree.Push([]byte{0, byte(i / 3), 's', byte(i)}))
+	}
+	root, err := tree.Root()
+	require.NoError(t, err)
+	return tree, root
+}
+
+func TestSelectIndices(t *testing.T) {
+	indices, err := SelectIndices(16, 16, nil)
+	require.NoError(t, err)
+	seen := map[int]bool{}
+	for _, index := range indices {
+		assert.GreaterOrEqual(t, index, 0)
+		assert.Less(t, index, 16)
+		seen[index] = true
+	}
+	assert.Len(t, seen, 16)
+
+	_, err = SelectIndices(4, 5, nil)
+	assert.ErrorIs(t, err, ErrInvalidSampleCount)
+
+	// the selection is deterministic for a deterministic source
+	seed := make([]byte, 4096)
+	for i := range seed {
+		seed[i] = byte(i * 31)
+	}
+	a, err := SelectIndices(64, 8, bytes.NewReader(seed))
+	require.NoError(t, err)
+	b, err := SelectIndices(64, 8, bytes.NewReader(seed))
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+}
+
+func TestSamples(t *testing.T) {
+	tree, root := exampleRow(t, 13)
+	indices, err := SelectIndices(tree.Size(), 5, nil)
+	require.NoError(t, err)
+	samples, err := NewSamples(tree, indices)
+	require.NoError(t, err)
+	require.NoError(t, VerifySamples(sha256.New, 2, tree.Size(), root, indices, samples))
+
+	// a sample answering another index is rejected
+	moved := samples[0]
+	moved.Index = (moved.Index + 1) % tree.Size()
+	assert.False(t, moved.Verify(sha256.New(), 2, tree.Size(), root))
+
+	// a sample with tampered data is rejected
+	tampered := samples[1]
+	tampered.Leaf = append(append([]byte(nil), tampered.Leaf...), 'x')
+	assert.False(t, tampered.Verify(sha256.New(), 2, tree.Size(), root))
+	samples[1] = tampered
+	assert.ErrorIs(t, VerifySamples(sha256.New, 2, tree.Size(), root, indices, samples), nmt.ErrInvalidProof)
+
+	assert.Error(t, VerifySamples(sha256.New, 2, tree.Size(), root, indices[:1], samples))
+
+	_, err = NewSample(tree, tree.Size())
+	assert.ErrorIs(t, err, nmt.ErrInvalidRange)
+}
+
+func TestSample_VerifyForgedIndex(t *testing.T) {
+	for size := 1; size <= 17; size++ {
+		tree, root := exampleRow(t, size)
+		for index := 0; index < size; index++ {
+			sample, err := NewSample(tree, index)
+			require.NoError(t, err)
+			assert.True(t, sample.Verify(sha256.New(), 2, size, root), "index %d of %d", index, size)
+		}
+	}
+
+	// the last leaf of a row of 3 leaves proven at index 1 as if the row had 2
+	// leaves, the first being the root of the first two leaves
+	tree, root := exampleRow(t, 3)
+	node, err := tree.ComputeSubtreeRoot(0, 2)
+	require.NoError(t, err)
+	leaf, err := tree.Leaf(2)
+	require.NoError(t, err)
+	forged := Sample{Index: 1, Leaf: leaf, Proof: nmt.NewInclusionProof(1, 2, [][]byte{node}, true)}
+	require.True(t, forged.Proof.VerifyInclusion(sha256.New(), leaf[:2], [][]byte{leaf[2:]}, root))
+	assert.False(t, forged.Verify(sha256.New(), 2, 3, root))
+	assert.ErrorIs(t, VerifySamples(sha256.New, 2, 3, root, []int{1}, []Sample{forged}), nmt.ErrInvalidProof)
+}
@@ -0,0 +1,289 @@
+package nmt
+
+import (
+	"bytes"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// checkInvariants panics with a report of the state of the tree if debug mode
+// is enabled and an internal invariant of the tree is violated after the
+// operation op. Debug mode is enabled by building with the nmt_debug build
+// tag, e.g., go test -tags nmt_debug ./..., and makes every operation at least
+// linear in the number of leaves; without the tag, checkInvariants compiles to
+// nothing.
+func (n *NamespacedMerkleTree) checkInvariants(op string) {
+	if debugInvariants {
+		n.assertInvariants(op)
+	}
+}
+
+// assertInvariants panics with a report of the state of the tree if one of its
+// invariants is violated after the operation op, see verifyInvariants.
+func (n *NamespacedMerkleTree) assertInvariants(op string) {
+	if err := n.verifyInvariants(); err != nil {
+		panic(fmt.Sprintf("nmt: invariant violated after %s: %v\n%s", op, err, n.debugReport()))
+	}
+}
+
+// verifyInvariants checks the consistency of the internal state of the tree:
+// the leaves are sorted by namespace and match their hashes, the namespace
+// index, the leaf indices and the minimum and maximum namespace IDs match the
+// leaves, and the memoized root and inner nodes match their recomputation from
+// the leaf hashes, which also verifies the namespace ranges of the inner nodes
+// against their children. Leaves added out of order by ForceAddLeaf disable
+// the checks depending on the order of the leaves.
+func (n *NamespacedMerkleTree) verifyInvariants() error {
+	if n.frontier != nil {
+		return n.verifyFrontier()
+	}
+	size := n.Size()
+	if len(n.leafHashes) != size {
+		return fmt.Errorf("%d leaves but %d leaf hashes", size, len(n.leafHashes))
+	}
+	if n.pending < 0 || n.pending > size || (n.pending > 0 && !n.lazyHashing) {
+		return fmt.Errorf("%d pending leaves out of %d", n.pending, size)
+	}
+	if err := n.verifyLeaves(); err != nil {
+		return err
+	}
+	if !n.unordered {
+		if err := n.verifyNamespaces(); err != nil {
+			return err
+		}
+	}
+	if err := n.verifyLeafIndices(); err != nil {
+		return err
+	}
+	return n.verifyCachedNodes()
+}
+
+// verifyFrontier checks the state of a frontier-only tree: it keeps no leaves
+// or inner nodes, its frontier matches its size, and the memoized root and the
+// maximum namespace ID match the frontier.
+func (n *NamespacedMerkleTree) verifyFrontier() error {
+	if len(n.leaves) != 0 || len(n.leafHashes) != 0 || len(n.innerNodes) != 0 || len(n.namespaceRanges) != 0 {
+		return fmt.Errorf("frontier-only tree keeps %d leaves, %d leaf hashes, %d inner nodes and %d namespaces",
+			len(n.leaves), len(n.leafHashes), len(n.innerNodes), len(n.namespaceRanges))
+	}
+	size := n.Size()
+	if want := bits.OnesCount(uint(size)); len(n.frontier.peaks) != want {
+		return fmt.Errorf("frontier of %d leaves has %d peaks, want %d", size, len(n.frontier.peaks), want)
+	}
+	if size > 0 && !bytes.Equal(n.maxNID, n.frontier.lastNID) {
+		return fmt.Errorf("maximum namespace ID is %s, but the last leaf has namespace %s", n.maxNID, n.frontier.lastNID)
+	}
+	if n.rawRoot != nil {
+		want, err := n.frontier.Root()
+		if err != nil {
+			return fmt.Errorf("failed to recompute the root: %w", err)
+		}
+		if !bytes.Equal(n.rawRoot, want) {
+			return fmt.Errorf("memoized root is %x, want %x", n.rawRoot, want)
+		}
+	}
+	return nil
+}
+
+// verifyLeaves checks the leaf hashes against the leaves and their order.
+func (n *NamespacedMerkleTree) verifyLeaves() error {
+	nidSize := n.NamespaceSize()
+	nodeSize := len(n.treeHasher.EmptyRoot())
+	hashed := n.Size() - n.pending
+	for i, leafHash := range n.leafHashes {
+		leaf := n.leaves[i]
+		if i >= hashed {
+			// the leaf hash of a pending leaf is its namespace ID
+			if len(leafHash) != int(nidSize) || !bytes.Equal(leafHash, leaf[:nidSize]) {
+				return fmt.Errorf("pending leaf %d has the placeholder %x, want its namespace ID %x", i, leafHash, leaf[:minInt(len(leaf), int(nidSize))])
+			}
+			continue
+		}
+		if len(leafHash) != nodeSize {
+			return fmt.Errorf("leaf hash %d has size %d, want %d", i, len(leafHash), nodeSize)
+		}
+		if leaf == nil {
+			continue
+		}
+		if n.dropLeafData {
+			return fmt.Errorf("data of hashed leaf %d was not dropped", i)
+		}
+		want, err := n.treeHasher.HashLeaf(leaf)
+		if err != nil {
+			return fmt.Errorf("failed to hash leaf %d: %w", i, err)
+		}
+		if !bytes.Equal(leafHash, want) {
+			return fmt.Errorf("leaf hash %d is %x, but the leaf %x hashes to %x", i, leafHash, leaf, want)
+		}
+	}
+	if n.unordered {
+		return nil
+	}
+	for i := 1; i < len(n.leafHashes); i++ {
+		prev, cur := namespace.ID(n.leafHashes[i-1][:nidSize]), namespace.ID(n.leafHashes[i][:nidSize])
+		if cur.Less(prev) {
+			return fmt.Errorf("leaf %d has namespace %s, which is smaller than the namespace %s of leaf %d", i, cur, prev, i-1)
+		}
+	}
+	return nil
+}
+
+// verifyNamespaces checks the namespace index and the minimum and maximum
+// namespace IDs of the tree against its sorted leaves.
+func (n *NamespacedMerkleTree) verifyNamespaces() error {
+	nidSize := n.NamespaceSize()
+	want := make(map[string]LeafRange)
+	for i, leafHash := range n.leafHashes {
+		nsStr := string(leafHash[:nidSize])
+		rng, found := want[nsStr]
+		if !found {
+			rng.Start = i
+		}
+		rng.End = i + 1
+		want[nsStr] = rng
+	}
+	if len(n.namespaceRanges) != len(want) {
+		return fmt.Errorf("namespace index holds %d namespaces, want %d", len(n.namespaceRanges), len(want))
+	}
+	for nsStr, rng := range want {
+		if got, found := n.namespaceRanges[nsStr]; !found || got != rng {
+			return fmt.Errorf("namespace %x has the range %v in the namespace index, want %v", nsStr, got, rng)
+		}
+		if n.filter != nil && !n.filter.mayContain([]byte(nsStr)) {
+			return fmt.Errorf("namespace %x is missing from the namespace filter", nsStr)
+		}
+	}
+	if size := n.Size(); size > 0 {
+		first, last := n.leafHashes[0][:nidSize], n.leafHashes[size-1][:nidSize]
+		if !bytes.Equal(n.minNID, first) || !bytes.Equal(n.maxNID, last) {
+			return fmt.Errorf("minimum and maximum namespace IDs are %s and %s, want %x and %x", n.minNID, n.maxNID, first, last)
+		}
+	}
+	return nil
+}
+
+// verifyLeafIndices checks that the leaf indices, if built, map the hash of
+// each hashed leaf to its first occurrence.
+func (n *NamespacedMerkleTree) verifyLeafIndices() error {
+	if n.leafIndices == nil {
+		return nil
+	}
+	hashed := n.leafHashes[:n.Size()-n.pending]
+	distinct := 0
+	for i, leafHash := range hashed {
+		index, found := n.leafIndices[string(leafHash)]
+		if !found {
+			return fmt.Errorf("leaf hash %d is missing from the leaf indices", i)
+		}
+		if index == i {
+			distinct++
+		} else if index > i || !bytes.Equal(hashed[index], leafHash) {
+			return fmt.Errorf("leaf hash %d is indexed at %d", i, index)
+		}
+	}
+	if len(n.leafIndices) != distinct {
+		return fmt.Errorf("leaf indices hold %d hashes, want %d", len(n.leafIndices), distinct)
+	}
+	return nil
+}
+
+// verifyCachedNodes checks the memoized root and inner nodes against their
+// recomputation from the leaf hashes.
+func (n *NamespacedMerkleTree) verifyCachedNodes() error {
+	if n.rawRoot == nil && len(n.innerNodes) == 0 {
+		return nil
+	}
+	if n.pending > 0 {
+		return fmt.Errorf("nodes are memoized while %d leaves are pending", n.pending)
+	}
+	memo := make(map[LeafRange][]byte, len(n.innerNodes))
+	for rng, hash := range n.innerNodes {
+		want, err := n.recomputeNode(memo, rng.Start, rng.End)
+		if err != nil {
+			return fmt.Errorf("failed to recompute memoized node %v: %w", rng, err)
+		}
+		if !bytes.Equal(hash, want) {
+			return fmt.Errorf("memoized node %v is %x, want %x", rng, hash, want)
+		}
+	}
+	if n.rawRoot != nil {
+		want, err := n.recomputeNode(memo, 0, n.sizeWithPadding())
+		if err != nil {
+			return fmt.Errorf("failed to recompute the root: %w", err)
+		}
+		if !bytes.Equal(n.rawRoot, want) {
+			return fmt.Errorf("memoized root is %x, want %x", n.rawRoot, want)
+		}
+	}
+	return nil
+}
+
+// recomputeNode computes the root of the leaves [start, end) like
+// computeRoot, but without consulting or updating the memoized inner nodes,
+// the NodeVisitorFn or the metrics of the tree. memo holds the nodes
+// recomputed so far.
+func (n *NamespacedMerkleTree) recomputeNode(memo map[LeafRange][]byte, start, end int) ([]byte, error) {
+	switch {
+	case start < 0 || start > end || end > n.sizeWithPadding():
+		return nil, fmt.Errorf("range [%d, %d) is out of the bounds of the tree: %w", start, end, ErrInvalidRange)
+	case start == end:
+		return n.treeHasher.EmptyRoot(), nil
+	case start >= n.Size():
+		return n.padSubtreeRoot(end - start)
+	case end-start == 1:
+		return n.leafHashes[start], nil
+	}
+	rng := LeafRange{Start: start, End: end}
+	if hash, found := memo[rng]; found {
+		return hash, nil
+	}
+	k := getSplitPoint(end - start)
+	left, err := n.recomputeNode(memo, start, start+k)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.recomputeNode(memo, start+k, end)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := n.treeHasher.HashNode(left, right)
+	if err != nil {
+		return nil, fmt.Errorf("children of node %v: %w", rng, err)
+	}
+	memo[rng] = hash
+	return hash, nil
+}
+
+// maxReportedNamespaces bounds the number of namespaces listed by
+// debugReport.
+const maxReportedNamespaces = 16
+
+// debugReport describes the state of the tree for invariant violations.
+func (n *NamespacedMerkleTree) debugReport() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "size: %d (%d pending), namespace size: %d, ignore max namespace: %t\n",
+		n.Size(), n.pending, n.NamespaceSize(), n.treeHasher.IsMaxNamespaceIDIgnored())
+	fmt.Fprintf(&b, "min namespace: %s, max namespace: %s, unordered: %t\n", n.minNID, n.maxNID, n.unordered)
+	fmt.Fprintf(&b, "memoized root: %x, inner nodes: %d, padding nodes: %d, leaf indices: %d\n",
+		n.rawRoot, len(n.innerNodes), len(n.padNodes), len(n.leafIndices))
+
+	namespaces := make([]string, 0, len(n.namespaceRanges))
+	for nsStr := range n.namespaceRanges {
+		namespaces = append(namespaces, nsStr)
+	}
+	sort.Strings(namespaces)
+	fmt.Fprintf(&b, "namespace index (%d namespaces):", len(namespaces))
+	for i, nsStr := range namespaces {
+		if i == maxReportedNamespaces {
+			fmt.Fprintf(&b, " ...")
+			break
+		}
+		rng := n.namespaceRanges[nsStr]
+		fmt.Fprintf(&b, " %x [%d, %d)", nsStr, rng.Start, rng.End)
+	}
+	return b.String()
+}
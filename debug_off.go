@@ -0,0 +1,7 @@
+//go:build !nmt_debug
+
+package nmt
+
+// debugInvariants enables the invariant checks of checkInvariants, see the
+// nmt_debug build tag.
+const debugInvariants = false
@@ -0,0 +1,6 @@
+//go:build nmt_debug
+
+package nmt
+
+// debugInvariants enables the invariant checks of checkInvariants.
+const debugInvariants = true
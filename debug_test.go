@@ -0,0 +1,132 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// skipInDebugMode skips tests that corrupt trees on purpose or count
+// allocations, which would trip or be skewed by the invariant checks of debug
+// mode.
+func skipInDebugMode(t *testing.T) {
+	if debugInvariants {
+		t.Skip("not supported in debug mode")
+	}
+}
+
+func TestVerifyInvariants(t *testing.T) {
+	for _, opts := range [][]Option{
+		{NamespaceIDSize(1)},
+		{NamespaceIDSize(1), LazyLeafHashing(true)},
+		{NamespaceIDSize(1), LazyLeafHashing(true), DropLeafData(true)},
+		{NamespaceIDSize(1), Padding(PadWithLastLeaf), NamespaceFilter(10, 0.01)},
+		{NamespaceIDSize(1), FixedHeight(4)},
+	} {
+		tree := New(sha256.New(), opts...)
+		require.NoError(t, tree.verifyInvariants())
+		for _, leaf := range [][]byte{{1, 'a'}, {1, 'b'}, {3, 'c'}, {4, 'd'}, {4, 'd'}} {
+			require.NoError(t, tree.Push(leaf))
+			require.NoError(t, tree.verifyInvariants())
+		}
+		_, err := tree.Root()
+		require.NoError(t, err)
+		require.NoError(t, tree.verifyInvariants())
+		_, err = tree.ProveNamespace(namespace.ID{2})
+		require.NoError(t, err)
+		_, found := tree.IndexOf(tree.leafHashes[4])
+		require.True(t, found)
+		snapshot := tree.Snapshot(sha256.New())
+		require.NoError(t, tree.verifyInvariants())
+		require.NoError(t, snapshot.verifyInvariants())
+		_, err = tree.Pop()
+		require.NoError(t, err)
+		require.NoError(t, tree.verifyInvariants())
+		require.NoError(t, snapshot.Push([]byte{5, 'e'}))
+		require.NoError(t, snapshot.verifyInvariants())
+		tree.Reset()
+		require.NoError(t, tree.verifyInvariants())
+	}
+
+	// leaves added out of order are tolerated
+	tree := exampleNMT(1, true, 1, 3)
+	require.NoError(t, tree.ForceAddLeaf([]byte{2, 'x'}))
+	assert.NoError(t, tree.verifyInvariants())
+	tree.Reset()
+	assert.False(t, tree.unordered)
+}
+
+func TestVerifyInvariants_Corrupted(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(tree *NamespacedMerkleTree)
+		wantErr string
+	}{
+		{
+			"leaf data",
+			func(tree *NamespacedMerkleTree) { tree.leaves[1][1] = 'x' },
+			"leaf hash 1 is",
+		},
+		{
+			"leaf order",
+			func(tree *NamespacedMerkleTree) {
+				tree.leafHashes[0], tree.leafHashes[3] = tree.leafHashes[3], tree.leafHashes[0]
+				tree.leaves[0], tree.leaves[3] = tree.leaves[3], tree.leaves[0]
+			},
+			"leaf 1 has namespace 02, which is smaller than the namespace 04 of leaf 0",
+		},
+		{
+			"namespace index",
+			func(tree *NamespacedMerkleTree) {
+				tree.namespaceRanges[string([]byte{2})] = LeafRange{Start: 1, End: 2}
+			},
+			"namespace 02 has the range {1 2} in the namespace index, want {1 3}",
+		},
+		{
+			"max namespace",
+			func(tree *NamespacedMerkleTree) { tree.maxNID = namespace.ID{3} },
+			"minimum and maximum namespace IDs are 01 and 03, want 01 and 04",
+		},
+		{
+			"leaf indices",
+			func(tree *NamespacedMerkleTree) {
+				tree.IndexOf(nil)
+				delete(tree.leafIndices, string(tree.leafHashes[2]))
+			},
+			"leaf hash 2 is missing from the leaf indices",
+		},
+		{
+			"inner node",
+			func(tree *NamespacedMerkleTree) {
+				rng := LeafRange{Start: 0, End: 2}
+				tree.innerNodes[rng] = tree.innerNodes[LeafRange{Start: 2, End: 4}]
+			},
+			"memoized node {0 2} is",
+		},
+		{
+			"root",
+			func(tree *NamespacedMerkleTree) { tree.rawRoot = tree.leafHashes[0] },
+			"memoized root is",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := exampleNMT(1, true, 1, 2, 2, 4)
+			_, err := tree.Root()
+			require.NoError(t, err)
+			require.NoError(t, tree.verifyInvariants())
+
+			tt.corrupt(tree)
+			err = tree.verifyInvariants()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.PanicsWithValue(t, "nmt: invariant violated after Test: "+err.Error()+"\n"+tree.debugReport(), func() {
+				tree.assertInvariants("Test")
+			})
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package nmt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ErrMismatchedPadding indicates that two trees are padded differently, see
+// Padding and FixedHeight.
+var ErrMismatchedPadding = errors.New("mismatched padding")
+
+// Difference describes a maximal range of leaves in which two trees differ.
+type Difference struct {
+	LeafRange
+	// Namespaces holds the namespace IDs of the leaves within the range in
+	// either tree, in ascending order and without duplicates.
+	Namespaces []namespace.ID
+}
+
+// Diff walks n and other top-down and returns the ranges of leaves in which
+// they differ, in ascending order. Subtrees with identical roots are skipped
+// without being traversed. Leaves that only exist in the larger tree are
+// reported as differences as well, while padding leaves are not leaves of
+// the trees and never reported. Diff returns an ErrMismatchedNamespaceSize
+// error if the trees use different namespace sizes and an
+// ErrMismatchedPadding error if they are padded differently, in which case
+// their roots differ even if their leaves do not.
+func (n *NamespacedMerkleTree) Diff(other *NamespacedMerkleTree) ([]Difference, error) {
+	if n.NamespaceSize() != other.NamespaceSize() {
+		return nil, fmt.Errorf("%w: got: %v, want: %v", ErrMismatchedNamespaceSize, other.NamespaceSize(), n.NamespaceSize())
+	}
+	if n.padding != other.padding || n.fixedSize != other.fixedSize {
+		return nil, fmt.Errorf("%w: got padding %d and capacity %d, want padding %d and capacity %d",
+			ErrMismatchedPadding, other.padding, other.fixedSize, n.padding, n.fixedSize)
+	}
+	// computing the roots memoizes the inner nodes compared below
+	if _, err := n.Root(); err != nil {
+		return nil, err
+	}
+	if _, err := other.Root(); err != nil {
+		return nil, err
+	}
+
+	var diffs []Difference
+	var recurse func(start, end int) error
+	recurse = func(start, end int) error {
+		nEnd, otherEnd := minInt(end, n.Size()), minInt(end, other.Size())
+		if start >= nEnd && start >= otherEnd {
+			return nil
+		}
+		if nEnd == otherEnd {
+			nRoot, err := n.subtreeRoot(start, nEnd)
+			if err != nil {
+				return err
+			}
+			otherRoot, err := other.subtreeRoot(start, otherEnd)
+			if err != nil {
+				return err
+			}
+			if bytes.Equal(nRoot, otherRoot) {
+				return nil
+			}
+		}
+		if end-start == 1 {
+			if last := len(diffs) - 1; last >= 0 && diffs[last].End == start {
+				diffs[last].End++
+			} else {
+				diffs = append(diffs, Difference{LeafRange: LeafRange{Start: start, End: end}})
+			}
+			return nil
+		}
+		k := getSplitPoint(end - start)
+		if err := recurse(start, start+k); err != nil {
+			return err
+		}
+		return recurse(start+k, end)
+	}
+
+	fullTreeSize := getSplitPoint(maxInt(maxInt(n.Size(), other.Size()), 1)) * 2
+	if n.padding != NoPadding {
+		// the trees are walked in the shape of their roots, whose inner nodes
+		// are memoized, skipping the padding leaves
+		fullTreeSize = maxInt(maxInt(n.sizeWithPadding(), other.sizeWithPadding()), 1)
+	}
+	if err := recurse(0, fullTreeSize); err != nil {
+		return nil, err
+	}
+	for i := range diffs {
+		diffs[i].Namespaces = diffNamespaces(diffs[i].LeafRange, n, other)
+	}
+	return diffs, nil
+}
+
+// diffNamespaces returns the sorted and deduplicated namespace IDs of the
+// leaves in rng of the given trees.
+func diffNamespaces(rng LeafRange, trees ...*NamespacedMerkleTree) []namespace.ID {
+	var nIDs []namespace.ID
+	for _, tree := range trees {
+		nidSize := tree.NamespaceSize()
+		for i := rng.Start; i < minInt(rng.End, tree.Size()); i++ {
+			nIDs = insertNamespace(nIDs, namespace.ID(tree.leafHashes[i][:nidSize]))
+		}
+	}
+	return nIDs
+}
+
+// insertNamespace inserts nID into the sorted nIDs unless already present.
+func insertNamespace(nIDs []namespace.ID, nID namespace.ID) []namespace.ID {
+	i := 0
+	for i < len(nIDs) && nIDs[i].Less(nID) {
+		i++
+	}
+	if i < len(nIDs) && nIDs[i].Equal(nID) {
+		return nIDs
+	}
+	nIDs = append(nIDs, nil)
+	copy(nIDs[i+1:], nIDs[i:])
+	nIDs[i] = nID
+	return nIDs
+}
+
+func maxInt(val1, val2 int) int {
+	if val1 > val2 {
+		return val1
+	}
+	return val2
+}
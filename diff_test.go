@@ -0,0 +1,112 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestDiff(t *testing.T) {
+	base := []byte{0, 0, 1, 2, 2, 2, 3, 5, 5, 6}
+	tree := exampleNMT(1, true, base...)
+
+	tests := []struct {
+		name  string
+		other *NamespacedMerkleTree
+		want  []Difference
+	}{
+		{"identical", exampleNMT(1, true, base...), nil},
+		{
+			"one leaf differs",
+			exampleNMT(1, true, 0, 0, 1, 2, 2, 2, 4, 5, 5, 6),
+			[]Difference{{LeafRange{6, 7}, []namespace.ID{{3}, {4}}}},
+		},
+		{
+			"adjacent leaves differ",
+			exampleNMT(1, true, 0, 0, 1, 1, 1, 2, 3, 5, 5, 6),
+			[]Difference{{LeafRange{3, 5}, []namespace.ID{{1}, {2}}}},
+		},
+		{
+			"disjoint ranges differ",
+			exampleNMT(1, true, 0, 1, 1, 2, 2, 2, 3, 5, 6, 6),
+			[]Difference{
+				{LeafRange{1, 2}, []namespace.ID{{0}, {1}}},
+				{LeafRange{8, 9}, []namespace.ID{{5}, {6}}},
+			},
+		},
+		{
+			"other is larger",
+			exampleNMT(1, true, append(append([]byte{}, base...), 7, 8)...),
+			[]Difference{{LeafRange{10, 12}, []namespace.ID{{7}, {8}}}},
+		},
+		{
+			"other is smaller",
+			exampleNMT(1, true, base[:7]...),
+			[]Difference{{LeafRange{7, 10}, []namespace.ID{{5}, {6}}}},
+		},
+		{
+			"other is empty",
+			exampleNMT(1, true),
+			[]Difference{{LeafRange{0, 10}, []namespace.ID{{0}, {1}, {2}, {3}, {5}, {6}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tree.Diff(tt.other)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDiff_SkipsIdenticalSubtrees(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 2, 3, 4, 5, 6, 7)
+	m := &countingMetrics{}
+	other := New(sha256.New(), NamespaceIDSize(1), CustomMetrics(m))
+	for _, leaf := range tree.leaves {
+		require.NoError(t, other.Push(leaf))
+	}
+	_, err := other.Root()
+	require.NoError(t, err)
+	m.nodeHashes = 0
+
+	diffs, err := tree.Diff(other)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+	assert.Zero(t, m.nodeHashes)
+}
+
+func TestDiff_MismatchedNamespaceSize(t *testing.T) {
+	_, err := exampleNMT(1, true, 0).Diff(exampleNMT(2, true, 0))
+	assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+}
+
+func TestDiff_Padding(t *testing.T) {
+	for _, setter := range []Option{Padding(PadWithEmptyLeaves), Padding(PadWithLastLeaf), FixedHeight(4)} {
+		newTree := func(nIDs ...byte) *NamespacedMerkleTree {
+			tree := New(sha256.New(), NamespaceIDSize(1), setter)
+			for _, nID := range nIDs {
+				require.NoError(t, tree.Push([]byte{nID, 'd'}))
+			}
+			return tree
+		}
+		tree := newTree(0, 1, 2, 3, 4)
+		diffs, err := tree.Diff(newTree(0, 1, 2, 3, 4))
+		require.NoError(t, err)
+		assert.Empty(t, diffs)
+
+		// the padding is not reported, even if it differs
+		diffs, err = tree.Diff(newTree(0, 1, 2, 3, 5, 6))
+		require.NoError(t, err)
+		assert.Equal(t, []Difference{{LeafRange{4, 6}, []namespace.ID{{4}, {5}, {6}}}}, diffs)
+	}
+
+	_, err := exampleNMT(1, true, 0).Diff(New(sha256.New(), NamespaceIDSize(1), FixedHeight(2)))
+	assert.ErrorIs(t, err, ErrMismatchedPadding)
+	_, err = New(sha256.New(), NamespaceIDSize(1), Padding(PadWithLastLeaf)).Diff(New(sha256.New(), NamespaceIDSize(1), Padding(PadWithEmptyLeaves)))
+	assert.ErrorIs(t, err, ErrMismatchedPadding)
+}
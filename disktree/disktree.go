@@ -0,0 +1,396 @@
+// Package disktree implements a namespaced Merkle tree whose leaf hashes and
+// inner nodes live in a memory-mapped file, so that trees with hundreds of
+// millions of leaves can be built and queried with bounded memory usage: the
+// operating system pages nodes in and out as needed, and neither pushing nor
+// proving allocates more than O(log n) nodes on the heap.
+//
+// The file has a fixed layout determined by the capacity of the tree. A
+// header of headerSize bytes is followed by one level of nodes per height,
+// starting with the leaf hashes; the level of height h holds the roots of the
+// capacity>>h perfect subtrees covering the leaves [i*2^h, (i+1)*2^h), which
+// are written as soon as their last leaf is pushed. The file is created at its
+// full size but, on file systems supporting sparse files, only occupies the
+// space of the nodes written so far.
+//
+// The roots and proofs of a Tree are identical to the ones of a
+// NamespacedMerkleTree created with the same hasher and without padding. Leaf
+// data is not stored; it is kept by the application, e.g., in the dataset the
+// tree is built from.
+package disktree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"os"
+	"sort"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ErrInvalidFile indicates that a file is not a tree file or was created with
+// a different hasher.
+var ErrInvalidFile = errors.New("invalid tree file")
+
+// headerSize is the size of the file header, which consists of
+//
+//	magic || version || namespace size || flags || node size || capacity || size
+//
+// where the version, namespace size and flags are big-endian uint32s, bit 0 of
+// the flags is set if the maximum namespace ID is ignored, and the sizes and
+// the capacity are big-endian uint64s. The rest of the header is reserved.
+const (
+	headerSize = 64
+	magic      = "NMTD"
+	version    = 1
+	sizeOffset = 32
+
+	flagIgnoreMax = 1
+)
+
+// Tree is a namespaced Merkle tree of fixed capacity backed by a
+// memory-mapped file. It is not safe for concurrent use.
+type Tree struct {
+	treeHasher nmt.Hasher
+	file       *os.File
+	// data is the mapping of the whole file.
+	data     []byte
+	nodeSize int
+	capacity int
+	size     int
+	// levels holds the offsets of the levels of nodes in data, see the
+	// package documentation.
+	levels []int
+}
+
+// Create creates a tree file at path for up to capacity leaves hashed with h
+// and opens the empty tree. It fails if the file already exists.
+func Create(path string, h nmt.Hasher, capacity int) (*Tree, error) {
+	if capacity < 1 {
+		return nil, fmt.Errorf("invalid capacity %d", capacity)
+	}
+	nodeSize := len(h.EmptyRoot())
+	levels, fileSize, err := layout(nodeSize, capacity)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(int64(fileSize)); err != nil {
+		_ = f.Close()
+		_ = os.Remove(path)
+		return nil, err
+	}
+	t, err := newTree(f, h, nodeSize, capacity, levels, fileSize)
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(path)
+		return nil, err
+	}
+
+	header := t.data[:headerSize]
+	copy(header, magic)
+	binary.BigEndian.PutUint32(header[4:], version)
+	binary.BigEndian.PutUint32(header[8:], uint32(h.NamespaceSize()))
+	if h.IsMaxNamespaceIDIgnored() {
+		binary.BigEndian.PutUint32(header[12:], flagIgnoreMax)
+	}
+	binary.BigEndian.PutUint64(header[16:], uint64(nodeSize))
+	binary.BigEndian.PutUint64(header[24:], uint64(capacity))
+	return t, nil
+}
+
+// Open opens the tree file at path created by Create. h must be configured
+// like the hasher the file was created with; Open returns an ErrInvalidFile
+// error if its namespace size, node size or handling of the maximum namespace
+// ID differ, or if the file is not a tree file.
+func Open(path string, h nmt.Hasher) (*Tree, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	t, err := open(f, h)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+func open(f *os.File, h nmt.Hasher) (*Tree, error) {
+	header := make([]byte, headerSize)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("%w: failed to read header: %w", ErrInvalidFile, err)
+	}
+	if string(header[:len(magic)]) != magic || binary.BigEndian.Uint32(header[4:]) != version {
+		return nil, fmt.Errorf("%w: unknown header", ErrInvalidFile)
+	}
+	var flags uint32
+	if h.IsMaxNamespaceIDIgnored() {
+		flags = flagIgnoreMax
+	}
+	nodeSize := len(h.EmptyRoot())
+	if binary.BigEndian.Uint32(header[8:]) != uint32(h.NamespaceSize()) || binary.BigEndian.Uint32(header[12:]) != flags ||
+		binary.BigEndian.Uint64(header[16:]) != uint64(nodeSize) {
+		return nil, fmt.Errorf("%w: created with a different hasher", ErrInvalidFile)
+	}
+	capacity, size := binary.BigEndian.Uint64(header[24:]), binary.BigEndian.Uint64(header[sizeOffset:])
+	if capacity < 1 || capacity > math.MaxInt || size > capacity {
+		return nil, fmt.Errorf("%w: size %d and capacity %d", ErrInvalidFile, size, capacity)
+	}
+	levels, fileSize, err := layout(nodeSize, int(capacity))
+	if err != nil {
+		return nil, err
+	}
+	if info, err := f.Stat(); err != nil {
+		return nil, err
+	} else if info.Size() != int64(fileSize) {
+		return nil, fmt.Errorf("%w: got file size %d, want %d", ErrInvalidFile, info.Size(), fileSize)
+	}
+	t, err := newTree(f, h, nodeSize, int(capacity), levels, fileSize)
+	if err != nil {
+		return nil, err
+	}
+	t.size = int(size)
+	return t, nil
+}
+
+func newTree(f *os.File, h nmt.Hasher, nodeSize, capacity int, levels []int, fileSize int) (*Tree, error) {
+	data, err := mmap(f, fileSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map tree file: %w", err)
+	}
+	return &Tree{treeHasher: h, file: f, data: data, nodeSize: nodeSize, capacity: capacity, levels: levels}, nil
+}
+
+// layout returns the offsets of the levels and the size of the file of a tree
+// with the given node size and capacity.
+func layout(nodeSize, capacity int) ([]int, int, error) {
+	var levels []int
+	offset := uint64(headerSize)
+	for c := capacity; c > 0; c >>= 1 {
+		levels = append(levels, int(offset))
+		offset += uint64(c) * uint64(nodeSize)
+		if offset > math.MaxInt {
+			return nil, 0, fmt.Errorf("capacity %d is too large", capacity)
+		}
+	}
+	return levels, int(offset), nil
+}
+
+// Size returns the number of leaves in the tree.
+func (t *Tree) Size() int {
+	return t.size
+}
+
+// Capacity returns the maximum number of leaves of the tree.
+func (t *Tree) Capacity() int {
+	return t.capacity
+}
+
+// Push adds the namespace-prefixed data as the next leaf. The same rules as
+// for NamespacedMerkleTree.Push apply, i.e., it returns an
+// nmt.ErrInvalidLeafLen error if the data is shorter than the namespace size
+// and an nmt.ErrInvalidPushOrder error if its namespace ID is smaller than the
+// one of the previous leaf. It returns an nmt.ErrTreeFull error once the tree
+// holds Capacity leaves.
+func (t *Tree) Push(namespacedData namespace.PrefixedData) error {
+	if t.size >= t.capacity {
+		return fmt.Errorf("%w: capacity %d", nmt.ErrTreeFull, t.capacity)
+	}
+	nidSize := t.treeHasher.NamespaceSize()
+	if len(namespacedData) < int(nidSize) {
+		return fmt.Errorf("%w: got: %v, want >= %v", nmt.ErrInvalidLeafLen, len(namespacedData), nidSize)
+	}
+	nID := namespacedData.NamespaceID(nidSize)
+	if t.size > 0 {
+		if lastNID := t.leafNamespace(t.size - 1); nID.Less(lastNID) {
+			return fmt.Errorf("%w: last namespace: %x, pushed: %x", nmt.ErrInvalidPushOrder, lastNID, nID)
+		}
+	}
+
+	node, err := t.treeHasher.HashLeaf(namespacedData)
+	if err != nil {
+		return err
+	}
+	if len(node) != t.nodeSize {
+		return fmt.Errorf("%w: got: %v, want: %v", nmt.ErrInvalidNodeLen, len(node), t.nodeSize)
+	}
+	copy(t.node(0, t.size), node)
+	// complete all perfect subtrees the leaf is the last leaf of
+	for height, i := 0, t.size; i&1 == 1; height, i = height+1, i>>1 {
+		if node, err = t.treeHasher.HashNode(t.node(height, i-1), node); err != nil {
+			return err
+		}
+		copy(t.node(height+1, i>>1), node)
+	}
+	t.size++
+	// the size is written last so that it never covers missing nodes
+	binary.BigEndian.PutUint64(t.data[sizeOffset:], uint64(t.size))
+	return nil
+}
+
+// LeafHash returns the namespaced hash of the leaf at index.
+func (t *Tree) LeafHash(index int) ([]byte, error) {
+	if index < 0 || index >= t.size {
+		return nil, fmt.Errorf("%w: leaf %d of %d", nmt.ErrInvalidRange, index, t.size)
+	}
+	return bytes.Clone(t.node(0, index)), nil
+}
+
+// Root returns the root of the tree.
+func (t *Tree) Root() ([]byte, error) {
+	if t.size == 0 {
+		return t.treeHasher.EmptyRoot(), nil
+	}
+	root, err := t.subtreeRoot(0, t.size)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Clone(root), nil
+}
+
+// ProveRange returns the inclusion proof of the leaves [start, end), see
+// NamespacedMerkleTree.ProveRange. It returns an nmt.ErrInvalidRange error if
+// the range is empty or out of bounds.
+func (t *Tree) ProveRange(start, end int) (nmt.Proof, error) {
+	if start < 0 || start >= end || end > t.size {
+		return nmt.Proof{}, nmt.ErrInvalidRange
+	}
+	nodes, err := t.rangeProof(0, t.size, start, end, [][]byte{})
+	if err != nil {
+		return nmt.Proof{}, err
+	}
+	return nmt.NewInclusionProof(start, end, nodes, t.treeHasher.IsMaxNamespaceIDIgnored()), nil
+}
+
+// ProveNamespace returns the namespace proof of nID, see
+// NamespacedMerkleTree.ProveNamespace. The range of leaves of nID is found by
+// a binary search over the leaf hashes. It returns an
+// nmt.ErrMismatchedNamespaceSize error if nID is not of the namespace size of
+// the tree.
+func (t *Tree) ProveNamespace(nID namespace.ID) (nmt.Proof, error) {
+	ignoreMax := t.treeHasher.IsMaxNamespaceIDIgnored()
+	if nID.Size() != t.treeHasher.NamespaceSize() {
+		return nmt.Proof{}, fmt.Errorf("%w: got: %v, want: %v", nmt.ErrMismatchedNamespaceSize, nID.Size(), t.treeHasher.NamespaceSize())
+	}
+	if t.size == 0 {
+		return nmt.NewEmptyRangeProof(ignoreMax), nil
+	}
+	root, err := t.subtreeRoot(0, t.size)
+	if err != nil {
+		return nmt.Proof{}, err
+	}
+	nidSize := t.treeHasher.NamespaceSize()
+	if nID.Less(nmt.MinNamespace(root, nidSize)) || namespace.ID(nmt.MaxNamespace(root, nidSize)).Less(nID) {
+		return nmt.NewEmptyRangeProof(ignoreMax), nil
+	}
+
+	start := sort.Search(t.size, func(i int) bool { return !t.leafNamespace(i).Less(nID) })
+	end := sort.Search(t.size, func(i int) bool { return nID.Less(t.leafNamespace(i)) })
+	if start == end {
+		// the leaf with the smallest namespace ID larger than nID proves the
+		// absence of nID
+		nodes, err := t.rangeProof(0, t.size, start, start+1, [][]byte{})
+		if err != nil {
+			return nmt.Proof{}, err
+		}
+		return nmt.NewAbsenceProof(start, start+1, nodes, bytes.Clone(t.node(0, start)), ignoreMax), nil
+	}
+	nodes, err := t.rangeProof(0, t.size, start, end, [][]byte{})
+	if err != nil {
+		return nmt.Proof{}, err
+	}
+	return nmt.NewInclusionProof(start, end, nodes, ignoreMax), nil
+}
+
+// Sync flushes all nodes pushed so far to the file. Once Sync returns, they
+// persist across crashes of the process and of the operating system. It
+// returns an os.ErrClosed error if the tree was closed.
+func (t *Tree) Sync() error {
+	if t.data == nil {
+		return os.ErrClosed
+	}
+	return msync(t.data)
+}
+
+// Close syncs and unmaps the file and closes it. The tree must not be used
+// afterwards; closing it again returns an os.ErrClosed error.
+func (t *Tree) Close() error {
+	if t.data == nil {
+		return os.ErrClosed
+	}
+	err := t.Sync()
+	if unmapErr := munmap(t.data); err == nil {
+		err = unmapErr
+	}
+	t.data = nil
+	if closeErr := t.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// node returns the node of the given height at index i of its level. The
+// returned slice references the mapping.
+func (t *Tree) node(height, i int) []byte {
+	offset := t.levels[height] + i*t.nodeSize
+	return t.data[offset : offset+t.nodeSize : offset+t.nodeSize]
+}
+
+func (t *Tree) leafNamespace(i int) namespace.ID {
+	return nmt.MinNamespace(t.node(0, i), t.treeHasher.NamespaceSize())
+}
+
+// subtreeRoot returns the root of the subtree covering the leaves [start,
+// end), which must be a subtree of the tree of Size leaves. Only the roots of
+// the subtrees on the right edge of the tree, which are not perfect, are
+// hashed.
+func (t *Tree) subtreeRoot(start, end int) ([]byte, error) {
+	if width := end - start; width&(width-1) == 0 {
+		height := bits.TrailingZeros(uint(width))
+		return t.node(height, start>>height), nil
+	}
+	k := splitPoint(end - start)
+	left, err := t.subtreeRoot(start, start+k)
+	if err != nil {
+		return nil, err
+	}
+	right, err := t.subtreeRoot(start+k, end)
+	if err != nil {
+		return nil, err
+	}
+	return t.treeHasher.HashNode(left, right)
+}
+
+// rangeProof appends the roots of the largest subtrees of the subtree [start,
+// end) not overlapping the range [proofStart, proofEnd) to nodes, in order.
+func (t *Tree) rangeProof(start, end, proofStart, proofEnd int, nodes [][]byte) ([][]byte, error) {
+	if end <= proofStart || start >= proofEnd {
+		root, err := t.subtreeRoot(start, end)
+		if err != nil {
+			return nil, err
+		}
+		return append(nodes, bytes.Clone(root)), nil
+	}
+	if end-start == 1 {
+		return nodes, nil
+	}
+	k := splitPoint(end - start)
+	nodes, err := t.rangeProof(start, start+k, proofStart, proofEnd, nodes)
+	if err != nil {
+		return nil, err
+	}
+	return t.rangeProof(start+k, end, proofStart, proofEnd, nodes)
+}
+
+// splitPoint returns the largest power of two smaller than n, see RFC 6962.
+func splitPoint(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
@@ -0,0 +1,144 @@
+package disktree
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func newHasher() nmt.Hasher {
+	return nmt.NewNmtHasher(sha256.New(), 2, true)
+}
+
+func create(t *testing.T, capacity int) (*Tree, string) {
+	path := filepath.Join(t.TempDir(), "tree")
+	tree, err := Create(path, newHasher(), capacity)
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("memory-mapped files are not supported on this platform")
+	}
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tree.Close() })
+	return tree, path
+}
+
+func leaf(i int) []byte {
+	nID := byte(i / 3)
+	return append([]byte{nID, nID}, []byte(fmt.Sprintf("leaf_%d", i))...)
+}
+
+func TestTree(t *testing.T) {
+	tree, _ := create(t, 40)
+	want := nmt.New(sha256.New(), nmt.NamespaceIDSize(2))
+	for size := 0; size <= 40; size++ {
+		wantRoot, err := want.Root()
+		require.NoError(t, err)
+		gotRoot, err := tree.Root()
+		require.NoError(t, err)
+		require.Equal(t, wantRoot, gotRoot, "size %d", size)
+
+		for start := 0; start < size; start += 3 {
+			for end := start + 1; end <= size; end += 5 {
+				wantProof, err := want.ProveRange(start, end)
+				require.NoError(t, err)
+				gotProof, err := tree.ProveRange(start, end)
+				require.NoError(t, err)
+				assert.True(t, wantProof.Equal(gotProof), "size %d, range [%d, %d)", size, start, end)
+			}
+		}
+		for nID := 0; nID <= 15; nID++ {
+			// namespaces above 0x0D0D are absent
+			for _, id := range []namespace.ID{{byte(nID), byte(nID)}, {byte(nID), 0}} {
+				wantProof, err := want.ProveNamespace(id)
+				require.NoError(t, err)
+				gotProof, err := tree.ProveNamespace(id)
+				require.NoError(t, err)
+				assert.True(t, wantProof.Equal(gotProof), "size %d, namespace %x", size, id)
+			}
+		}
+
+		if size < 40 {
+			require.NoError(t, want.Push(leaf(size)))
+			require.NoError(t, tree.Push(leaf(size)))
+		}
+	}
+	assert.Equal(t, 40, tree.Size())
+	leafHash, err := tree.LeafHash(39)
+	require.NoError(t, err)
+	wantLeafHash, err := newHasher().HashLeaf(leaf(39))
+	require.NoError(t, err)
+	assert.Equal(t, wantLeafHash, leafHash)
+}
+
+func TestTree_Errors(t *testing.T) {
+	tree, _ := create(t, 2)
+	assert.ErrorIs(t, tree.Push([]byte{1}), nmt.ErrInvalidLeafLen)
+	require.NoError(t, tree.Push([]byte{0, 2, 'a'}))
+	assert.ErrorIs(t, tree.Push([]byte{0, 1, 'b'}), nmt.ErrInvalidPushOrder)
+	require.NoError(t, tree.Push([]byte{0, 2, 'b'}))
+	assert.ErrorIs(t, tree.Push([]byte{0, 3, 'c'}), nmt.ErrTreeFull)
+	assert.Equal(t, 2, tree.Size())
+
+	_, err := tree.ProveRange(1, 3)
+	assert.ErrorIs(t, err, nmt.ErrInvalidRange)
+	_, err = tree.LeafHash(2)
+	assert.ErrorIs(t, err, nmt.ErrInvalidRange)
+	_, err = tree.ProveNamespace(namespace.ID{1})
+	assert.ErrorIs(t, err, nmt.ErrMismatchedNamespaceSize)
+}
+
+func TestOpen(t *testing.T) {
+	tree, path := create(t, 10)
+	for i := 0; i < 7; i++ {
+		require.NoError(t, tree.Push(leaf(i)))
+	}
+	want, err := tree.Root()
+	require.NoError(t, err)
+	require.NoError(t, tree.Close())
+
+	_, err = Create(path, newHasher(), 10)
+	assert.ErrorIs(t, err, os.ErrExist)
+
+	reopened, err := Open(path, newHasher())
+	require.NoError(t, err)
+	defer func() { _ = reopened.Close() }()
+	assert.Equal(t, 7, reopened.Size())
+	assert.Equal(t, 10, reopened.Capacity())
+	got, err := reopened.Root()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	// pushing continues where the tree was closed
+	assert.ErrorIs(t, reopened.Push([]byte{0, 0}), nmt.ErrInvalidPushOrder)
+	require.NoError(t, reopened.Push(leaf(7)))
+}
+
+func TestOpen_Invalid(t *testing.T) {
+	tree, path := create(t, 4)
+	require.NoError(t, tree.Close())
+
+	for _, h := range []nmt.Hasher{
+		nmt.NewNmtHasher(sha256.New(), 1, true),
+		nmt.NewNmtHasher(sha256.New(), 2, false),
+	} {
+		_, err := Open(path, h)
+		assert.ErrorIs(t, err, ErrInvalidFile)
+	}
+
+	other := filepath.Join(t.TempDir(), "other")
+	require.NoError(t, os.WriteFile(other, []byte("not a tree"), 0o600))
+	_, err := Open(other, newHasher())
+	assert.ErrorIs(t, err, ErrInvalidFile)
+
+	// a truncated file is rejected
+	require.NoError(t, os.Truncate(path, headerSize+1))
+	_, err = Open(path, newHasher())
+	assert.ErrorIs(t, err, ErrInvalidFile)
+}
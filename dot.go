@@ -0,0 +1,104 @@
+package nmt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// dotDigestPrefixLen is the number of digest bytes shown per node in the DOT
+// output.
+const dotDigestPrefixLen = 4
+
+// Dump writes the structure of the tree to w in the Graphviz DOT format. Every
+// node is annotated with the leaf range [start, end) it covers, its namespace
+// range and a truncated digest. The output can be rendered with e.g.
+// `dot -Tsvg`. Dump is meant as a debugging aid and recomputes all inner nodes
+// of the tree. Subtrees consisting of padding leaves only (see Padding and
+// FixedHeight) are shown as a single node.
+func (n *NamespacedMerkleTree) Dump(w io.Writer) error {
+	if err := n.hashPendingLeaves(); err != nil {
+		return err
+	}
+	d := &dotWriter{tree: n, w: w}
+	d.printf("digraph NMT {\n\tnode [shape=box, fontname=monospace];\n")
+	if n.sizeWithPadding() == 0 {
+		d.node(0, 0, n.treeHasher.EmptyRoot())
+	} else if _, _, err := d.walk(0, n.sizeWithPadding()); err != nil {
+		return err
+	}
+	d.printf("}\n")
+	return d.err
+}
+
+// ToDOT returns the output of Dump as a string.
+func (n *NamespacedMerkleTree) ToDOT() (string, error) {
+	var buf bytes.Buffer
+	if err := n.Dump(&buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+type dotWriter struct {
+	tree   *NamespacedMerkleTree
+	w      io.Writer
+	nextID int
+	// err holds the first write error; subsequent writes are skipped.
+	err error
+}
+
+func (d *dotWriter) printf(format string, args ...interface{}) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = fmt.Fprintf(d.w, format, args...)
+}
+
+// node emits a node for the subtree [start, end) with the namespaced hash h and
+// returns its DOT identifier.
+func (d *dotWriter) node(start, end int, h []byte) int {
+	id := d.nextID
+	d.nextID++
+	nidSize := d.tree.NamespaceSize()
+	digest := h[2*nidSize:]
+	if len(digest) > dotDigestPrefixLen {
+		digest = digest[:dotDigestPrefixLen]
+	}
+	d.printf("\tn%d [label=\"[%d, %d)\\nmin: %x\\nmax: %x\\nhash: %s…\"];\n",
+		id, start, end, MinNamespace(h, nidSize), MaxNamespace(h, nidSize), hex.EncodeToString(digest))
+	return id
+}
+
+// walk emits the subtree [start, end) in the same shape computeRoot uses and
+// returns the identifier and hash of its root.
+func (d *dotWriter) walk(start, end int) (int, []byte, error) {
+	if start >= d.tree.Size() {
+		h, err := d.tree.padSubtreeRoot(end - start)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to compute padding subtree root [%d, %d): %w", start, end, err)
+		}
+		return d.node(start, end, h), h, nil
+	}
+	if end-start == 1 {
+		h := d.tree.leafHashes[start]
+		return d.node(start, end, h), h, nil
+	}
+	k := getSplitPoint(end - start)
+	leftID, left, err := d.walk(start, start+k)
+	if err != nil {
+		return 0, nil, err
+	}
+	rightID, right, err := d.walk(start+k, end)
+	if err != nil {
+		return 0, nil, err
+	}
+	h, err := d.tree.treeHasher.HashNode(left, right)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to compute subtree root [%d, %d): %w", start, end, err)
+	}
+	id := d.node(start, end, h)
+	d.printf("\tn%d -> n%d;\n\tn%d -> n%d;\n", id, leftID, id, rightID)
+	return id, h, nil
+}
@@ -0,0 +1,72 @@
+package nmt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToDOT(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 0, 1, 2, 3)
+	dot, err := tree.ToDOT()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(dot, "digraph NMT {"))
+	assert.True(t, strings.HasSuffix(dot, "}\n"))
+	// 5 leaves and 4 inner nodes, with two edges per inner node
+	assert.Equal(t, 9, strings.Count(dot, "[label="))
+	assert.Equal(t, 8, strings.Count(dot, "->"))
+	assert.Contains(t, dot, `[0, 5)\nmin: 00\nmax: 03`)
+	assert.Contains(t, dot, `[2, 3)\nmin: 01\nmax: 01`)
+
+	root, err := tree.Root()
+	require.NoError(t, err)
+	assert.Contains(t, dot, "hash: "+hex.EncodeToString(root[2:2+dotDigestPrefixLen])+"…")
+}
+
+func TestToDOT_EmptyTree(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1))
+	dot, err := tree.ToDOT()
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(dot, "[label="))
+	assert.Contains(t, dot, `[0, 0)\nmin: 00\nmax: 00`)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("write failed") }
+
+func TestDump_WriteError(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1)
+	assert.Error(t, tree.Dump(failingWriter{}))
+	assert.NoError(t, tree.Dump(&bytes.Buffer{}))
+}
+
+func TestToDOT_Padding(t *testing.T) {
+	for _, setter := range []Option{Padding(PadWithEmptyLeaves), Padding(PadWithLastLeaf), FixedHeight(3)} {
+		tree := New(sha256.New(), NamespaceIDSize(1), setter)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, tree.Push([]byte{byte(i), 'd'}))
+		}
+		dot, err := tree.ToDOT()
+		require.NoError(t, err)
+
+		root, err := tree.Root()
+		require.NoError(t, err)
+		assert.Contains(t, dot, "hash: "+hex.EncodeToString(root[2:2+dotDigestPrefixLen])+"…")
+		assert.Contains(t, dot, `[3, 4)\n`)
+	}
+	// the padding of a tree of fixed height is a single node
+	tree := New(sha256.New(), NamespaceIDSize(1), FixedHeight(3))
+	require.NoError(t, tree.Push([]byte{0, 'd'}))
+	dot, err := tree.ToDOT()
+	require.NoError(t, err)
+	assert.Contains(t, dot, `[4, 8)\n`)
+	assert.NotContains(t, dot, `[5, 6)\n`)
+}
@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+pragma solidity ^0.8.5;
+
+/// @title NMTVerifier
+/// @notice Verifies inclusion proofs of namespaced Merkle trees using SHA256 as
+/// generated by evm.EncodeInclusionProof of github.com/celestiaorg/nmt.
+/// @dev The proof is a sequence of 32-byte words: the flags (bit 0 is set if
+/// the tree ignores the maximum namespace ID), the number of siblings and, for
+/// every sibling from the leaf up to the root, its side flag
+/// (1 if the sibling is the left child), its minimum and maximum namespace IDs
+/// (left-aligned and zero-padded) and its digest.
+library NMTVerifier {
+    struct Node {
+        bytes32 min;
+        bytes32 max;
+        bytes32 digest;
+    }
+
+    uint256 private constant HEADER_SIZE = 64;
+    uint256 private constant SIBLING_SIZE = 128;
+
+    /// @notice Returns whether leaf, including its namespace ID prefix, is a
+    /// leaf of the tree with the given root and namespace IDs of nidSize bytes
+    /// according to proof.
+    function verifyInclusion(Node memory root, uint256 nidSize, bytes calldata leaf, bytes calldata proof)
+        internal
+        pure
+        returns (bool)
+    {
+        if (proof.length < HEADER_SIZE) {
+            return false;
+        }
+        uint256 count = uint256(bytes32(proof[32:64]));
+        bool ignoreMax;
+        {
+            uint256 flags = uint256(bytes32(proof[0:32]));
+            if (nidSize == 0 || nidSize > 32 || flags > 1 || leaf.length < nidSize) {
+                return false;
+            }
+            ignoreMax = flags == 1;
+        }
+        if (count > (proof.length - HEADER_SIZE) / SIBLING_SIZE || proof.length != HEADER_SIZE + count * SIBLING_SIZE) {
+            return false;
+        }
+        // maxNid is the maximum namespace ID, which is also the mask of the
+        // namespace ID bytes of a word
+        bytes32 maxNid = bytes32(~(type(uint256).max >> (8 * nidSize)));
+
+        Node memory cur = leafNode(leaf, nidSize);
+        for (uint256 i = 0; i < count; i++) {
+            bool ok;
+            (ok, cur) = step(cur, proof, HEADER_SIZE + i * SIBLING_SIZE, nidSize, ignoreMax, maxNid);
+            if (!ok) {
+                return false;
+            }
+        }
+        return cur.min == root.min && cur.max == root.max && cur.digest == root.digest;
+    }
+
+    /// @notice Computes the leaf hash of leaf like NmtHasher.HashLeaf.
+    function leafNode(bytes calldata leaf, uint256 nidSize) private pure returns (Node memory) {
+        bytes32 nid = bytes32(leaf[0:nidSize]);
+        return Node(nid, nid, sha256(abi.encodePacked(bytes1(0x00), leaf)));
+    }
+
+    /// @notice Combines cur with the sibling encoded at offset off of proof.
+    /// It returns false if the sibling is malformed or the siblings are not
+    /// ordered by namespace.
+    function step(Node memory cur, bytes calldata proof, uint256 off, uint256 nidSize, bool ignoreMax, bytes32 maxNid)
+        private
+        pure
+        returns (bool, Node memory)
+    {
+        uint256 side = uint256(bytes32(proof[off:off + 32]));
+        Node memory sibling =
+            Node(bytes32(proof[off + 32:off + 64]), bytes32(proof[off + 64:off + 96]), bytes32(proof[off + 96:off + 128]));
+        if (
+            side > 1 || (sibling.min & ~maxNid) != 0 || (sibling.max & ~maxNid) != 0 || sibling.max < sibling.min
+        ) {
+            return (false, cur);
+        }
+        Node memory left = cur;
+        Node memory right = sibling;
+        if (side == 1) {
+            left = sibling;
+            right = cur;
+        }
+        if (right.min < left.max) {
+            return (false, cur);
+        }
+        return (true, hashNode(left, right, nidSize, ignoreMax, maxNid));
+    }
+
+    /// @notice Computes the parent of left and right like NmtHasher.HashNode.
+    function hashNode(Node memory left, Node memory right, uint256 nidSize, bool ignoreMax, bytes32 maxNid)
+        private
+        pure
+        returns (Node memory)
+    {
+        bytes32 max = ignoreMax && right.min == maxNid ? left.max : right.max;
+        bytes memory packed = abi.encodePacked(
+            bytes1(0x01),
+            prefix(left.min, nidSize),
+            prefix(left.max, nidSize),
+            left.digest,
+            prefix(right.min, nidSize),
+            prefix(right.max, nidSize),
+            right.digest
+        );
+        return Node(left.min, max, sha256(packed));
+    }
+
+    /// @notice Returns the first size bytes of word.
+    function prefix(bytes32 word, uint256 size) private pure returns (bytes memory out) {
+        out = new bytes(size);
+        for (uint256 i = 0; i < size; i++) {
+            out[i] = word[i];
+        }
+    }
+}
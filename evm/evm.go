@@ -0,0 +1,221 @@
+// Package evm serializes NMT inclusion proofs into a calldata-friendly layout
+// of 32-byte words, so that bridges can verify the inclusion of namespaced
+// leaves on-chain against a posted root. The matching Solidity library is
+// available as VerifierSource, and Verify is a Go implementation of the very
+// same routine, e.g., to check encoded proofs before submitting them.
+//
+// Only inclusion proofs of single leaves of trees using SHA256 and namespace
+// IDs of at most 32 bytes are supported. An encoded proof consists of the
+// flags (bit 0 is set if the tree ignores the maximum namespace ID), the number
+// of siblings and, for every sibling from the leaf up to the root, its side
+// flag (1 if the sibling is the left child), its minimum and maximum namespace
+// IDs (left-aligned and zero-padded) and its digest, each in a word of its own.
+// The namespace size is not part of the encoding since the verifier must know
+// it along with the root.
+package evm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// WordSize is the size of an EVM word in bytes.
+const WordSize = 32
+
+const (
+	headerWords   = 2
+	siblingWords  = 4
+	flagIgnoreMax = 1
+)
+
+// ErrUnsupportedProof indicates that a proof or root cannot be encoded for the
+// on-chain verifier.
+var ErrUnsupportedProof = errors.New("unsupported proof")
+
+// VerifierSource is the source code of the Solidity library NMTVerifier,
+// whose verifyInclusion function verifies proofs encoded by
+// EncodeInclusionProof.
+//
+//go:embed NMTVerifier.sol
+var VerifierSource string
+
+// Word is an EVM word.
+type Word [WordSize]byte
+
+// Root is a namespaced root in the layout of the NMTVerifier.Node struct.
+type Root struct {
+	Min, Max, Digest Word
+}
+
+// EncodeRoot converts the namespaced root of a tree with namespace IDs of
+// nidSize bytes into a Root. It returns an ErrUnsupportedProof error if the
+// root does not consist of two namespace IDs of at most WordSize bytes and a
+// SHA256 digest.
+func EncodeRoot(root []byte, nidSize namespace.IDSize) (Root, error) {
+	if nidSize == 0 || nidSize > WordSize {
+		return Root{}, fmt.Errorf("%w: namespace size %d is not within [1, %d]", ErrUnsupportedProof, nidSize, WordSize)
+	}
+	if len(root) != 2*int(nidSize)+sha256.Size {
+		return Root{}, fmt.Errorf("%w: got node size %d, want %d", ErrUnsupportedProof, len(root), 2*int(nidSize)+sha256.Size)
+	}
+	var r Root
+	copy(r.Min[:], nmt.MinNamespace(root, nidSize))
+	copy(r.Max[:], nmt.MaxNamespace(root, nidSize))
+	copy(r.Digest[:], root[2*nidSize:])
+	return r, nil
+}
+
+// EncodeInclusionProof encodes the inclusion proof of a single leaf, e.g., as
+// returned by NamespacedMerkleTree.Prove, of a tree of treeSize leaves
+// (including padding) with namespace IDs of nidSize bytes. It returns an
+// ErrUnsupportedProof error if the proof is an absence proof, covers more
+// than one leaf, or its nodes cannot be encoded (see EncodeRoot).
+func EncodeInclusionProof(proof nmt.Proof, treeSize int, nidSize namespace.IDSize) ([]byte, error) {
+	if proof.IsOfAbsence() || proof.End()-proof.Start() != 1 {
+		return nil, fmt.Errorf("%w: only inclusion proofs of single leaves are supported", ErrUnsupportedProof)
+	}
+	nodes, err := proof.GIndexNodes(treeSize)
+	if err != nil {
+		return nil, err
+	}
+	gindex, err := nmt.LeafGIndex(treeSize, proof.Start())
+	if err != nil {
+		return nil, err
+	}
+
+	var flags uint64
+	if proof.IsMaxNamespaceIDIgnored() {
+		flags |= flagIgnoreMax
+	}
+	words := []Word{uintWord(flags), uintWord(uint64(len(nodes)))}
+	// the siblings are ordered from the leaf up to the root; siblings
+	// covering no leaves do not exist
+	for ; gindex > 1; gindex >>= 1 {
+		node, found := nodes[gindex^1]
+		if !found {
+			continue
+		}
+		sibling, err := EncodeRoot(node, nidSize)
+		if err != nil {
+			return nil, err
+		}
+		var side uint64
+		if gindex&1 == 1 {
+			side = 1
+		}
+		words = append(words, uintWord(side), sibling.Min, sibling.Max, sibling.Digest)
+	}
+	if len(words) != headerWords+siblingWords*len(nodes) {
+		return nil, fmt.Errorf("%w: proof nodes are not on the path of leaf %d", ErrUnsupportedProof, proof.Start())
+	}
+
+	encoded := make([]byte, 0, len(words)*WordSize)
+	for _, word := range words {
+		encoded = append(encoded, word[:]...)
+	}
+	return encoded, nil
+}
+
+// Verify checks that leaf, including its namespace ID prefix, is a leaf of the
+// tree with the given root and namespace IDs of nidSize bytes according to the
+// encoded proof, exactly like NMTVerifier.verifyInclusion does on-chain.
+func Verify(root Root, nidSize namespace.IDSize, leaf, proof []byte) bool {
+	if len(proof) < headerWords*WordSize {
+		return false
+	}
+	flags, ok1 := wordUint(wordAt(proof, 0))
+	count, ok2 := wordUint(wordAt(proof, 1))
+	if !ok1 || !ok2 {
+		return false
+	}
+	if nidSize == 0 || nidSize > WordSize || flags > flagIgnoreMax || len(leaf) < int(nidSize) {
+		return false
+	}
+	if count > uint64(len(proof)/WordSize-headerWords)/siblingWords || uint64(len(proof)) != (headerWords+count*siblingWords)*WordSize {
+		return false
+	}
+	size, ignoreMax := int(nidSize), flags == flagIgnoreMax
+
+	var maxNID Word
+	for i := 0; i < size; i++ {
+		maxNID[i] = 0xFF
+	}
+	var nID Word
+	copy(nID[:], leaf[:size])
+	cur := Root{Min: nID, Max: nID, Digest: sha256.Sum256(append([]byte{nmt.LeafPrefix}, leaf...))}
+	for i := 0; i < int(count); i++ {
+		off := headerWords + i*siblingWords
+		side, ok := wordUint(wordAt(proof, off))
+		sibling := Root{Min: wordAt(proof, off+1), Max: wordAt(proof, off+2), Digest: wordAt(proof, off+3)}
+		if !ok || side > 1 || !isPrefixWord(sibling.Min, size) || !isPrefixWord(sibling.Max, size) ||
+			bytes.Compare(sibling.Max[:], sibling.Min[:]) < 0 {
+			return false
+		}
+		left, right := cur, sibling
+		if side == 1 {
+			left, right = sibling, cur
+		}
+		if bytes.Compare(right.Min[:], left.Max[:]) < 0 {
+			return false
+		}
+		cur = hashNode(left, right, size, ignoreMax, maxNID)
+	}
+	return cur == root
+}
+
+// hashNode computes the parent of left and right like NmtHasher.HashNode.
+func hashNode(left, right Root, nidSize int, ignoreMax bool, maxNID Word) Root {
+	parent := Root{Min: left.Min, Max: right.Max}
+	if ignoreMax && right.Min == maxNID {
+		parent.Max = left.Max
+	}
+	h := sha256.New()
+	h.Write([]byte{nmt.NodePrefix})
+	for _, node := range []Root{left, right} {
+		h.Write(node.Min[:nidSize])
+		h.Write(node.Max[:nidSize])
+		h.Write(node.Digest[:])
+	}
+	copy(parent.Digest[:], h.Sum(nil))
+	return parent
+}
+
+func uintWord(v uint64) Word {
+	var w Word
+	binary.BigEndian.PutUint64(w[WordSize-8:], v)
+	return w
+}
+
+// wordUint returns the value of w as an unsigned integer if it fits into 64
+// bits.
+func wordUint(w Word) (uint64, bool) {
+	for _, b := range w[:WordSize-8] {
+		if b != 0 {
+			return 0, false
+		}
+	}
+	return binary.BigEndian.Uint64(w[WordSize-8:]), true
+}
+
+// isPrefixWord returns whether all but the first size bytes of w are zero.
+func isPrefixWord(w Word, size int) bool {
+	for _, b := range w[size:] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func wordAt(b []byte, i int) Word {
+	var w Word
+	copy(w[:], b[i*WordSize:(i+1)*WordSize])
+	return w
+}
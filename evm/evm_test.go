@@ -0,0 +1,118 @@
+package evm
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestEncodeInclusionProof(t *testing.T) {
+	for _, ignoreMax := range []bool{false, true} {
+		for size := 1; size <= 9; size++ {
+			t.Run(fmt.Sprintf("ignoreMax=%v/size=%d", ignoreMax, size), func(t *testing.T) {
+				tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(2), nmt.IgnoreMaxNamespace(ignoreMax))
+				leaves := make([][]byte, size)
+				for i := range leaves {
+					nID := []byte{0, byte(i / 2)}
+					if i == size-1 && size > 2 {
+						nID = []byte{0xFF, 0xFF}
+					}
+					leaves[i] = append(nID, byte(i))
+					require.NoError(t, tree.Push(leaves[i]))
+				}
+				rawRoot, err := tree.Root()
+				require.NoError(t, err)
+				root, err := EncodeRoot(rawRoot, 2)
+				require.NoError(t, err)
+
+				for i, leaf := range leaves {
+					proof, err := tree.Prove(i)
+					require.NoError(t, err)
+					encoded, err := EncodeInclusionProof(proof, size, 2)
+					require.NoError(t, err)
+					assert.Len(t, encoded, (headerWords+siblingWords*len(proof.Nodes()))*WordSize)
+					assert.True(t, Verify(root, 2, leaf, encoded), "leaf %d", i)
+					assert.False(t, Verify(root, 2, append([]byte{}, leaf[:2]...), encoded), "leaf %d", i)
+					for j := range encoded {
+						if j/WordSize == 0 {
+							// the flags only matter if a node of the
+							// maximum namespace ID is a right child
+							continue
+						}
+						tampered := append([]byte{}, encoded...)
+						tampered[j] ^= 1
+						assert.False(t, Verify(root, 2, leaf, tampered), "leaf %d, byte %d", i, j)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestEncodeInclusionProof_Padded(t *testing.T) {
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(1), nmt.FixedHeight(3))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, tree.Push([]byte{byte(i), 'x'}))
+	}
+	rawRoot, err := tree.Root()
+	require.NoError(t, err)
+	root, err := EncodeRoot(rawRoot, 1)
+	require.NoError(t, err)
+	proof, err := tree.Prove(2)
+	require.NoError(t, err)
+	encoded, err := EncodeInclusionProof(proof, tree.Capacity(), 1)
+	require.NoError(t, err)
+	assert.True(t, Verify(root, 1, []byte{2, 'x'}, encoded))
+}
+
+func TestEncodeInclusionProof_Unsupported(t *testing.T) {
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(1))
+	for _, leaf := range [][]byte{{1, 'a'}, {3, 'b'}} {
+		require.NoError(t, tree.Push(leaf))
+	}
+	absence, err := tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	_, err = EncodeInclusionProof(absence, 2, 1)
+	assert.ErrorIs(t, err, ErrUnsupportedProof)
+
+	rng, err := tree.ProveRange(0, 2)
+	require.NoError(t, err)
+	_, err = EncodeInclusionProof(rng, 2, 1)
+	assert.ErrorIs(t, err, ErrUnsupportedProof)
+
+	_, err = EncodeRoot(make([]byte, 2*33+sha256.Size), 33)
+	assert.ErrorIs(t, err, ErrUnsupportedProof)
+	_, err = EncodeRoot(make([]byte, 2+20), 1)
+	assert.ErrorIs(t, err, ErrUnsupportedProof)
+}
+
+func TestVerify_Malformed(t *testing.T) {
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(1))
+	require.NoError(t, tree.Push([]byte{1, 'a'}))
+	rawRoot, err := tree.Root()
+	require.NoError(t, err)
+	root, err := EncodeRoot(rawRoot, 1)
+	require.NoError(t, err)
+	proof, err := tree.Prove(0)
+	require.NoError(t, err)
+	encoded, err := EncodeInclusionProof(proof, 1, 1)
+	require.NoError(t, err)
+	require.True(t, Verify(root, 1, []byte{1, 'a'}, encoded))
+
+	assert.False(t, Verify(root, 1, []byte{1, 'a'}, encoded[:WordSize]))
+	assert.False(t, Verify(root, 1, []byte{1, 'a'}, append(encoded, make([]byte, WordSize)...)))
+	hugeCount := append([]byte{}, encoded...)
+	hugeCount[WordSize] = 1
+	assert.False(t, Verify(root, 1, []byte{1, 'a'}, hugeCount))
+}
+
+func TestVerifierSource(t *testing.T) {
+	assert.Contains(t, VerifierSource, "library NMTVerifier")
+	assert.Contains(t, VerifierSource, "function verifyInclusion(")
+}
@@ -0,0 +1,32 @@
+package nmt_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/celestiaorg/nmt"
+)
+
+// This example recomputes the root of a two-leaf tree node by node using the
+// exported hasher methods, as e.g. an external verifier would.
+func ExampleNmtHasher_HashNode() {
+	leaf0 := append([]byte{0}, []byte("leaf_0")...)
+	leaf1 := append([]byte{1}, []byte("leaf_1")...)
+
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(1))
+	_ = tree.Push(leaf0)
+	_ = tree.Push(leaf1)
+	root, _ := tree.Root()
+
+	hasher := nmt.NewNmtHasher(sha256.New(), 1, true)
+	left, _ := hasher.HashLeaf(leaf0)
+	right, _ := hasher.HashLeaf(leaf1)
+	node, _ := hasher.HashNode(left, right)
+
+	fmt.Printf("minNID: %x, maxNID: %x\n", node[:1], node[1:2])
+	fmt.Println(bytes.Equal(node, root))
+	// Output:
+	// minNID: 00, maxNID: 01
+	// true
+}
@@ -0,0 +1,163 @@
+//go:build cgo && nmt_ffi
+
+// Command ffi is a C-compatible shim exposing tree construction, roots,
+// namespace proofs and their verification as C functions, so that non-Go hosts
+// can reuse this implementation. It is only built with the nmt_ffi build tag:
+//
+//	go build -tags nmt_ffi -buildmode=c-shared -o libnmt.so ./ffi
+//
+// which also generates the C header libnmt.h. All trees use SHA256. Trees are
+// referenced by opaque handles, which must be released with nmt_free and must
+// not be used concurrently. All data is passed in flat byte buffers: results
+// are copied into caller-provided buffers, and functions returning a result
+// return its size, writing nothing if it exceeds the capacity of the buffer so
+// that the caller can retry with a larger one. Negative return values are the
+// NMT_ERR_* error codes. Proofs are encoded as nmt.pb.Proof protobuf messages.
+package main
+
+/*
+#include <stddef.h>
+#include <stdint.h>
+
+// NMT_ERR_INVALID_ARGUMENT indicates an invalid handle or malformed input.
+#define NMT_ERR_INVALID_ARGUMENT -1
+// NMT_ERR_FAILED indicates that the tree rejected the operation, e.g., a
+// leaf pushed out of namespace order.
+#define NMT_ERR_FAILED -2
+*/
+import "C"
+
+import (
+	"crypto/sha256"
+	"runtime/cgo"
+	"unsafe"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func main() {}
+
+// nmt_new creates a tree with namespace IDs of nid_size bytes that ignores the
+// maximum namespace ID if ignore_max is non-zero, and returns its handle, or 0
+// if nid_size is invalid.
+//
+//export nmt_new
+func nmt_new(nidSize C.int, ignoreMax C.int) C.uintptr_t {
+	if nidSize < 0 || nidSize > namespace.IDMaxSize {
+		return 0
+	}
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(int(nidSize)), nmt.IgnoreMaxNamespace(ignoreMax != 0))
+	return C.uintptr_t(cgo.NewHandle(tree))
+}
+
+// nmt_free releases the tree with the given handle.
+//
+//export nmt_free
+func nmt_free(handle C.uintptr_t) {
+	if _, ok := lookup(handle); ok {
+		cgo.Handle(handle).Delete()
+	}
+}
+
+// nmt_push pushes the namespace-prefixed leaf data[0:len] to the tree. It
+// returns 0 on success.
+//
+//export nmt_push
+func nmt_push(handle C.uintptr_t, data *C.uint8_t, length C.size_t) C.int {
+	tree, ok := lookup(handle)
+	if !ok {
+		return C.NMT_ERR_INVALID_ARGUMENT
+	}
+	if err := tree.Push(C.GoBytes(unsafe.Pointer(data), C.int(length))); err != nil {
+		return C.NMT_ERR_FAILED
+	}
+	return 0
+}
+
+// nmt_root copies the root of the tree to out and returns its size.
+//
+//export nmt_root
+func nmt_root(handle C.uintptr_t, out *C.uint8_t, outCap C.size_t) C.int {
+	tree, ok := lookup(handle)
+	if !ok {
+		return C.NMT_ERR_INVALID_ARGUMENT
+	}
+	root, err := tree.Root()
+	if err != nil {
+		return C.NMT_ERR_FAILED
+	}
+	return C.int(copyOut(out, outCap, root))
+}
+
+// nmt_prove_namespace copies the encoded namespace proof of the namespace ID
+// nid[0:nid_len] to out and returns its size.
+//
+//export nmt_prove_namespace
+func nmt_prove_namespace(handle C.uintptr_t, nid *C.uint8_t, nidLen C.size_t, out *C.uint8_t, outCap C.size_t) C.int {
+	tree, ok := lookup(handle)
+	if !ok {
+		return C.NMT_ERR_INVALID_ARGUMENT
+	}
+	proof, err := tree.ProveNamespace(C.GoBytes(unsafe.Pointer(nid), C.int(nidLen)))
+	if err != nil {
+		return C.NMT_ERR_FAILED
+	}
+	encoded, err := proof.GobEncode()
+	if err != nil {
+		return C.NMT_ERR_FAILED
+	}
+	return C.int(copyOut(out, outCap, encoded))
+}
+
+// nmt_verify_namespace verifies the encoded namespace proof
+// proof[0:proof_len] of the namespace ID nid[0:nid_len] and its leaves against
+// root[0:root_len]. The leaves are concatenated in leaves[0:leaves_len], each
+// prefixed with its size as a 4-byte big-endian integer. It returns 1 if the
+// proof is valid and 0 otherwise.
+//
+//export nmt_verify_namespace
+func nmt_verify_namespace(
+	proof *C.uint8_t, proofLen C.size_t,
+	nid *C.uint8_t, nidLen C.size_t,
+	leaves *C.uint8_t, leavesLen C.size_t,
+	root *C.uint8_t, rootLen C.size_t,
+) C.int {
+	var p nmt.Proof
+	if err := p.GobDecode(C.GoBytes(unsafe.Pointer(proof), C.int(proofLen))); err != nil {
+		return C.NMT_ERR_INVALID_ARGUMENT
+	}
+	decoded, err := splitLeaves(C.GoBytes(unsafe.Pointer(leaves), C.int(leavesLen)))
+	if err != nil {
+		return C.NMT_ERR_INVALID_ARGUMENT
+	}
+	nID := namespace.ID(C.GoBytes(unsafe.Pointer(nid), C.int(nidLen)))
+	if p.VerifyNamespace(sha256.New(), nID, decoded, C.GoBytes(unsafe.Pointer(root), C.int(rootLen))) {
+		return 1
+	}
+	return 0
+}
+
+// lookup returns the tree with the given handle.
+func lookup(handle C.uintptr_t) (tree *nmt.NamespacedMerkleTree, ok bool) {
+	if handle == 0 {
+		return nil, false
+	}
+	// Value panics if the handle is invalid, e.g., if it has been freed
+	defer func() {
+		if recover() != nil {
+			tree, ok = nil, false
+		}
+	}()
+	tree, ok = cgo.Handle(handle).Value().(*nmt.NamespacedMerkleTree)
+	return tree, ok
+}
+
+// copyOut copies data to out if it fits into outCap bytes and returns the size
+// of data.
+func copyOut(out *C.uint8_t, outCap C.size_t, data []byte) int {
+	if len(data) <= int(outCap) && len(data) > 0 {
+		copy(unsafe.Slice((*byte)(unsafe.Pointer(out)), len(data)), data)
+	}
+	return len(data)
+}
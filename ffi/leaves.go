@@ -0,0 +1,35 @@
+//go:build nmt_ffi
+
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// errMalformedLeaves indicates that a buffer of size-prefixed leaves is
+// truncated.
+var errMalformedLeaves = errors.New("malformed leaves buffer")
+
+// leafSizeLen is the size of the big-endian size prefix of each leaf in a
+// leaves buffer.
+const leafSizeLen = 4
+
+// splitLeaves decodes a buffer of concatenated leaves, each prefixed with its
+// size as a 4-byte big-endian integer.
+func splitLeaves(buf []byte) ([][]byte, error) {
+	var leaves [][]byte
+	for len(buf) > 0 {
+		if len(buf) < leafSizeLen {
+			return nil, errMalformedLeaves
+		}
+		size := binary.BigEndian.Uint32(buf)
+		buf = buf[leafSizeLen:]
+		if uint64(len(buf)) < uint64(size) {
+			return nil, errMalformedLeaves
+		}
+		leaves = append(leaves, buf[:size:size])
+		buf = buf[size:]
+	}
+	return leaves, nil
+}
@@ -0,0 +1,37 @@
+//go:build nmt_ffi
+
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitLeaves(t *testing.T) {
+	leaves := [][]byte{{1, 'a'}, {}, {2, 'b', 'c'}}
+	got, err := splitLeaves(joinLeaves(leaves))
+	require.NoError(t, err)
+	assert.Equal(t, leaves, got)
+
+	got, err = splitLeaves(nil)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	for _, buf := range [][]byte{{0, 0, 0}, {0, 0, 0, 2, 'a'}} {
+		_, err := splitLeaves(buf)
+		assert.ErrorIs(t, err, errMalformedLeaves)
+	}
+}
+
+// joinLeaves encodes leaves as expected by splitLeaves.
+func joinLeaves(leaves [][]byte) []byte {
+	var buf []byte
+	for _, leaf := range leaves {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(leaf)))
+		buf = append(buf, leaf...)
+	}
+	return buf
+}
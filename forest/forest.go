@@ -0,0 +1,155 @@
+// Package forest manages a collection of NMTs sharing the same configuration,
+// keyed by an identifier such as a block height and row index. It provides
+// bulk root listing and namespace queries across all trees, as commonly
+// needed by indexers.
+package forest
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"hash"
+	"slices"
+	"sync"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+var (
+	// ErrTreeExists indicates that a tree with the same key is already part of
+	// the forest.
+	ErrTreeExists = errors.New("tree already exists")
+	// ErrTreeNotFound indicates that the forest contains no tree with the
+	// given key.
+	ErrTreeNotFound = errors.New("tree not found")
+)
+
+// Key identifies a tree of the forest.
+type Key struct {
+	// Height is e.g. the height of the block the tree belongs to.
+	Height uint64
+	// Index is e.g. the row or column index of the tree within its block.
+	Index int
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%d/%d", k.Height, k.Index)
+}
+
+// compareKeys orders keys by height first and index second.
+func compareKeys(a, b Key) int {
+	if c := cmp.Compare(a.Height, b.Height); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Index, b.Index)
+}
+
+// Root is the root of the tree with the given key.
+type Root struct {
+	Key  Key
+	Root []byte
+}
+
+// Forest is a collection of NMTs created with the same options. Adding,
+// looking up and removing trees is safe for concurrent use; the trees
+// themselves are not.
+type Forest struct {
+	newHash func() hash.Hash
+	opts    []nmt.Option
+
+	mtx   sync.RWMutex
+	trees map[Key]*nmt.NamespacedMerkleTree
+}
+
+// New returns an empty forest whose trees are created with the hash function
+// returned by newHash and the given options.
+func New(newHash func() hash.Hash, opts ...nmt.Option) *Forest {
+	return &Forest{
+		newHash: newHash,
+		opts:    opts,
+		trees:   make(map[Key]*nmt.NamespacedMerkleTree),
+	}
+}
+
+// Create adds a new empty tree with the given key and returns it. It returns
+// an ErrTreeExists error if the forest already contains a tree with that key.
+func (f *Forest) Create(key Key) (*nmt.NamespacedMerkleTree, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if _, found := f.trees[key]; found {
+		return nil, fmt.Errorf("%w: %s", ErrTreeExists, key)
+	}
+	tree := nmt.New(f.newHash(), f.opts...)
+	f.trees[key] = tree
+	return tree, nil
+}
+
+// Tree returns the tree with the given key.
+func (f *Forest) Tree(key Key) (*nmt.NamespacedMerkleTree, bool) {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	tree, found := f.trees[key]
+	return tree, found
+}
+
+// Delete removes the tree with the given key from the forest. It returns an
+// ErrTreeNotFound error if there is no such tree.
+func (f *Forest) Delete(key Key) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if _, found := f.trees[key]; !found {
+		return fmt.Errorf("%w: %s", ErrTreeNotFound, key)
+	}
+	delete(f.trees, key)
+	return nil
+}
+
+// Len returns the number of trees in the forest.
+func (f *Forest) Len() int {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	return len(f.trees)
+}
+
+// Keys returns the keys of all trees, ordered by height and index.
+func (f *Forest) Keys() []Key {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	keys := make([]Key, 0, len(f.trees))
+	for key := range f.trees {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, compareKeys)
+	return keys
+}
+
+// Roots returns the roots of all trees, ordered by height and index.
+func (f *Forest) Roots() ([]Root, error) {
+	roots := make([]Root, 0, f.Len())
+	for _, key := range f.Keys() {
+		tree, found := f.Tree(key)
+		if !found { // removed concurrently
+			continue
+		}
+		root, err := tree.Root()
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute root of tree %s: %w", key, err)
+		}
+		roots = append(roots, Root{Key: key, Root: root})
+	}
+	return roots, nil
+}
+
+// FindNamespace returns the keys of all trees containing at least one leaf of
+// namespace nID, ordered by height and index.
+func (f *Forest) FindNamespace(nID namespace.ID) []Key {
+	var keys []Key
+	for _, key := range f.Keys() {
+		tree, found := f.Tree(key)
+		if found && len(tree.Get(nID)) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
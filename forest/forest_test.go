@@ -0,0 +1,57 @@
+package forest
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestForest(t *testing.T) {
+	f := New(sha256.New, nmt.NamespaceIDSize(1))
+	rows := map[Key][]byte{
+		{Height: 2, Index: 0}: {1, 2},
+		{Height: 1, Index: 1}: {2, 3},
+		{Height: 1, Index: 0}: {1, 1},
+	}
+	for key, nIDs := range rows {
+		tree, err := f.Create(key)
+		require.NoError(t, err)
+		assert.Equal(t, namespace.IDSize(1), tree.NamespaceSize())
+		for _, nID := range nIDs {
+			require.NoError(t, tree.Push([]byte{nID, 'd'}))
+		}
+	}
+	_, err := f.Create(Key{Height: 1, Index: 0})
+	assert.ErrorIs(t, err, ErrTreeExists)
+
+	want := []Key{{1, 0}, {1, 1}, {2, 0}}
+	assert.Equal(t, want, f.Keys())
+	assert.Equal(t, 3, f.Len())
+
+	roots, err := f.Roots()
+	require.NoError(t, err)
+	require.Len(t, roots, 3)
+	for i, root := range roots {
+		assert.Equal(t, want[i], root.Key)
+		tree, found := f.Tree(root.Key)
+		require.True(t, found)
+		treeRoot, err := tree.Root()
+		require.NoError(t, err)
+		assert.Equal(t, treeRoot, root.Root)
+	}
+
+	assert.Equal(t, []Key{{1, 0}, {2, 0}}, f.FindNamespace(namespace.ID{1}))
+	assert.Equal(t, []Key{{1, 1}, {2, 0}}, f.FindNamespace(namespace.ID{2}))
+	assert.Empty(t, f.FindNamespace(namespace.ID{4}))
+
+	require.NoError(t, f.Delete(Key{Height: 1, Index: 0}))
+	assert.ErrorIs(t, f.Delete(Key{Height: 1, Index: 0}), ErrTreeNotFound)
+	_, found := f.Tree(Key{Height: 1, Index: 0})
+	assert.False(t, found)
+	assert.Equal(t, []Key{{2, 0}}, f.FindNamespace(namespace.ID{1}))
+}
@@ -0,0 +1,124 @@
+package nmt
+
+import (
+	"errors"
+	"hash"
+	"slices"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ErrFrontierOnly indicates that a method requires leaves or inner nodes that
+// a tree in frontier-only mode does not keep, see FrontierOnly.
+var ErrFrontierOnly = errors.New("tree only keeps its frontier")
+
+// FrontierOnly makes the tree keep only the roots of the O(log n) perfect
+// subtrees on its right frontier, like a RootComputer, instead of its leaves,
+// leaf hashes and inner nodes. Leaves are hashed and folded into the frontier
+// as they are pushed, and Root, Size and the minimum and maximum namespace IDs
+// remain available, but methods requiring leaves or other nodes, such as the
+// proof methods, Leaf or IndexOf, return an ErrFrontierOnly error, and lookups
+// of leaves, such as Get or LocateNamespace, find no leaves. Pop is not
+// supported either, while batches can still be rolled back. This suits
+// producers that only commit to their leaves. Frontier-only trees cannot
+// record their history, see History. Defaults to false.
+func FrontierOnly(enable bool) Option {
+	return func(opts *Options) {
+		opts.FrontierOnly = enable
+	}
+}
+
+// pushFrontier folds the leaf hash of the next leaf, whose namespace ID nID
+// has been validated, into the frontier of a frontier-only tree.
+func (n *NamespacedMerkleTree) pushFrontier(leafHash []byte, nID namespace.ID) error {
+	if err := n.frontier.pushLeafHash(leafHash); err != nil {
+		return err
+	}
+	// nID aliases the leaf, which is not retained
+	if n.Size() == 1 || !nID.Equal(n.frontier.lastNID) {
+		n.frontier.lastNID = append(n.frontier.lastNID[:0], nID...)
+		if n.filter != nil {
+			n.filter.add(nID)
+		}
+		n.updateMinMaxID(slices.Clone(nID))
+	}
+	n.rawRoot = nil
+	return nil
+}
+
+// frontierState is the state of a frontier-only tree, which allows rolling
+// back pushes.
+type frontierState struct {
+	computer       RootComputer
+	minNID, maxNID namespace.ID
+}
+
+// saveFrontier returns the state of a frontier-only tree, or nil for other
+// trees.
+func (n *NamespacedMerkleTree) saveFrontier() *frontierState {
+	if n.frontier == nil {
+		return nil
+	}
+	return &frontierState{computer: *n.frontier.clone(), minNID: n.minNID, maxNID: n.maxNID}
+}
+
+// restoreFrontier restores the state returned by saveFrontier.
+func (n *NamespacedMerkleTree) restoreFrontier(state *frontierState) {
+	n.frontier = state.computer.clone()
+	n.minNID, n.maxNID = state.minNID, state.maxNID
+	n.rawRoot = nil
+}
+
+// clone returns a copy of the RootComputer that can be used independently.
+func (c *RootComputer) clone() *RootComputer {
+	clone := *c
+	clone.peaks = slices.Clone(c.peaks)
+	clone.lastNID = slices.Clone(c.lastNID)
+	return &clone
+}
+
+// MarshalFrontier returns a checkpoint of the frontier of the tree in the
+// format of RootComputer.MarshalBinary, from which NewFromFrontier restores a
+// frontier-only tree continuing with identical roots, e.g., after a restart
+// or on another service taking over the stream of leaves. The checkpoint of a
+// tree keeping its leaves is computed from its leaf hashes.
+func (n *NamespacedMerkleTree) MarshalFrontier() ([]byte, error) {
+	if n.frontier != nil {
+		return n.frontier.MarshalBinary()
+	}
+	if err := n.hashPendingLeaves(); err != nil {
+		return nil, err
+	}
+	c := &RootComputer{treeHasher: n.treeHasher, padding: n.padding, fixedSize: n.fixedSize}
+	for _, leafHash := range n.leafHashes {
+		if err := c.pushLeafHash(leafHash); err != nil {
+			return nil, err
+		}
+	}
+	return c.MarshalBinary()
+}
+
+// NewFromFrontier returns a frontier-only tree (see FrontierOnly) restored
+// from a checkpoint returned by MarshalFrontier. The tree must be created
+// with the same base hash function and options as the tree the checkpoint was
+// taken of; otherwise, or if the checkpoint is malformed, NewFromFrontier
+// returns an ErrInvalidCheckpoint error. Since the namespaces of the leaves
+// pushed before the checkpoint are unknown, it panics if the options enable a
+// NamespaceFilter.
+func NewFromFrontier(h hash.Hash, data []byte, setters ...Option) (*NamespacedMerkleTree, error) {
+	n := New(h, append(setters, FrontierOnly(true))...)
+	if n.filter != nil {
+		panic("Got NamespaceFilter. Expected no namespace filter for a tree restored from its frontier.")
+	}
+	if err := n.frontier.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if n.frontier.Size() > 0 {
+		// the left-most peak covers the first leaf and the last leaf hash the
+		// last one
+		n.updateMinMaxID(slices.Clone(MinNamespace(n.frontier.peaks[0], n.NamespaceSize())))
+		n.updateMinMaxID(slices.Clone(n.frontier.lastNID))
+	}
+	n.checkInvariants("NewFromFrontier")
+	return n, nil
+}
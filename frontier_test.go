@@ -0,0 +1,200 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestFrontierOnly_Root(t *testing.T) {
+	for _, opts := range [][]Option{
+		{NamespaceIDSize(2)},
+		{NamespaceIDSize(2), IgnoreMaxNamespace(false)},
+		{NamespaceIDSize(2), Padding(PadWithLastLeaf)},
+		{NamespaceIDSize(2), FixedHeight(6)},
+	} {
+		frontier := New(sha256.New(), append(opts, FrontierOnly(true))...)
+		want := New(sha256.New(), opts...)
+		for i := 0; i < 50; i++ {
+			requireSameRoot(t, want, frontier)
+			assert.Equal(t, want.Size(), frontier.Size())
+			leaf := append([]byte{0, byte(i / 3)}, []byte(fmt.Sprintf("leaf_%d", i))...)
+			if i == 49 {
+				leaf[0], leaf[1] = 0xFF, 0xFF
+			}
+			require.NoError(t, frontier.Push(leaf))
+			require.NoError(t, want.Push(leaf))
+		}
+		requireSameRoot(t, want, frontier)
+		assert.Equal(t, want.MinLeafNamespace(), frontier.MinLeafNamespace())
+		assert.Equal(t, want.MaxLeafNamespace(), frontier.MaxLeafNamespace())
+		assert.Empty(t, frontier.leaves)
+		assert.Empty(t, frontier.leafHashes)
+		assert.Len(t, frontier.frontier.peaks, 3)
+
+		frontier.Reset()
+		want.Reset()
+		requireSameRoot(t, want, frontier)
+	}
+}
+
+func TestFrontierOnly_Push(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), RejectMaxNamespace(true), NamespaceFilter(10, 0.01), FrontierOnly(true))
+	want := New(sha256.New(), NamespaceIDSize(1))
+	for _, leaf := range [][]byte{{1, 'a'}, {3, 'b'}} {
+		require.NoError(t, tree.Push(leaf))
+		require.NoError(t, want.Push(leaf))
+	}
+	assert.ErrorIs(t, tree.Push([]byte{2, 'c'}), ErrInvalidPushOrder)
+	assert.ErrorIs(t, tree.Push([]byte{0xFF, 'c'}), ErrReservedNamespace)
+	assert.ErrorIs(t, tree.Push(nil), ErrInvalidLeafLen)
+	assert.True(t, tree.MayContainNamespace(namespace.ID{3}))
+
+	leafHash, err := NewNmtHasher(sha256.New(), 1, true).HashLeaf([]byte{4, 'd'})
+	require.NoError(t, err)
+	require.NoError(t, tree.PushLeafHash(leafHash))
+	require.NoError(t, want.PushLeafHash(leafHash))
+	requireSameRoot(t, want, tree)
+
+	// batches are rolled back by restoring the frontier
+	assert.Error(t, tree.PushAll([][]byte{{5, 'e'}, {6, 'f'}, {1, 'g'}}))
+	assert.Equal(t, 3, tree.Size())
+	assert.Equal(t, namespace.ID{4}, tree.MaxLeafNamespace())
+	requireSameRoot(t, want, tree)
+	require.NoError(t, tree.PushAll([][]byte{{5, 'e'}, {6, 'f'}}))
+	require.NoError(t, want.PushAll([][]byte{{5, 'e'}, {6, 'f'}}))
+	requireSameRoot(t, want, tree)
+
+	// snapshots continue independently
+	snapshot := tree.Snapshot(sha256.New())
+	require.NoError(t, snapshot.Push([]byte{7, 'g'}))
+	require.NoError(t, tree.Push([]byte{8, 'h'}))
+	require.NoError(t, want.Push([]byte{8, 'h'}))
+	requireSameRoot(t, want, tree)
+	assert.Equal(t, 6, snapshot.Size())
+	assert.Equal(t, namespace.ID{7}, snapshot.MaxLeafNamespace())
+}
+
+func TestFrontierOnly_Unsupported(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), FrontierOnly(true))
+	for _, leaf := range [][]byte{{1, 'a'}, {1, 'b'}, {3, 'c'}} {
+		require.NoError(t, tree.Push(leaf))
+	}
+	nID := namespace.ID{1}
+
+	assert.Empty(t, tree.Get(nID))
+	_, found := tree.IndexOf([]byte{1})
+	assert.False(t, found)
+	assert.Equal(t, 3, tree.Size())
+	for name, fn := range map[string]func() error{
+		"Prove":          func() error { _, err := tree.Prove(0); return err },
+		"ProveRange":     func() error { _, err := tree.ProveRange(0, 2); return err },
+		"ProveNamespace": func() error { _, err := tree.ProveNamespace(nID); return err },
+		"Leaf":           func() error { _, err := tree.Leaf(0); return err },
+		"GetNamespaceData": func() error {
+			_, err := tree.GetNamespaceData(nID)
+			return err
+		},
+		"Pop":                func() error { _, err := tree.Pop(); return err },
+		"ForceAddLeaf":       func() error { return tree.ForceAddLeaf([]byte{0, 'x'}) },
+		"ComputeSubtreeRoot": func() error { _, err := tree.ComputeSubtreeRoot(0, 2); return err },
+		"SubtreeRoot":        func() error { _, err := tree.SubtreeRoot(0, 2); return err },
+		"NodeByGIndex":       func() error { _, err := tree.NodeByGIndex(2); return err },
+		"ProveMulti":         func() error { _, err := tree.ProveMulti([]int{0, 2}); return err },
+		"ProveAllNamespaces": func() error { _, err := tree.ProveAllNamespaces(); return err },
+		"NamespaceRoots":     func() error { _, err := tree.NamespaceRoots(); return err },
+		"ProveLeafInNamespace": func() error {
+			_, err := tree.ProveLeafInNamespace(nID, 0)
+			return err
+		},
+		"Prune": func() error {
+			_, err := tree.Prune(func(namespace.ID) bool { return true })
+			return err
+		},
+	} {
+		assert.ErrorIs(t, fn(), ErrFrontierOnly, name)
+	}
+	assert.Equal(t, 3, tree.Size())
+
+	// the remaining methods do not fail on the missing leaves
+	assert.NotPanics(t, func() {
+		_, _, _ = tree.GetWithProof(nID)
+		_, _ = tree.NamespaceRoot(nID)
+		_ = tree.GetNamespaceRange(nID, nID)
+		_, _ = tree.ProveNamespaceRange(nID, nID)
+		_, _ = tree.LocateNamespace(nID)
+		_, _ = tree.GetPayloads(nID)
+		_, _ = tree.Diff(tree.Snapshot(sha256.New()))
+		_, _ = tree.ToDOT()
+		_ = tree.Stats()
+		_ = tree.String()
+		_, _ = tree.ProveInNamespace(nID, 0)
+	})
+}
+
+func TestFrontierOnly_History(t *testing.T) {
+	assert.Panics(t, func() { New(sha256.New(), FrontierOnly(true), History(true)) })
+}
+
+func TestFrontierOnly_MarshalFrontier(t *testing.T) {
+	for _, opts := range [][]Option{
+		{NamespaceIDSize(2)},
+		{NamespaceIDSize(2), Padding(PadWithLastLeaf)},
+		{NamespaceIDSize(2), FixedHeight(6), LazyLeafHashing(true)},
+	} {
+		leaves := make([][]byte, 40)
+		for i := range leaves {
+			leaves[i] = append([]byte{0, byte(i / 3)}, []byte(fmt.Sprintf("leaf_%d", i))...)
+		}
+		want := New(sha256.New(), opts...)
+		require.NoError(t, want.PushAll(leaves))
+
+		for _, stop := range []int{0, 1, 7, 16, 39} {
+			// checkpoints of trees keeping their leaves and of frontier-only
+			// trees are interchangeable
+			for _, frontierOnly := range []bool{false, true} {
+				tree := New(sha256.New(), append(opts, FrontierOnly(frontierOnly))...)
+				require.NoError(t, tree.PushAll(leaves[:stop]))
+				data, err := tree.MarshalFrontier()
+				require.NoError(t, err)
+
+				resumed, err := NewFromFrontier(sha256.New(), data, opts...)
+				require.NoError(t, err)
+				assert.Equal(t, stop, resumed.Size())
+				if stop > 0 {
+					assert.Equal(t, tree.MinLeafNamespace(), resumed.MinLeafNamespace())
+					assert.Equal(t, tree.MaxLeafNamespace(), resumed.MaxLeafNamespace())
+					requireSameRoot(t, tree, resumed)
+				}
+				if stop > 3 {
+					// the push order is restored as well
+					assert.ErrorIs(t, resumed.Push([]byte{0, 0}), ErrInvalidPushOrder)
+				}
+				require.NoError(t, resumed.PushAll(leaves[stop:]))
+				requireSameRoot(t, want, resumed)
+			}
+		}
+	}
+}
+
+func TestNewFromFrontier_Invalid(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, tree.PushAll([][]byte{{1, 'a'}, {2, 'b'}, {3, 'c'}}))
+	data, err := tree.MarshalFrontier()
+	require.NoError(t, err)
+
+	_, err = NewFromFrontier(sha256.New(), data[:len(data)-1], NamespaceIDSize(1))
+	assert.ErrorIs(t, err, ErrInvalidCheckpoint)
+	_, err = NewFromFrontier(sha256.New(), data, NamespaceIDSize(2))
+	assert.ErrorIs(t, err, ErrInvalidCheckpoint)
+	_, err = NewFromFrontier(sha256.New(), data, NamespaceIDSize(1), Padding(PadWithEmptyLeaves))
+	assert.ErrorIs(t, err, ErrInvalidCheckpoint)
+	assert.Panics(t, func() {
+		_, _ = NewFromFrontier(sha256.New(), data, NamespaceIDSize(1), NamespaceFilter(10, 0.01))
+	})
+}
@@ -2,6 +2,8 @@ package nmt_test
 
 import (
 	"crypto/sha256"
+	"encoding/binary"
+	"math"
 	"reflect"
 	"sort"
 	"testing"
@@ -102,6 +104,110 @@ func TestFuzzProveVerifyNameSpace(t *testing.T) {
 	}
 }
 
+// FuzzVerifyNamespace checks that untrusted proofs, namespace IDs and roots,
+// e.g., truncated ones, are rejected by the verifiers instead of crashing
+// them. The seed corpus consists of valid proofs and truncations thereof; run
+// go test -fuzz FuzzVerifyNamespace to search for more.
+func FuzzVerifyNamespace(f *testing.F) {
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(2), nmt.IgnoreMaxNamespace(true))
+	for i := 0; i < 7; i++ {
+		require.NoError(f, tree.Push([]byte{0, byte(i / 2 * 2), byte(i)}))
+	}
+	require.NoError(f, tree.Push([]byte{0xFF, 0xFF, 7}))
+	root, err := tree.Root()
+	require.NoError(f, err)
+	for _, nID := range []namespace.ID{{0, 2}, {0, 3}, {0, 6}, {0xFF, 0xFF}, {1, 0}} {
+		proof, err := tree.ProveNamespace(nID)
+		require.NoError(f, err)
+		encoded, err := proof.GobEncode()
+		require.NoError(f, err)
+		f.Add(encoded, []byte(nID), root)
+		for _, cut := range []int{1, len(root) / 2, len(root) + 1} {
+			if cut < len(encoded) {
+				f.Add(encoded[:len(encoded)-cut], []byte(nID), root)
+			}
+		}
+		f.Add(encoded, []byte(nID[:1]), root)
+		f.Add(encoded, []byte(nID), root[:len(root)-1])
+	}
+
+	f.Fuzz(func(t *testing.T, encoded, nID, root []byte) {
+		var proof nmt.Proof
+		if err := proof.GobDecode(encoded); err != nil {
+			return
+		}
+		leaves := tree.Get(nID)
+		proof.VerifyNamespace(sha256.New(), nID, leaves, root)
+		proof.VerifyNamespaceRange(sha256.New(), nID, nID, leaves, root)
+
+		nth := nmt.NewNmtHasher(sha256.New(), 2, proof.IsMaxNamespaceIDIgnored())
+		leavesWithoutNamespace := make([][]byte, len(leaves))
+		leafHashes := make([][]byte, len(leaves))
+		for i, leaf := range leaves {
+			leavesWithoutNamespace[i] = leaf[2:]
+			leafHashes[i] = nth.MustHashLeaf(leaf)
+		}
+		proof.VerifyInclusion(sha256.New(), nID, leavesWithoutNamespace, root)
+		if proof.IsOfAbsence() {
+			leafHashes = [][]byte{proof.LeafHash()}
+		}
+		_, _ = proof.VerifyLeafHashes(nth, true, nID, leafHashes, root)
+	})
+}
+
+func FuzzVerifyMultiProof(f *testing.F) {
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(2), nmt.IgnoreMaxNamespace(true))
+	for i := 0; i < 7; i++ {
+		require.NoError(f, tree.Push([]byte{0, byte(i / 2 * 2), byte(i)}))
+	}
+	root, err := tree.Root()
+	require.NoError(f, err)
+	// indices are encoded as big-endian uint64s, nodes are concatenated
+	encode := func(indices []int, nodes [][]byte) ([]byte, []byte) {
+		var encodedIndices, encodedNodes []byte
+		for _, index := range indices {
+			encodedIndices = binary.BigEndian.AppendUint64(encodedIndices, uint64(index))
+		}
+		for _, node := range nodes {
+			encodedNodes = append(encodedNodes, node...)
+		}
+		return encodedIndices, encodedNodes
+	}
+	for _, indices := range [][]int{{0}, {1, 4}, {0, 2, 6}, {6}} {
+		proof, err := tree.ProveMulti(indices)
+		require.NoError(f, err)
+		encodedIndices, encodedNodes := encode(proof.Indices(), proof.Nodes())
+		f.Add(encodedIndices, encodedNodes, root)
+	}
+	for _, indices := range [][]int{{math.MaxInt}, {math.MaxInt/2 + 1}, {-1}, {3, 1}} {
+		encodedIndices, _ := encode(indices, nil)
+		f.Add(encodedIndices, []byte{}, root)
+	}
+
+	nodeSize := len(root)
+	f.Fuzz(func(t *testing.T, encodedIndices, encodedNodes, root []byte) {
+		var indices []int
+		var leaves [][]byte
+		for ; len(encodedIndices) >= 8; encodedIndices = encodedIndices[8:] {
+			index := int(binary.BigEndian.Uint64(encodedIndices))
+			indices = append(indices, index)
+			if index >= 0 && index < tree.Size() {
+				leaf, err := tree.Leaf(index)
+				require.NoError(t, err)
+				leaves = append(leaves, leaf)
+			} else {
+				leaves = append(leaves, []byte{0, 0, 0})
+			}
+		}
+		var nodes [][]byte
+		for ; len(encodedNodes) >= nodeSize; encodedNodes = encodedNodes[nodeSize:] {
+			nodes = append(nodes, encodedNodes[:nodeSize])
+		}
+		proof := nmt.NewMultiProof(indices, nodes, true)
+		proof.VerifyInclusion(sha256.New(), 2, leaves, root)
+	})
+}
+
 func makeRandDataAndSortedKeys(size testNamespaceSizes, minNumOfNs, maxNumOfNs, minElemsPerNs, maxElemsPerNs int, emptyNsProb float64) (map[string][][]byte, []string) {
 	nidDataMap := make(map[string][][]byte)
 	f := makeFuzzer(size, minNumOfNs, maxNumOfNs, minElemsPerNs, maxElemsPerNs, emptyNsProb)
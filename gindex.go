@@ -0,0 +1,147 @@
+package nmt
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// ErrInvalidGIndex indicates that a generalized index does not address a node
+// of a tree of the given size.
+var ErrInvalidGIndex = errors.New("invalid generalized index")
+
+// Generalized indices (gindices) address the nodes of a tree as in SSZ: the
+// root has gindex 1 and the children of the node with gindex g have gindices
+// 2g and 2g+1. The tree is treated as a perfect binary tree over the smallest
+// power of two number of leaves that is at least the tree size. Nodes of that
+// perfect tree that cover no leaf do not exist, and a node whose right child
+// does not exist has the same hash as its left child, which yields exactly the
+// nodes of the namespaced Merkle tree.
+
+// paddedSize returns the number of leaves of the perfect binary tree the
+// gindices of a tree of treeSize leaves refer to.
+func paddedSize(treeSize int) int {
+	if treeSize <= 1 {
+		return 1
+	}
+	return getSplitPoint(treeSize) * 2
+}
+
+// GIndexRange returns the range of leaves [Start, End) covered by the node
+// with the given gindex in a tree of treeSize leaves. It returns an
+// ErrInvalidGIndex error if no such node exists.
+func GIndexRange(treeSize int, gindex uint64) (LeafRange, error) {
+	if treeSize <= 0 || gindex == 0 {
+		return LeafRange{}, fmt.Errorf("%w: %d for tree size %d", ErrInvalidGIndex, gindex, treeSize)
+	}
+	padded := uint64(paddedSize(treeSize))
+	depth := bits.Len64(gindex) - 1
+	if uint64(1)<<depth > padded {
+		return LeafRange{}, fmt.Errorf("%w: %d is below the leaves of a tree of size %d", ErrInvalidGIndex, gindex, treeSize)
+	}
+	width := padded >> depth
+	start := (gindex - uint64(1)<<depth) * width
+	if start >= uint64(treeSize) {
+		return LeafRange{}, fmt.Errorf("%w: %d covers no leaf of a tree of size %d", ErrInvalidGIndex, gindex, treeSize)
+	}
+	end := start + width
+	if end > uint64(treeSize) {
+		end = uint64(treeSize)
+	}
+	return LeafRange{Start: int(start), End: int(end)}, nil
+}
+
+// LeafGIndex returns the gindex of the leaf at index in a tree of treeSize
+// leaves. It returns an ErrInvalidRange error if index is not in [0, treeSize).
+func LeafGIndex(treeSize, index int) (uint64, error) {
+	if index < 0 || index >= treeSize {
+		return 0, fmt.Errorf("leaf index %d is out of the tree range [0, %d): %w", index, treeSize, ErrInvalidRange)
+	}
+	return uint64(paddedSize(treeSize)) + uint64(index), nil
+}
+
+// NodeByGIndex returns the hash of the node with the given gindex. It returns
+// an ErrInvalidGIndex error if the tree has no such node. For padded trees
+// (see Padding), the padding leaves are addressable as well.
+func (n *NamespacedMerkleTree) NodeByGIndex(gindex uint64) ([]byte, error) {
+	rng, err := GIndexRange(n.sizeWithPadding(), gindex)
+	if err != nil {
+		return nil, err
+	}
+	return n.subtreeRoot(rng.Start, rng.End)
+}
+
+// GIndexNodes returns the nodes of the proof, including the leaf hash of an
+// absence proof, keyed by their gindex in a tree of treeSize leaves. It
+// returns an ErrInvalidGIndex error if the number of nodes does not match the
+// proof range for that tree size.
+func (proof Proof) GIndexNodes(treeSize int) (map[uint64][]byte, error) {
+	nodes := make(map[uint64][]byte, len(proof.nodes)+1)
+	if proof.IsEmptyProof() {
+		return nodes, nil
+	}
+	if proof.start < 0 || proof.end > treeSize || proof.start >= proof.end {
+		return nil, fmt.Errorf("proof range [%d, %d) is out of the tree range [0, %d): %w", proof.start, proof.end, treeSize, ErrInvalidRange)
+	}
+	gindices := proofGIndices(proof.start, proof.end, treeSize)
+	if len(gindices) != len(proof.nodes) {
+		return nil, fmt.Errorf("%w: proof has %d nodes, want %d for tree size %d", ErrInvalidGIndex, len(proof.nodes), len(gindices), treeSize)
+	}
+	for i, gindex := range gindices {
+		nodes[gindex] = proof.nodes[i]
+	}
+	if proof.IsOfAbsence() {
+		gindex, err := LeafGIndex(treeSize, proof.start)
+		if err != nil {
+			return nil, err
+		}
+		nodes[gindex] = proof.leafHash
+	}
+	return nodes, nil
+}
+
+// NewInclusionProofFromGIndices creates an inclusion proof of the leaves
+// [proofStart, proofEnd) of a tree of treeSize leaves from nodes keyed by
+// their gindex, e.g., as returned by Proof.GIndexNodes. Nodes not required by
+// the proof are ignored. It returns an ErrInvalidGIndex error if a required
+// node is missing.
+func NewInclusionProofFromGIndices(proofStart, proofEnd, treeSize int, nodes map[uint64][]byte, ignoreMaxNamespace bool) (Proof, error) {
+	if proofStart < 0 || proofEnd > treeSize || proofStart >= proofEnd {
+		return Proof{}, fmt.Errorf("proof range [%d, %d) is out of the tree range [0, %d): %w", proofStart, proofEnd, treeSize, ErrInvalidRange)
+	}
+	gindices := proofGIndices(proofStart, proofEnd, treeSize)
+	proofNodes := make([][]byte, len(gindices))
+	for i, gindex := range gindices {
+		node, found := nodes[gindex]
+		if !found {
+			return Proof{}, fmt.Errorf("%w: missing node %d", ErrInvalidGIndex, gindex)
+		}
+		proofNodes[i] = node
+	}
+	return NewInclusionProof(proofStart, proofEnd, proofNodes, ignoreMaxNamespace), nil
+}
+
+// proofGIndices returns the gindices of the nodes of a range proof of
+// [proofStart, proofEnd) in a tree of treeSize leaves, in the order in which
+// they appear in the proof (see buildRangeProof).
+func proofGIndices(proofStart, proofEnd, treeSize int) []uint64 {
+	var gindices []uint64
+	var recurse func(start, end int, gindex uint64)
+	recurse = func(start, end int, gindex uint64) {
+		if start >= treeSize {
+			return
+		}
+		if end <= proofStart || start >= proofEnd {
+			gindices = append(gindices, gindex)
+			return
+		}
+		if end-start == 1 {
+			return
+		}
+		k := (end - start) / 2
+		recurse(start, start+k, 2*gindex)
+		recurse(start+k, end, 2*gindex+1)
+	}
+	recurse(0, paddedSize(treeSize), 1)
+	return gindices
+}
@@ -0,0 +1,139 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestGIndexRange(t *testing.T) {
+	tests := []struct {
+		treeSize int
+		gindex   uint64
+		want     LeafRange
+		wantErr  bool
+	}{
+		{1, 1, LeafRange{Start: 0, End: 1}, false},
+		{1, 2, LeafRange{}, true},
+		{5, 1, LeafRange{Start: 0, End: 5}, false},
+		{5, 2, LeafRange{Start: 0, End: 4}, false},
+		{5, 3, LeafRange{Start: 4, End: 5}, false},
+		{5, 6, LeafRange{Start: 4, End: 5}, false},
+		{5, 7, LeafRange{}, true},
+		{5, 12, LeafRange{Start: 4, End: 5}, false},
+		{5, 13, LeafRange{}, true},
+		{5, 24, LeafRange{}, true},
+		{5, 0, LeafRange{}, true},
+		{0, 1, LeafRange{}, true},
+	}
+	for _, tt := range tests {
+		got, err := GIndexRange(tt.treeSize, tt.gindex)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidGIndex, "size %d gindex %d", tt.treeSize, tt.gindex)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "size %d gindex %d", tt.treeSize, tt.gindex)
+	}
+}
+
+func TestNodeByGIndex(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 3, 4, 5)
+	root, err := tree.Root()
+	require.NoError(t, err)
+
+	node, err := tree.NodeByGIndex(1)
+	require.NoError(t, err)
+	assert.Equal(t, root, node)
+	// the right child of the root is the last leaf
+	node, err = tree.NodeByGIndex(3)
+	require.NoError(t, err)
+	assert.Equal(t, tree.leafHashes[4], node)
+
+	for i := 0; i < tree.Size(); i++ {
+		gindex, err := LeafGIndex(tree.Size(), i)
+		require.NoError(t, err)
+		node, err := tree.NodeByGIndex(gindex)
+		require.NoError(t, err)
+		assert.Equal(t, tree.leafHashes[i], node)
+	}
+	_, err = LeafGIndex(tree.Size(), 5)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+	_, err = tree.NodeByGIndex(7)
+	assert.ErrorIs(t, err, ErrInvalidGIndex)
+}
+
+func TestGIndexNodes(t *testing.T) {
+	for size := 1; size <= 17; size++ {
+		nIDs := make([]byte, size)
+		for i := range nIDs {
+			nIDs[i] = byte(i)
+		}
+		tree := exampleNMT(1, true, nIDs...)
+		root, err := tree.Root()
+		require.NoError(t, err)
+		for start := 0; start < size; start++ {
+			for end := start + 1; end <= size; end++ {
+				t.Run(fmt.Sprintf("size %d range [%d, %d)", size, start, end), func(t *testing.T) {
+					proof, err := tree.ProveRange(start, end)
+					require.NoError(t, err)
+					nodes, err := proof.GIndexNodes(size)
+					require.NoError(t, err)
+					assert.Len(t, nodes, len(proof.Nodes()))
+					for gindex, node := range nodes {
+						want, err := tree.NodeByGIndex(gindex)
+						require.NoError(t, err)
+						assert.Equal(t, want, node)
+					}
+
+					got, err := NewInclusionProofFromGIndices(start, end, size, nodes, true)
+					require.NoError(t, err)
+					assert.Equal(t, proof, got)
+					if end-start == 1 {
+						leaf := tree.leaves[start]
+						assert.True(t, got.VerifyInclusion(sha256.New(), leaf[:1], [][]byte{leaf[1:]}, root))
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestGIndexNodes_Absence(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 3, 5)
+	proof, err := tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	require.True(t, proof.IsOfAbsence())
+	nodes, err := proof.GIndexNodes(tree.Size())
+	require.NoError(t, err)
+	assert.Len(t, nodes, len(proof.Nodes())+1)
+	gindex, err := LeafGIndex(tree.Size(), proof.Start())
+	require.NoError(t, err)
+	assert.Equal(t, proof.LeafHash(), nodes[gindex])
+}
+
+func TestGIndexNodes_Errors(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 3, 4)
+	proof, err := tree.ProveRange(1, 2)
+	require.NoError(t, err)
+	// wrong tree size
+	_, err = proof.GIndexNodes(8)
+	assert.ErrorIs(t, err, ErrInvalidGIndex)
+	_, err = proof.GIndexNodes(1)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+
+	nodes, err := proof.GIndexNodes(4)
+	require.NoError(t, err)
+	delete(nodes, 3)
+	_, err = NewInclusionProofFromGIndices(1, 2, 4, nodes, true)
+	assert.ErrorIs(t, err, ErrInvalidGIndex)
+
+	empty, err := NewEmptyRangeProof(true).GIndexNodes(4)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
@@ -0,0 +1,35 @@
+package nmt
+
+import (
+	"hash"
+	"sync"
+)
+
+// HashPool is a pool of hash.Hash instances created by a single constructor.
+// Callers that verify many proofs or build many short-lived trees can use it
+// to reuse hash states instead of allocating a fresh one for every operation.
+// It is safe for concurrent use.
+type HashPool struct {
+	pool sync.Pool
+}
+
+// NewHashPool creates a HashPool that allocates new instances using newHash
+// when the pool is empty.
+func NewHashPool(newHash func() hash.Hash) *HashPool {
+	return &HashPool{
+		pool: sync.Pool{New: func() interface{} { return newHash() }},
+	}
+}
+
+// Get returns a reset hash.Hash from the pool. It should be returned using Put
+// once it is no longer in use.
+func (p *HashPool) Get() hash.Hash {
+	h := p.pool.Get().(hash.Hash)
+	h.Reset()
+	return h
+}
+
+// Put returns h to the pool. h must not be used after calling Put.
+func (p *HashPool) Put(h hash.Hash) {
+	p.pool.Put(h)
+}
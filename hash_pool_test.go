@@ -0,0 +1,49 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestHashPool(t *testing.T) {
+	pool := NewHashPool(sha256.New)
+	h := pool.Get()
+	h.Write([]byte("dirty"))
+	pool.Put(h)
+
+	// a hash taken from the pool is always reset
+	h = pool.Get()
+	assert.Equal(t, sha256.New().Sum(nil), h.Sum(nil))
+	pool.Put(h)
+}
+
+func TestHashPool_Concurrent(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 3, 4)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	nID := namespace.ID{3}
+	proof, err := tree.ProveNamespace(nID)
+	require.NoError(t, err)
+	leaves := tree.Get(nID)
+
+	pool := NewHashPool(sha256.New)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				h := pool.Get()
+				assert.True(t, proof.VerifyNamespace(h, nID, leaves, root))
+				pool.Put(h)
+			}
+		}()
+	}
+	wg.Wait()
+}
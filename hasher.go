@@ -10,7 +10,11 @@ import (
 )
 
 const (
+	// LeafPrefix is the domain separation byte prepended to leaf data before
+	// hashing, see HashLeaf.
 	LeafPrefix = 0
+	// NodePrefix is the domain separation byte prepended to the concatenated
+	// children of an inner node before hashing, see HashNode.
 	NodePrefix = 1
 )
 
@@ -25,14 +29,38 @@ var (
 
 // Hasher describes the interface nmts use to hash leafs and nodes.
 //
+// Every hash produced by a Hasher is a namespaced (or "flagged") hash with the
+// layout
+//
+//	minNID || maxNID || digest
+//
+// where minNID and maxNID are NamespaceSize() bytes each and denote the
+// smallest and largest namespace ID covered by the node, and digest is the
+// output of the underlying hash function. For the default NmtHasher:
+//
+//	HashLeaf(ndata)       = ndata[:nIDSize] || ndata[:nIDSize] || h(LeafPrefix || ndata)
+//	HashNode(left, right) = minNID || maxNID || h(NodePrefix || left || right)
+//
+// where left and right are the complete namespaced hashes of the children,
+// including their namespace flags. See HashNode for how minNID and maxNID of
+// an inner node are derived from its children.
+//
 // Note: it is not advised to create alternative hashers if following the
 // specification is desired. The main reason this exists is to not follow the
 // specification for testing purposes.
 type Hasher interface {
+	// IsMaxNamespaceIDIgnored reports whether the maximum namespace ID is
+	// excluded from the namespace range of inner nodes where possible.
 	IsMaxNamespaceIDIgnored() bool
+	// NamespaceSize returns the size of namespace IDs in bytes.
 	NamespaceSize() namespace.IDSize
+	// HashLeaf returns the namespaced hash of the namespace-prefixed leaf
+	// data.
 	HashLeaf(data []byte) ([]byte, error)
+	// HashNode returns the namespaced hash of an inner node given the
+	// namespaced hashes of its children.
 	HashNode(leftChild, rightChild []byte) ([]byte, error)
+	// EmptyRoot returns the namespaced hash of a tree without leaves.
 	EmptyRoot() []byte
 }
 
@@ -56,6 +84,11 @@ type NmtHasher struct { //nolint:revive
 
 	tp   byte   // keeps type of NMT node to be hashed
 	data []byte // written data of the NMT node
+
+	// leafPrefix and nodePrefix hold the domain separation prefixes written
+	// to the base hasher, so that writing them does not allocate.
+	leafPrefix [1]byte
+	nodePrefix [1]byte
 }
 
 func (n *NmtHasher) IsMaxNamespaceIDIgnored() bool {
@@ -72,6 +105,8 @@ func NewNmtHasher(baseHasher hash.Hash, nidLen namespace.IDSize, ignoreMaxNamesp
 		NamespaceLen:     nidLen,
 		ignoreMaxNs:      ignoreMaxNamespace,
 		precomputedMaxNs: bytes.Repeat([]byte{0xFF}, int(nidLen)),
+		leafPrefix:       [1]byte{LeafPrefix},
+		nodePrefix:       [1]byte{NodePrefix},
 	}
 }
 
@@ -152,6 +187,9 @@ func (n *NmtHasher) BlockSize() int {
 	return n.baseHasher.BlockSize()
 }
 
+// EmptyRoot returns the root of a tree without leaves, i.e., the zero
+// namespace ID as both the minimum and maximum namespace ID followed by the
+// digest of the empty input, see the package-level EmptyRoot.
 func (n *NmtHasher) EmptyRoot() []byte {
 	n.baseHasher.Reset()
 	emptyNs := bytes.Repeat([]byte{0}, int(n.NamespaceLen))
@@ -161,6 +199,20 @@ func (n *NmtHasher) EmptyRoot() []byte {
 	return digest
 }
 
+// EmptyRoot returns the root of a tree without leaves for namespace IDs of
+// size nIDSize and the base hash function h, which is reset. Verifiers can
+// compare a root against it to recognize that the root commits to zero leaves,
+// see also IsEmptyRoot.
+func EmptyRoot(h hash.Hash, nIDSize namespace.IDSize) []byte {
+	return NewNmtHasher(h, nIDSize, false).EmptyRoot()
+}
+
+// IsEmptyRoot reports whether root is the root of a tree without leaves for
+// namespace IDs of size nIDSize and the base hash function h, which is reset.
+func IsEmptyRoot(h hash.Hash, nIDSize namespace.IDSize, root []byte) bool {
+	return bytes.Equal(root, EmptyRoot(h, nIDSize))
+}
+
 // ValidateLeaf verifies if data is namespaced and returns an error if not.
 func (n *NmtHasher) ValidateLeaf(data []byte) (err error) {
 	nidSize := int(n.NamespaceSize())
@@ -175,31 +227,32 @@ func (n *NmtHasher) ValidateLeaf(data []byte) (err error) {
 // ns(ndata) || ns(ndata) || hash(leafPrefix || ndata), where ns(ndata) is the
 // namespaceID inside the data item namely leaf[:n.NamespaceLen]). Note that for
 // leaves minNs = maxNs = ns(leaf) = leaf[:NamespaceLen]. HashLeaf can return the ErrInvalidNodeLen error if the input is not namespaced.
-//
-//nolint:errcheck
 func (n *NmtHasher) HashLeaf(ndata []byte) ([]byte, error) {
-	h := n.baseHasher
-	h.Reset()
-
 	if err := n.ValidateLeaf(ndata); err != nil {
 		return nil, err
 	}
+	return n.hashLeafInto(make([]byte, 0, n.Size()), ndata), nil
+}
+
+// hashLeafInto is like HashLeaf for leaves that have already been validated,
+// but appends the result to dst instead of allocating it.
+//
+//nolint:errcheck
+func (n *NmtHasher) hashLeafInto(dst, ndata []byte) []byte {
+	h := n.baseHasher
+	h.Reset()
 
 	nID := ndata[:n.NamespaceLen]
-	resLen := int(2*n.NamespaceLen) + n.baseHasher.Size()
-	minMaxNIDs := make([]byte, 0, resLen)
-	minMaxNIDs = append(minMaxNIDs, nID...) // nID
+	minMaxNIDs := append(dst, nID...)       // nID
 	minMaxNIDs = append(minMaxNIDs, nID...) // nID || nID
 
-	// add LeafPrefix to the ndata
-	leafPrefixedNData := make([]byte, 0, len(ndata)+1)
-	leafPrefixedNData = append(leafPrefixedNData, LeafPrefix)
-	leafPrefixedNData = append(leafPrefixedNData, ndata...)
-	h.Write(leafPrefixedNData)
+	// write LeafPrefix || ndata without copying the potentially large ndata
+	// into a prefixed buffer
+	h.Write(n.leafPrefix[:])
+	h.Write(ndata)
 
 	// compute h(LeafPrefix || ndata) and append it to the minMaxNIDs
-	nameSpacedHash := h.Sum(minMaxNIDs) // nID || nID || h(LeafPrefix || ndata)
-	return nameSpacedHash, nil
+	return h.Sum(minMaxNIDs) // nID || nID || h(LeafPrefix || ndata)
 }
 
 // MustHashLeaf is a wrapper around HashLeaf that panics if an error is
@@ -221,14 +274,25 @@ func (n *NmtHasher) ValidateNodeFormat(node []byte) (err error) {
 		return fmt.Errorf("%w: got: %v, want %v", ErrInvalidNodeLen, nodeLen, expectedNodeLen)
 	}
 	// check the namespace order
-	minNID := namespace.ID(MinNamespace(node, n.NamespaceSize()))
-	maxNID := namespace.ID(MaxNamespace(node, n.NamespaceSize()))
+	minNID := minNamespaceView(node, n.NamespaceSize())
+	maxNID := maxNamespaceView(node, n.NamespaceSize())
 	if maxNID.Less(minNID) {
 		return fmt.Errorf("%w: max namespace ID %d is less than min namespace ID %d ", ErrInvalidNodeNamespaceOrder, maxNID, minNID)
 	}
 	return nil
 }
 
+// validateNamespaceID returns an ErrMismatchedNamespaceSize error if the length
+// of nID differs from the namespace size of n. Namespace IDs passed to
+// verifiers are untrusted and may be longer than namespace.IDMaxSize, in which
+// case nID.Size() wraps around, hence their length is compared instead.
+func (n *NmtHasher) validateNamespaceID(nID namespace.ID) error {
+	if len(nID) != int(n.NamespaceSize()) {
+		return fmt.Errorf("namespace ID size (%d) does not match the namespace size of the NMT hasher (%d): %w", len(nID), n.NamespaceSize(), ErrMismatchedNamespaceSize)
+	}
+	return nil
+}
+
 // validateSiblingsNamespaceOrder checks whether left and right as two sibling
 // nodes in an NMT have correct namespace IDs relative to each other, more
 // specifically, the maximum namespace ID of the left sibling should not exceed
@@ -240,8 +304,8 @@ func (n *NmtHasher) validateSiblingsNamespaceOrder(left, right []byte) (err erro
 	if err := n.ValidateNodeFormat(right); err != nil {
 		return fmt.Errorf("%w: right node does not match the namesapce hash format", err)
 	}
-	leftMaxNs := namespace.ID(MaxNamespace(left, n.NamespaceSize()))
-	rightMinNs := namespace.ID(MinNamespace(right, n.NamespaceSize()))
+	leftMaxNs := maxNamespaceView(left, n.NamespaceSize())
+	rightMinNs := minNamespaceView(right, n.NamespaceSize())
 
 	// check the namespace range of the left and right children
 	if rightMinNs.Less(leftMaxNs) {
@@ -250,6 +314,36 @@ func (n *NmtHasher) validateSiblingsNamespaceOrder(left, right []byte) (err erro
 	return nil
 }
 
+// namespaceOrder checks that the nodes of a proof, visited in the order of the
+// leaves they cover, are ordered by namespace ID, i.e., that the maximum
+// namespace ID of each node does not exceed the minimum namespace ID of the
+// next one. HashNode only compares siblings, whose namespace ranges may hide
+// misordered descendants if the maximum namespace ID is ignored.
+type namespaceOrder struct {
+	nth     *NmtHasher
+	prevMax namespace.ID
+}
+
+// next checks node against the nodes visited before. It returns an
+// ErrUnorderedSiblings error if they are misordered. Nil nodes are skipped.
+func (o *namespaceOrder) next(node []byte) error {
+	if node == nil {
+		return nil
+	}
+	minNs := minNamespaceView(node, o.nth.NamespaceSize())
+	if o.prevMax != nil && minNs.Less(o.prevMax) {
+		return fmt.Errorf("%w: node with min namespace %x follows a node with max namespace %x", ErrUnorderedSiblings, minNs, o.prevMax)
+	}
+	o.prevMax = maxNamespaceView(node, o.nth.NamespaceSize())
+	return nil
+}
+
+// pop is like popIfNonEmpty but checks the order of the popped node.
+func (o *namespaceOrder) pop(s *[][]byte) ([]byte, error) {
+	node := popIfNonEmpty(s)
+	return node, o.next(node)
+}
+
 // ValidateNodes is a helper function  to verify the
 // validity of the inputs of HashNode. It verifies whether left
 // and right comply by the namespace hash format, and are correctly ordered
@@ -282,30 +376,46 @@ func (n *NmtHasher) HashNode(left, right []byte) ([]byte, error) {
 	if err := n.ValidateNodes(left, right); err != nil {
 		return nil, err
 	}
+	return n.hashNodeInto(make([]byte, 0, n.Size()), left, right), nil
+}
 
+// hashNodeInto is like HashNode for nodes that have already been validated,
+// but appends the result to dst instead of allocating it. If dst has a
+// capacity of at least n.Size(), hashNodeInto does not allocate.
+func (n *NmtHasher) hashNodeInto(dst, left, right []byte) []byte {
 	h := n.baseHasher
 	h.Reset()
 
-	leftMinNs, leftMaxNs := MinNamespace(left, n.NamespaceLen), MaxNamespace(left, n.NamespaceLen)
-	rightMinNs, rightMaxNs := MinNamespace(right, n.NamespaceLen), MaxNamespace(right, n.NamespaceLen)
+	leftMinNs, leftMaxNs := minNamespaceView(left, n.NamespaceLen), maxNamespaceView(left, n.NamespaceLen)
+	rightMinNs, rightMaxNs := minNamespaceView(right, n.NamespaceLen), maxNamespaceView(right, n.NamespaceLen)
 
 	// compute the namespace range of the parent node
 	minNs, maxNs := computeNsRange(leftMinNs, leftMaxNs, rightMinNs, rightMaxNs, n.ignoreMaxNs, n.precomputedMaxNs)
 
-	res := make([]byte, 0)
-	res = append(res, minNs...)
+	res := append(dst, minNs...)
 	res = append(res, maxNs...)
 
-	// Note this seems a little faster than calling several Write()s on the
-	// underlying Hash function (see:
-	// https://github.com/google/trillian/pull/1503):
-	data := make([]byte, 0, 1+len(left)+len(right))
-	data = append(data, NodePrefix)
-	data = append(data, left...)
-	data = append(data, right...)
+	// the prefix and the children are written to the hash state directly
+	// rather than being concatenated first, which would copy every node of
+	// the tree once more
+	//nolint:errcheck
+	h.Write(n.nodePrefix[:])
 	//nolint:errcheck
-	h.Write(data)
-	return h.Sum(res), nil
+	h.Write(left)
+	//nolint:errcheck
+	h.Write(right)
+	return h.Sum(res)
+}
+
+// minNamespaceView and maxNamespaceView are like MinNamespace and
+// MaxNamespace but return sub-slices of hash instead of copies. The results
+// must not be modified or retained.
+func minNamespaceView(hash []byte, size namespace.IDSize) namespace.ID {
+	return hash[:size]
+}
+
+func maxNamespaceView(hash []byte, size namespace.IDSize) namespace.ID {
+	return hash[size : size*2]
 }
 
 func max(ns []byte, ns2 []byte) []byte {
@@ -0,0 +1,98 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownHasher is returned by GetHasher for names that are not registered.
+var ErrUnknownHasher = errors.New("unknown hasher")
+
+// HasherFactory creates a new Hasher. Hashers are not safe for concurrent use,
+// so every call should return a fresh instance.
+type HasherFactory func() Hasher
+
+var (
+	hashersMtx sync.RWMutex
+	hashers    = map[string]HasherFactory{
+		// the default configuration of New
+		"sha256-ns8": func() Hasher {
+			return NewNmtHasher(sha256.New(), DefaultNamespaceIDLen, true)
+		},
+		// the configuration used by Celestia
+		"sha256-ns29": func() Hasher {
+			return NewNmtHasher(sha256.New(), 29, true)
+		},
+	}
+)
+
+// RegisterHasher makes a hasher available under name, so that configuration
+// files and wire messages can reference it by a stable identifier and nodes
+// can check that they compute the same tree hash by comparing names. The
+// built-in names are "sha256-ns8" and "sha256-ns29" for the default hasher
+// with SHA-256 and namespace IDs of 8 and 29 bytes, both ignoring the maximum
+// namespace ID. Names are meant to be permanent: a name must never be reused
+// for a hasher computing different hashes. RegisterHasher panics if name is
+// empty or already registered, or if factory is nil. It is typically called
+// from an init function.
+func RegisterHasher(name string, factory HasherFactory) {
+	if name == "" {
+		panic("Got empty hasher name. Expected a non-empty name.")
+	}
+	if factory == nil {
+		panic("Got nil hasher factory. Expected a non-nil factory.")
+	}
+	hashersMtx.Lock()
+	defer hashersMtx.Unlock()
+	if _, found := hashers[name]; found {
+		panic(fmt.Sprintf("Got hasher name %q, which is already registered. Expected a new name.", name))
+	}
+	hashers[name] = factory
+}
+
+// GetHasher returns a new instance of the hasher registered under name. It
+// returns an ErrUnknownHasher error if no hasher is registered under name.
+func GetHasher(name string) (Hasher, error) {
+	hashersMtx.RLock()
+	factory, found := hashers[name]
+	hashersMtx.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownHasher, name)
+	}
+	return factory(), nil
+}
+
+// RegisteredHashers returns the names of all registered hashers in ascending
+// order.
+func RegisteredHashers() []string {
+	hashersMtx.RLock()
+	defer hashersMtx.RUnlock()
+	names := make([]string, 0, len(hashers))
+	for name := range hashers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NamedHasher replaces the default hasher by a new instance of the hasher
+// registered under name, and sets the namespace size and the handling of the
+// maximum namespace ID of the tree to the ones of the hasher. The base hash
+// function passed to New is ignored. NamedHasher panics if no hasher is
+// registered under name.
+func NamedHasher(name string) Option {
+	if _, err := GetHasher(name); err != nil {
+		panic(fmt.Sprintf("Got invalid hasher name: %v. Expected a registered name.", err))
+	}
+	return func(opts *Options) {
+		// the name is known to be registered, and names cannot be
+		// unregistered
+		h, _ := GetHasher(name)
+		opts.Hasher = h
+		opts.NamespaceIDSize = h.NamespaceSize()
+		opts.IgnoreMaxNamespace = h.IsMaxNamespaceIDIgnored()
+	}
+}
@@ -0,0 +1,70 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestGetHasher_Builtin(t *testing.T) {
+	h, err := GetHasher("sha256-ns8")
+	require.NoError(t, err)
+	assert.Equal(t, namespace.IDSize(DefaultNamespaceIDLen), h.NamespaceSize())
+	assert.True(t, h.IsMaxNamespaceIDIgnored())
+	assert.Equal(t, New(sha256.New()).treeHasher.EmptyRoot(), h.EmptyRoot())
+
+	h, err = GetHasher("sha256-ns29")
+	require.NoError(t, err)
+	assert.Equal(t, namespace.IDSize(29), h.NamespaceSize())
+
+	// every call returns a fresh instance
+	other, err := GetHasher("sha256-ns29")
+	require.NoError(t, err)
+	assert.NotSame(t, h, other)
+
+	_, err = GetHasher("sha256-ns7")
+	assert.ErrorIs(t, err, ErrUnknownHasher)
+}
+
+func TestRegisterHasher(t *testing.T) {
+	name := "sha512-ns4-test"
+	RegisterHasher(name, func() Hasher { return NewNmtHasher(sha512.New(), 4, false) })
+	t.Cleanup(func() {
+		hashersMtx.Lock()
+		delete(hashers, name)
+		hashersMtx.Unlock()
+	})
+	assert.Contains(t, RegisteredHashers(), name)
+	assert.IsIncreasing(t, RegisteredHashers())
+
+	h, err := GetHasher(name)
+	require.NoError(t, err)
+	assert.Len(t, h.EmptyRoot(), 2*4+sha512.Size)
+
+	assert.Panics(t, func() { RegisterHasher(name, func() Hasher { return nil }) })
+	assert.Panics(t, func() { RegisterHasher("", func() Hasher { return nil }) })
+	assert.Panics(t, func() { RegisterHasher("nil-factory", nil) })
+}
+
+func TestNamedHasher(t *testing.T) {
+	leaves := [][]byte{append(make([]byte, 29), 'a'), append(make([]byte, 29), 'b')}
+	named := New(nil, NamedHasher("sha256-ns29"))
+	explicit := New(sha256.New(), NamespaceIDSize(29))
+	for _, leaf := range leaves {
+		require.NoError(t, named.Push(leaf))
+		require.NoError(t, explicit.Push(leaf))
+	}
+	assert.Equal(t, namespace.IDSize(29), named.NamespaceSize())
+	got, err := named.Root()
+	require.NoError(t, err)
+	want, err := explicit.Root()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	assert.Panics(t, func() { NamedHasher("unknown") })
+}
@@ -964,3 +964,69 @@ func TestEmptyRoot(t *testing.T) {
 	// the empty root should be the same before and after the operation
 	assert.True(t, bytes.Equal(gotEmptyRoot, expectedEmptyRoot))
 }
+
+func TestEmptyRoot_PackageLevel(t *testing.T) {
+	for _, size := range []namespace.IDSize{0, 1, 8, 29} {
+		want := NewNmtHasher(sha256.New(), size, true).EmptyRoot()
+		got := EmptyRoot(sha256.New(), size)
+		assert.Equal(t, want, got)
+		assert.Len(t, got, 2*int(size)+sha256.Size)
+		// the empty root is the same no matter the state of the base hasher
+		h := sha256.New()
+		h.Write([]byte("data"))
+		assert.True(t, IsEmptyRoot(h, size, got))
+
+		tree := New(sha256.New(), NamespaceIDSize(int(size)))
+		root, err := tree.Root()
+		require.NoError(t, err)
+		assert.True(t, IsEmptyRoot(sha256.New(), size, root))
+		if size > 0 {
+			require.NoError(t, tree.Push(append(bytes.Repeat([]byte{0}, int(size)), 'a')))
+			root, err = tree.Root()
+			require.NoError(t, err)
+			assert.False(t, IsEmptyRoot(sha256.New(), size, root))
+		}
+	}
+	assert.False(t, IsEmptyRoot(sha256.New(), 8, EmptyRoot(sha256.New(), 7)))
+	assert.False(t, IsEmptyRoot(sha256.New(), 8, nil))
+}
+
+func TestHashAllocs(t *testing.T) {
+	nth := NewNmtHasher(sha256.New(), DefaultNamespaceIDLen, true)
+	leaf := append(bytes.Repeat([]byte{1}, DefaultNamespaceIDLen), []byte("leaf data")...)
+	left, err := nth.HashLeaf(leaf)
+	require.NoError(t, err)
+	right, err := nth.HashLeaf(leaf)
+	require.NoError(t, err)
+
+	// the resulting hash is the only allocation
+	leafAllocs := testing.AllocsPerRun(100, func() {
+		_, _ = nth.HashLeaf(leaf)
+	})
+	assert.LessOrEqual(t, leafAllocs, float64(1))
+	nodeAllocs := testing.AllocsPerRun(100, func() {
+		_, _ = nth.HashNode(left, right)
+	})
+	assert.LessOrEqual(t, nodeAllocs, float64(1))
+
+	// hashing into a supplied buffer does not allocate at all
+	dst := make([]byte, 0, nth.Size())
+	intoAllocs := testing.AllocsPerRun(100, func() {
+		_ = nth.hashNodeInto(dst, left, right)
+	})
+	assert.Zero(t, intoAllocs)
+}
+
+func TestHashNode_MatchesConcatenation(t *testing.T) {
+	nth := NewNmtHasher(sha256.New(), 1, false)
+	left, err := nth.HashLeaf([]byte{1, 'a'})
+	require.NoError(t, err)
+	right, err := nth.HashLeaf([]byte{2, 'b'})
+	require.NoError(t, err)
+	got, err := nth.HashNode(left, right)
+	require.NoError(t, err)
+
+	digest := sha256.Sum256(append(append([]byte{NodePrefix}, left...), right...))
+	want := append([]byte{1, 2}, digest[:]...)
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,148 @@
+package nmt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+var (
+	// ErrHistoryDisabled indicates that a checkpoint was requested from a tree
+	// that was not created with the History option.
+	ErrHistoryDisabled = errors.New("tree history is disabled")
+	// ErrUnknownVersion indicates that no checkpoint with the requested
+	// version has been recorded.
+	ErrUnknownVersion = errors.New("unknown tree version")
+)
+
+// Checkpoint identifies a past state of a tree with history, see History.
+type Checkpoint struct {
+	// Version is the index of the checkpoint in the tree's history, starting
+	// from 0.
+	Version int
+	// Size is the number of leaves of the tree at the checkpoint.
+	Size int
+	// Root is the root of the tree at the checkpoint.
+	Root []byte
+}
+
+// historyEntry is a recorded checkpoint together with a lazily created view
+// of the tree at the checkpoint, which memoizes its inner nodes.
+type historyEntry struct {
+	checkpoint Checkpoint
+	view       *NamespacedMerkleTree
+}
+
+// History enables the history mode of the tree. In history mode, the tree
+// records its root at checkpoints, i.e., whenever Checkpoint is called or a
+// Batch is committed, and can generate proofs against the root of any
+// recorded checkpoint using ProveRangeAt and ProveNamespaceAt. Since leaves
+// are only appended, the state at a checkpoint is the prefix of leaves the
+// tree had at that time. Checkpoints whose leaves are removed, e.g., by Pop,
+// Rollback or Reset, are discarded. Defaults to false.
+func History(enabled bool) Option {
+	return func(opts *Options) {
+		opts.History = enabled
+	}
+}
+
+// Checkpoint records the current root of the tree as a new version of its
+// history. It returns an ErrHistoryDisabled error if the tree was not created
+// with the History option.
+func (n *NamespacedMerkleTree) Checkpoint() (Checkpoint, error) {
+	if !n.keepHistory {
+		return Checkpoint{}, ErrHistoryDisabled
+	}
+	root, err := n.Root()
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	cp := Checkpoint{Version: len(n.history), Size: n.Size(), Root: root}
+	n.history = append(n.history, historyEntry{checkpoint: cp})
+	return cp, nil
+}
+
+// Checkpoints returns the recorded checkpoints of the tree ordered by
+// version.
+func (n *NamespacedMerkleTree) Checkpoints() []Checkpoint {
+	checkpoints := make([]Checkpoint, len(n.history))
+	for i, entry := range n.history {
+		checkpoints[i] = entry.checkpoint
+	}
+	return checkpoints
+}
+
+// ProveRangeAt is like ProveRange but returns a proof against the root of the
+// checkpoint with the given version. The range must be within the leaves the
+// tree had at the checkpoint. It returns an ErrUnknownVersion error if no such
+// checkpoint exists.
+func (n *NamespacedMerkleTree) ProveRangeAt(version, start, end int) (Proof, error) {
+	view, err := n.versionView(version)
+	if err != nil {
+		return Proof{}, err
+	}
+	return view.ProveRange(start, end)
+}
+
+// ProveNamespaceAt is like ProveNamespace but returns a proof against the root
+// of the checkpoint with the given version. It returns an ErrUnknownVersion
+// error if no such checkpoint exists.
+func (n *NamespacedMerkleTree) ProveNamespaceAt(version int, nID namespace.ID) (Proof, error) {
+	view, err := n.versionView(version)
+	if err != nil {
+		return Proof{}, err
+	}
+	return view.ProveNamespace(nID)
+}
+
+// versionView returns the view of the tree at the checkpoint with the given
+// version.
+func (n *NamespacedMerkleTree) versionView(version int) (*NamespacedMerkleTree, error) {
+	if version < 0 || version >= len(n.history) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	entry := &n.history[version]
+	if entry.view == nil {
+		entry.view = n.prefixView(entry.checkpoint.Size)
+	}
+	return entry.view, nil
+}
+
+// prefixView returns a tree consisting of the first size leaves of n, which
+// shares the leaves and the hasher with n. It must not be modified and is only
+// valid as long as n keeps these leaves.
+func (n *NamespacedMerkleTree) prefixView(size int) *NamespacedMerkleTree {
+	nidSize := n.NamespaceSize()
+	view := &NamespacedMerkleTree{
+		treeHasher:      n.treeHasher,
+		metrics:         NoopMetrics{},
+		codec:           n.codec,
+		leaves:          n.leaves[:size:size],
+		leafHashes:      n.leafHashes[:size:size],
+		namespaceRanges: make(map[string]LeafRange),
+		padding:         n.padding,
+		fixedSize:       n.fixedSize,
+		unordered:       n.unordered,
+		minNID:          bytes.Repeat([]byte{0xFF}, int(nidSize)),
+		maxNID:          bytes.Repeat([]byte{0x00}, int(nidSize)),
+	}
+	for nsStr, rng := range n.namespaceRanges {
+		if rng.Start < size {
+			view.namespaceRanges[nsStr] = LeafRange{Start: rng.Start, End: minInt(rng.End, size)}
+		}
+	}
+	for _, leafHash := range view.leafHashes {
+		view.updateMinMaxID(leafHash[:nidSize])
+	}
+	return view
+}
+
+// truncateHistory discards the checkpoints with more than size leaves.
+func (n *NamespacedMerkleTree) truncateHistory(size int) {
+	for len(n.history) > 0 && n.history[len(n.history)-1].checkpoint.Size > size {
+		n.history[len(n.history)-1] = historyEntry{}
+		n.history = n.history[:len(n.history)-1]
+	}
+}
@@ -0,0 +1,108 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestHistory_ProofsAgainstOldRoots(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), History(true))
+	batches := [][][]byte{
+		{{1, 'a'}, {2, 'b'}},
+		{{2, 'c'}, {3, 'd'}, {3, 'e'}},
+		{{5, 'f'}},
+	}
+	for _, batch := range batches {
+		require.NoError(t, tree.PushAll(batch))
+	}
+	checkpoints := tree.Checkpoints()
+	require.Len(t, checkpoints, 3)
+
+	var pushed [][]byte
+	for version, batch := range batches {
+		pushed = append(pushed, batch...)
+		cp := checkpoints[version]
+		assert.Equal(t, version, cp.Version)
+		assert.Equal(t, len(pushed), cp.Size)
+
+		// the root of the checkpoint is the root of the leaves pushed so far
+		want := New(sha256.New(), NamespaceIDSize(1))
+		require.NoError(t, want.PushAll(pushed))
+		wantRoot, err := want.Root()
+		require.NoError(t, err)
+		assert.Equal(t, wantRoot, cp.Root)
+
+		for i, leaf := range pushed {
+			proof, err := tree.ProveRangeAt(version, i, i+1)
+			require.NoError(t, err)
+			assert.True(t, proof.VerifyInclusion(sha256.New(), leaf[:1], [][]byte{leaf[1:]}, cp.Root))
+		}
+		for nID := byte(0); nID <= 6; nID++ {
+			proof, err := tree.ProveNamespaceAt(version, namespace.ID{nID})
+			require.NoError(t, err)
+			wantProof, err := want.ProveNamespace(namespace.ID{nID})
+			require.NoError(t, err)
+			assert.Equal(t, wantProof, proof)
+			assert.True(t, proof.VerifyNamespace(sha256.New(), namespace.ID{nID}, want.Get(namespace.ID{nID}), cp.Root))
+		}
+		_, err = tree.ProveRangeAt(version, 0, len(pushed)+1)
+		assert.ErrorIs(t, err, ErrInvalidRange)
+	}
+}
+
+func TestHistory_Checkpoint(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), History(true))
+	cp, err := tree.Checkpoint()
+	require.NoError(t, err)
+	assert.Equal(t, Checkpoint{Version: 0, Size: 0, Root: tree.treeHasher.EmptyRoot()}, cp)
+
+	require.NoError(t, tree.Push([]byte{1, 'a'}))
+	cp, err = tree.Checkpoint()
+	require.NoError(t, err)
+	assert.Equal(t, 1, cp.Version)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	assert.Equal(t, root, cp.Root)
+
+	_, err = tree.ProveRangeAt(2, 0, 1)
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+	_, err = tree.ProveNamespaceAt(-1, namespace.ID{1})
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+
+	_, err = New(sha256.New()).Checkpoint()
+	assert.ErrorIs(t, err, ErrHistoryDisabled)
+}
+
+func TestHistory_Truncation(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), History(true))
+	require.NoError(t, tree.PushAll([][]byte{{1, 'a'}, {2, 'b'}}))
+	require.NoError(t, tree.PushAll([][]byte{{3, 'c'}}))
+	first := tree.Checkpoints()[0]
+	// memoize the view of the first checkpoint
+	_, err := tree.ProveRangeAt(0, 0, 1)
+	require.NoError(t, err)
+
+	// popping discards the checkpoints covering the popped leaf
+	_, err = tree.Pop()
+	require.NoError(t, err)
+	assert.Equal(t, []Checkpoint{first}, tree.Checkpoints())
+
+	// the remaining checkpoints are unaffected by subsequent pushes
+	require.NoError(t, tree.Push([]byte{4, 'd'}))
+	proof, err := tree.ProveRangeAt(0, 1, 2)
+	require.NoError(t, err)
+	assert.True(t, proof.VerifyInclusion(sha256.New(), namespace.ID{2}, [][]byte{{'b'}}, first.Root))
+
+	batch := tree.Begin()
+	require.NoError(t, batch.Push([]byte{5, 'e'}))
+	require.NoError(t, batch.Rollback())
+	assert.Len(t, tree.Checkpoints(), 1)
+
+	tree.Reset()
+	assert.Empty(t, tree.Checkpoints())
+}
@@ -0,0 +1,297 @@
+// Package httpapi serves the root, leaves, namespaces and proofs of a
+// namespaced Merkle tree as JSON over HTTP, so that explorers, dashboards and
+// other light infrastructure can query a node holding a tree without gRPC
+// tooling. The handler returned by NewHandler serves the following endpoints:
+//
+//	GET /root                        the root and size of the tree
+//	GET /namespaces                  the namespaces of the tree and their ranges
+//	GET /namespaces/{namespace}      the leaves of a namespace with their proof
+//	GET /leaves                      leaves by index with their proof
+//
+// Namespace IDs are encoded in hexadecimal, both in paths and in responses;
+// leaves and hashes are base64-encoded like the fields of a proof (see
+// nmt.Proof.MarshalJSON). All endpoints but /root return pages of at most
+// limit items starting at offset, both given as query parameters, and report
+// the offset of the next page, if any. Errors are returned as a JSON object
+// with an "error" field.
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+const (
+	// DefaultPageSize is the number of items returned if a request does not
+	// specify a limit.
+	DefaultPageSize = 100
+	// DefaultMaxPageSize is the default maximum limit of a request, see
+	// MaxPageSize.
+	DefaultMaxPageSize = 1000
+)
+
+// RootResponse is the response of GET /root.
+type RootResponse struct {
+	Root []byte `json:"root"`
+	Size int    `json:"size"`
+	// MinNamespace and MaxNamespace are the namespace range of the root.
+	MinNamespace string `json:"min_namespace"`
+	MaxNamespace string `json:"max_namespace"`
+}
+
+// Namespace is a namespace of the tree and the range of its leaves [Start,
+// End).
+type Namespace struct {
+	Namespace string `json:"namespace"`
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+}
+
+// NamespacesResponse is the response of GET /namespaces.
+type NamespacesResponse struct {
+	Namespaces []Namespace `json:"namespaces"`
+	// Total is the number of namespaces of the tree.
+	Total int `json:"total"`
+	// NextOffset is the offset of the next page, if any.
+	NextOffset *int `json:"next_offset,omitempty"`
+}
+
+// LeavesResponse is the response of GET /leaves and GET
+// /namespaces/{namespace}.
+type LeavesResponse struct {
+	// Namespace is the requested namespace; it is empty for GET /leaves.
+	Namespace string `json:"namespace,omitempty"`
+	// Start is the index of the first leaf of the page in the tree.
+	Start  int      `json:"start"`
+	Leaves [][]byte `json:"leaves"`
+	// Total is the number of leaves of the namespace or the tree,
+	// respectively.
+	Total int `json:"total"`
+	// NextOffset is the offset of the next page, if any.
+	NextOffset *int `json:"next_offset,omitempty"`
+	// Proof is the inclusion proof of Leaves, see
+	// NamespacedMerkleTree.ProveRange. If the page holds all leaves of the
+	// requested namespace, it is the namespace proof of the namespace as
+	// well, see NamespacedMerkleTree.ProveNamespace; in particular, it is
+	// the proof of absence if the tree does not contain the namespace.
+	Proof nmt.Proof `json:"proof"`
+}
+
+// ErrorResponse is the response to failed requests.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Option is a function that configures the handler returned by NewHandler.
+type Option func(*handler)
+
+// MaxPageSize sets the maximum number of items returned per page. Requests
+// with larger limits are rejected. It panics if size is not positive.
+func MaxPageSize(size int) Option {
+	if size <= 0 {
+		panic(fmt.Sprintf("invalid max page size %d", size))
+	}
+	return func(h *handler) {
+		h.maxPageSize = size
+	}
+}
+
+// Locker sets the lock held while the tree is accessed. Since reading a tree
+// may update its caches, the handler serializes its requests by default; an
+// application modifying the tree while it is served must pass the lock it
+// holds while doing so.
+func Locker(l sync.Locker) Option {
+	return func(h *handler) {
+		h.mu = l
+	}
+}
+
+type handler struct {
+	tree        *nmt.NamespacedMerkleTree
+	mu          sync.Locker
+	maxPageSize int
+}
+
+// NewHandler returns an http.Handler serving tree, see the package
+// documentation.
+func NewHandler(tree *nmt.NamespacedMerkleTree, opts ...Option) http.Handler {
+	h := &handler{tree: tree, mu: &sync.Mutex{}, maxPageSize: DefaultMaxPageSize}
+	for _, opt := range opts {
+		opt(h)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /root", h.serve(h.root))
+	mux.HandleFunc("GET /namespaces", h.serve(h.namespaces))
+	mux.HandleFunc("GET /namespaces/{namespace}", h.serve(h.namespace))
+	mux.HandleFunc("GET /leaves", h.serve(h.leaves))
+	return mux
+}
+
+// httpError is an error with an HTTP status code.
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e httpError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(format string, args ...any) error {
+	return httpError{status: http.StatusBadRequest, err: fmt.Errorf(format, args...)}
+}
+
+// serve adapts fn to an http.HandlerFunc that writes the value returned by fn,
+// or the error, as JSON. fn is called with the lock held.
+func (h *handler) serve(fn func(r *http.Request) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.mu.Lock()
+		resp, err := fn(r)
+		h.mu.Unlock()
+
+		status := http.StatusOK
+		if err != nil {
+			var httpErr httpError
+			switch {
+			case errors.As(err, &httpErr):
+				status = httpErr.status
+			case errors.Is(err, nmt.ErrLeafDataDropped):
+				status = http.StatusGone
+			default:
+				status = http.StatusInternalServerError
+			}
+			resp = ErrorResponse{Error: err.Error()}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (h *handler) root(*http.Request) (any, error) {
+	root, err := h.tree.Root()
+	if err != nil {
+		return nil, err
+	}
+	nidSize := h.tree.NamespaceSize()
+	return RootResponse{
+		Root:         root,
+		Size:         h.tree.Size(),
+		MinNamespace: namespace.ID(nmt.MinNamespace(root, nidSize)).String(),
+		MaxNamespace: namespace.ID(nmt.MaxNamespace(root, nidSize)).String(),
+	}, nil
+}
+
+func (h *handler) namespaces(r *http.Request) (any, error) {
+	offset, limit, err := h.page(r)
+	if err != nil {
+		return nil, err
+	}
+	resp := NamespacesResponse{Namespaces: []Namespace{}}
+	for nID, rng := range h.tree.Namespaces() {
+		if resp.Total >= offset && resp.Total-offset < limit {
+			resp.Namespaces = append(resp.Namespaces, Namespace{Namespace: nID.String(), Start: rng.Start, End: rng.End})
+		}
+		resp.Total++
+	}
+	resp.NextOffset = nextOffset(offset, limit, resp.Total)
+	return resp, nil
+}
+
+func (h *handler) namespace(r *http.Request) (any, error) {
+	nID, err := namespace.ParseID(r.PathValue("namespace"), h.tree.NamespaceSize())
+	if err != nil {
+		return nil, badRequest("invalid namespace: %w", err)
+	}
+	offset, limit, err := h.page(r)
+	if err != nil {
+		return nil, err
+	}
+	rng, found := h.tree.LocateNamespace(nID)
+	if !found {
+		proof, err := h.tree.ProveNamespace(nID)
+		if err != nil {
+			return nil, err
+		}
+		return LeavesResponse{Namespace: nID.String(), Start: rng.Start, Leaves: [][]byte{}, Proof: proof}, nil
+	}
+	resp, err := h.leafPage(rng, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	resp.Namespace = nID.String()
+	return resp, nil
+}
+
+func (h *handler) leaves(r *http.Request) (any, error) {
+	offset, limit, err := h.page(r)
+	if err != nil {
+		return nil, err
+	}
+	return h.leafPage(nmt.LeafRange{Start: 0, End: h.tree.Size()}, offset, limit)
+}
+
+// leafPage returns the page of the leaves in rng starting at offset.
+func (h *handler) leafPage(rng nmt.LeafRange, offset, limit int) (LeavesResponse, error) {
+	total := rng.End - rng.Start
+	if total == 0 && offset == 0 {
+		return LeavesResponse{Start: rng.Start, Leaves: [][]byte{}}, nil
+	}
+	if offset >= total {
+		return LeavesResponse{}, httpError{
+			status: http.StatusNotFound,
+			err:    fmt.Errorf("offset %d is out of the range of %d leaves", offset, total),
+		}
+	}
+	start := rng.Start + offset
+	end := min(start+limit, rng.End)
+	resp := LeavesResponse{Start: start, Leaves: make([][]byte, 0, end-start), Total: total}
+	for i := start; i < end; i++ {
+		leaf, err := h.tree.Leaf(i)
+		if err != nil {
+			return LeavesResponse{}, err
+		}
+		resp.Leaves = append(resp.Leaves, leaf)
+	}
+	proof, err := h.tree.ProveRange(start, end)
+	if err != nil {
+		return LeavesResponse{}, err
+	}
+	resp.Proof = proof
+	resp.NextOffset = nextOffset(offset, limit, total)
+	return resp, nil
+}
+
+// page parses the offset and limit query parameters of r.
+func (h *handler) page(r *http.Request) (offset, limit int, err error) {
+	query := r.URL.Query()
+	offset, limit = 0, min(DefaultPageSize, h.maxPageSize)
+	if s := query.Get("offset"); s != "" {
+		if offset, err = strconv.Atoi(s); err != nil || offset < 0 {
+			return 0, 0, badRequest("invalid offset %q", s)
+		}
+	}
+	if s := query.Get("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit <= 0 || limit > h.maxPageSize {
+			return 0, 0, badRequest("invalid limit %q: must be within [1, %d]", s, h.maxPageSize)
+		}
+	}
+	return offset, limit, nil
+}
+
+// nextOffset returns the offset of the page following the page at offset
+// holding up to limit of total items, or nil if it is the last page.
+func nextOffset(offset, limit, total int) *int {
+	if limit >= total-offset {
+		return nil
+	}
+	next := offset + limit
+	return &next
+}
@@ -0,0 +1,159 @@
+package httpapi
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// newServer serves a tree with namespace IDs of 2 bytes whose leaf i has the
+// namespace {0, i / 3}.
+func newServer(t *testing.T, size int, opts ...Option) (*nmt.NamespacedMerkleTree, *httptest.Server) {
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(2))
+	for i := 0; i < size; i++ {
+		require.NoError(t, tree.Push(append([]byte{0, byte(i / 3)}, []byte(fmt.Sprintf("leaf_%d", i))...)))
+	}
+	server := httptest.NewServer(NewHandler(tree, opts...))
+	t.Cleanup(server.Close)
+	return tree, server
+}
+
+func get(t *testing.T, server *httptest.Server, path string, wantStatus int, resp any) {
+	r, err := http.Get(server.URL + path)
+	require.NoError(t, err)
+	defer r.Body.Close()
+	require.Equal(t, wantStatus, r.StatusCode, path)
+	assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(r.Body).Decode(resp))
+}
+
+func TestRoot(t *testing.T) {
+	tree, server := newServer(t, 10)
+	var resp RootResponse
+	get(t, server, "/root", http.StatusOK, &resp)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	assert.Equal(t, RootResponse{Root: root, Size: 10, MinNamespace: "0000", MaxNamespace: "0003"}, resp)
+}
+
+func TestNamespaces(t *testing.T) {
+	_, server := newServer(t, 10)
+	var resp NamespacesResponse
+	get(t, server, "/namespaces?limit=3", http.StatusOK, &resp)
+	assert.Equal(t, []Namespace{{"0000", 0, 3}, {"0001", 3, 6}, {"0002", 6, 9}}, resp.Namespaces)
+	assert.Equal(t, 4, resp.Total)
+	require.NotNil(t, resp.NextOffset)
+	assert.Equal(t, 3, *resp.NextOffset)
+
+	resp = NamespacesResponse{}
+	get(t, server, "/namespaces?offset=3&limit=3", http.StatusOK, &resp)
+	assert.Equal(t, []Namespace{{"0003", 9, 10}}, resp.Namespaces)
+	assert.Nil(t, resp.NextOffset)
+}
+
+func TestNamespace(t *testing.T) {
+	tree, server := newServer(t, 10)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	nID := namespace.ID{0, 1}
+
+	// a single page holds all leaves of the namespace and its proof
+	var resp LeavesResponse
+	get(t, server, "/namespaces/0001", http.StatusOK, &resp)
+	assert.Equal(t, "0001", resp.Namespace)
+	assert.Equal(t, 3, resp.Start)
+	assert.Equal(t, 3, resp.Total)
+	assert.Nil(t, resp.NextOffset)
+	assert.Equal(t, tree.Get(nID), resp.Leaves)
+	assert.True(t, resp.Proof.VerifyNamespace(sha256.New(), nID, resp.Leaves, root))
+
+	// pages of a namespace are proven to be part of the tree
+	resp = LeavesResponse{}
+	get(t, server, "/namespaces/0001?offset=1&limit=1", http.StatusOK, &resp)
+	assert.Equal(t, 4, resp.Start)
+	require.NotNil(t, resp.NextOffset)
+	assert.Equal(t, 2, *resp.NextOffset)
+	assert.Equal(t, tree.Get(nID)[1:2], resp.Leaves)
+	assert.True(t, resp.Proof.VerifyInclusion(sha256.New(), nID, [][]byte{resp.Leaves[0][2:]}, root))
+
+	// absent namespaces come with a proof of absence
+	tree.Reset()
+	for _, leaf := range [][]byte{{0, 1}, {0, 3}} {
+		require.NoError(t, tree.Push(leaf))
+	}
+	root, err = tree.Root()
+	require.NoError(t, err)
+	resp = LeavesResponse{}
+	get(t, server, "/namespaces/0002", http.StatusOK, &resp)
+	assert.Empty(t, resp.Leaves)
+	assert.True(t, resp.Proof.IsOfAbsence())
+	assert.True(t, resp.Proof.VerifyNamespace(sha256.New(), namespace.ID{0, 2}, nil, root))
+}
+
+func TestLeaves(t *testing.T) {
+	tree, server := newServer(t, 10, MaxPageSize(4))
+	root, err := tree.Root()
+	require.NoError(t, err)
+
+	var leaves [][]byte
+	for offset := 0; ; {
+		var resp LeavesResponse
+		get(t, server, fmt.Sprintf("/leaves?offset=%d", offset), http.StatusOK, &resp)
+		assert.Equal(t, offset, resp.Start)
+		assert.Equal(t, 10, resp.Total)
+		assert.LessOrEqual(t, len(resp.Leaves), 4)
+		for i, leaf := range resp.Leaves {
+			proof, err := tree.ProveRange(offset+i, offset+i+1)
+			require.NoError(t, err)
+			assert.True(t, proof.VerifyInclusion(sha256.New(), leaf[:2], [][]byte{leaf[2:]}, root))
+		}
+		leaves = append(leaves, resp.Leaves...)
+		if resp.NextOffset == nil {
+			break
+		}
+		offset = *resp.NextOffset
+	}
+	require.Len(t, leaves, 10)
+	for i, leaf := range leaves {
+		want, err := tree.Leaf(i)
+		require.NoError(t, err)
+		assert.Equal(t, want, leaf)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	_, server := newServer(t, 10, MaxPageSize(4))
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/leaves?limit=5", http.StatusBadRequest},
+		{"/leaves?limit=0", http.StatusBadRequest},
+		{"/leaves?offset=-1", http.StatusBadRequest},
+		{"/leaves?offset=x", http.StatusBadRequest},
+		{"/leaves?offset=10", http.StatusNotFound},
+		{"/namespaces/xyz", http.StatusBadRequest},
+		{"/namespaces/000102", http.StatusBadRequest},
+		{"/namespaces/0001?offset=3", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		var resp ErrorResponse
+		get(t, server, tt.path, tt.wantStatus, &resp)
+		assert.NotEmpty(t, resp.Error, tt.path)
+	}
+
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(1), nmt.DropLeafData(true))
+	require.NoError(t, tree.Push([]byte{1, 'a'}))
+	dropped := httptest.NewServer(NewHandler(tree))
+	defer dropped.Close()
+	get(t, dropped, "/leaves", http.StatusGone, &ErrorResponse{})
+}
@@ -0,0 +1,142 @@
+// Package bech32 implements the bech32 encoding of BIP 173 without the
+// 90 character length limit, which namespaced hashes usually exceed.
+package bech32
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// ErrInvalid indicates that a string is not valid bech32.
+var ErrInvalid = errors.New("invalid bech32 string")
+
+// Encode returns the bech32 encoding of data with the human readable part
+// hrp. hrp must consist of lower case ASCII characters in [33, 126].
+func Encode(hrp string, data []byte) (string, error) {
+	if err := validateHRP(hrp); err != nil {
+		return "", err
+	}
+	values := convertBits(data, 8, 5, true)
+	checksum := createChecksum(hrp, values)
+
+	var sb strings.Builder
+	sb.Grow(len(hrp) + 1 + len(values) + len(checksum))
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, v := range append(values, checksum...) {
+		sb.WriteByte(charset[v])
+	}
+	return sb.String(), nil
+}
+
+// Decode returns the human readable part and the data of a bech32 string.
+// Strings in mixed case are rejected.
+func Decode(s string) (hrp string, data []byte, err error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, fmt.Errorf("%w: mixed case", ErrInvalid)
+	}
+	s = strings.ToLower(s)
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, fmt.Errorf("%w: missing separator or checksum", ErrInvalid)
+	}
+	hrp = s[:sep]
+	if err := validateHRP(hrp); err != nil {
+		return "", nil, err
+	}
+	values := make([]byte, 0, len(s)-sep-1)
+	for i := sep + 1; i < len(s); i++ {
+		v := strings.IndexByte(charset, s[i])
+		if v < 0 {
+			return "", nil, fmt.Errorf("%w: invalid character %q", ErrInvalid, s[i])
+		}
+		values = append(values, byte(v))
+	}
+	if polymod(append(expandHRP(hrp), values...)) != 1 {
+		return "", nil, fmt.Errorf("%w: checksum mismatch", ErrInvalid)
+	}
+	values = values[:len(values)-6]
+	// the padding bits must be zero and shorter than a full group
+	if len(values)*5%8 >= 5 {
+		return "", nil, fmt.Errorf("%w: invalid padding", ErrInvalid)
+	}
+	if pad := len(values) * 5 % 8; pad > 0 && len(values) > 0 && values[len(values)-1]&(1<<pad-1) != 0 {
+		return "", nil, fmt.Errorf("%w: non-zero padding", ErrInvalid)
+	}
+	return hrp, convertBits(values, 5, 8, false), nil
+}
+
+func validateHRP(hrp string) error {
+	if len(hrp) == 0 {
+		return fmt.Errorf("%w: empty human readable part", ErrInvalid)
+	}
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 || (hrp[i] >= 'A' && hrp[i] <= 'Z') {
+			return fmt.Errorf("%w: invalid human readable part character %q", ErrInvalid, hrp[i])
+		}
+	}
+	return nil
+}
+
+func polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i, g := range generator {
+			if (top>>i)&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
+
+func expandHRP(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+func createChecksum(hrp string, values []byte) []byte {
+	input := append(expandHRP(hrp), values...)
+	input = append(input, 0, 0, 0, 0, 0, 0)
+	mod := polymod(input) ^ 1
+	checksum := make([]byte, 6)
+	for i := range checksum {
+		checksum[i] = byte(mod >> (5 * (5 - i)) & 31)
+	}
+	return checksum
+}
+
+// convertBits regroups data from fromBits to toBits bit groups. Incomplete
+// trailing groups are zero padded if pad is set and dropped otherwise.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) []byte {
+	var acc uint32
+	var bits uint
+	maxValue := uint32(1)<<toBits - 1
+	out := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, b := range data {
+		acc = acc<<fromBits | uint32(b)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxValue))
+		}
+	}
+	if pad && bits > 0 {
+		out = append(out, byte(acc<<(toBits-bits)&maxValue))
+	}
+	return out
+}
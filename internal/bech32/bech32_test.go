@@ -0,0 +1,67 @@
+package bech32
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode_BIP173Vectors(t *testing.T) {
+	valid := []string{
+		"A12UEL5L",
+		"a12uel5l",
+		"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"11qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqc8247j",
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+	}
+	for _, s := range valid {
+		_, _, err := Decode(s)
+		assert.NoError(t, err, s)
+	}
+
+	invalid := []string{
+		"pzry9x0s0muk",  // no separator
+		"1pzry9x0s0muk", // empty hrp
+		"x1b4n0q5v",     // invalid data character
+		"li1dgmt3",      // too short checksum
+		"A1G7SGD8",      // checksum calculated with uppercase hrp
+		"10a06t8",       // empty hrp
+		"1qzzfhee",      // empty hrp
+		"a12UEL5L",      // mixed case
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxx", // wrong checksum
+	}
+	for _, s := range invalid {
+		_, _, err := Decode(s)
+		assert.ErrorIs(t, err, ErrInvalid, s)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for size := 0; size < 100; size += 7 {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i * 37)
+		}
+		s, err := Encode("nmt", data)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(s, "nmt1"))
+
+		hrp, got, err := Decode(s)
+		require.NoError(t, err)
+		assert.Equal(t, "nmt", hrp)
+		assert.Equal(t, data, got)
+
+		hrp, got, err = Decode(strings.ToUpper(s))
+		require.NoError(t, err)
+		assert.Equal(t, "nmt", hrp)
+		assert.Equal(t, data, got)
+	}
+
+	_, err := Encode("", nil)
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = Encode("NMT", nil)
+	assert.ErrorIs(t, err, ErrInvalid)
+}
@@ -0,0 +1,25 @@
+package nmt
+
+import (
+	"crypto/hmac"
+	"hash"
+)
+
+// KeyedHash returns a constructor of HMAC instances of newHash with the given
+// secret key. The instances can be used as the base hash function of trees,
+// hashers and verifiers wherever a hash.Hash is expected, e.g., with New,
+// NewHashPool or Proof.VerifyNamespace. Roots and proofs computed with a keyed
+// hash have the same structure as unkeyed ones but can neither be predicted
+// nor verified without the key. Hash functions that support keys natively,
+// such as keyed BLAKE2b, can be passed to these functions directly instead.
+// KeyedHash panics if key is empty.
+func KeyedHash(newHash func() hash.Hash, key []byte) func() hash.Hash {
+	if len(key) == 0 {
+		panic("Got empty key. Expected a non-empty secret key.")
+	}
+	// the caller may reuse the key's array
+	key = append([]byte(nil), key...)
+	return func() hash.Hash {
+		return hmac.New(newHash, key)
+	}
+}
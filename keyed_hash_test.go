@@ -0,0 +1,50 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestKeyedHash(t *testing.T) {
+	leaves := [][]byte{{1, 'a'}, {2, 'b'}, {2, 'c'}, {4, 'd'}}
+	rootWith := func(newTree func() *NamespacedMerkleTree) []byte {
+		tree := newTree()
+		for _, leaf := range leaves {
+			require.NoError(t, tree.Push(leaf))
+		}
+		root, err := tree.Root()
+		require.NoError(t, err)
+		return root
+	}
+	key := []byte("secret")
+	keyed := KeyedHash(sha256.New, key)
+	// the key is copied
+	key[0] = 'S'
+
+	root := rootWith(func() *NamespacedMerkleTree { return New(keyed(), NamespaceIDSize(1)) })
+	assert.Equal(t, root, rootWith(func() *NamespacedMerkleTree {
+		return New(KeyedHash(sha256.New, []byte("secret"))(), NamespaceIDSize(1))
+	}))
+	assert.NotEqual(t, root, rootWith(func() *NamespacedMerkleTree { return New(sha256.New(), NamespaceIDSize(1)) }))
+	assert.NotEqual(t, root, rootWith(func() *NamespacedMerkleTree {
+		return New(KeyedHash(sha256.New, []byte("other"))(), NamespaceIDSize(1))
+	}))
+	// the namespace range of the root is unaffected by the key
+	assert.Equal(t, []byte{1, 4}, root[:2])
+
+	tree := New(keyed(), NamespaceIDSize(1))
+	for _, leaf := range leaves {
+		require.NoError(t, tree.Push(leaf))
+	}
+	proof, err := tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	assert.True(t, proof.VerifyNamespace(keyed(), namespace.ID{2}, leaves[1:3], root))
+	assert.False(t, proof.VerifyNamespace(sha256.New(), namespace.ID{2}, leaves[1:3], root))
+
+	assert.Panics(t, func() { KeyedHash(sha256.New, nil) })
+}
@@ -0,0 +1,91 @@
+package nmt
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+	"math/bits"
+	"slices"
+)
+
+// ProveLastLeaf returns a SizeProof of the tree, which opens its last leaf.
+// Unlike a proof of an arbitrary leaf, it also commits to the size of the
+// tree, so that a verifier can check that a proposed append extends the tree
+// at the correct position, see SizeProof.VerifyLastLeaf. It returns an
+// ErrInvalidRange error if the tree is empty and an ErrLeafDataDropped error
+// if the data of the leaves opened by the proof is not available.
+func (n *NamespacedMerkleTree) ProveLastLeaf() (SizeProof, error) {
+	if n.Size() == 0 {
+		return SizeProof{}, fmt.Errorf("%w: the tree is empty", ErrInvalidRange)
+	}
+	return n.ProveSize()
+}
+
+// VerifyLastLeaf verifies that p.LastLeaf is the last of the p.Size leaves of
+// the tree with the given root, as Verify. h and setters must match the base
+// hash function and the options of the tree, including its padding and fixed
+// height. The nodes of the proof are the frontier of the tree without its
+// last leaf; on success, VerifyLastLeaf returns a RootComputer holding the
+// frontier of the whole tree, to which the leaves of a proposed append can be
+// pushed to compute the root of the extended tree. It returns an error
+// wrapping ErrInvalidProof if the verification fails or the proof is of an
+// empty tree.
+func (p SizeProof) VerifyLastLeaf(h hash.Hash, root []byte, setters ...Option) (*RootComputer, error) {
+	if p.Size < 1 {
+		return nil, fmt.Errorf("%w: proof of size %d has no last leaf", ErrInvalidProof, p.Size)
+	}
+	c := NewRootComputer(h, setters...)
+	numNodes := bits.OnesCount(uint(p.Size - 1))
+	if len(p.Nodes) != numNodes || len(p.Openings) != numNodes {
+		return nil, fmt.Errorf("%w: got %d nodes and %d openings, want %d", ErrInvalidProof, len(p.Nodes), len(p.Openings), numNodes)
+	}
+	// the openings prove the heights of the nodes, which could otherwise be
+	// passed off as the roots of smaller subtrees
+	i := 0
+	for height := bits.Len(uint(p.Size-1)) - 1; height >= 0; height-- {
+		if (p.Size-1)&(1<<height) == 0 {
+			continue
+		}
+		if err := p.Openings[i].verify(c.treeHasher, height, p.Nodes[i]); err != nil {
+			return nil, fmt.Errorf("%w: subtree %d: %w", ErrInvalidProof, i, err)
+		}
+		i++
+	}
+	leafHash, err := hashSizeProofLeaf(c.treeHasher, p.LastLeaf)
+	if err != nil {
+		return nil, fmt.Errorf("%w: last leaf: %w", ErrInvalidProof, err)
+	}
+	if err := c.verifyLastLeaf(p.Size, p.Nodes, leafHash, root); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// verifyLastLeaf restores the frontier of a tree of size leaves from the
+// roots of the perfect subtrees to the left of its last leaf, whose heights
+// must have been verified, and the hash leafHash of the last leaf, and checks
+// that it results in root. c must be empty.
+func (c *RootComputer) verifyLastLeaf(size int, nodes [][]byte, leafHash, root []byte) error {
+	if c.fixedSize > 0 && size > c.fixedSize {
+		return fmt.Errorf("%w: size %d exceeds the capacity %d of the tree", ErrInvalidProof, size, c.fixedSize)
+	}
+	nodeSize := len(c.treeHasher.EmptyRoot())
+	if len(leafHash) != nodeSize {
+		return fmt.Errorf("%w: leaf hash has size %d, want %d", ErrInvalidProof, len(leafHash), nodeSize)
+	}
+
+	c.peaks = slices.Clone(nodes)
+	c.size = size - 1
+	if err := c.pushLeafHash(bytes.Clone(leafHash)); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidProof, err)
+	}
+	c.lastNID = append(c.lastNID, MinNamespace(leafHash, c.treeHasher.NamespaceSize())...)
+	got, err := c.Root()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidProof, err)
+	}
+	if !bytes.Equal(got, root) {
+		return fmt.Errorf("%w: leaf %d is not the last leaf of the tree with root %x", ErrInvalidProof, size-1, root)
+	}
+	return nil
+}
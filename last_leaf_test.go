@@ -0,0 +1,105 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveLastLeaf(t *testing.T) {
+	for _, opts := range [][]Option{
+		{NamespaceIDSize(2)},
+		{NamespaceIDSize(2), IgnoreMaxNamespace(false)},
+		{NamespaceIDSize(2), Padding(PadWithLastLeaf)},
+		{NamespaceIDSize(2), Padding(PadWithEmptyLeaves)},
+		{NamespaceIDSize(2), FixedHeight(5)},
+	} {
+		leaves := make([][]byte, 20)
+		for i := range leaves {
+			leaves[i] = append([]byte{0, byte(i / 3)}, []byte(fmt.Sprintf("leaf_%d", i))...)
+		}
+		for size := 1; size < len(leaves); size++ {
+			tree := New(sha256.New(), opts...)
+			require.NoError(t, tree.PushAll(leaves[:size]))
+			root, err := tree.Root()
+			require.NoError(t, err)
+			proof, err := tree.ProveLastLeaf()
+			require.NoError(t, err)
+
+			c, err := proof.VerifyLastLeaf(sha256.New(), root, opts...)
+			require.NoError(t, err, "size %d", size)
+			assert.Equal(t, size, c.Size())
+
+			// the proposed append is checked against the frontier
+			for _, leaf := range leaves[size:] {
+				require.NoError(t, c.Push(leaf))
+			}
+			assert.ErrorIs(t, c.Push([]byte{0, 0}), ErrInvalidPushOrder)
+			require.NoError(t, tree.PushAll(leaves[size:]))
+			want, err := tree.Root()
+			require.NoError(t, err)
+			got, err := c.Root()
+			require.NoError(t, err)
+			assert.Equal(t, want, got, "size %d", size)
+		}
+	}
+}
+
+func TestSizeProof_VerifyLastLeafInvalid(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1))
+	_, err := tree.ProveLastLeaf()
+	assert.ErrorIs(t, err, ErrInvalidRange)
+
+	require.NoError(t, tree.PushAll([][]byte{{1, 'a'}, {2, 'b'}, {3, 'c'}, {4, 'd'}, {5, 'e'}}))
+	root, err := tree.Root()
+	require.NoError(t, err)
+	proof, err := tree.ProveLastLeaf()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		proof SizeProof
+		root  []byte
+		opts  []Option
+	}{
+		{"empty", SizeProof{}, root, nil},
+		{"other leaf", SizeProof{Size: 5, Nodes: proof.Nodes, Openings: proof.Openings, LastLeaf: []byte{4, 'd'}}, root, nil},
+		{"short leaf", SizeProof{Size: 5, Nodes: proof.Nodes, Openings: proof.Openings, LastLeaf: []byte{}}, root, nil},
+		{"root", proof, tree.treeHasher.EmptyRoot(), nil},
+		{"missing opening", SizeProof{Size: 5, Nodes: proof.Nodes, LastLeaf: proof.LastLeaf}, root, nil},
+		{"padding", proof, root, []Option{Padding(PadWithLastLeaf)}},
+		{"capacity", proof, root, []Option{FixedHeight(2)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.proof.VerifyLastLeaf(sha256.New(), tt.root, append([]Option{NamespaceIDSize(1)}, tt.opts...)...)
+			assert.ErrorIs(t, err, ErrInvalidProof)
+		})
+	}
+}
+
+func TestSizeProof_VerifyLastLeafForgedSize(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, tree.PushAll([][]byte{{1, 'a'}, {2, 'b'}, {3, 'c'}}))
+	root, err := tree.Root()
+	require.NoError(t, err)
+	node, err := tree.ComputeSubtreeRoot(0, 2)
+	require.NoError(t, err)
+
+	// the root of the first two leaves passed off as the first leaf of a tree
+	// of two leaves, whose last leaf is the real last leaf
+	forged := SizeProof{
+		Size:     2,
+		Nodes:    [][]byte{node},
+		Openings: []SubtreeOpening{{FirstLeaf: []byte{1, 'a'}}},
+		LastLeaf: []byte{3, 'c'},
+	}
+	_, err = forged.VerifyLastLeaf(sha256.New(), root, NamespaceIDSize(1))
+	assert.ErrorIs(t, err, ErrInvalidProof)
+	forged.Openings[0].Siblings = [][]byte{tree.leafHashes[1]}
+	_, err = forged.VerifyLastLeaf(sha256.New(), root, NamespaceIDSize(1))
+	assert.ErrorIs(t, err, ErrInvalidProof)
+}
@@ -0,0 +1,44 @@
+package nmt
+
+import (
+	"fmt"
+)
+
+// LazyLeafHashing defers hashing pushed leaves until their hashes are needed,
+// e.g., by Root, a proof or IndexOf, at which point all pending leaves are
+// hashed in bulk. This keeps Push cheap, in particular for trees that are
+// discarded before their root is computed. Errors of the leaf hasher are then
+// returned by the method triggering the hashing rather than by Push; the
+// default hasher cannot fail on leaves accepted by Push. Defaults to false.
+func LazyLeafHashing(lazy bool) Option {
+	return func(opts *Options) {
+		opts.LazyLeafHashing = lazy
+	}
+}
+
+// hashPendingLeaves computes the hashes of the leaves pushed lazily, see
+// LazyLeafHashing.
+func (n *NamespacedMerkleTree) hashPendingLeaves() error {
+	if n.frontier != nil {
+		// the hashes are needed, but not kept
+		return ErrFrontierOnly
+	}
+	for ; n.pending > 0; n.pending-- {
+		i := n.Size() - n.pending
+		leafHash, err := n.hashLeaf(n.leaves[i], n.pending)
+		if err != nil {
+			return fmt.Errorf("failed to hash leaf %d: %w", i, err)
+		}
+		n.metrics.LeafHashed()
+		n.leafHashes[i] = leafHash
+		if n.dropLeafData {
+			n.leaves[i] = nil
+		}
+		if n.leafIndices != nil {
+			if _, found := n.leafIndices[string(leafHash)]; !found {
+				n.leafIndices[string(leafHash)] = i
+			}
+		}
+	}
+	return nil
+}
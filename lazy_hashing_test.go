@@ -0,0 +1,130 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestLazyLeafHashing(t *testing.T) {
+	m := &countingMetrics{}
+	lazy := New(sha256.New(), NamespaceIDSize(1), LazyLeafHashing(true), CustomMetrics(m))
+	eager := New(sha256.New(), NamespaceIDSize(1))
+	for _, leaf := range [][]byte{{1, 'a'}, {2, 'b'}, {2, 'c'}, {4, 'd'}, {5, 'e'}} {
+		require.NoError(t, lazy.Push(leaf))
+		require.NoError(t, eager.Push(leaf))
+	}
+	assert.Equal(t, 0, m.leafHashes)
+	assert.Equal(t, eager.Get(namespace.ID{2}), lazy.Get(namespace.ID{2}))
+	assert.Equal(t, 0, m.leafHashes)
+
+	wantRoot, err := eager.Root()
+	require.NoError(t, err)
+	root, err := lazy.Root()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoot, root)
+	assert.Equal(t, 5, m.leafHashes)
+	requireSameState(t, eager, lazy)
+
+	// leaves pushed after the root are hashed once they are needed
+	require.NoError(t, lazy.Push([]byte{6, 'f'}))
+	require.NoError(t, eager.Push([]byte{6, 'f'}))
+	assert.Equal(t, 5, m.leafHashes)
+	want, err := eager.ProveNamespace(namespace.ID{6})
+	require.NoError(t, err)
+	proof, err := lazy.ProveNamespace(namespace.ID{6})
+	require.NoError(t, err)
+	assert.Equal(t, want, proof)
+	assert.Equal(t, 6, m.leafHashes)
+}
+
+func TestLazyLeafHashing_Entrypoints(t *testing.T) {
+	leaves := [][]byte{{1, 'a'}, {2, 'b'}, {2, 'c'}, {4, 'd'}}
+	eager := New(sha256.New(), NamespaceIDSize(1))
+	for _, leaf := range leaves {
+		require.NoError(t, eager.Push(leaf))
+	}
+	newLazy := func() *NamespacedMerkleTree {
+		tree := New(sha256.New(), NamespaceIDSize(1), LazyLeafHashing(true))
+		for _, leaf := range leaves {
+			require.NoError(t, tree.Push(leaf))
+		}
+		return tree
+	}
+
+	index, found := newLazy().IndexOf(eager.leafHashes[2])
+	assert.True(t, found)
+	assert.Equal(t, 2, index)
+
+	want, err := eager.ProveRange(1, 3)
+	require.NoError(t, err)
+	got, err := newLazy().ProveRange(1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	wantMulti, err := eager.ProveMulti([]int{0, 3})
+	require.NoError(t, err)
+	gotMulti, err := newLazy().ProveMulti([]int{0, 3})
+	require.NoError(t, err)
+	assert.Equal(t, wantMulti, gotMulti)
+
+	wantRoot, err := eager.NamespaceRoot(namespace.ID{2})
+	require.NoError(t, err)
+	gotRoot, err := newLazy().NamespaceRoot(namespace.ID{2})
+	require.NoError(t, err)
+	assert.Equal(t, wantRoot, gotRoot)
+
+	wantNode, err := eager.SubtreeRoot(0, 1)
+	require.NoError(t, err)
+	gotNode, err := newLazy().SubtreeRoot(0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, wantNode, gotNode)
+
+	// pushing a leaf hash hashes the pending leaves first
+	tree := newLazy()
+	require.NoError(t, tree.PushLeafHash(eager.leafHashes[3]))
+	assert.Equal(t, eager.leafHashes[3], tree.leafHashes[3])
+}
+
+func TestLazyLeafHashing_Truncate(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), LazyLeafHashing(true))
+	require.NoError(t, tree.Push([]byte{1, 'a'}))
+	_, err := tree.Root()
+	require.NoError(t, err)
+	require.NoError(t, tree.Push([]byte{2, 'b'}))
+	require.NoError(t, tree.Push([]byte{3, 'c'}))
+
+	_, err = tree.Pop()
+	require.NoError(t, err)
+	assert.Equal(t, 1, tree.pending)
+	require.NoError(t, tree.Push([]byte{4, 'd'}))
+
+	// the pending leaves are hashed before they are shared with the snapshot
+	snapshot := tree.Snapshot(sha256.New())
+	assert.Equal(t, 0, tree.pending)
+	assert.Equal(t, 0, snapshot.pending)
+	require.NoError(t, tree.Push([]byte{5, 'e'}))
+
+	want := New(sha256.New(), NamespaceIDSize(1))
+	for _, leaf := range [][]byte{{1, 'a'}, {2, 'b'}, {4, 'd'}, {5, 'e'}} {
+		require.NoError(t, want.Push(leaf))
+	}
+	_, err = tree.Root()
+	require.NoError(t, err)
+	requireSameState(t, want, tree)
+
+	wantSnapshot := New(sha256.New(), NamespaceIDSize(1))
+	for _, leaf := range [][]byte{{1, 'a'}, {2, 'b'}, {4, 'd'}} {
+		require.NoError(t, wantSnapshot.Push(leaf))
+	}
+	_, err = snapshot.Root()
+	require.NoError(t, err)
+	requireSameState(t, wantSnapshot, snapshot)
+
+	tree.Reset()
+	assert.Equal(t, 0, tree.pending)
+}
@@ -0,0 +1,34 @@
+package nmt
+
+import (
+	"errors"
+)
+
+// ErrLeafDataDropped indicates that the data of a leaf was requested from a
+// tree that drops leaf data, see DropLeafData.
+var ErrLeafDataDropped = errors.New("leaf data has been dropped")
+
+// DropLeafData makes the tree discard the namespace-prefixed data of its
+// leaves once they are hashed and only retain their leaf hashes, which roughly
+// halves the memory used by trees of small leaves. Roots and proofs are
+// unaffected, but methods returning leaf data, such as Leaf or
+// GetNamespaceData, return an ErrLeafDataDropped error. Get, which cannot
+// report errors, returns nil leaves instead, just like for leaves added using
+// PushLeafHash. Defaults to false.
+func DropLeafData(drop bool) Option {
+	return func(opts *Options) {
+		opts.DropLeafData = drop
+	}
+}
+
+// checkLeafData returns an ErrLeafDataDropped error if the tree drops leaf
+// data.
+func (n *NamespacedMerkleTree) checkLeafData() error {
+	if n.frontier != nil {
+		return ErrFrontierOnly
+	}
+	if n.dropLeafData {
+		return ErrLeafDataDropped
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestDropLeafData(t *testing.T) {
+	for _, lazy := range []bool{false, true} {
+		tree := New(sha256.New(), NamespaceIDSize(1), DropLeafData(true), LazyLeafHashing(lazy))
+		want := New(sha256.New(), NamespaceIDSize(1))
+		for _, leaf := range [][]byte{{1, 'a'}, {2, 'b'}, {2, 'c'}, {4, 'd'}} {
+			require.NoError(t, tree.Push(leaf))
+			require.NoError(t, want.Push(leaf))
+		}
+
+		wantRoot, err := want.Root()
+		require.NoError(t, err)
+		root, err := tree.Root()
+		require.NoError(t, err)
+		assert.Equal(t, wantRoot, root)
+		assert.Equal(t, [][]byte{nil, nil, nil, nil}, tree.leaves)
+
+		wantProof, err := want.ProveNamespace(namespace.ID{2})
+		require.NoError(t, err)
+		proof, err := tree.ProveNamespace(namespace.ID{2})
+		require.NoError(t, err)
+		assert.Equal(t, wantProof, proof)
+		assert.True(t, proof.VerifyNamespace(sha256.New(), namespace.ID{2}, want.Get(namespace.ID{2}), root))
+
+		assert.Equal(t, [][]byte{nil, nil}, tree.Get(namespace.ID{2}))
+		_, err = tree.Leaf(0)
+		assert.ErrorIs(t, err, ErrLeafDataDropped)
+		_, err = tree.GetNamespaceData(namespace.ID{2})
+		assert.ErrorIs(t, err, ErrLeafDataDropped)
+		_, _, err = tree.GetWithProof(namespace.ID{2})
+		assert.ErrorIs(t, err, ErrLeafDataDropped)
+		_, err = tree.GetPayloads(namespace.ID{2})
+		assert.ErrorIs(t, err, ErrLeafDataDropped)
+		assert.Equal(t, 0, tree.Stats().Bytes)
+	}
+}
+
+func TestDropLeafData_Snapshot(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), DropLeafData(true), LazyLeafHashing(true))
+	require.NoError(t, tree.Push([]byte{1, 'a'}))
+	snapshot := tree.Snapshot(sha256.New())
+	root, err := tree.Root()
+	require.NoError(t, err)
+	// the tree dropping the data of its pending leaf does not affect the
+	// snapshot, which has not hashed it yet
+	snapshotRoot, err := snapshot.Root()
+	require.NoError(t, err)
+	assert.Equal(t, root, snapshotRoot)
+}
@@ -0,0 +1,72 @@
+package nmt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ErrInvalidLeafHash indicates that a precomputed leaf hash is not a valid
+// namespaced leaf hash.
+var ErrInvalidLeafHash = errors.New("invalid leaf hash")
+
+// NewFromLeafHashes initializes a namespaced Merkle tree just like New and
+// adds the given precomputed namespaced leaf hashes to it, see PushLeafHash.
+func NewFromLeafHashes(h hash.Hash, leafHashes [][]byte, setters ...Option) (*NamespacedMerkleTree, error) {
+	n := New(h, setters...)
+	for i, leafHash := range leafHashes {
+		if err := n.PushLeafHash(leafHash); err != nil {
+			return nil, fmt.Errorf("failed to add leaf hash %d: %w", i, err)
+		}
+	}
+	return n, nil
+}
+
+// PushLeafHash adds a leaf to the tree given only its namespaced leaf hash
+// (i.e., nID || nID || h(LeafPrefix || ndata)) as returned by the tree's
+// Hasher. This allows a node that pruned the raw data to still regenerate the
+// root and proofs of the tree.
+//
+// The same ordering rules as for Push apply. Since the raw data of such leaves
+// is unknown, they are represented by nil in the results of Get, Leaf and
+// GetWithProof and are passed as nil to the NodeVisitorFn.
+// PushLeafHash returns an ErrInvalidLeafHash error if leafHash is not of the
+// tree's node size or its minimum and maximum namespace IDs differ, an
+// ErrInvalidPushOrder error if its namespace ID is smaller than the one of the
+// last leaf in the tree, and an ErrReservedNamespace error if the tree rejects
+// its namespace ID.
+func (n *NamespacedMerkleTree) PushLeafHash(leafHash []byte) error {
+	err := n.pushLeafHash(leafHash)
+	n.metrics.Pushed(err)
+	n.logPush("PushLeafHash", err)
+	n.checkInvariants("PushLeafHash")
+	return err
+}
+
+func (n *NamespacedMerkleTree) pushLeafHash(leafHash []byte) error {
+	nidSize := n.NamespaceSize()
+	if want := len(n.treeHasher.EmptyRoot()); len(leafHash) != want {
+		return fmt.Errorf("%w: got size %d, want %d", ErrInvalidLeafHash, len(leafHash), want)
+	}
+	nID := namespace.ID(MinNamespace(leafHash, nidSize))
+	if !nID.Equal(MaxNamespace(leafHash, nidSize)) {
+		return fmt.Errorf("%w: min namespace %x differs from max namespace %x", ErrInvalidLeafHash, nID, MaxNamespace(leafHash, nidSize))
+	}
+	if err := n.validatePushedNamespace(nID); err != nil {
+		return err
+	}
+	if n.frontier != nil {
+		return n.pushFrontier(bytes.Clone(leafHash), nID)
+	}
+	if err := n.hashPendingLeaves(); err != nil {
+		return err
+	}
+
+	// the leaf hash is copied into the leaf arena so that the caller may reuse
+	// it
+	n.addLeaf(nil, append(n.nextLeafHash(cap(n.leafHashes)-n.Size()), leafHash...), nID)
+	return nil
+}
@@ -0,0 +1,70 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestNewFromLeafHashes(t *testing.T) {
+	full := exampleNMT(1, true, 0, 0, 1, 3, 3, 3, 255)
+	wantRoot, err := full.Root()
+	require.NoError(t, err)
+
+	pruned, err := NewFromLeafHashes(sha256.New(), full.leafHashes, NamespaceIDSize(1), IgnoreMaxNamespace(true))
+	require.NoError(t, err)
+	assert.Equal(t, full.Size(), pruned.Size())
+	root, err := pruned.Root()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoot, root)
+
+	for _, nID := range []byte{0, 1, 2, 3, 255} {
+		want, err := full.ProveNamespace(namespace.ID{nID})
+		require.NoError(t, err)
+		got, err := pruned.ProveNamespace(namespace.ID{nID})
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "namespace %d", nID)
+	}
+	want, err := full.ProveRange(1, 5)
+	require.NoError(t, err)
+	got, err := pruned.ProveRange(1, 5)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// raw data is unknown for leaves added by hash
+	leaf, err := pruned.Leaf(0)
+	require.NoError(t, err)
+	assert.Nil(t, leaf)
+
+	// raw leaves and leaf hashes can be mixed
+	mixed := exampleNMT(1, true, 0, 0, 1)
+	for _, leafHash := range full.leafHashes[3:] {
+		require.NoError(t, mixed.PushLeafHash(leafHash))
+	}
+	root, err = mixed.Root()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoot, root)
+}
+
+func TestPushLeafHash_Errors(t *testing.T) {
+	full := exampleNMT(1, true, 0, 2)
+	tree := exampleNMT(1, true)
+
+	err := tree.PushLeafHash(full.leafHashes[0][1:])
+	assert.ErrorIs(t, err, ErrInvalidLeafHash)
+
+	root, err := full.Root()
+	require.NoError(t, err)
+	assert.ErrorIs(t, tree.PushLeafHash(root), ErrInvalidLeafHash)
+
+	require.NoError(t, tree.PushLeafHash(full.leafHashes[1]))
+	assert.ErrorIs(t, tree.PushLeafHash(full.leafHashes[0]), ErrInvalidPushOrder)
+	assert.ErrorIs(t, tree.Push(full.leaves[0]), ErrInvalidPushOrder)
+
+	_, err = NewFromLeafHashes(sha256.New(), [][]byte{full.leafHashes[1], full.leafHashes[0]}, NamespaceIDSize(1))
+	assert.ErrorIs(t, err, ErrInvalidPushOrder)
+}
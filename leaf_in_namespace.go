@@ -0,0 +1,149 @@
+package nmt
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// LeafInNamespaceProof proves that a leaf is the offset-th leaf of its
+// namespace. It consists of a MultiProof over the proven leaf and the leaves
+// bounding the namespace on the left, i.e., the first leaf of the namespace
+// and the leaf preceding it (if any). Since leaves are ordered by namespace,
+// these suffice to show that exactly offset leaves of the namespace precede
+// the proven leaf.
+type LeafInNamespaceProof struct {
+	// multiProof covers the boundary leaves and the proven leaf.
+	multiProof MultiProof
+	// boundaryLeafHashes hold the leaf hashes of the leaves at index
+	// namespaceStart-1 (if namespaceStart > 0) and namespaceStart (if the
+	// proven leaf is not the first leaf of the namespace), in that order.
+	boundaryLeafHashes [][]byte
+	// namespaceStart is the index of the first leaf of the namespace.
+	namespaceStart int
+}
+
+// NamespaceStart returns the index of the first leaf of the namespace.
+func (p LeafInNamespaceProof) NamespaceStart() int {
+	return p.namespaceStart
+}
+
+// Index returns the index of the proven leaf in the tree.
+func (p LeafInNamespaceProof) Index() int {
+	indices := p.multiProof.Indices()
+	if len(indices) == 0 {
+		return -1
+	}
+	return indices[len(indices)-1]
+}
+
+// ProveLeafInNamespace returns a proof for the offset-th leaf (starting from
+// 0) of the namespace nID. It returns an ErrNamespaceNotFound error if the
+// tree has no leaves of that namespace, an ErrInvalidRange error if offset
+// exceeds the number of leaves of the namespace and an
+// ErrMismatchedNamespaceSize error if the size of nID does not match the tree's
+// namespace size.
+func (n *NamespacedMerkleTree) ProveLeafInNamespace(nID namespace.ID, offset int) (LeafInNamespaceProof, error) {
+	if err := n.validateNamespaceSize(nID); err != nil {
+		return LeafInNamespaceProof{}, err
+	}
+	if err := n.hashPendingLeaves(); err != nil {
+		return LeafInNamespaceProof{}, err
+	}
+	found, start, end := n.foundInRange(nID)
+	if !found {
+		return LeafInNamespaceProof{}, fmt.Errorf("namespace %x: %w", nID, ErrNamespaceNotFound)
+	}
+	if offset < 0 || start+offset >= end {
+		return LeafInNamespaceProof{}, fmt.Errorf("offset %d is out of the namespace range [0, %d): %w", offset, end-start, ErrInvalidRange)
+	}
+
+	var indices []int
+	var boundaryLeafHashes [][]byte
+	if start > 0 {
+		indices = append(indices, start-1)
+		boundaryLeafHashes = append(boundaryLeafHashes, n.leafHashes[start-1])
+	}
+	if offset > 0 {
+		indices = append(indices, start)
+		boundaryLeafHashes = append(boundaryLeafHashes, n.leafHashes[start])
+	}
+	indices = append(indices, start+offset)
+
+	multiProof, err := n.ProveMulti(indices)
+	if err != nil {
+		return LeafInNamespaceProof{}, err
+	}
+	return LeafInNamespaceProof{
+		multiProof:         multiProof,
+		boundaryLeafHashes: boundaryLeafHashes,
+		namespaceStart:     start,
+	}, nil
+}
+
+// VerifyLeafInNamespace verifies that leaf, a namespace-prefixed leaf of
+// namespace nID, is the offset-th leaf of that namespace in the tree with the
+// given root. `h` MUST be the same as the underlying hash function used to
+// generate the proof.
+func (p LeafInNamespaceProof) VerifyLeafInNamespace(h hash.Hash, nID namespace.ID, offset int, leaf []byte, root []byte) bool {
+	nIDSize := nID.Size()
+	nth := NewNmtHasher(h, nIDSize, p.multiProof.IsMaxNamespaceIDIgnored())
+	if offset < 0 || p.namespaceStart < 0 || p.Index() != p.namespaceStart+offset {
+		return false
+	}
+	if nth.validateNamespaceID(nID) != nil || len(leaf) < int(nIDSize) || !nID.Equal(leaf[:nIDSize]) {
+		return false
+	}
+	// the boundary leaf hashes are inspected before the multiproof validates
+	// them
+	if validateLeafHashes(nth, p.boundaryLeafHashes) != nil {
+		return false
+	}
+
+	// reconstruct the expected indices and check the boundary leaves
+	var wantIndices []int
+	boundaryLeafHashes := p.boundaryLeafHashes
+	if p.namespaceStart > 0 {
+		wantIndices = append(wantIndices, p.namespaceStart-1)
+		if len(boundaryLeafHashes) == 0 {
+			return false
+		}
+		// the leaf preceding the namespace must have a smaller namespace
+		if !namespace.ID(MaxNamespace(boundaryLeafHashes[0], nIDSize)).Less(nID) {
+			return false
+		}
+		boundaryLeafHashes = boundaryLeafHashes[1:]
+	}
+	if offset > 0 {
+		wantIndices = append(wantIndices, p.namespaceStart)
+		if len(boundaryLeafHashes) == 0 {
+			return false
+		}
+		// the first leaf of the namespace must belong to it
+		if !nID.Equal(MinNamespace(boundaryLeafHashes[0], nIDSize)) {
+			return false
+		}
+		boundaryLeafHashes = boundaryLeafHashes[1:]
+	}
+	wantIndices = append(wantIndices, p.namespaceStart+offset)
+	if len(boundaryLeafHashes) != 0 || len(wantIndices) != len(p.multiProof.Indices()) {
+		return false
+	}
+	for i, index := range wantIndices {
+		if p.multiProof.Indices()[i] != index {
+			return false
+		}
+	}
+
+	leafHash, err := nth.HashLeaf(leaf)
+	if err != nil {
+		return false
+	}
+	leafHashes := append(append(make([][]byte, 0, len(wantIndices)), p.boundaryLeafHashes...), leafHash)
+	res, err := p.multiProof.VerifyLeafHashes(nth, leafHashes, root)
+	if err != nil {
+		return false
+	}
+	return res
+}
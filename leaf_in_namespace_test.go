@@ -0,0 +1,55 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestProveLeafInNamespace(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 1, 1, 2, 3, 3)
+	root, err := tree.Root()
+	require.NoError(t, err)
+
+	tests := []struct {
+		nID    namespace.ID
+		offset int
+	}{
+		{namespace.ID{0}, 0},
+		{namespace.ID{1}, 0},
+		{namespace.ID{1}, 1},
+		{namespace.ID{1}, 2},
+		{namespace.ID{2}, 0},
+		{namespace.ID{3}, 1},
+	}
+	for _, tt := range tests {
+		proof, err := tree.ProveLeafInNamespace(tt.nID, tt.offset)
+		require.NoError(t, err)
+		leaf := tree.Get(tt.nID)[tt.offset]
+		assert.Equal(t, proof.NamespaceStart()+tt.offset, proof.Index())
+		assert.True(t, proof.VerifyLeafInNamespace(sha256.New(), tt.nID, tt.offset, leaf, root))
+
+		// claiming a different offset or namespace must fail
+		assert.False(t, proof.VerifyLeafInNamespace(sha256.New(), tt.nID, tt.offset+1, leaf, root))
+		assert.False(t, proof.VerifyLeafInNamespace(sha256.New(), namespace.ID{tt.nID[0] + 1}, tt.offset, leaf, root))
+	}
+
+	// a proof for a leaf of another namespace must not verify
+	proof, err := tree.ProveLeafInNamespace(namespace.ID{1}, 2)
+	require.NoError(t, err)
+	assert.False(t, proof.VerifyLeafInNamespace(sha256.New(), namespace.ID{1}, 2, tree.leaves[4], root))
+}
+
+func TestProveLeafInNamespace_Errors(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 1, 3)
+	_, err := tree.ProveLeafInNamespace(namespace.ID{2}, 0)
+	assert.ErrorIs(t, err, ErrNamespaceNotFound)
+	_, err = tree.ProveLeafInNamespace(namespace.ID{1}, 2)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+	_, err = tree.ProveLeafInNamespace(namespace.ID{1}, -1)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+}
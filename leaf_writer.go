@@ -0,0 +1,106 @@
+package nmt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// ErrInvalidLeafStream indicates that data written to a LeafWriter is not a
+// valid sequence of length-prefixed namespaced leaves.
+var ErrInvalidLeafStream = errors.New("invalid leaf stream")
+
+// LeafWriter computes the root of a namespaced Merkle tree from a byte
+// stream of leaves written through its io.Writer interface. Each leaf is written as its length encoded as an unsigned
+// varint followed by the namespace-prefixed leaf data. Leaves may be split
+// across Write calls arbitrarily. Only complete leaves are kept in memory
+// until they are pushed to an underlying RootComputer, so the tree itself is
+// never held in memory.
+type LeafWriter struct {
+	computer *RootComputer
+	// pending holds the bytes of the leaf currently being written, including
+	// its length prefix.
+	pending []byte
+	// err is the first error encountered; once set, all further writes fail.
+	err error
+}
+
+var _ io.Writer = (*LeafWriter)(nil)
+
+// NewLeafWriter returns a LeafWriter for the given base hash function. It
+// accepts the same options as NewRootComputer.
+func NewLeafWriter(h hash.Hash, setters ...Option) *LeafWriter {
+	return &LeafWriter{computer: NewRootComputer(h, setters...)}
+}
+
+// Write consumes p as part of the stream of length-prefixed leaves. It returns
+// an error wrapping ErrInvalidLeafStream if a length prefix is malformed or
+// exceeds MaxStreamLeafSize, and any error of RootComputer.Push for invalid
+// leaves. Errors are sticky: after an error, every Write fails until Reset.
+func (w *LeafWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.pending = append(w.pending, p...)
+	// consumed is the number of bytes of the complete leaves in pending
+	consumed := 0
+	for {
+		rest := w.pending[consumed:]
+		size, n := binary.Uvarint(rest)
+		if n == 0 {
+			// the length prefix is incomplete
+			break
+		}
+		if n < 0 || size > MaxStreamLeafSize {
+			w.err = fmt.Errorf("%w: invalid leaf length", ErrInvalidLeafStream)
+			return 0, w.err
+		}
+		if uint64(len(rest)-n) < size {
+			// the leaf is incomplete
+			break
+		}
+		leaf := rest[n : n+int(size)]
+		if err := w.computer.Push(leaf); err != nil {
+			w.err = fmt.Errorf("leaf %d: %w", w.computer.Size(), err)
+			return 0, w.err
+		}
+		consumed += n + int(size)
+	}
+	if consumed > 0 {
+		// move the incomplete leaf to the beginning of the buffer so that it
+		// does not grow indefinitely; the buffer is only appended to while a
+		// leaf is incomplete
+		w.pending = append(w.pending[:0], w.pending[consumed:]...)
+	}
+	return len(p), nil
+}
+
+// Root returns the root of the tree consisting of all complete leaves written
+// so far. It does not change the underlying state, and an incomplete trailing
+// leaf is not included. Root returns the error of a previous failed Write, if
+// any.
+func (w *LeafWriter) Root() ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	return w.computer.Root()
+}
+
+// Reset discards all written data and any previous error.
+func (w *LeafWriter) Reset() {
+	w.computer.Reset()
+	w.pending = w.pending[:0]
+	w.err = nil
+}
+
+// Leaves returns the number of complete leaves written so far.
+func (w *LeafWriter) Leaves() int {
+	return w.computer.Size()
+}
+
+// Buffered returns the number of bytes of an incomplete leaf buffered by w.
+func (w *LeafWriter) Buffered() int {
+	return len(w.pending)
+}
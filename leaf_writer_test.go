@@ -0,0 +1,113 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lengthPrefixed(leaves ...[]byte) []byte {
+	var stream []byte
+	for _, leaf := range leaves {
+		stream = binary.AppendUvarint(stream, uint64(len(leaf)))
+		stream = append(stream, leaf...)
+	}
+	return stream
+}
+
+func TestLeafWriter(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 2, 3, 5, 8)
+	want, err := tree.Root()
+	require.NoError(t, err)
+	stream := lengthPrefixed(tree.leaves...)
+
+	// write the stream in chunks of all possible sizes
+	for chunk := 1; chunk <= len(stream); chunk++ {
+		w := NewLeafWriter(sha256.New(), NamespaceIDSize(1))
+		for i := 0; i < len(stream); i += chunk {
+			n, err := w.Write(stream[i:minInt(i+chunk, len(stream))])
+			require.NoError(t, err)
+			assert.Equal(t, minInt(chunk, len(stream)-i), n)
+		}
+		root, err := w.Root()
+		require.NoError(t, err)
+		assert.Equal(t, want, root, "chunk size %d", chunk)
+		assert.Equal(t, tree.Size(), w.Leaves())
+		assert.Zero(t, w.Buffered())
+	}
+}
+
+func TestLeafWriter_ChunkedLeafIsNotCopied(t *testing.T) {
+	leaf := make([]byte, 1<<20)
+	stream := lengthPrefixed(leaf, leaf)
+	w := NewLeafWriter(sha256.New(), NamespaceIDSize(1))
+	// the buffered leaf is only copied once it is complete, so the buffer
+	// is reused after the first run
+	allocs := testing.AllocsPerRun(1, func() {
+		w.Reset()
+		for i := 0; i < len(stream); i += 1 << 10 {
+			_, err := w.Write(stream[i:minInt(i+1<<10, len(stream))])
+			require.NoError(t, err)
+		}
+	})
+	assert.Less(t, allocs, float64(100))
+	assert.Equal(t, 2, w.Leaves())
+}
+
+func TestLeafWriter_Root(t *testing.T) {
+	w := NewLeafWriter(sha256.New(), NamespaceIDSize(1))
+	root, err := w.Root()
+	require.NoError(t, err)
+	assert.Equal(t, w.computer.treeHasher.EmptyRoot(), root)
+
+	_, err = io.WriteString(w, string(lengthPrefixed([]byte{1, 'a'})))
+	require.NoError(t, err)
+	// Root leaves the state unchanged
+	root, err = w.Root()
+	require.NoError(t, err)
+	again, err := w.Root()
+	require.NoError(t, err)
+	assert.Equal(t, root, again)
+
+	// an incomplete leaf is not part of the root
+	_, err = w.Write(lengthPrefixed([]byte{2, 'b'})[:2])
+	require.NoError(t, err)
+	again, err = w.Root()
+	require.NoError(t, err)
+	assert.Equal(t, root, again)
+	assert.Equal(t, 2, w.Buffered())
+
+	w.Reset()
+	assert.Zero(t, w.Leaves())
+	assert.Zero(t, w.Buffered())
+	root, err = w.Root()
+	require.NoError(t, err)
+	assert.Equal(t, w.computer.treeHasher.EmptyRoot(), root)
+}
+
+func TestLeafWriter_Errors(t *testing.T) {
+	w := NewLeafWriter(sha256.New(), NamespaceIDSize(1))
+	_, err := w.Write(lengthPrefixed([]byte{2, 'a'}, []byte{1, 'b'}))
+	assert.ErrorIs(t, err, ErrInvalidPushOrder)
+	// errors are sticky
+	_, err = w.Write(lengthPrefixed([]byte{3, 'c'}))
+	assert.ErrorIs(t, err, ErrInvalidPushOrder)
+	_, err = w.Root()
+	assert.ErrorIs(t, err, ErrInvalidPushOrder)
+
+	w.Reset()
+	_, err = w.Write(lengthPrefixed([]byte{}))
+	assert.ErrorIs(t, err, ErrInvalidLeafLen)
+
+	w.Reset()
+	_, err = w.Write(binary.AppendUvarint(nil, MaxStreamLeafSize+1))
+	assert.ErrorIs(t, err, ErrInvalidLeafStream)
+
+	w.Reset()
+	_, err = w.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+	assert.ErrorIs(t, err, ErrInvalidLeafStream)
+}
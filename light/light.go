@@ -0,0 +1,140 @@
+// Package light contains helpers for light clients that verify namespaced data
+// against a trusted NMT root without holding the tree itself. A Verifier
+// bundles all safety checks (namespace size, hashing mode, completeness and
+// absence semantics) behind a small API.
+package light
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"sync"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+var (
+	// ErrNoTrustedRoot indicates that no trusted root has been set yet.
+	ErrNoTrustedRoot = errors.New("no trusted root")
+	// ErrVerificationFailed indicates that a proof does not verify against the
+	// trusted root.
+	ErrVerificationFailed = errors.New("verification failed")
+	// ErrIgnoreMaxNamespaceMismatch indicates that a proof has been generated
+	// with a different IgnoreMaxNamespace setting than the verifier expects.
+	ErrIgnoreMaxNamespaceMismatch = errors.New("proof IgnoreMaxNamespace setting does not match the verifier")
+)
+
+// Verifier verifies namespaced data against a trusted root. It is safe for
+// concurrent use.
+type Verifier struct {
+	hashes             *nmt.HashPool
+	nIDSize            namespace.IDSize
+	ignoreMaxNamespace bool
+
+	mtx               sync.RWMutex
+	trustedRoot       []byte
+	namespaceVersions namespace.VersionRules
+}
+
+// NewVerifier creates a Verifier for trees built with the hash function
+// returned by newHash, namespace IDs of nIDSize bytes and the given
+// IgnoreMaxNamespace setting. A trusted root must be set using SetTrustedRoot
+// before any data can be verified.
+func NewVerifier(newHash func() hash.Hash, nIDSize namespace.IDSize, ignoreMaxNamespace bool) *Verifier {
+	return &Verifier{
+		hashes:             nmt.NewHashPool(newHash),
+		nIDSize:            nIDSize,
+		ignoreMaxNamespace: ignoreMaxNamespace,
+	}
+}
+
+// SetTrustedRoot replaces the trusted root. It returns an error if root does
+// not conform to the namespaced hash format of the verifier.
+func (v *Verifier) SetTrustedRoot(root []byte) error {
+	h := v.hashes.Get()
+	defer v.hashes.Put(h)
+	nth := nmt.NewNmtHasher(h, v.nIDSize, v.ignoreMaxNamespace)
+	if err := nth.ValidateNodeFormat(root); err != nil {
+		return fmt.Errorf("invalid trusted root: %w", err)
+	}
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	v.trustedRoot = append([]byte(nil), root...)
+	return nil
+}
+
+// TrustedRoot returns a copy of the current trusted root, or nil if none has
+// been set.
+func (v *Verifier) TrustedRoot() []byte {
+	v.mtx.RLock()
+	defer v.mtx.RUnlock()
+	return bytes.Clone(v.trustedRoot)
+}
+
+// SetNamespaceVersions makes the verifier reject queries for namespace IDs
+// that violate the given version rules, see namespace.VersionRules.Validate.
+// Passing nil disables the check.
+func (v *Verifier) SetNamespaceVersions(rules namespace.VersionRules) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	v.namespaceVersions = rules
+}
+
+// GetVerified verifies that data are all the namespace-prefixed leaves of
+// namespace nID in the tree with the trusted root, and returns them. If the
+// proof shows that the namespace is absent from the tree, GetVerified returns
+// an empty result and no error; data must be empty in that case. Any failed
+// check results in an error and no data.
+func (v *Verifier) GetVerified(nID namespace.ID, proof nmt.Proof, data [][]byte) ([][]byte, error) {
+	root, err := v.check(nID, proof)
+	if err != nil {
+		return nil, err
+	}
+	if (proof.IsOfAbsence() || proof.IsEmptyProof()) && len(data) != 0 {
+		return nil, fmt.Errorf("%w: proof of absence supplied with %d leaves", ErrVerificationFailed, len(data))
+	}
+	h := v.hashes.Get()
+	defer v.hashes.Put(h)
+	if !proof.VerifyNamespace(h, nID, data, root) {
+		return nil, fmt.Errorf("%w: namespace %x", ErrVerificationFailed, nID)
+	}
+	if len(data) == 0 {
+		return [][]byte{}, nil
+	}
+	return data, nil
+}
+
+// VerifyAbsence verifies that the tree with the trusted root does not contain
+// any leaf of namespace nID.
+func (v *Verifier) VerifyAbsence(nID namespace.ID, proof nmt.Proof) error {
+	if !proof.IsOfAbsence() && !proof.IsEmptyProof() {
+		return fmt.Errorf("%w: not a proof of absence", ErrVerificationFailed)
+	}
+	_, err := v.GetVerified(nID, proof, nil)
+	return err
+}
+
+// check performs the checks common to all verifications and returns the
+// trusted root.
+func (v *Verifier) check(nID namespace.ID, proof nmt.Proof) ([]byte, error) {
+	v.mtx.RLock()
+	root, versions := v.trustedRoot, v.namespaceVersions
+	v.mtx.RUnlock()
+	if root == nil {
+		return nil, ErrNoTrustedRoot
+	}
+	if nID.Size() != v.nIDSize {
+		return nil, fmt.Errorf("namespace ID size %d, expected %d: %w", nID.Size(), v.nIDSize, nmt.ErrMismatchedNamespaceSize)
+	}
+	if versions != nil {
+		if err := versions.Validate(nID); err != nil {
+			return nil, err
+		}
+	}
+	if proof.IsMaxNamespaceIDIgnored() != v.ignoreMaxNamespace {
+		return nil, ErrIgnoreMaxNamespaceMismatch
+	}
+	return root, nil
+}
@@ -0,0 +1,120 @@
+package light
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func exampleTree(t *testing.T, ignoreMaxNamespace bool) (*nmt.NamespacedMerkleTree, []byte) {
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(1), nmt.IgnoreMaxNamespace(ignoreMaxNamespace))
+	for _, nID := range []byte{1, 1, 3, 5} {
+		require.NoError(t, tree.Push([]byte{nID, 'd', nID}))
+	}
+	root, err := tree.Root()
+	require.NoError(t, err)
+	return tree, root
+}
+
+func TestVerifier_GetVerified(t *testing.T) {
+	tree, root := exampleTree(t, true)
+	v := NewVerifier(sha256.New, 1, true)
+
+	proof, err := tree.ProveNamespace(namespace.ID{1})
+	require.NoError(t, err)
+	_, err = v.GetVerified(namespace.ID{1}, proof, tree.Get(namespace.ID{1}))
+	assert.ErrorIs(t, err, ErrNoTrustedRoot)
+
+	require.NoError(t, v.SetTrustedRoot(root))
+	assert.Equal(t, root, v.TrustedRoot())
+	// modifying the returned root does not affect the verifier
+	v.TrustedRoot()[0] ^= 1
+	assert.Equal(t, root, v.TrustedRoot())
+
+	got, err := v.GetVerified(namespace.ID{1}, proof, tree.Get(namespace.ID{1}))
+	require.NoError(t, err)
+	assert.Equal(t, tree.Get(namespace.ID{1}), got)
+
+	// incomplete data
+	_, err = v.GetVerified(namespace.ID{1}, proof, tree.Get(namespace.ID{1})[:1])
+	assert.ErrorIs(t, err, ErrVerificationFailed)
+	// wrong namespace size
+	_, err = v.GetVerified(namespace.ID{1, 0}, proof, tree.Get(namespace.ID{1}))
+	assert.ErrorIs(t, err, nmt.ErrMismatchedNamespaceSize)
+}
+
+func TestVerifier_Absence(t *testing.T) {
+	tree, root := exampleTree(t, true)
+	v := NewVerifier(sha256.New, 1, true)
+	require.NoError(t, v.SetTrustedRoot(root))
+
+	for _, nID := range []namespace.ID{{0}, {2}, {4}, {9}} {
+		proof, err := tree.ProveNamespace(nID)
+		require.NoError(t, err)
+		got, err := v.GetVerified(nID, proof, nil)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+		assert.NoError(t, v.VerifyAbsence(nID, proof))
+
+		// claiming data with a proof of absence must fail
+		_, err = v.GetVerified(nID, proof, tree.Get(namespace.ID{1}))
+		assert.ErrorIs(t, err, ErrVerificationFailed)
+	}
+
+	// a proof of presence is not a proof of absence
+	proof, err := tree.ProveNamespace(namespace.ID{3})
+	require.NoError(t, err)
+	assert.ErrorIs(t, v.VerifyAbsence(namespace.ID{3}, proof), ErrVerificationFailed)
+	// an empty proof for a namespace within the root's range is rejected
+	assert.ErrorIs(t, v.VerifyAbsence(namespace.ID{3}, nmt.NewEmptyRangeProof(true)), ErrVerificationFailed)
+}
+
+func TestVerifier_IgnoreMaxNamespaceMismatch(t *testing.T) {
+	tree, root := exampleTree(t, false)
+	v := NewVerifier(sha256.New, 1, true)
+	require.NoError(t, v.SetTrustedRoot(root))
+
+	proof, err := tree.ProveNamespace(namespace.ID{3})
+	require.NoError(t, err)
+	_, err = v.GetVerified(namespace.ID{3}, proof, tree.Get(namespace.ID{3}))
+	assert.ErrorIs(t, err, ErrIgnoreMaxNamespaceMismatch)
+}
+
+func TestVerifier_SetTrustedRoot_Invalid(t *testing.T) {
+	v := NewVerifier(sha256.New, 1, true)
+	assert.ErrorIs(t, v.SetTrustedRoot([]byte{1, 2}), nmt.ErrInvalidNodeLen)
+	assert.Nil(t, v.TrustedRoot())
+}
+
+func TestVerifier_NamespaceVersions(t *testing.T) {
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(3))
+	for _, nID := range []namespace.ID{{0, 0, 1}, {0, 0, 2}, {1, 2, 3}} {
+		require.NoError(t, tree.Push(namespace.PrefixedData(append(nID, 'd'))))
+	}
+	root, err := tree.Root()
+	require.NoError(t, err)
+	v := NewVerifier(sha256.New, 3, true)
+	require.NoError(t, v.SetTrustedRoot(root))
+	v.SetNamespaceVersions(namespace.VersionRules{0: namespace.RequireLeadingZeros(1)})
+
+	nID := namespace.ID{0, 0, 2}
+	proof, err := tree.ProveNamespace(nID)
+	require.NoError(t, err)
+	_, err = v.GetVerified(nID, proof, tree.Get(nID))
+	require.NoError(t, err)
+
+	nID = namespace.ID{1, 2, 3}
+	proof, err = tree.ProveNamespace(nID)
+	require.NoError(t, err)
+	_, err = v.GetVerified(nID, proof, tree.Get(nID))
+	assert.ErrorIs(t, err, namespace.ErrUnsupportedVersion)
+
+	v.SetNamespaceVersions(nil)
+	_, err = v.GetVerified(nID, proof, tree.Get(nID))
+	assert.NoError(t, err)
+}
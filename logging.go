@@ -0,0 +1,95 @@
+package nmt
+
+import (
+	"context"
+	"hash"
+	"log/slog"
+	"time"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// Logger makes the tree log significant events to l: rejected pushes at level
+// Warn, and computed roots and generated proofs at level Debug, or Warn if
+// they failed. Namespace IDs and roots are logged abbreviated. Logging is
+// disabled by default. The logger is shared with snapshots of the tree.
+func Logger(l *slog.Logger) Option {
+	return func(opts *Options) {
+		opts.Logger = l
+	}
+}
+
+// logPush logs a rejected push.
+func (n *NamespacedMerkleTree) logPush(op string, err error) {
+	if n.logger == nil || err == nil {
+		return
+	}
+	n.logger.LogAttrs(context.Background(), slog.LevelWarn, "nmt: push rejected",
+		slog.String("op", op),
+		slog.Int("size", n.Size()),
+		slog.String("error", err.Error()),
+	)
+}
+
+// logRoot logs the computation of a fresh root.
+func (n *NamespacedMerkleTree) logRoot(d time.Duration, root []byte, err error) {
+	if n.logger == nil {
+		return
+	}
+	if err != nil {
+		n.logger.LogAttrs(context.Background(), slog.LevelWarn, "nmt: root computation failed",
+			slog.Int("size", n.Size()),
+			slog.Duration("duration", d),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	n.logger.LogAttrs(context.Background(), slog.LevelDebug, "nmt: root computed",
+		slog.Int("size", n.Size()),
+		slog.Duration("duration", d),
+		slog.String("root", namespace.ID(root).Abbrev()),
+	)
+}
+
+// logProof logs the generation of a proof by the method op.
+func (n *NamespacedMerkleTree) logProof(op string, d time.Duration, proof Proof, err error) {
+	if n.logger == nil {
+		return
+	}
+	if err != nil {
+		n.logger.LogAttrs(context.Background(), slog.LevelWarn, "nmt: proof generation failed",
+			slog.String("op", op),
+			slog.Int("size", n.Size()),
+			slog.Duration("duration", d),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	n.logger.LogAttrs(context.Background(), slog.LevelDebug, "nmt: proof generated",
+		slog.String("op", op),
+		slog.Int("size", n.Size()),
+		slog.Duration("duration", d),
+		slog.String("proof", proof.String()),
+	)
+}
+
+// VerifyNamespaceLogged is like VerifyNamespace but logs the reason of a
+// failed verification to l at level Warn, together with the proof, the
+// namespace ID and the root. Successful verifications are logged at level
+// Debug.
+func (proof Proof) VerifyNamespaceLogged(l *slog.Logger, h hash.Hash, nID namespace.ID, leaves [][]byte, root []byte) bool {
+	err := proof.checkNamespaceRange(h, nID, nID, leaves, root)
+	attrs := []slog.Attr{
+		slog.String("proof", proof.String()),
+		slog.String("namespace", nID.Abbrev()),
+		slog.Int("leaves", len(leaves)),
+		slog.String("root", namespace.ID(root).Abbrev()),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("reason", err.Error()))
+		l.LogAttrs(context.Background(), slog.LevelWarn, "nmt: verification failed", attrs...)
+		return false
+	}
+	l.LogAttrs(context.Background(), slog.LevelDebug, "nmt: verification succeeded", attrs...)
+	return true
+}
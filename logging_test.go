@@ -0,0 +1,106 @@
+package nmt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// logRecords decodes the JSON log records written to buf.
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tree := New(sha256.New(), NamespaceIDSize(1), Logger(logger))
+
+	require.NoError(t, tree.Push([]byte{2, 'a'}))
+	assert.Empty(t, buf.String())
+	require.Error(t, tree.Push([]byte{1, 'b'}))
+	root, err := tree.Root()
+	require.NoError(t, err)
+	_, err = tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	_, err = tree.ProveRange(0, 2)
+	require.Error(t, err)
+
+	records := logRecords(t, &buf)
+	require.Len(t, records, 4)
+	assert.Equal(t, "nmt: push rejected", records[0]["msg"])
+	assert.Equal(t, "WARN", records[0]["level"])
+	assert.Equal(t, "Push", records[0]["op"])
+	assert.Contains(t, records[0]["error"], ErrInvalidPushOrder.Error())
+	assert.Equal(t, "nmt: root computed", records[1]["msg"])
+	assert.Equal(t, "DEBUG", records[1]["level"])
+	assert.Equal(t, namespace.ID(root).Abbrev(), records[1]["root"])
+	assert.Equal(t, "nmt: proof generated", records[2]["msg"])
+	assert.Equal(t, "Proof{range: [0, 1), nodes: 0}", records[2]["proof"])
+	assert.Equal(t, "nmt: proof generation failed", records[3]["msg"])
+	assert.Equal(t, "ProveRange", records[3]["op"])
+
+	// the root is only logged when it is computed
+	buf.Reset()
+	_, err = tree.Root()
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+}
+
+func TestVerifyNamespaceLogged(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 2, 4)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	nID := namespace.ID{2}
+	proof, err := tree.ProveNamespace(nID)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	leaves := tree.Get(nID)
+	assert.True(t, proof.VerifyNamespaceLogged(logger, sha256.New(), nID, leaves, root))
+	// successful verifications are logged at level Debug
+	assert.Empty(t, buf.String())
+
+	tests := []struct {
+		name   string
+		leaves [][]byte
+		root   []byte
+		reason string
+	}{
+		{"missing leaf", leaves[:1], root, ErrWrongLeafHashesSize.Error()},
+		{"foreign leaf", [][]byte{leaves[0], {3, 'x'}}, root, "leaf 1 has namespace 03 outside the namespace range [02, 02]"},
+		{"other root", leaves, tree.leafHashes[0], "computed root does not match"},
+		{"malformed root", leaves, root[:3], ErrInvalidNodeLen.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			assert.False(t, proof.VerifyNamespaceLogged(logger, sha256.New(), nID, tt.leaves, tt.root))
+			assert.False(t, proof.VerifyNamespace(sha256.New(), nID, tt.leaves, tt.root))
+			records := logRecords(t, &buf)
+			require.Len(t, records, 1)
+			assert.Equal(t, "nmt: verification failed", records[0]["msg"])
+			assert.Equal(t, "02", records[0]["namespace"])
+			assert.Contains(t, records[0]["reason"], tt.reason)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package nmt
+
+import "time"
+
+// Metrics is the instrumentation interface of a NamespacedMerkleTree. It can be
+// implemented on top of a metrics library such as Prometheus or OpenTelemetry
+// and registered via the CustomMetrics option.
+//
+// All methods are invoked synchronously from the goroutine operating on the
+// tree and should therefore return quickly.
+type Metrics interface {
+	// Pushed is invoked once per Push with its outcome; err is nil if the
+	// leaf was added to the tree.
+	Pushed(err error)
+	// LeafHashed is invoked every time a leaf hash is computed.
+	LeafHashed()
+	// NodeHashed is invoked every time an inner node hash is computed,
+	// either during Root() or during proof generation.
+	NodeHashed()
+	// RootComputed is invoked every time Root() computes a fresh root, i.e.,
+	// cache hits are not reported.
+	RootComputed(d time.Duration, err error)
+	// ProofGenerated is invoked after each call to ProveRange (and hence
+	// Prove) and ProveNamespace with the size of the generated proof in
+	// number of nodes.
+	ProofGenerated(d time.Duration, proofNodes int, err error)
+}
+
+var _ Metrics = NoopMetrics{}
+
+// NoopMetrics implements Metrics by discarding all events. It can be embedded
+// by implementations that are only interested in a subset of the events.
+type NoopMetrics struct{}
+
+func (NoopMetrics) Pushed(error)                             {}
+func (NoopMetrics) LeafHashed()                              {}
+func (NoopMetrics) NodeHashed()                              {}
+func (NoopMetrics) RootComputed(time.Duration, error)        {}
+func (NoopMetrics) ProofGenerated(time.Duration, int, error) {}
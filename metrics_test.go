@@ -0,0 +1,66 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// countingMetrics is a Metrics implementation that counts the reported events.
+type countingMetrics struct {
+	NoopMetrics
+	pushes, rejectedPushes int
+	leafHashes, nodeHashes int
+	roots                  int
+	proofs, proofNodes     int
+}
+
+func (m *countingMetrics) Pushed(err error) {
+	if err != nil {
+		m.rejectedPushes++
+		return
+	}
+	m.pushes++
+}
+
+func (m *countingMetrics) LeafHashed() { m.leafHashes++ }
+
+func (m *countingMetrics) NodeHashed() { m.nodeHashes++ }
+
+func (m *countingMetrics) RootComputed(time.Duration, error) { m.roots++ }
+
+func (m *countingMetrics) ProofGenerated(_ time.Duration, proofNodes int, _ error) {
+	m.proofs++
+	m.proofNodes += proofNodes
+}
+
+func TestCustomMetrics(t *testing.T) {
+	m := &countingMetrics{}
+	tree := New(sha256.New(), NamespaceIDSize(1), CustomMetrics(m))
+	for i := 0; i < 4; i++ {
+		require.NoError(t, tree.Push([]byte{byte(i + 1), 'd'}))
+	}
+	require.Error(t, tree.Push([]byte{0, 'd'}))
+	assert.Equal(t, 4, m.pushes)
+	assert.Equal(t, 1, m.rejectedPushes)
+	assert.Equal(t, 4, m.leafHashes)
+
+	_, err := tree.Root()
+	require.NoError(t, err)
+	_, err = tree.Root() // cached
+	require.NoError(t, err)
+	assert.Equal(t, 1, m.roots)
+	assert.Equal(t, 3, m.nodeHashes)
+
+	proof, err := tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	_, err = tree.Prove(0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, m.proofs)
+	assert.Equal(t, len(proof.Nodes())+2, m.proofNodes)
+}
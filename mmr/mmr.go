@@ -0,0 +1,258 @@
+// Package mmr implements a namespaced Merkle mountain range: an append-only
+// accumulator for ever-growing namespaced logs. Leaves are appended in
+// ascending namespace order and organized in perfect namespaced Merkle trees
+// ("peaks"). The peaks are bagged from right to left into a single root, which
+// is identical to the root of an NMT over the same leaves.
+//
+// An inclusion proof consists of the path from a leaf to its peak and the
+// peaks of the accumulator. As the accumulator grows, the path of an existing
+// proof stays valid and only needs to be extended, see ExtendProof, or
+// Proof.Update for holders of proofs that only know the AppendRecord.
+package mmr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+var (
+	// ErrInvalidIndex indicates that a leaf index is out of the range of the
+	// accumulator.
+	ErrInvalidIndex = errors.New("leaf index out of range")
+	// ErrInconsistentProof indicates that a proof does not belong to the
+	// accumulator it is extended with.
+	ErrInconsistentProof = errors.New("inconsistent proof")
+)
+
+// Accumulator is a namespaced Merkle mountain range. It is not safe for
+// concurrent use.
+type Accumulator struct {
+	treeHasher nmt.Hasher
+	// levels holds all nodes of the perfect subtrees by height; levels[h][i]
+	// is the root of the subtree covering the leaves [i*2^h, (i+1)*2^h).
+	levels [][][]byte
+	size   int
+	// lastNID is the namespace ID of the last appended leaf.
+	lastNID namespace.ID
+	// opts holds the namespace restrictions of appended leaves.
+	opts nmt.Options
+}
+
+// New returns an empty accumulator using the given hasher. Of the tree
+// options, only RejectMaxNamespace and NamespaceVersions are taken into
+// account, and appended leaves are restricted as they would be when pushed to
+// an NMT with those options; all other properties of the tree are determined
+// by h.
+func New(h nmt.Hasher, setters ...nmt.Option) *Accumulator {
+	a := &Accumulator{treeHasher: h}
+	for _, setter := range setters {
+		setter(&a.opts)
+	}
+	return a
+}
+
+// Size returns the number of leaves in the accumulator.
+func (a *Accumulator) Size() int {
+	return a.size
+}
+
+// Append adds the namespace-prefixed data as the next leaf and returns its
+// index. It returns an nmt.ErrInvalidLeafLen error if the data is shorter than
+// the namespace size, an nmt.ErrInvalidPushOrder error if its namespace ID is
+// smaller than the one of the previous leaf, and the errors of
+// nmt.Options.ValidateNamespace if its namespace ID violates the options of the
+// accumulator.
+func (a *Accumulator) Append(namespacedData namespace.PrefixedData) (int, error) {
+	nidSize := int(a.treeHasher.NamespaceSize())
+	if len(namespacedData) < nidSize {
+		return 0, fmt.Errorf("%w: got: %v, want >= %v", nmt.ErrInvalidLeafLen, len(namespacedData), nidSize)
+	}
+	nID := namespacedData.NamespaceID(a.treeHasher.NamespaceSize())
+	if err := a.opts.ValidateNamespace(nID); err != nil {
+		return 0, err
+	}
+	if a.size > 0 && nID.Less(a.lastNID) {
+		return 0, fmt.Errorf("%w: last namespace: %x, pushed: %x", nmt.ErrInvalidPushOrder, a.lastNID, nID)
+	}
+	node, err := a.treeHasher.HashLeaf(namespacedData)
+	if err != nil {
+		return 0, err
+	}
+
+	// merge equally sized subtrees upwards, just like carrying when
+	// incrementing a binary counter
+	for height := 0; ; height++ {
+		if height == len(a.levels) {
+			a.levels = append(a.levels, nil)
+		}
+		a.levels[height] = append(a.levels[height], node)
+		count := len(a.levels[height])
+		if count%2 == 1 {
+			break
+		}
+		node, err = a.treeHasher.HashNode(a.levels[height][count-2], node)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	index := a.size
+	a.size++
+	a.lastNID = append(a.lastNID[:0], nID...)
+	return index, nil
+}
+
+// Peaks returns the roots of the perfect subtrees of the accumulator, from
+// the left-most (largest) to the right-most (smallest) one.
+func (a *Accumulator) Peaks() [][]byte {
+	peaks := make([][]byte, 0, bits.OnesCount(uint(a.size)))
+	for height := len(a.levels) - 1; height >= 0; height-- {
+		if a.size&(1<<height) != 0 {
+			level := a.levels[height]
+			peaks = append(peaks, level[len(level)-1])
+		}
+	}
+	return peaks
+}
+
+// Root returns the root of the accumulator, i.e., its bagged peaks.
+func (a *Accumulator) Root() ([]byte, error) {
+	return bagPeaks(a.treeHasher, a.Peaks())
+}
+
+// Prove returns an inclusion proof of the leaf at index against the current
+// root of the accumulator.
+func (a *Accumulator) Prove(index int) (Proof, error) {
+	if index < 0 || index >= a.size {
+		return Proof{}, fmt.Errorf("%w: %d not in [0, %d)", ErrInvalidIndex, index, a.size)
+	}
+	_, height, _ := peakOf(index, a.size)
+	path := make([][]byte, 0, height)
+	for h := 0; h < height; h++ {
+		path = append(path, a.levels[h][(index>>h)^1])
+	}
+	return Proof{Index: index, Size: a.size, Path: path, Peaks: a.Peaks()}, nil
+}
+
+// ExtendProof updates a proof generated by this accumulator at an earlier
+// size to verify against its current root. The path of the given proof is
+// kept and only extended by the siblings of the subtrees its peak has been
+// merged into since. It returns an ErrInconsistentProof error if the proof
+// was not generated by this accumulator.
+func (a *Accumulator) ExtendProof(p Proof) (Proof, error) {
+	if p.Size > a.size || p.Index < 0 || p.Index >= p.Size {
+		return Proof{}, fmt.Errorf("%w: proof of leaf %d at size %d for accumulator of size %d", ErrInconsistentProof, p.Index, p.Size, a.size)
+	}
+	current, err := a.Prove(p.Index)
+	if err != nil {
+		return Proof{}, err
+	}
+	if len(p.Path) > len(current.Path) {
+		return Proof{}, fmt.Errorf("%w: path is longer than the current path", ErrInconsistentProof)
+	}
+	for i, node := range p.Path {
+		if !bytes.Equal(node, current.Path[i]) {
+			return Proof{}, fmt.Errorf("%w: path node %d differs", ErrInconsistentProof, i)
+		}
+	}
+	return Proof{
+		Index: p.Index,
+		Size:  a.size,
+		Path:  append(p.Path[:len(p.Path):len(p.Path)], current.Path[len(p.Path):]...),
+		Peaks: current.Peaks,
+	}, nil
+}
+
+// Proof is an inclusion proof of a leaf in an accumulator of a given size.
+type Proof struct {
+	// Index is the index of the proven leaf.
+	Index int
+	// Size is the number of leaves of the accumulator the proof was
+	// generated for.
+	Size int
+	// Path holds the siblings on the path from the leaf to its peak, from
+	// the bottom up.
+	Path [][]byte
+	// Peaks holds all peaks of the accumulator, from left to right.
+	Peaks [][]byte
+}
+
+// Verify checks that leaf is the namespace-prefixed leaf at p.Index of the
+// accumulator with the given root and size. Since the root does not commit to
+// the number of leaves, size must be trusted like the root: the same peaks
+// may be bagged into the root of accumulators of different sizes, in which
+// the leaf would be at different indices. h must be configured like the
+// hasher of the accumulator.
+func (p Proof) Verify(h nmt.Hasher, leaf namespace.PrefixedData, size int, root []byte) bool {
+	if p.Size != size || p.Index < 0 || p.Index >= p.Size || len(p.Peaks) != bits.OnesCount(uint(p.Size)) {
+		return false
+	}
+	peak, height, start := peakOf(p.Index, p.Size)
+	if len(p.Path) != height {
+		return false
+	}
+	if len(leaf) < int(h.NamespaceSize()) {
+		return false
+	}
+	node, err := h.HashLeaf(leaf)
+	if err != nil {
+		return false
+	}
+	offset := p.Index - start
+	for i, sibling := range p.Path {
+		if offset&(1<<i) == 0 {
+			node, err = h.HashNode(node, sibling)
+		} else {
+			node, err = h.HashNode(sibling, node)
+		}
+		if err != nil {
+			return false
+		}
+	}
+	if !bytes.Equal(node, p.Peaks[peak]) {
+		return false
+	}
+	bagged, err := bagPeaks(h, p.Peaks)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(bagged, root)
+}
+
+// peakOf returns the position of the peak containing the leaf at index in an
+// accumulator of the given size, together with the height of the peak and
+// the index of its first leaf.
+func peakOf(index, size int) (peak, height, start int) {
+	for height = bits.Len(uint(size)) - 1; height >= 0; height-- {
+		if size&(1<<height) == 0 {
+			continue
+		}
+		if index < start+1<<height {
+			return peak, height, start
+		}
+		start += 1 << height
+		peak++
+	}
+	panic(fmt.Sprintf("index %d out of range [0, %d)", index, size))
+}
+
+// bagPeaks folds the peaks from right to left into a single root.
+func bagPeaks(h nmt.Hasher, peaks [][]byte) ([]byte, error) {
+	if len(peaks) == 0 {
+		return h.EmptyRoot(), nil
+	}
+	root := peaks[len(peaks)-1]
+	for i := len(peaks) - 2; i >= 0; i-- {
+		var err error
+		root, err = h.HashNode(peaks[i], root)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
@@ -0,0 +1,149 @@
+package mmr
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func newHasher() nmt.Hasher {
+	return nmt.NewNmtHasher(sha256.New(), 1, true)
+}
+
+func leaf(i int) []byte {
+	return append([]byte{byte(i / 5)}, []byte(fmt.Sprintf("entry_%d", i))...)
+}
+
+func TestAccumulator(t *testing.T) {
+	acc := New(newHasher())
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(1))
+	for i := 0; i < 40; i++ {
+		root, err := acc.Root()
+		require.NoError(t, err)
+		treeRoot, err := tree.Root()
+		require.NoError(t, err)
+		require.Equal(t, treeRoot, root, "size %d", i)
+
+		for j := 0; j < acc.Size(); j++ {
+			proof, err := acc.Prove(j)
+			require.NoError(t, err)
+			require.True(t, proof.Verify(newHasher(), leaf(j), acc.Size(), root), "leaf %d at size %d", j, i)
+			assert.False(t, proof.Verify(newHasher(), leaf(j+1), acc.Size(), root))
+		}
+
+		index, err := acc.Append(leaf(i))
+		require.NoError(t, err)
+		assert.Equal(t, i, index)
+		require.NoError(t, tree.Push(leaf(i)))
+	}
+
+	_, err := acc.Prove(acc.Size())
+	assert.ErrorIs(t, err, ErrInvalidIndex)
+	_, err = acc.Append([]byte{0})
+	assert.ErrorIs(t, err, nmt.ErrInvalidPushOrder)
+}
+
+func TestAccumulator_NamespaceRules(t *testing.T) {
+	acc := New(newHasher(), nmt.RejectMaxNamespace(true),
+		nmt.NamespaceVersions(namespace.VersionRules{0: nil, 0xFF: nil}))
+	_, err := acc.Append([]byte{0, 'a'})
+	require.NoError(t, err)
+	_, err = acc.Append([]byte{1, 'b'})
+	assert.ErrorIs(t, err, namespace.ErrUnsupportedVersion)
+	_, err = acc.Append([]byte{0xFF, 'c'})
+	assert.ErrorIs(t, err, nmt.ErrReservedNamespace)
+	assert.Equal(t, 1, acc.Size())
+}
+
+func TestExtendProof(t *testing.T) {
+	acc := New(newHasher())
+	for i := 0; i < 3; i++ {
+		_, err := acc.Append(leaf(i))
+		require.NoError(t, err)
+	}
+	proof, err := acc.Prove(2)
+	require.NoError(t, err)
+
+	for i := 3; i < 21; i++ {
+		_, err := acc.Append(leaf(i))
+		require.NoError(t, err)
+		root, err := acc.Root()
+		require.NoError(t, err)
+
+		extended, err := acc.ExtendProof(proof)
+		require.NoError(t, err)
+		require.True(t, extended.Verify(newHasher(), leaf(2), acc.Size(), root), "size %d", acc.Size())
+		// the previous path is a prefix of the extended one
+		assert.Equal(t, proof.Path, extended.Path[:len(proof.Path)])
+		proof = extended
+	}
+
+	proof.Path[0] = proof.Peaks[0]
+	_, err = acc.ExtendProof(proof)
+	assert.ErrorIs(t, err, ErrInconsistentProof)
+	proof.Size = acc.Size() + 1
+	_, err = acc.ExtendProof(proof)
+	assert.ErrorIs(t, err, ErrInconsistentProof)
+}
+
+func TestProof_VerifyMalformed(t *testing.T) {
+	acc := New(newHasher())
+	for i := 0; i < 7; i++ {
+		_, err := acc.Append(leaf(i))
+		require.NoError(t, err)
+	}
+	root, err := acc.Root()
+	require.NoError(t, err)
+	proof, err := acc.Prove(4)
+	require.NoError(t, err)
+	require.True(t, proof.Verify(newHasher(), leaf(4), acc.Size(), root))
+
+	tests := []struct {
+		name   string
+		modify func(p *Proof)
+	}{
+		{"wrong index", func(p *Proof) { p.Index = 5 }},
+		{"index out of range", func(p *Proof) { p.Index = 7 }},
+		{"missing peak", func(p *Proof) { p.Peaks = p.Peaks[1:] }},
+		{"extra path node", func(p *Proof) { p.Path = append(p.Path, p.Peaks[0]) }},
+		{"wrong size", func(p *Proof) { p.Size = 8 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := proof
+			p.Path = append([][]byte(nil), proof.Path...)
+			p.Peaks = append([][]byte(nil), proof.Peaks...)
+			tt.modify(&p)
+			assert.False(t, p.Verify(newHasher(), leaf(4), acc.Size(), root))
+		})
+	}
+}
+
+func TestProof_VerifyMovedLeaf(t *testing.T) {
+	acc := New(newHasher())
+	for i := 0; i < 5; i++ {
+		_, err := acc.Append(leaf(i))
+		require.NoError(t, err)
+	}
+	root, err := acc.Root()
+	require.NoError(t, err)
+	proof, err := acc.Prove(4)
+	require.NoError(t, err)
+	require.True(t, proof.Verify(newHasher(), leaf(4), 5, root))
+
+	// the peaks of 5 leaves have the layout of the peaks of 9 or 3 leaves,
+	// which would place the leaf at a different index
+	for _, moved := range []Proof{
+		{Index: 8, Size: 9, Path: proof.Path, Peaks: proof.Peaks},
+		{Index: 2, Size: 3, Path: proof.Path, Peaks: proof.Peaks},
+	} {
+		assert.False(t, moved.Verify(newHasher(), leaf(4), 5, root), "index %d size %d", moved.Index, moved.Size)
+	}
+	assert.False(t, proof.Verify(newHasher(), leaf(4), 6, root))
+}
@@ -0,0 +1,104 @@
+package mmr
+
+import (
+	"bytes"
+	"fmt"
+	"math/bits"
+
+	"github.com/celestiaorg/nmt"
+)
+
+// AppendRecord describes how the peaks of an accumulator were merged when it
+// grew from OldSize to NewSize leaves. It allows the holders of proofs
+// generated at OldSize, e.g., light clients, to update them to NewSize
+// without the accumulator, see Proof.Update. Its size is logarithmic in the
+// number of leaves, independently of the number of proofs to update.
+type AppendRecord struct {
+	OldSize int
+	NewSize int
+	// Siblings holds, for each peak at OldSize from left to right, the
+	// siblings on the path from that peak to its peak at NewSize, from the
+	// bottom up.
+	Siblings [][][]byte
+	// Peaks holds all peaks at NewSize, from left to right.
+	Peaks [][]byte
+}
+
+// AppendRecord returns the record of the leaves appended since the
+// accumulator had oldSize leaves. Full nodes publish it, e.g., once per
+// block, so that light clients can keep their proofs fresh. It returns an
+// ErrInvalidIndex error if oldSize is not in [0, Size()].
+func (a *Accumulator) AppendRecord(oldSize int) (AppendRecord, error) {
+	if oldSize < 0 || oldSize > a.size {
+		return AppendRecord{}, fmt.Errorf("%w: size %d not in [0, %d]", ErrInvalidIndex, oldSize, a.size)
+	}
+	record := AppendRecord{
+		OldSize:  oldSize,
+		NewSize:  a.size,
+		Siblings: make([][][]byte, 0, bits.OnesCount(uint(oldSize))),
+		Peaks:    a.Peaks(),
+	}
+	start := 0
+	for height := bits.Len(uint(oldSize)) - 1; height >= 0; height-- {
+		if oldSize&(1<<height) == 0 {
+			continue
+		}
+		// the peak is merged into the peak holding its first leaf
+		_, newHeight, _ := peakOf(start, a.size)
+		siblings := make([][]byte, 0, newHeight-height)
+		for h := height; h < newHeight; h++ {
+			siblings = append(siblings, a.levels[h][(start>>h)^1])
+		}
+		record.Siblings = append(record.Siblings, siblings)
+		start += 1 << height
+	}
+	return record, nil
+}
+
+// Update updates the proof, generated at r.OldSize, to verify against the
+// root of the accumulator at r.NewSize by extending its path with the
+// siblings of its peak recorded in r. h must be configured like the hasher
+// of the accumulator. Update checks that r merges the peak of the proof into
+// the recorded peak, but the returned proof still needs to be verified
+// against a trusted root. It returns an ErrInconsistentProof error if the
+// proof does not match the record.
+func (p Proof) Update(h nmt.Hasher, r AppendRecord) (Proof, error) {
+	if p.Size != r.OldSize || r.NewSize < r.OldSize || p.Index < 0 || p.Index >= p.Size {
+		return Proof{}, fmt.Errorf("%w: proof of leaf %d at size %d for record from size %d to %d", ErrInconsistentProof, p.Index, p.Size, r.OldSize, r.NewSize)
+	}
+	numPeaks := bits.OnesCount(uint(p.Size))
+	if len(p.Peaks) != numPeaks || len(r.Siblings) != numPeaks || len(r.Peaks) != bits.OnesCount(uint(r.NewSize)) {
+		return Proof{}, fmt.Errorf("%w: wrong number of peaks", ErrInconsistentProof)
+	}
+	peak, height, _ := peakOf(p.Index, p.Size)
+	if len(p.Path) != height {
+		return Proof{}, fmt.Errorf("%w: path has %d nodes, want %d", ErrInconsistentProof, len(p.Path), height)
+	}
+	newPeak, newHeight, _ := peakOf(p.Index, r.NewSize)
+	siblings := r.Siblings[peak]
+	if len(siblings) != newHeight-height {
+		return Proof{}, fmt.Errorf("%w: record has %d siblings for peak %d, want %d", ErrInconsistentProof, len(siblings), peak, newHeight-height)
+	}
+
+	node := p.Peaks[peak]
+	for i, sibling := range siblings {
+		var err error
+		if p.Index&(1<<(height+i)) == 0 {
+			node, err = h.HashNode(node, sibling)
+		} else {
+			node, err = h.HashNode(sibling, node)
+		}
+		if err != nil {
+			return Proof{}, fmt.Errorf("%w: %w", ErrInconsistentProof, err)
+		}
+	}
+	if !bytes.Equal(node, r.Peaks[newPeak]) {
+		return Proof{}, fmt.Errorf("%w: peak %d is not merged into peak %d of the record", ErrInconsistentProof, peak, newPeak)
+	}
+	return Proof{
+		Index: p.Index,
+		Size:  r.NewSize,
+		Path:  append(p.Path[:len(p.Path):len(p.Path)], siblings...),
+		Peaks: r.Peaks,
+	}, nil
+}
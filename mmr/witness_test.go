@@ -0,0 +1,98 @@
+package mmr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProof_Update(t *testing.T) {
+	acc := New(newHasher())
+	// proofs of all leaves, kept fresh from block to block
+	var proofs []Proof
+	size := 0
+	for _, blockSize := range []int{1, 2, 5, 1, 8, 3, 16, 0, 7} {
+		for i := 0; i < blockSize; i++ {
+			_, err := acc.Append(leaf(size + i))
+			require.NoError(t, err)
+		}
+		record, err := acc.AppendRecord(size)
+		require.NoError(t, err)
+		root, err := acc.Root()
+		require.NoError(t, err)
+
+		for j, proof := range proofs {
+			updated, err := proof.Update(newHasher(), record)
+			require.NoError(t, err, "leaf %d from size %d to %d", j, size, acc.Size())
+			require.True(t, updated.Verify(newHasher(), leaf(j), acc.Size(), root), "leaf %d from size %d to %d", j, size, acc.Size())
+			want, err := acc.Prove(j)
+			require.NoError(t, err)
+			assert.Equal(t, want, updated)
+			proofs[j] = updated
+		}
+		for j := size; j < acc.Size(); j++ {
+			proof, err := acc.Prove(j)
+			require.NoError(t, err)
+			proofs = append(proofs, proof)
+		}
+		size = acc.Size()
+	}
+}
+
+func TestProof_UpdateSkippedBlocks(t *testing.T) {
+	acc := New(newHasher())
+	for i := 0; i < 6; i++ {
+		_, err := acc.Append(leaf(i))
+		require.NoError(t, err)
+	}
+	proof, err := acc.Prove(4)
+	require.NoError(t, err)
+	for i := 6; i < 29; i++ {
+		_, err := acc.Append(leaf(i))
+		require.NoError(t, err)
+	}
+
+	// a single record spans several blocks
+	record, err := acc.AppendRecord(6)
+	require.NoError(t, err)
+	updated, err := proof.Update(newHasher(), record)
+	require.NoError(t, err)
+	root, err := acc.Root()
+	require.NoError(t, err)
+	assert.True(t, updated.Verify(newHasher(), leaf(4), acc.Size(), root))
+}
+
+func TestProof_UpdateInconsistent(t *testing.T) {
+	acc := New(newHasher())
+	for i := 0; i < 5; i++ {
+		_, err := acc.Append(leaf(i))
+		require.NoError(t, err)
+	}
+	proof, err := acc.Prove(1)
+	require.NoError(t, err)
+	for i := 5; i < 12; i++ {
+		_, err := acc.Append(leaf(i))
+		require.NoError(t, err)
+	}
+	record, err := acc.AppendRecord(5)
+	require.NoError(t, err)
+
+	_, err = acc.AppendRecord(13)
+	assert.ErrorIs(t, err, ErrInvalidIndex)
+
+	stale, err := acc.AppendRecord(4)
+	require.NoError(t, err)
+	_, err = proof.Update(newHasher(), stale)
+	assert.ErrorIs(t, err, ErrInconsistentProof)
+
+	tampered := record
+	tampered.Siblings = [][][]byte{{record.Peaks[0]}, record.Siblings[1]}
+	_, err = proof.Update(newHasher(), tampered)
+	assert.ErrorIs(t, err, ErrInconsistentProof)
+
+	truncated := proof
+	truncated.Path = truncated.Path[1:]
+	_, err = truncated.Update(newHasher(), record)
+	assert.ErrorIs(t, err, ErrInconsistentProof)
+}
@@ -0,0 +1,71 @@
+package nmt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// Multihash codes of common hash functions, see
+// https://github.com/multiformats/multicodec/blob/master/table.csv.
+const (
+	MultihashSHA256 uint64 = 0x12
+	MultihashSHA512 uint64 = 0x13
+)
+
+// ErrInvalidMultihash indicates that a digest is not a well-formed multihash.
+var ErrInvalidMultihash = errors.New("invalid multihash")
+
+// MultihashHash returns a constructor of instances of newHash whose digests
+// are encoded as multihashes, i.e., prefixed with the unsigned varints of code
+// and of the digest size, so that the digests of nodes and roots identify the
+// hash function they were computed with. Like KeyedHash, the instances can be
+// used wherever a hash.Hash is expected; in particular, proofs of trees using
+// multihash digests verify against verifiers using the same constructor.
+// code MUST be the multihash code of newHash, e.g., MultihashSHA256 for
+// sha256.New. Use SplitMultihash to decode the digests.
+func MultihashHash(newHash func() hash.Hash, code uint64) func() hash.Hash {
+	size := newHash().Size()
+	prefix := binary.AppendUvarint(nil, code)
+	prefix = binary.AppendUvarint(prefix, uint64(size))
+	return func() hash.Hash {
+		return &multihashHash{Hash: newHash(), prefix: prefix}
+	}
+}
+
+// multihashHash wraps a hash.Hash whose digests it prefixes with a multihash
+// prefix.
+type multihashHash struct {
+	hash.Hash
+	// prefix holds the varints of the multihash code and the digest size.
+	prefix []byte
+}
+
+func (m *multihashHash) Size() int {
+	return len(m.prefix) + m.Hash.Size()
+}
+
+func (m *multihashHash) Sum(b []byte) []byte {
+	return m.Hash.Sum(append(b, m.prefix...))
+}
+
+// SplitMultihash decodes the multihash mh, e.g., the digest of a node computed
+// with a hash created by MultihashHash, i.e., node[2*NamespaceSize():], into
+// its multihash code and digest. It returns an ErrInvalidMultihash error if mh
+// is malformed or the size of the digest does not match the encoded one.
+func SplitMultihash(mh []byte) (code uint64, digest []byte, err error) {
+	code, n := binary.Uvarint(mh)
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("%w: malformed code", ErrInvalidMultihash)
+	}
+	size, m := binary.Uvarint(mh[n:])
+	if m <= 0 {
+		return 0, nil, fmt.Errorf("%w: malformed digest size", ErrInvalidMultihash)
+	}
+	digest = mh[n+m:]
+	if uint64(len(digest)) != size {
+		return 0, nil, fmt.Errorf("%w: got digest size %d, want %d", ErrInvalidMultihash, len(digest), size)
+	}
+	return code, digest, nil
+}
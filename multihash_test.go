@@ -0,0 +1,60 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestMultihashHash(t *testing.T) {
+	h := MultihashHash(sha256.New, MultihashSHA256)()
+	assert.Equal(t, 2+sha256.Size, h.Size())
+	h.Write([]byte("data"))
+	digest := sha256.Sum256([]byte("data"))
+	assert.Equal(t, append([]byte{0x12, 0x20}, digest[:]...), h.Sum(nil))
+
+	code, got, err := SplitMultihash(h.Sum(nil))
+	require.NoError(t, err)
+	assert.Equal(t, MultihashSHA256, code)
+	assert.Equal(t, digest[:], got)
+
+	// codes of 128 or more take several bytes
+	h = MultihashHash(sha512.New, 0x1013)()
+	code, got, err = SplitMultihash(h.Sum(nil))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0x1013), code)
+	assert.Len(t, got, sha512.Size)
+	assert.Equal(t, 3+sha512.Size, h.Size())
+}
+
+func TestMultihashHash_Tree(t *testing.T) {
+	mh := MultihashHash(sha256.New, MultihashSHA256)
+	tree := New(mh(), NamespaceIDSize(1))
+	leaves := [][]byte{{1, 'a'}, {2, 'b'}, {2, 'c'}, {4, 'd'}}
+	for _, leaf := range leaves {
+		require.NoError(t, tree.Push(leaf))
+	}
+	root, err := tree.Root()
+	require.NoError(t, err)
+	code, digest, err := SplitMultihash(root[2:])
+	require.NoError(t, err)
+	assert.Equal(t, MultihashSHA256, code)
+	assert.Len(t, digest, sha256.Size)
+
+	proof, err := tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	assert.True(t, proof.VerifyNamespace(mh(), namespace.ID{2}, leaves[1:3], root))
+	assert.False(t, proof.VerifyNamespace(sha256.New(), namespace.ID{2}, leaves[1:3], root))
+}
+
+func TestSplitMultihash_Errors(t *testing.T) {
+	for _, mh := range [][]byte{nil, {0x80}, {0x12}, {0x12, 0x80}, {0x12, 0x02, 0xAA}, {0x12, 0x01, 0xAA, 0xBB}} {
+		_, _, err := SplitMultihash(mh)
+		assert.ErrorIs(t, err, ErrInvalidMultihash, "%x", mh)
+	}
+}
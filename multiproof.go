@@ -0,0 +1,249 @@
+package nmt
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+	"math"
+	"sort"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// MultiProof is a Merkle inclusion proof for an arbitrary, not necessarily
+// contiguous, set of leaves. Inner nodes shared by the paths of several leaves
+// are included only once, which makes a MultiProof considerably smaller than
+// one Proof per leaf.
+type MultiProof struct {
+	// indices holds the indices of the proven leaves in ascending order.
+	indices []int
+	// nodes hold the roots of the maximal subtrees that contain none of the
+	// proven leaves, in the order of an in-order traversal of the tree.
+	nodes [][]byte
+	// isMaxNamespaceIDIgnored has the same meaning as in Proof.
+	isMaxNamespaceIDIgnored bool
+}
+
+// NewMultiProof constructs a MultiProof for the leaves at the supplied indices.
+// indices must be sorted in ascending order and must not contain duplicates.
+func NewMultiProof(indices []int, nodes [][]byte, ignoreMaxNamespace bool) MultiProof {
+	return MultiProof{indices, nodes, ignoreMaxNamespace}
+}
+
+// Indices returns the indices of the proven leaves in ascending order.
+func (mp MultiProof) Indices() []int {
+	return mp.indices
+}
+
+// Nodes returns the proof nodes that together with the proven leaves can be
+// used to recompute the root.
+func (mp MultiProof) Nodes() [][]byte {
+	return mp.nodes
+}
+
+// IsMaxNamespaceIDIgnored returns true if the proof has been created under the
+// ignore max namespace logic.
+func (mp MultiProof) IsMaxNamespaceIDIgnored() bool {
+	return mp.isMaxNamespaceIDIgnored
+}
+
+// ProveMulti returns a MultiProof for the leaves at the supplied indices. The
+// indices do not need to be sorted; duplicates are ignored. ProveMulti returns
+// an ErrInvalidRange error if indices is empty or contains an index outside of
+// [0, n.Size()).
+func (n *NamespacedMerkleTree) ProveMulti(indices []int) (MultiProof, error) {
+	isMaxNsIgnored := n.treeHasher.IsMaxNamespaceIDIgnored()
+	sorted, err := normalizeIndices(indices, n.Size())
+	if err != nil {
+		return MultiProof{}, err
+	}
+	if err := n.hashPendingLeaves(); err != nil {
+		return MultiProof{}, err
+	}
+
+	proof := [][]byte{}
+	// remaining holds the indices of the leaves not yet passed by the
+	// in-order traversal
+	remaining := sorted
+	var recurse func(start, end int) ([]byte, error)
+	recurse = func(start, end int) ([]byte, error) {
+		if start >= n.Size() && n.padding == NoPadding {
+			return nil, nil
+		}
+		if len(remaining) == 0 || remaining[0] >= end {
+			// no proven leaf within [start, end), which may consist of
+			// padding leaves only
+			hash, err := n.computeRoot(start, minInt(end, n.sizeWithPadding()))
+			if err != nil {
+				return nil, err
+			}
+			proof = append(proof, hash)
+			return hash, nil
+		}
+		if end-start == 1 {
+			remaining = remaining[1:]
+			return n.leafHashes[start], nil
+		}
+
+		k := getSplitPoint(end - start)
+		left, err := recurse(start, start+k)
+		if err != nil {
+			return nil, err
+		}
+		right, err := recurse(start+k, end)
+		if err != nil {
+			return nil, err
+		}
+		if right == nil {
+			return left, nil
+		}
+		return n.treeHasher.HashNode(left, right)
+	}
+
+	fullTreeSize := getSplitPoint(n.Size()) * 2
+	if fullTreeSize < 1 {
+		fullTreeSize = 1
+	}
+	if n.padding != NoPadding {
+		// padded trees are perfect
+		fullTreeSize = n.sizeWithPadding()
+	}
+	if _, err := recurse(0, fullTreeSize); err != nil {
+		return MultiProof{}, err
+	}
+	return NewMultiProof(sorted, proof, isMaxNsIgnored), nil
+}
+
+// normalizeIndices returns a sorted copy of indices without duplicates. It
+// returns an ErrInvalidRange error if indices is empty or any index falls
+// outside of [0, size).
+func normalizeIndices(indices []int, size int) ([]int, error) {
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("no leaf indices supplied: %w", ErrInvalidRange)
+	}
+	sorted := make([]int, len(indices))
+	copy(sorted, indices)
+	sort.Ints(sorted)
+	res := sorted[:0]
+	for i, index := range sorted {
+		if index < 0 || index >= size {
+			return nil, fmt.Errorf("leaf index %d is out of the tree range [0, %d): %w", index, size, ErrInvalidRange)
+		}
+		if i > 0 && index == sorted[i-1] {
+			continue
+		}
+		res = append(res, index)
+	}
+	return res, nil
+}
+
+// VerifyInclusion checks that the supplied namespace-prefixed leaves are
+// included in the tree with the given root at the indices of the proof.
+// leaves[i] corresponds to the leaf at index mp.Indices()[i]. `h` MUST be the
+// same as the underlying hash function used to generate the proof.
+func (mp MultiProof) VerifyInclusion(h hash.Hash, nIDSize namespace.IDSize, leaves [][]byte, root []byte) bool {
+	nth := NewNmtHasher(h, nIDSize, mp.isMaxNamespaceIDIgnored)
+	leafHashes := make([][]byte, 0, len(leaves))
+	for _, leaf := range leaves {
+		leafHash, err := nth.HashLeaf(leaf)
+		if err != nil {
+			return false
+		}
+		leafHashes = append(leafHashes, leafHash)
+	}
+	res, err := mp.VerifyLeafHashes(nth, leafHashes, root)
+	if err != nil {
+		return false
+	}
+	return res
+}
+
+// VerifyLeafHashes checks whether the proof is a valid Merkle multiproof for
+// the supplied leaf hashes, where leafHashes[i] is the hash of the leaf at
+// index mp.Indices()[i]. If the proof is malformed, e.g., a node does not
+// conform to the namespace hash format or the nodes and leaf hashes are not
+// ordered by namespace ID (see Proof.VerifyLeafHashes), an error describing
+// the root cause is returned.
+func (mp MultiProof) VerifyLeafHashes(nth *NmtHasher, leafHashes [][]byte, root []byte) (bool, error) {
+	if len(mp.indices) == 0 {
+		return false, fmt.Errorf("multiproof does not contain any index: %w", ErrInvalidRange)
+	}
+	if len(leafHashes) != len(mp.indices) {
+		return false, fmt.Errorf(
+			"supplied leafHashes size %d, expected size %d: %w",
+			len(leafHashes), len(mp.indices), ErrWrongLeafHashesSize)
+	}
+	for i, index := range mp.indices {
+		if index < 0 || (i > 0 && index <= mp.indices[i-1]) {
+			return false, fmt.Errorf("multiproof indices must be non-negative and strictly ascending: %w", ErrInvalidRange)
+		}
+	}
+	if err := nth.ValidateNodeFormat(root); err != nil {
+		return false, fmt.Errorf("root does not match the NMT hasher's hash format: %w", err)
+	}
+	for i, node := range mp.nodes {
+		if err := nth.ValidateNodeFormat(node); err != nil {
+			return false, fmt.Errorf("proof node %d does not match the NMT hasher's hash format: %w", i, err)
+		}
+	}
+	if err := validateLeafHashes(nth, leafHashes); err != nil {
+		return false, err
+	}
+
+	nodes := mp.nodes
+	indices := mp.indices
+	order := namespaceOrder{nth: nth}
+	var computeRoot func(start, end int) ([]byte, error)
+	computeRoot = func(start, end int) ([]byte, error) {
+		if len(indices) == 0 || indices[0] >= end {
+			// no proven leaf within [start, end), pop a proof node if
+			// present, else return nil because the subtree doesn't exist
+			return order.pop(&nodes)
+		}
+		if end-start == 1 {
+			indices = indices[1:]
+			return order.pop(&leafHashes)
+		}
+		k := getSplitPoint(end - start)
+		left, err := computeRoot(start, start+k)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute subtree root [%d, %d): %w", start, start+k, err)
+		}
+		right, err := computeRoot(start+k, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute subtree root [%d, %d): %w", start+k, end, err)
+		}
+		if right == nil {
+			return left, nil
+		}
+		hash, err := nth.HashNode(left, right)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash node: %w", err)
+		}
+		return hash, nil
+	}
+
+	// estimate the leaf size of the subtree containing all proven leaves; it
+	// is computed as uint64 so that it cannot overflow for indices close to
+	// the maximum int
+	lastIndex := mp.indices[len(mp.indices)-1]
+	estimate := fullTreeSize(uint64(lastIndex) + 1)
+	if estimate > math.MaxInt {
+		return false, fmt.Errorf("leaf index %d exceeds the platform's int range of tree sizes: %w", lastIndex, ErrInvalidRange)
+	}
+	subtreeEstimate := int(estimate)
+	rootHash, err := computeRoot(0, subtreeEstimate)
+	if err != nil {
+		return false, fmt.Errorf("failed to compute root [%d, %d): %w", 0, subtreeEstimate, err)
+	}
+	for _, node := range nodes {
+		if err := order.next(node); err != nil {
+			return false, err
+		}
+		rootHash, err = nth.HashNode(rootHash, node)
+		if err != nil {
+			return false, fmt.Errorf("failed to hash node: %w", err)
+		}
+	}
+	return bytes.Equal(rootHash, root), nil
+}
@@ -0,0 +1,126 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveMulti(t *testing.T) {
+	for _, size := range []int{1, 2, 3, 5, 8, 13, 16, 33} {
+		nIDs := make([]byte, size)
+		for i := range nIDs {
+			nIDs[i] = byte(i / 2)
+		}
+		tree := exampleNMT(1, true, nIDs...)
+		root, err := tree.Root()
+		require.NoError(t, err)
+
+		rnd := rand.New(rand.NewSource(int64(size)))
+		for iter := 0; iter < 20; iter++ {
+			indices := rnd.Perm(size)[:1+rnd.Intn(size)]
+			proof, err := tree.ProveMulti(indices)
+			require.NoError(t, err)
+
+			leaves := make([][]byte, 0, len(proof.Indices()))
+			for _, index := range proof.Indices() {
+				leaves = append(leaves, tree.leaves[index])
+			}
+			assert.True(t, proof.VerifyInclusion(sha256.New(), 1, leaves, root), "size %d, indices %v", size, proof.Indices())
+
+			// swapping the data of two proven leaves must fail the verification
+			if len(leaves) > 1 {
+				leaves[0], leaves[1] = leaves[1], leaves[0]
+				assert.False(t, proof.VerifyInclusion(sha256.New(), 1, leaves, root))
+			}
+		}
+	}
+}
+
+func TestProveMulti_Padding(t *testing.T) {
+	for _, setter := range []Option{Padding(PadWithEmptyLeaves), Padding(PadWithLastLeaf), FixedHeight(4)} {
+		for _, size := range []int{1, 2, 3, 5, 11, 16} {
+			tree := New(sha256.New(), NamespaceIDSize(1), setter)
+			for i := 0; i < size; i++ {
+				require.NoError(t, tree.Push([]byte{byte(i / 2), 'd', byte(i)}))
+			}
+			root, err := tree.Root()
+			require.NoError(t, err)
+
+			rnd := rand.New(rand.NewSource(int64(size)))
+			for iter := 0; iter < 10; iter++ {
+				indices := rnd.Perm(size)[:1+rnd.Intn(size)]
+				proof, err := tree.ProveMulti(indices)
+				require.NoError(t, err)
+
+				leaves := make([][]byte, 0, len(proof.Indices()))
+				for _, index := range proof.Indices() {
+					leaves = append(leaves, tree.leaves[index])
+				}
+				assert.True(t, proof.VerifyInclusion(sha256.New(), 1, leaves, root), "size %d, indices %v", size, proof.Indices())
+			}
+		}
+	}
+}
+
+func TestProveMulti_SharesNodes(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 2, 3, 4, 5, 6, 7)
+	proof, err := tree.ProveMulti([]int{0, 1, 6})
+	require.NoError(t, err)
+	// [2, 4), [4, 6) and leaf 7
+	assert.Len(t, proof.Nodes(), 3)
+
+	proof, err = tree.ProveMulti([]int{7, 0, 0})
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 7}, proof.Indices())
+}
+
+func TestProveMulti_Errors(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 2, 3)
+	_, err := tree.ProveMulti(nil)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+	_, err = tree.ProveMulti([]int{0, 4})
+	assert.ErrorIs(t, err, ErrInvalidRange)
+	_, err = tree.ProveMulti([]int{-1})
+	assert.ErrorIs(t, err, ErrInvalidRange)
+
+	root, err := tree.Root()
+	require.NoError(t, err)
+	proof, err := tree.ProveMulti([]int{1, 3})
+	require.NoError(t, err)
+	nth := NewNmtHasher(sha256.New(), 1, true)
+
+	_, err = proof.VerifyLeafHashes(nth, tree.leafHashes[1:2], root)
+	assert.ErrorIs(t, err, ErrWrongLeafHashesSize)
+
+	unordered := NewMultiProof([]int{3, 1}, proof.Nodes(), true)
+	_, err = unordered.VerifyLeafHashes(nth, [][]byte{tree.leafHashes[3], tree.leafHashes[1]}, root)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+
+	corrupted := NewMultiProof(proof.Indices(), [][]byte{{1, 2, 3}}, true)
+	_, err = corrupted.VerifyLeafHashes(nth, [][]byte{tree.leafHashes[1], tree.leafHashes[3]}, root)
+	assert.ErrorIs(t, err, ErrInvalidNodeLen)
+
+	// indices beyond any tree size must neither panic nor overflow
+	for _, index := range []int{math.MaxInt, math.MaxInt/2 + 1} {
+		huge := NewMultiProof([]int{index}, proof.Nodes(), true)
+		_, err = huge.VerifyLeafHashes(nth, tree.leafHashes[1:2], root)
+		assert.ErrorIs(t, err, ErrInvalidRange, "index %d", index)
+	}
+	huge := NewMultiProof([]int{1, math.MaxInt / 2}, proof.Nodes(), true)
+	ok, _ := huge.VerifyLeafHashes(nth, [][]byte{tree.leafHashes[1], tree.leafHashes[3]}, root)
+	assert.False(t, ok)
+}
+
+func TestMultiProof_VerifyLeafHashes_UnorderedNodes(t *testing.T) {
+	leafHashes, root := misorderedTree(t)
+	nth := NewNmtHasher(sha256.New(), 1, true)
+	proof := NewMultiProof([]int{0, 2}, [][]byte{leafHashes[1], leafHashes[3]}, true)
+	ok, err := proof.VerifyLeafHashes(nth, [][]byte{leafHashes[0], leafHashes[2]}, root)
+	assert.ErrorIs(t, err, ErrUnorderedSiblings)
+	assert.False(t, ok)
+}
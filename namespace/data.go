@@ -1,8 +1,75 @@
 package namespace
 
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPrefixedData indicates that data is too short to be prefixed with
+// a namespace ID of the expected size.
+var ErrInvalidPrefixedData = errors.New("invalid namespace-prefixed data")
+
 // PrefixedData simply represents a slice of bytes which consists of a
 // namespace.ID and raw data. The user has to guarantee that the bytes are valid
 // namespace prefixed data. Go's type system does not allow enforcing the
 // structure we want: [namespaceID, rawData ...], especially as this type does
 // not expect any particular size for the namespace.
+//
+// The accessors of PrefixedData return sub-slices of the underlying buffer
+// rather than copies, so that leaves can be inspected without allocating.
+// Their results alias the buffer: modifying the buffer modifies the results
+// and vice versa, and they remain valid only as long as the buffer is not
+// reused.
 type PrefixedData []byte
+
+// NamespaceID returns the namespace ID of d, i.e., its first size bytes,
+// without copying. The capacity of the returned ID is limited to its length,
+// so that appending to it copies it instead of overwriting the raw data of d.
+// NamespaceID panics if d is shorter than size, see Validate.
+func (d PrefixedData) NamespaceID(size IDSize) ID {
+	return ID(d[:size:size])
+}
+
+// Data returns the raw data of d following its namespace ID of size bytes,
+// without copying. Data panics if d is shorter than size, see Validate.
+func (d PrefixedData) Data(size IDSize) []byte {
+	return d[size:]
+}
+
+// Bytes returns d, i.e., the namespace ID followed by the raw data, as a plain
+// byte slice without copying.
+func (d PrefixedData) Bytes() []byte {
+	return d
+}
+
+// NewPrefixedData checks that data is prefixed with a namespace ID of size
+// bytes and returns it as PrefixedData without copying. It returns an
+// ErrInvalidPrefixedData error if data is shorter than size, so that malformed
+// leaves are caught before they are hashed.
+func NewPrefixedData(size IDSize, data []byte) (PrefixedData, error) {
+	d := PrefixedData(data)
+	if err := d.Validate(size); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// NewPrefixedDataFromParts returns nID || raw in a newly allocated buffer. It
+// returns an ErrInvalidSize error if nID is not of size bytes.
+func NewPrefixedDataFromParts(size IDSize, nID ID, raw []byte) (PrefixedData, error) {
+	if len(nID) != int(size) {
+		return nil, fmt.Errorf("%w: got %d bytes, want %d", ErrInvalidSize, len(nID), size)
+	}
+	d := make(PrefixedData, 0, len(nID)+len(raw))
+	d = append(d, nID...)
+	return append(d, raw...), nil
+}
+
+// Validate returns an ErrInvalidPrefixedData error if d is shorter than a
+// namespace ID of size bytes.
+func (d PrefixedData) Validate(size IDSize) error {
+	if len(d) < int(size) {
+		return fmt.Errorf("%w: got %d bytes, want at least %d", ErrInvalidPrefixedData, len(d), size)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package namespace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixedData_Views(t *testing.T) {
+	d := PrefixedData{1, 2, 3, 4, 5}
+	assert.Equal(t, ID{1, 2}, d.NamespaceID(2))
+	assert.Equal(t, []byte{3, 4, 5}, d.Data(2))
+	assert.Equal(t, []byte{1, 2, 3, 4, 5}, d.Bytes())
+	assert.Equal(t, ID{}, d.NamespaceID(0))
+	assert.Equal(t, []byte{}, d.Data(5))
+
+	// the views alias d
+	d.NamespaceID(2)[0] = 9
+	d.Data(2)[0] = 8
+	assert.Equal(t, PrefixedData{9, 2, 8, 4, 5}, d)
+
+	// appending to the namespace ID does not overwrite the data
+	nID := append(d.NamespaceID(2), 7)
+	assert.Equal(t, ID{9, 2, 7}, nID)
+	assert.Equal(t, PrefixedData{9, 2, 8, 4, 5}, d)
+
+	assert.Panics(t, func() { d.NamespaceID(6) })
+	assert.Panics(t, func() { d.Data(6) })
+}
+
+func TestPrefixedData_ViewsDoNotAllocate(t *testing.T) {
+	d := PrefixedData(make([]byte, 64))
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = d.NamespaceID(29)
+		_ = d.Data(29)
+		_ = d.Bytes()
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestNewPrefixedData(t *testing.T) {
+	data := []byte{1, 2, 3}
+	d, err := NewPrefixedData(2, data)
+	require.NoError(t, err)
+	assert.Equal(t, ID{1, 2}, d.NamespaceID(2))
+	// the data is not copied
+	data[0] = 9
+	assert.Equal(t, ID{9, 2}, d.NamespaceID(2))
+
+	d, err = NewPrefixedData(3, data)
+	require.NoError(t, err)
+	assert.Empty(t, d.Data(3))
+
+	_, err = NewPrefixedData(4, data)
+	assert.ErrorIs(t, err, ErrInvalidPrefixedData)
+	_, err = NewPrefixedData(1, nil)
+	assert.ErrorIs(t, err, ErrInvalidPrefixedData)
+	assert.ErrorIs(t, PrefixedData{1}.Validate(2), ErrInvalidPrefixedData)
+	assert.NoError(t, PrefixedData{}.Validate(0))
+}
+
+func TestNewPrefixedDataFromParts(t *testing.T) {
+	nID, raw := ID{1, 2}, []byte{3, 4}
+	d, err := NewPrefixedDataFromParts(2, nID, raw)
+	require.NoError(t, err)
+	assert.Equal(t, PrefixedData{1, 2, 3, 4}, d)
+	// the parts are copied
+	nID[0], raw[0] = 9, 9
+	assert.Equal(t, PrefixedData{1, 2, 3, 4}, d)
+
+	d, err = NewPrefixedDataFromParts(2, ID{1, 2}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, PrefixedData{1, 2}, d)
+
+	_, err = NewPrefixedDataFromParts(3, ID{1, 2}, raw)
+	assert.ErrorIs(t, err, ErrInvalidSize)
+	_, err = NewPrefixedDataFromParts(1, make(ID, 257), raw)
+	assert.ErrorIs(t, err, ErrInvalidSize)
+}
@@ -0,0 +1,65 @@
+package namespace
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/celestiaorg/nmt/internal/bech32"
+	"github.com/celestiaorg/nmt/pb"
+)
+
+var (
+	// ErrInvalidEncoding indicates that a string is not a valid encoding of a
+	// namespace ID.
+	ErrInvalidEncoding = errors.New("invalid namespace ID encoding")
+	// ErrInvalidSize indicates that a parsed namespace ID does not have the
+	// expected size.
+	ErrInvalidSize = errors.New("invalid namespace ID size")
+)
+
+// ParseID parses the hexadecimal encoding of a namespace ID as returned by
+// ID.String and checks that it is of the given size.
+func ParseID(s string, size IDSize) (ID, error) {
+	nid, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	return checkSize(nid, size)
+}
+
+// Bech32 returns the bech32 encoding of nid with the human readable part hrp.
+func (nid ID) Bech32(hrp string) (string, error) {
+	return bech32.Encode(hrp, nid)
+}
+
+// ParseBech32ID parses the bech32 encoding of a namespace ID as returned by
+// ID.Bech32 and checks its human readable part and size.
+func ParseBech32ID(s, hrp string, size IDSize) (ID, error) {
+	gotHRP, nid, err := bech32.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	if gotHRP != hrp {
+		return nil, fmt.Errorf("%w: human readable part %q, expected %q", ErrInvalidEncoding, gotHRP, hrp)
+	}
+	return checkSize(nid, size)
+}
+
+// ToProto returns the protobuf representation of nid.
+func (nid ID) ToProto() pb.NamespaceID {
+	return pb.NamespaceID{Id: nid}
+}
+
+// ProtoToID creates a namespace ID from its protobuf representation and checks
+// that it is of the given size.
+func ProtoToID(protoID pb.NamespaceID, size IDSize) (ID, error) {
+	return checkSize(protoID.Id, size)
+}
+
+func checkSize(nid ID, size IDSize) (ID, error) {
+	if len(nid) != int(size) {
+		return nil, fmt.Errorf("%w: got %d bytes, want %d", ErrInvalidSize, len(nid), size)
+	}
+	return nid, nil
+}
@@ -0,0 +1,53 @@
+package namespace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/pb"
+)
+
+func TestParseID(t *testing.T) {
+	nid := ID{0, 1, 0xAB, 0xFF}
+	got, err := ParseID(nid.String(), 4)
+	require.NoError(t, err)
+	assert.Equal(t, nid, got)
+
+	_, err = ParseID(nid.String(), 3)
+	assert.ErrorIs(t, err, ErrInvalidSize)
+	_, err = ParseID("0g", 1)
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+}
+
+func TestParseBech32ID(t *testing.T) {
+	nid := ID{0, 1, 0xAB, 0xFF}
+	s, err := nid.Bech32("ns")
+	require.NoError(t, err)
+	got, err := ParseBech32ID(s, "ns", 4)
+	require.NoError(t, err)
+	assert.Equal(t, nid, got)
+
+	_, err = ParseBech32ID(s, "other", 4)
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+	_, err = ParseBech32ID(s, "ns", 8)
+	assert.ErrorIs(t, err, ErrInvalidSize)
+	_, err = ParseBech32ID(s[:len(s)-1]+"q", "ns", 4)
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+}
+
+func TestIDProto(t *testing.T) {
+	nid := ID{0, 1, 0xAB, 0xFF}
+	protoID := nid.ToProto()
+	data, err := protoID.Marshal()
+	require.NoError(t, err)
+	var decoded pb.NamespaceID
+	require.NoError(t, decoded.Unmarshal(data))
+	got, err := ProtoToID(decoded, 4)
+	require.NoError(t, err)
+	assert.Equal(t, nid, got)
+
+	_, err = ProtoToID(decoded, 3)
+	assert.ErrorIs(t, err, ErrInvalidSize)
+}
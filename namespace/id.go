@@ -2,13 +2,96 @@ package namespace
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
+	"errors"
+	"fmt"
 )
 
+// ErrValueOutOfRange indicates that an integer does not fit into a namespace
+// ID of the requested size, or vice versa.
+var ErrValueOutOfRange = errors.New("value out of range")
+
 type ID []byte
 
+// FromUint64 returns the big-endian encoding of v as a namespace ID of the
+// given size. IDs larger than 8 bytes are left-padded with zeros. The numeric
+// order of the encoded values matches the order of the resulting IDs, i.e.,
+// FromUint64(a, size).Less(FromUint64(b, size)) iff a < b. FromUint64 returns
+// an ErrValueOutOfRange error if v does not fit into size bytes.
+func FromUint64(v uint64, size IDSize) (ID, error) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	if size < 8 {
+		for _, b := range buf[:8-size] {
+			if b != 0 {
+				return nil, fmt.Errorf("%w: %d does not fit into %d bytes", ErrValueOutOfRange, v, size)
+			}
+		}
+		nid := make(ID, size)
+		copy(nid, buf[8-size:])
+		return nid, nil
+	}
+	nid := make(ID, size)
+	copy(nid[size-8:], buf[:])
+	return nid, nil
+}
+
+// Uint64 interprets nid as a big-endian unsigned integer; it is the inverse of
+// FromUint64. It returns an ErrValueOutOfRange error if the value does not fit
+// into an uint64, i.e., if nid is larger than 8 bytes and any of its leading
+// bytes is non-zero.
+func (nid ID) Uint64() (uint64, error) {
+	if len(nid) > 8 {
+		for _, b := range nid[:len(nid)-8] {
+			if b != 0 {
+				return 0, fmt.Errorf("%w: %s does not fit into an uint64", ErrValueOutOfRange, nid)
+			}
+		}
+		return binary.BigEndian.Uint64(nid[len(nid)-8:]), nil
+	}
+	var buf [8]byte
+	copy(buf[8-len(nid):], nid)
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+// Next returns the successor of nid, i.e., the smallest ID of the same size
+// that is larger than nid, such as the exclusive upper bound of a range ending
+// at nid. nid is not modified. Next returns an ErrValueOutOfRange error if nid
+// is the maximum ID of its size, i.e., consists of 0xFF bytes only.
+func (nid ID) Next() (ID, error) {
+	next := make(ID, len(nid))
+	copy(next, nid)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			return next, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s has no successor", ErrValueOutOfRange, nid)
+}
+
+// Prev returns the predecessor of nid, i.e., the largest ID of the same size
+// that is smaller than nid. nid is not modified. Prev returns an
+// ErrValueOutOfRange error if nid is the minimum ID of its size, i.e.,
+// consists of zero bytes only.
+func (nid ID) Prev() (ID, error) {
+	prev := make(ID, len(nid))
+	copy(prev, nid)
+	for i := len(prev) - 1; i >= 0; i-- {
+		prev[i]--
+		if prev[i] != 0xFF {
+			return prev, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s has no predecessor", ErrValueOutOfRange, nid)
+}
+
 // Less returns true if nid < other, otherwise, false.
 func (nid ID) Less(other ID) bool {
+	if len(nid) == 8 && len(other) == 8 {
+		return binary.BigEndian.Uint64(nid) < binary.BigEndian.Uint64(other)
+	}
 	return bytes.Compare(nid, other) < 0
 }
 
@@ -19,6 +102,9 @@ func (nid ID) Equal(other ID) bool {
 
 // LessOrEqual returns true if nid <= other, otherwise, false.
 func (nid ID) LessOrEqual(other ID) bool {
+	if len(nid) == 8 && len(other) == 8 {
+		return binary.BigEndian.Uint64(nid) <= binary.BigEndian.Uint64(other)
+	}
 	return bytes.Compare(nid, other) <= 0
 }
 
@@ -28,7 +114,21 @@ func (nid ID) Size() IDSize {
 }
 
 // String returns the hexadecimal encoding of the nid. The output of
-// nid.String() is not equivalent to string(nid).
+// nid.String() is not equivalent to string(nid). It is the format accepted by
+// ParseID; use Abbrev for a shorter representation in logs.
 func (nid ID) String() string {
 	return hex.EncodeToString(nid)
 }
+
+// abbrevBytes is the number of leading and trailing bytes kept by Abbrev.
+const abbrevBytes = 4
+
+// Abbrev returns the hexadecimal encoding of the nid like String, but
+// abbreviated to its first and last 4 bytes, separated by "...", if the nid is
+// longer than 8 bytes, e.g., for logs and error messages.
+func (nid ID) Abbrev() string {
+	if len(nid) <= 2*abbrevBytes {
+		return nid.String()
+	}
+	return hex.EncodeToString(nid[:abbrevBytes]) + "..." + hex.EncodeToString(nid[len(nid)-abbrevBytes:])
+}
@@ -1,9 +1,13 @@
 package namespace
 
 import (
+	"bytes"
+	"fmt"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestString verifies that id.String() returns the hexadecimal encoding of id.
@@ -24,6 +28,13 @@ func TestString(t *testing.T) {
 	}
 }
 
+func TestAbbrev(t *testing.T) {
+	assert.Equal(t, "", ID{}.Abbrev())
+	assert.Equal(t, "0102030405060708", ID{1, 2, 3, 4, 5, 6, 7, 8}.Abbrev())
+	assert.Equal(t, "01020304...06070809", ID{1, 2, 3, 4, 5, 6, 7, 8, 9}.Abbrev())
+	assert.Equal(t, "00000000...000000ff", ID(append(make([]byte, 28), 0xFF)).Abbrev())
+}
+
 // Test_string verifies that string(id) returns the native string representation of id.
 func Test_string(t *testing.T) {
 	type testCase struct {
@@ -41,3 +52,131 @@ func Test_string(t *testing.T) {
 		assert.Equal(t, tc.want, string(tc.id))
 	}
 }
+
+func TestFromUint64(t *testing.T) {
+	testCases := []struct {
+		v       uint64
+		size    IDSize
+		want    ID
+		wantErr bool
+	}{
+		{0, 0, ID{}, false},
+		{1, 0, nil, true},
+		{0x0102, 2, ID{1, 2}, false},
+		{0x010203, 2, nil, true},
+		{0x0102030405060708, 8, ID{1, 2, 3, 4, 5, 6, 7, 8}, false},
+		{math.MaxUint64, 8, ID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, false},
+		{0x0102, 10, ID{0, 0, 0, 0, 0, 0, 0, 0, 1, 2}, false},
+	}
+	for _, tc := range testCases {
+		got, err := FromUint64(tc.v, tc.size)
+		if tc.wantErr {
+			assert.ErrorIs(t, err, ErrValueOutOfRange)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got)
+		back, err := got.Uint64()
+		require.NoError(t, err)
+		assert.Equal(t, tc.v, back)
+	}
+}
+
+func TestFromUint64_Order(t *testing.T) {
+	values := []uint64{0, 1, 255, 256, 65535, 1 << 40, math.MaxUint64}
+	for _, size := range []IDSize{8, 29} {
+		for i := 1; i < len(values); i++ {
+			prev, err := FromUint64(values[i-1], size)
+			require.NoError(t, err)
+			cur, err := FromUint64(values[i], size)
+			require.NoError(t, err)
+			assert.True(t, prev.Less(cur))
+		}
+	}
+}
+
+func TestUint64_OutOfRange(t *testing.T) {
+	_, err := ID{1, 0, 0, 0, 0, 0, 0, 0, 0}.Uint64()
+	assert.ErrorIs(t, err, ErrValueOutOfRange)
+}
+
+func TestNextPrev(t *testing.T) {
+	testCases := []struct {
+		nid  ID
+		next ID
+	}{
+		{ID{0}, ID{1}},
+		{ID{0, 0xFE}, ID{0, 0xFF}},
+		{ID{0, 0xFF}, ID{1, 0}},
+		{ID{1, 0xFF, 0xFF}, ID{2, 0, 0}},
+		{ID{0, 0, 0, 0, 0, 0, 0, 0xFF, 0xFF}, ID{0, 0, 0, 0, 0, 0, 1, 0, 0}},
+	}
+	for _, tc := range testCases {
+		orig := append(ID(nil), tc.nid...)
+		next, err := tc.nid.Next()
+		require.NoError(t, err)
+		assert.Equal(t, tc.next, next)
+		assert.True(t, tc.nid.Less(next))
+		prev, err := next.Prev()
+		require.NoError(t, err)
+		assert.Equal(t, tc.nid, prev)
+		// the receivers are not modified
+		assert.Equal(t, orig, tc.nid)
+	}
+
+	// the successor of v is v+1
+	for _, v := range []uint64{0, 255, 256, 1<<40 - 1} {
+		nid, err := FromUint64(v, 8)
+		require.NoError(t, err)
+		next, err := nid.Next()
+		require.NoError(t, err)
+		got, err := next.Uint64()
+		require.NoError(t, err)
+		assert.Equal(t, v+1, got)
+	}
+}
+
+func TestNextPrev_OutOfRange(t *testing.T) {
+	for _, nid := range []ID{{}, {0xFF}, {0xFF, 0xFF, 0xFF}} {
+		_, err := nid.Next()
+		assert.ErrorIs(t, err, ErrValueOutOfRange, "%x", nid)
+	}
+	for _, nid := range []ID{{}, {0}, {0, 0, 0}} {
+		_, err := nid.Prev()
+		assert.ErrorIs(t, err, ErrValueOutOfRange, "%x", nid)
+	}
+}
+
+func TestLess(t *testing.T) {
+	ids := []ID{
+		{0, 0, 0, 0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0, 0, 0, 1},
+		{0, 0, 0, 0, 0, 0, 1, 0},
+		{0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+		{0x80, 0, 0, 0, 0, 0, 0, 0},
+		{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+		// IDs of other sizes are compared lexicographically
+		{0, 1},
+		{0, 0, 0, 0, 0, 0, 0, 0, 1},
+	}
+	for _, a := range ids {
+		for _, b := range ids {
+			assert.Equal(t, bytes.Compare(a, b) < 0, a.Less(b), "%x < %x", a, b)
+			assert.Equal(t, bytes.Compare(a, b) <= 0, a.LessOrEqual(b), "%x <= %x", a, b)
+		}
+	}
+}
+
+func BenchmarkLess(b *testing.B) {
+	for _, size := range []int{8, 29} {
+		x, y := make(ID, size), make(ID, size)
+		x[size-1], y[size-1] = 1, 2
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if !x.Less(y) {
+					b.Fatal("wrong order")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package namespace
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnsupportedVersion indicates that the version byte of a namespace ID
+	// is not part of the configured VersionRules.
+	ErrUnsupportedVersion = errors.New("unsupported namespace version")
+	// ErrInvalidVersionedID indicates that a namespace ID violates the rules of
+	// its version.
+	ErrInvalidVersionedID = errors.New("invalid versioned namespace ID")
+)
+
+// VersionSize is the size of the version byte leading a versioned namespace
+// ID.
+const VersionSize = 1
+
+// VersionValidator validates the part of a versioned namespace ID that follows
+// the version byte.
+type VersionValidator func(id []byte) error
+
+// VersionRules maps the supported namespace versions to the validation rules
+// of the IDs of that version. A nil VersionValidator accepts any ID.
+type VersionRules map[byte]VersionValidator
+
+// Validate checks that nid starts with a supported version byte and that the
+// remainder of nid satisfies the rules of that version. It returns an
+// ErrUnsupportedVersion or ErrInvalidVersionedID error otherwise.
+func (r VersionRules) Validate(nid ID) error {
+	if len(nid) < VersionSize {
+		return fmt.Errorf("%w: namespace ID %x has no version byte", ErrInvalidVersionedID, nid)
+	}
+	validate, found := r[nid.Version()]
+	if !found {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, nid.Version())
+	}
+	if validate == nil {
+		return nil
+	}
+	if err := validate(nid[VersionSize:]); err != nil {
+		return fmt.Errorf("%w: version %d: %v", ErrInvalidVersionedID, nid.Version(), err)
+	}
+	return nil
+}
+
+// RequireLeadingZeros returns a VersionValidator requiring the first n bytes
+// of an ID to be zero, as for instance version 0 namespaces do to reserve
+// space for future versions.
+func RequireLeadingZeros(n int) VersionValidator {
+	return func(id []byte) error {
+		if len(id) < n {
+			return fmt.Errorf("ID of %d bytes cannot have %d leading zero bytes", len(id), n)
+		}
+		for i, b := range id[:n] {
+			if b != 0 {
+				return fmt.Errorf("byte %d of the ID is %#x, expected %d leading zero bytes", i, b, n)
+			}
+		}
+		return nil
+	}
+}
+
+// Version returns the version byte of a versioned namespace ID, i.e., its
+// first byte. It returns 0 for an empty ID.
+func (nid ID) Version() byte {
+	if len(nid) == 0 {
+		return 0
+	}
+	return nid[0]
+}
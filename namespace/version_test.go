@@ -0,0 +1,43 @@
+package namespace
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionRules_Validate(t *testing.T) {
+	rules := VersionRules{
+		0:    RequireLeadingZeros(2),
+		0xFF: nil,
+	}
+	tests := []struct {
+		name    string
+		nid     ID
+		wantErr error
+	}{
+		{"valid v0", ID{0, 0, 0, 1, 2}, nil},
+		{"v0 with too few leading zeros", ID{0, 0, 1, 1, 2}, ErrInvalidVersionedID},
+		{"v0 too short", ID{0, 0}, ErrInvalidVersionedID},
+		{"unrestricted version", ID{0xFF, 1, 2, 3, 4}, nil},
+		{"unsupported version", ID{1, 0, 0, 0, 0}, ErrUnsupportedVersion},
+		{"empty ID", ID{}, ErrInvalidVersionedID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rules.Validate(tt.nid)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestID_Version(t *testing.T) {
+	assert.Equal(t, byte(7), ID{7, 1}.Version())
+	assert.Equal(t, byte(0), ID{}.Version())
+	assert.Equal(t, byte(0xFF), ID(bytes.Repeat([]byte{0xFF}, 29)).Version())
+}
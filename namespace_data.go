@@ -0,0 +1,45 @@
+package nmt
+
+import (
+	"hash"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// NamespaceData bundles all leaves of a namespace with the proof of their
+// inclusion and completeness, e.g., to answer "give me namespace X with proof"
+// requests.
+type NamespaceData struct {
+	// Namespace is the queried namespace ID.
+	Namespace namespace.ID
+	// Leaves holds the namespace-prefixed leaves of Namespace; it is empty if
+	// the tree does not contain the namespace.
+	Leaves [][]byte
+	// Proof is the namespace proof of Leaves, see ProveNamespace.
+	Proof Proof
+}
+
+// GetNamespaceData returns the leaves of the namespace nID together with their
+// namespace proof. The leaves are taken from the range of the proof, hence the
+// namespace is only looked up once. It returns an ErrLeafDataDropped error if
+// the tree drops leaf data.
+func (n *NamespacedMerkleTree) GetNamespaceData(nID namespace.ID) (NamespaceData, error) {
+	if err := n.checkLeafData(); err != nil {
+		return NamespaceData{}, err
+	}
+	proof, err := n.ProveNamespace(nID)
+	if err != nil {
+		return NamespaceData{}, err
+	}
+	leaves := n.leaves[:0:0]
+	if !proof.IsOfAbsence() && !proof.IsEmptyProof() {
+		leaves = n.leaves[proof.Start():proof.End()]
+	}
+	return NamespaceData{Namespace: nID, Leaves: leaves, Proof: proof}, nil
+}
+
+// Verify checks that d holds all leaves of d.Namespace in the tree with the
+// given root, see Proof.VerifyNamespace.
+func (d NamespaceData) Verify(h hash.Hash, root []byte) bool {
+	return d.Proof.VerifyNamespace(h, d.Namespace, d.Leaves, root)
+}
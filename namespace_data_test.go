@@ -0,0 +1,31 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestGetNamespaceData(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 1, 3, 5)
+	root, err := tree.Root()
+	require.NoError(t, err)
+
+	for _, nID := range []namespace.ID{{0}, {1}, {2}, {3}, {5}, {6}} {
+		d, err := tree.GetNamespaceData(nID)
+		require.NoError(t, err)
+		assert.Equal(t, nID, d.Namespace)
+		assert.Equal(t, tree.Get(nID), d.Leaves, "namespace %x", nID)
+		proof, err := tree.ProveNamespace(nID)
+		require.NoError(t, err)
+		assert.Equal(t, proof, d.Proof)
+		assert.True(t, d.Verify(sha256.New(), root), "namespace %x", nID)
+	}
+
+	_, err = tree.GetNamespaceData(namespace.ID{1, 1})
+	assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+}
@@ -0,0 +1,127 @@
+package nmt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// GetNamespaceRange returns the leaves whose namespace ID falls within
+// [nIDStart, nIDEnd] (both inclusive), in the order of their index in the
+// tree.
+func (n *NamespacedMerkleTree) GetNamespaceRange(nIDStart, nIDEnd namespace.ID) [][]byte {
+	start, end := n.namespaceRangeBounds(nIDStart, nIDEnd)
+	return n.leaves[start:end]
+}
+
+// ProveNamespaceRange returns a proof for all leaves whose namespace ID falls
+// within [nIDStart, nIDEnd] (both inclusive). The returned proof can be
+// verified using Proof.VerifyNamespaceRange. It follows the semantics of
+// ProveNamespace:
+//
+// case 1) If the namespace range does not overlap with the tree's min and max
+// namespace, an empty proof is returned.
+//
+// case 2) If the namespace range overlaps with the tree's min and max namespace
+// but no leaf falls within it, an absence proof for the first leaf with a
+// namespace ID larger than nIDEnd is returned.
+//
+// case 3) Otherwise, an inclusion proof for the range of leaves [start, end)
+// falling within the namespace range is returned.
+//
+// ProveNamespaceRange returns an ErrInvalidRange error if nIDEnd < nIDStart,
+// and an ErrMismatchedNamespaceSize error if the size of either namespace ID
+// does not match the tree's namespace size.
+func (n *NamespacedMerkleTree) ProveNamespaceRange(nIDStart, nIDEnd namespace.ID) (Proof, error) {
+	isMaxNsIgnored := n.treeHasher.IsMaxNamespaceIDIgnored()
+	for _, nID := range []namespace.ID{nIDStart, nIDEnd} {
+		if err := n.validateNamespaceSize(nID); err != nil {
+			return Proof{}, err
+		}
+	}
+	if nIDEnd.Less(nIDStart) {
+		return Proof{}, fmt.Errorf("namespace range end %x is smaller than its start %x: %w", nIDEnd, nIDStart, ErrInvalidRange)
+	}
+	if n.sizeWithPadding() == 0 {
+		return NewEmptyRangeProof(isMaxNsIgnored), nil
+	}
+
+	root, err := n.Root()
+	if err != nil {
+		return Proof{}, fmt.Errorf("failed to get root: %w", err)
+	}
+	treeMinNs := namespace.ID(MinNamespace(root, n.NamespaceSize()))
+	treeMaxNs := namespace.ID(MaxNamespace(root, n.NamespaceSize()))
+
+	// case 1)
+	if nIDEnd.Less(treeMinNs) || treeMaxNs.Less(nIDStart) {
+		return NewEmptyRangeProof(isMaxNsIgnored), nil
+	}
+
+	proofStart, proofEnd := n.namespaceRangeBounds(nIDStart, nIDEnd)
+	found := proofStart < proofEnd
+	// case 2) proofStart points to the first leaf with a namespace ID larger
+	// than nIDEnd
+	if !found {
+		proofEnd = proofStart + 1
+	}
+
+	proof, err := n.buildRangeProof(proofStart, proofEnd)
+	if err != nil {
+		return Proof{}, err
+	}
+	// case 3)
+	if found {
+		return NewInclusionProof(proofStart, proofEnd, proof, isMaxNsIgnored), nil
+	}
+	leafHash, err := n.subtreeRoot(proofStart, proofEnd)
+	if err != nil {
+		return Proof{}, err
+	}
+	return NewAbsenceProof(proofStart, proofEnd, proof, leafHash, isMaxNsIgnored), nil
+}
+
+// LocateNamespace returns the range of leaves [Start, End) of namespace nID
+// using two binary searches over the leaves, which are sorted by namespace ID.
+// If the tree contains no leaf of namespace nID, found is false and Start and
+// End both equal the index at which a leaf of namespace nID would have been
+// inserted, i.e., the index of the first leaf with a larger namespace ID.
+func (n *NamespacedMerkleTree) LocateNamespace(nID namespace.ID) (rng LeafRange, found bool) {
+	start, end := n.namespaceRangeBounds(nID, nID)
+	return LeafRange{Start: start, End: end}, start < end
+}
+
+// namespaceRangeBounds returns the range of leaves [start, end) whose
+// namespace ID falls within [nIDStart, nIDEnd]. If there is no such leaf,
+// start equals end and points to the first leaf with a namespace ID larger
+// than nIDEnd.
+func (n *NamespacedMerkleTree) namespaceRangeBounds(nIDStart, nIDEnd namespace.ID) (start, end int) {
+	nidSize := n.NamespaceSize()
+	if nidSize == 8 && len(nIDStart) == 8 && len(nIDEnd) == 8 {
+		return n.namespaceRangeBounds8(binary.BigEndian.Uint64(nIDStart), binary.BigEndian.Uint64(nIDEnd))
+	}
+	start = sort.Search(len(n.leafHashes), func(i int) bool {
+		return nIDStart.LessOrEqual(n.leafHashes[i][:nidSize])
+	})
+	end = sort.Search(len(n.leafHashes), func(i int) bool {
+		return nIDEnd.Less(n.leafHashes[i][:nidSize])
+	})
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+// namespaceRangeBounds8 is namespaceRangeBounds for trees with namespace IDs
+// of 8 bytes, which are compared as integers.
+func (n *NamespacedMerkleTree) namespaceRangeBounds8(nIDStart, nIDEnd uint64) (start, end int) {
+	start = sort.Search(len(n.leafHashes), func(i int) bool {
+		return nIDStart <= binary.BigEndian.Uint64(n.leafHashes[i])
+	})
+	end = sort.Search(len(n.leafHashes), func(i int) bool {
+		return nIDEnd < binary.BigEndian.Uint64(n.leafHashes[i])
+	})
+	return start, maxInt(start, end)
+}
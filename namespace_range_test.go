@@ -0,0 +1,121 @@
+package nmt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestProveNamespaceRange(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 2, 4, 5, 5, 8, 9)
+	root, err := tree.Root()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name               string
+		nIDStart, nIDEnd   namespace.ID
+		wantStart, wantEnd int
+		wantAbsence        bool
+		wantLeaves         int
+	}{
+		{"single namespace", namespace.ID{2}, namespace.ID{2}, 1, 3, false, 2},
+		{"several namespaces", namespace.ID{2}, namespace.ID{5}, 1, 6, false, 5},
+		{"bounds absent from the tree", namespace.ID{3}, namespace.ID{7}, 3, 6, false, 3},
+		{"whole tree", namespace.ID{0}, namespace.ID{0xFF}, 0, 8, false, 8},
+		{"gap within the tree", namespace.ID{6}, namespace.ID{7}, 6, 7, true, 0},
+		{"below the tree", namespace.ID{0}, namespace.ID{0}, 0, 0, false, 0},
+		{"above the tree", namespace.ID{10}, namespace.ID{20}, 0, 0, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof, err := tree.ProveNamespaceRange(tt.nIDStart, tt.nIDEnd)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStart, proof.Start())
+			assert.Equal(t, tt.wantEnd, proof.End())
+			assert.Equal(t, tt.wantAbsence, proof.IsOfAbsence())
+
+			leaves := tree.GetNamespaceRange(tt.nIDStart, tt.nIDEnd)
+			assert.Len(t, leaves, tt.wantLeaves)
+			assert.True(t, proof.VerifyNamespaceRange(sha256.New(), tt.nIDStart, tt.nIDEnd, leaves, root))
+
+			// omitting a leaf must fail the verification
+			if len(leaves) > 1 {
+				assert.False(t, proof.VerifyNamespaceRange(sha256.New(), tt.nIDStart, tt.nIDEnd, leaves[1:], root))
+			}
+			// a wider range than proven must fail the completeness check
+			if !proof.IsEmptyProof() && tt.nIDStart[0] > 0 {
+				wider := namespace.ID{tt.nIDStart[0] - 1}
+				if len(tree.GetNamespaceRange(wider, tt.nIDEnd)) != len(leaves) {
+					assert.False(t, proof.VerifyNamespaceRange(sha256.New(), wider, tt.nIDEnd, leaves, root))
+				}
+			}
+		})
+	}
+}
+
+func TestProveNamespaceRange_Errors(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 3)
+	_, err := tree.ProveNamespaceRange(namespace.ID{3}, namespace.ID{2})
+	assert.ErrorIs(t, err, ErrInvalidRange)
+	_, err = tree.ProveNamespaceRange(namespace.ID{1, 0}, namespace.ID{2, 0})
+	assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+
+	proof, err := tree.ProveNamespaceRange(namespace.ID{1}, namespace.ID{2})
+	require.NoError(t, err)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	leaves := tree.GetNamespaceRange(namespace.ID{1}, namespace.ID{2})
+	assert.False(t, proof.VerifyNamespaceRange(sha256.New(), namespace.ID{2}, namespace.ID{1}, leaves, root))
+}
+
+func TestProveNamespaceRange_EmptyTree(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1))
+	proof, err := tree.ProveNamespaceRange(namespace.ID{0}, namespace.ID{5})
+	require.NoError(t, err)
+	assert.True(t, proof.IsEmptyProof())
+	root, err := tree.Root()
+	require.NoError(t, err)
+	assert.True(t, proof.VerifyNamespaceRange(sha256.New(), namespace.ID{0}, namespace.ID{5}, nil, root))
+}
+
+func TestLocateNamespace(t *testing.T) {
+	tests := []struct {
+		nID       byte
+		wantRange LeafRange
+		wantFound bool
+	}{
+		{0, LeafRange{Start: 0, End: 0}, false},
+		{1, LeafRange{Start: 0, End: 2}, true},
+		{2, LeafRange{Start: 2, End: 2}, false},
+		{3, LeafRange{Start: 2, End: 3}, true},
+		{5, LeafRange{Start: 3, End: 6}, true},
+		{7, LeafRange{Start: 6, End: 6}, false},
+		{8, LeafRange{Start: 6, End: 7}, true},
+		{9, LeafRange{Start: 7, End: 7}, false},
+	}
+	// namespace IDs of 8 bytes are compared as integers
+	for _, nidSize := range []int{1, 8} {
+		tree := exampleNMT(nidSize, true, 1, 1, 3, 5, 5, 5, 8)
+		for _, tt := range tests {
+			nID := namespace.ID(bytes.Repeat([]byte{tt.nID}, nidSize))
+			rng, found := tree.LocateNamespace(nID)
+			assert.Equal(t, tt.wantFound, found, "namespace %x", nID)
+			assert.Equal(t, tt.wantRange, rng, "namespace %x", nID)
+			if found {
+				assert.Equal(t, tree.Get(nID), tree.leaves[rng.Start:rng.End])
+			}
+		}
+	}
+}
+
+func TestCalculateAbsenceIndex(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 3, 3, 5, 8)
+	assert.Equal(t, 1, tree.calculateAbsenceIndex(namespace.ID{2}))
+	assert.Equal(t, 3, tree.calculateAbsenceIndex(namespace.ID{4}))
+	assert.Equal(t, 4, tree.calculateAbsenceIndex(namespace.ID{6}))
+}
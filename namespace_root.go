@@ -0,0 +1,148 @@
+package nmt
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ErrForeignLeaf indicates that a leaf does not belong to the namespace it is
+// supposed to be part of.
+var ErrForeignLeaf = errors.New("leaf does not belong to the namespace")
+
+// NamespaceRoot returns the namespace root of nID, i.e., the root of the
+// namespaced Merkle tree, computed with the tree's hasher, whose leaves are
+// exactly the leaves of nID in the order they were pushed. Unlike the tree
+// root, the namespace root depends neither on the leaves of other namespaces
+// nor on the position of the namespace within the tree, and its namespace
+// range is [nID, nID]. Namespace roots are never padded.
+//
+// The namespace root relates to the tree root through the leaves of the
+// namespace: after verifying the namespace proof of nID (see ProveNamespace)
+// against the tree root, a verifier can recompute the namespace root from the
+// proven leaves with ComputeNamespaceRoot. If the leaves of nID make up a
+// single inner node of the tree, the namespace root is that node, see
+// SubtreeRoot.
+//
+// NamespaceRoot returns an ErrNamespaceNotFound error if the tree has no
+// leaves of nID and an ErrMismatchedNamespaceSize error if the size of nID
+// does not match the tree's namespace size.
+func (n *NamespacedMerkleTree) NamespaceRoot(nID namespace.ID) ([]byte, error) {
+	tree, err := n.namespaceTree(nID)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Root()
+}
+
+// NamespaceRoots returns the namespace root (see NamespaceRoot) of every
+// namespace present in the tree, keyed by the string representation of the
+// namespace ID.
+func (n *NamespacedMerkleTree) NamespaceRoots() (map[string][]byte, error) {
+	if err := n.hashPendingLeaves(); err != nil {
+		return nil, err
+	}
+	roots := make(map[string][]byte, len(n.namespaceRanges))
+	for nID, rng := range n.Namespaces() {
+		root, err := n.namespaceSubtree(nID, rng).Root()
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute the root of namespace %x: %w", nID, err)
+		}
+		roots[string(nID)] = root
+	}
+	return roots, nil
+}
+
+// ComputeNamespaceRoot computes the namespace root of nID (see
+// NamespacedMerkleTree.NamespaceRoot) from all of its namespace-prefixed
+// leaves, e.g., after verifying them with Proof.VerifyNamespace. `h` and
+// ignoreMaxNamespace MUST match the configuration of the tree. It returns an
+// ErrForeignLeaf error if a leaf is not prefixed with nID and an
+// ErrNamespaceNotFound error if there are no leaves.
+func ComputeNamespaceRoot(h hash.Hash, nID namespace.ID, leaves [][]byte, ignoreMaxNamespace bool) ([]byte, error) {
+	if len(leaves) == 0 {
+		return nil, fmt.Errorf("namespace %x has no leaves: %w", nID, ErrNamespaceNotFound)
+	}
+	computer := NewRootComputer(h, NamespaceIDSize(int(nID.Size())), IgnoreMaxNamespace(ignoreMaxNamespace))
+	for i, leaf := range leaves {
+		if len(leaf) < int(nID.Size()) || !nID.Equal(leaf[:nID.Size()]) {
+			return nil, fmt.Errorf("leaf %d of namespace %x: %w", i, nID, ErrForeignLeaf)
+		}
+		if err := computer.Push(leaf); err != nil {
+			return nil, err
+		}
+	}
+	return computer.Root()
+}
+
+// ProveInNamespace returns an inclusion proof of the offset-th leaf (starting
+// from 0) of namespace nID against the namespace root of nID (see
+// NamespaceRoot). The range of the proof is [offset, offset+1), i.e., it is
+// relative to the first leaf of the namespace, and the proof can be verified
+// with VerifyNamespaceRootInclusion without knowing the tree root. It returns
+// an ErrNamespaceNotFound error if the tree has no leaves of nID, an
+// ErrInvalidRange error if offset exceeds the number of leaves of the
+// namespace and an ErrMismatchedNamespaceSize error if the size of nID does
+// not match the tree's namespace size.
+func (n *NamespacedMerkleTree) ProveInNamespace(nID namespace.ID, offset int) (Proof, error) {
+	tree, err := n.namespaceTree(nID)
+	if err != nil {
+		return Proof{}, err
+	}
+	if offset < 0 || offset >= tree.Size() {
+		return Proof{}, fmt.Errorf("offset %d is out of the namespace range [0, %d): %w", offset, tree.Size(), ErrInvalidRange)
+	}
+	return tree.Prove(offset)
+}
+
+// VerifyNamespaceRootInclusion checks that leafWithoutNamespace, prefixed with
+// nID, is included in the namespace with the namespace root namespaceRoot at
+// the position given by the proof, see ProveInNamespace. In addition to
+// VerifyInclusion, it checks that the namespace range of namespaceRoot is
+// [nID, nID], so that the proof does not verify against roots covering other
+// namespaces, such as the tree root. `h` MUST be the same as the underlying
+// hash function used to generate the proof.
+func (proof Proof) VerifyNamespaceRootInclusion(h hash.Hash, nID namespace.ID, leafWithoutNamespace, namespaceRoot []byte) bool {
+	nidSize := nID.Size()
+	if len(namespaceRoot) < 2*int(nidSize) {
+		return false
+	}
+	if !nID.Equal(minNamespaceView(namespaceRoot, nidSize)) || !nID.Equal(maxNamespaceView(namespaceRoot, nidSize)) {
+		return false
+	}
+	return proof.VerifyInclusion(h, nID, [][]byte{leafWithoutNamespace}, namespaceRoot)
+}
+
+// namespaceTree returns the tree consisting of the leaves of nID, see
+// namespaceSubtree.
+func (n *NamespacedMerkleTree) namespaceTree(nID namespace.ID) (*NamespacedMerkleTree, error) {
+	if err := n.validateNamespaceSize(nID); err != nil {
+		return nil, err
+	}
+	found, start, end := n.foundInRange(nID)
+	if !found {
+		return nil, fmt.Errorf("namespace %x: %w", nID, ErrNamespaceNotFound)
+	}
+	if err := n.hashPendingLeaves(); err != nil {
+		return nil, err
+	}
+	return n.namespaceSubtree(nID, LeafRange{Start: start, End: end}), nil
+}
+
+// namespaceSubtree returns a tree consisting of the leaves of nID in rng,
+// which shares the leaves and the hasher with n. The leaves in rng must have
+// been hashed. The tree must not be modified and is only valid until n is.
+func (n *NamespacedMerkleTree) namespaceSubtree(nID namespace.ID, rng LeafRange) *NamespacedMerkleTree {
+	return &NamespacedMerkleTree{
+		treeHasher:      n.treeHasher,
+		metrics:         NoopMetrics{},
+		codec:           n.codec,
+		leaves:          n.leaves[rng.Start:rng.End:rng.End],
+		leafHashes:      n.leafHashes[rng.Start:rng.End:rng.End],
+		namespaceRanges: map[string]LeafRange{string(nID): {Start: 0, End: rng.End - rng.Start}},
+		minNID:          nID,
+		maxNID:          nID,
+	}
+}
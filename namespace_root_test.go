@@ -0,0 +1,129 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestNamespaceRoot(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 2, 2, 3, 3, 3, 3, 5)
+	roots, err := tree.NamespaceRoots()
+	require.NoError(t, err)
+	assert.Len(t, roots, 4)
+
+	for nID := range tree.Namespaces() {
+		leaves := tree.Get(nID)
+		// the namespace root is the root of a tree of the namespace's leaves
+		nsTree := New(sha256.New(), NamespaceIDSize(1))
+		for _, leaf := range leaves {
+			require.NoError(t, nsTree.Push(leaf))
+		}
+		want, err := nsTree.Root()
+		require.NoError(t, err)
+
+		got, err := tree.NamespaceRoot(nID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, want, roots[string(nID)])
+		assert.Equal(t, []byte(nID), MinNamespace(got, 1))
+		assert.Equal(t, []byte(nID), MaxNamespace(got, 1))
+
+		computed, err := ComputeNamespaceRoot(sha256.New(), nID, leaves, true)
+		require.NoError(t, err)
+		assert.Equal(t, want, computed)
+	}
+
+	// namespace 3 covers the aligned subtree [4, 8)
+	subtreeRoot, err := tree.SubtreeRoot(4, 8)
+	require.NoError(t, err)
+	assert.Equal(t, subtreeRoot, roots[string([]byte{3})])
+}
+
+func TestNamespaceRoot_IndependentOfNeighbors(t *testing.T) {
+	before, err := exampleNMT(1, true, 2, 2, 2).NamespaceRoot(namespace.ID{2})
+	require.NoError(t, err)
+
+	// exampleNMT numbers leaves by their index in the tree, hence the leaves
+	// of namespace 2 are rebuilt with the same data
+	tree := exampleNMT(1, true, 1, 1, 1)
+	leaves := exampleNMT(1, true, 2, 2, 2).Get(namespace.ID{2})
+	for _, leaf := range leaves {
+		require.NoError(t, tree.Push(leaf))
+	}
+	require.NoError(t, tree.Push([]byte{3, 'x'}))
+	after, err := tree.NamespaceRoot(namespace.ID{2})
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	root, err := tree.Root()
+	require.NoError(t, err)
+	assert.NotEqual(t, root, after)
+}
+
+func TestNamespaceRoot_Errors(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 3)
+	_, err := tree.NamespaceRoot(namespace.ID{4})
+	assert.ErrorIs(t, err, ErrNamespaceNotFound)
+	_, err = tree.NamespaceRoot(namespace.ID{1, 1})
+	assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+
+	roots, err := New(sha256.New()).NamespaceRoots()
+	require.NoError(t, err)
+	assert.Empty(t, roots)
+
+	_, err = ComputeNamespaceRoot(sha256.New(), namespace.ID{1}, nil, true)
+	assert.ErrorIs(t, err, ErrNamespaceNotFound)
+	_, err = ComputeNamespaceRoot(sha256.New(), namespace.ID{1}, [][]byte{{1, 'a'}, {2, 'b'}}, true)
+	assert.ErrorIs(t, err, ErrForeignLeaf)
+	_, err = ComputeNamespaceRoot(sha256.New(), namespace.ID{1, 1}, [][]byte{{1}}, true)
+	assert.ErrorIs(t, err, ErrForeignLeaf)
+}
+
+func TestProveInNamespace(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 2, 2, 3, 3, 3, 3, 5)
+	for nID, rng := range tree.Namespaces() {
+		nsRoot, err := tree.NamespaceRoot(nID)
+		require.NoError(t, err)
+		for offset := 0; offset < rng.End-rng.Start; offset++ {
+			proof, err := tree.ProveInNamespace(nID, offset)
+			require.NoError(t, err)
+			assert.Equal(t, offset, proof.Start())
+			assert.Equal(t, offset+1, proof.End())
+
+			leaf := tree.leaves[rng.Start+offset]
+			assert.True(t, proof.VerifyNamespaceRootInclusion(sha256.New(), nID, leaf[1:], nsRoot))
+			assert.False(t, proof.VerifyNamespaceRootInclusion(sha256.New(), nID, []byte("other"), nsRoot))
+		}
+	}
+}
+
+func TestVerifyNamespaceRootInclusion_RejectsOtherRoots(t *testing.T) {
+	// the proof of a leaf against the tree root does not verify against a
+	// root that covers several namespaces
+	tree := exampleNMT(1, true, 1, 2)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	proof, err := tree.Prove(1)
+	require.NoError(t, err)
+	leaf := tree.leaves[1]
+	require.True(t, proof.VerifyInclusion(sha256.New(), namespace.ID{2}, [][]byte{leaf[1:]}, root))
+	assert.False(t, proof.VerifyNamespaceRootInclusion(sha256.New(), namespace.ID{2}, leaf[1:], root))
+	assert.False(t, proof.VerifyNamespaceRootInclusion(sha256.New(), namespace.ID{2}, leaf[1:], []byte{2}))
+}
+
+func TestProveInNamespace_Errors(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 2)
+	_, err := tree.ProveInNamespace(namespace.ID{3}, 0)
+	assert.ErrorIs(t, err, ErrNamespaceNotFound)
+	_, err = tree.ProveInNamespace(namespace.ID{2}, 2)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+	_, err = tree.ProveInNamespace(namespace.ID{2}, -1)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+	_, err = tree.ProveInNamespace(namespace.ID{2, 2}, 0)
+	assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+}
@@ -0,0 +1,117 @@
+package nmt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"slices"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ErrNamespaceRewritten indicates that the leaves of a namespace in a tree are
+// not the leaves of the namespace in an older tree followed by new leaves.
+var ErrNamespaceRewritten = errors.New("namespace was not only appended to")
+
+// NamespaceTransitionProof proves that the leaves of a namespace in a new
+// tree are the leaves of the namespace in an old tree followed by an appended
+// suffix, e.g., for the trees of two consecutive blocks. It carries the leaf
+// hashes of the old leaves instead of the leaves themselves, so that
+// verifiers syncing the namespace incrementally only need the suffix.
+type NamespaceTransitionProof struct {
+	// Old and New are the namespace proofs of the namespace in the old and
+	// the new tree, see ProveNamespace.
+	Old Proof
+	New Proof
+	// LeafHashes holds the hashes of the leaves of the namespace in the old
+	// tree.
+	LeafHashes [][]byte
+}
+
+// ProveNamespaceTransition returns a NamespaceTransitionProof of the leaves
+// of namespace nID appended to newTree since oldTree. It returns an
+// ErrNamespaceRewritten error if the leaves of nID in oldTree are not a
+// prefix of those in newTree.
+func ProveNamespaceTransition(oldTree, newTree *NamespacedMerkleTree, nID namespace.ID) (NamespaceTransitionProof, error) {
+	oldProof, err := oldTree.ProveNamespace(nID)
+	if err != nil {
+		return NamespaceTransitionProof{}, err
+	}
+	newProof, err := newTree.ProveNamespace(nID)
+	if err != nil {
+		return NamespaceTransitionProof{}, err
+	}
+	var oldHashes, newHashes [][]byte
+	if !oldProof.IsOfAbsence() {
+		oldHashes = oldTree.leafHashes[oldProof.Start():oldProof.End()]
+	}
+	if !newProof.IsOfAbsence() {
+		newHashes = newTree.leafHashes[newProof.Start():newProof.End()]
+	}
+	if len(oldHashes) > len(newHashes) {
+		return NamespaceTransitionProof{}, fmt.Errorf("%w: namespace %s has %d leaves in the old tree, but %d in the new tree", ErrNamespaceRewritten, nID, len(oldHashes), len(newHashes))
+	}
+	for i, leafHash := range oldHashes {
+		if !bytes.Equal(leafHash, newHashes[i]) {
+			return NamespaceTransitionProof{}, fmt.Errorf("%w: leaf %d of namespace %s differs", ErrNamespaceRewritten, i, nID)
+		}
+	}
+	leafHashes := make([][]byte, len(oldHashes))
+	for i, leafHash := range oldHashes {
+		leafHashes[i] = bytes.Clone(leafHash)
+	}
+	return NamespaceTransitionProof{Old: oldProof, New: newProof, LeafHashes: leafHashes}, nil
+}
+
+// Verify checks that the leaves of namespace nID in the tree with the root
+// newRoot are the leaves of nID in the tree with the root oldRoot, whose
+// hashes are p.LeafHashes, followed by the namespace-prefixed leaves of
+// suffix. Both proofs are verified for completeness, as by VerifyNamespace. h
+// must be the base hash function of the trees. It returns an error wrapping
+// ErrInvalidProof if the verification fails.
+func (p NamespaceTransitionProof) Verify(h hash.Hash, nID namespace.ID, suffix [][]byte, oldRoot, newRoot []byte) error {
+	if err := verifyNamespaceLeafHashes(h, p.Old, nID, p.LeafHashes, oldRoot); err != nil {
+		return fmt.Errorf("%w: old root: %w", ErrInvalidProof, err)
+	}
+	nth := NewNmtHasher(h, nID.Size(), p.New.IsMaxNamespaceIDIgnored())
+	leafHashes := slices.Clip(p.LeafHashes)
+	for i, leaf := range suffix {
+		if err := nth.ValidateLeaf(leaf); err != nil {
+			return fmt.Errorf("%w: appended leaf %d: %w", ErrInvalidProof, i, err)
+		}
+		if leafNID := namespace.ID(leaf[:nID.Size()]); !leafNID.Equal(nID) {
+			return fmt.Errorf("%w: appended leaf %d has namespace %s, want %s", ErrInvalidProof, i, leafNID, nID)
+		}
+		leafHash, err := nth.HashLeaf(leaf)
+		if err != nil {
+			return fmt.Errorf("%w: appended leaf %d: %w", ErrInvalidProof, i, err)
+		}
+		leafHashes = append(leafHashes, leafHash)
+	}
+	if err := verifyNamespaceLeafHashes(h, p.New, nID, leafHashes, newRoot); err != nil {
+		return fmt.Errorf("%w: new root: %w", ErrInvalidProof, err)
+	}
+	return nil
+}
+
+// verifyNamespaceLeafHashes verifies the namespace proof of nID for the
+// hashes of all leaves of the namespace, which are empty for proofs of
+// absence.
+func verifyNamespaceLeafHashes(h hash.Hash, proof Proof, nID namespace.ID, leafHashes [][]byte, root []byte) error {
+	if proof.IsOfAbsence() || proof.IsEmptyProof() {
+		if len(leafHashes) != 0 {
+			return fmt.Errorf("proof of absence for %d leaves", len(leafHashes))
+		}
+		return proof.checkNamespaceRange(h, nID, nID, nil, root)
+	}
+	nth := NewNmtHasher(h, nID.Size(), proof.IsMaxNamespaceIDIgnored())
+	ok, err := proof.VerifyLeafHashes(nth, true, nID, leafHashes, root)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("computed root does not match the root %x", root)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestProveNamespaceTransition(t *testing.T) {
+	blocks := [][][]byte{
+		{{1, 'a'}, {3, 'b'}, {5, 'c'}},
+		{{1, 'a'}, {2, 'x'}, {3, 'b'}, {3, 'd'}, {3, 'e'}, {5, 'c'}, {5, 'f'}},
+	}
+	oldTree := New(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, oldTree.PushAll(blocks[0]))
+	newTree := New(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, newTree.PushAll(blocks[1]))
+	oldRoot, err := oldTree.Root()
+	require.NoError(t, err)
+	newRoot, err := newTree.Root()
+	require.NoError(t, err)
+
+	tests := []struct {
+		nID    namespace.ID
+		suffix [][]byte
+	}{
+		{namespace.ID{3}, [][]byte{{3, 'd'}, {3, 'e'}}},
+		{namespace.ID{5}, [][]byte{{5, 'f'}}},
+		{namespace.ID{1}, nil},
+		// namespaces absent from the old tree
+		{namespace.ID{2}, [][]byte{{2, 'x'}}},
+		{namespace.ID{4}, nil},
+		{namespace.ID{9}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nID.String(), func(t *testing.T) {
+			proof, err := ProveNamespaceTransition(oldTree, newTree, tt.nID)
+			require.NoError(t, err)
+			require.NoError(t, proof.Verify(sha256.New(), tt.nID, tt.suffix, oldRoot, newRoot))
+
+			assert.ErrorIs(t, proof.Verify(sha256.New(), tt.nID, append(tt.suffix, append(tt.nID, 'z')), oldRoot, newRoot), ErrInvalidProof)
+			if !proof.New.IsEmptyProof() {
+				assert.ErrorIs(t, proof.Verify(sha256.New(), tt.nID, tt.suffix, newRoot, oldRoot), ErrInvalidProof)
+			}
+			if len(tt.suffix) > 0 {
+				assert.ErrorIs(t, proof.Verify(sha256.New(), tt.nID, tt.suffix[1:], oldRoot, newRoot), ErrInvalidProof)
+			}
+			if len(proof.LeafHashes) > 0 {
+				truncated := proof
+				truncated.LeafHashes = proof.LeafHashes[1:]
+				assert.ErrorIs(t, truncated.Verify(sha256.New(), tt.nID, tt.suffix, oldRoot, newRoot), ErrInvalidProof)
+			}
+		})
+	}
+
+	// the proof of another namespace does not prove the transition
+	proof, err := ProveNamespaceTransition(oldTree, newTree, namespace.ID{3})
+	require.NoError(t, err)
+	assert.ErrorIs(t, proof.Verify(sha256.New(), namespace.ID{5}, [][]byte{{5, 'd'}, {5, 'e'}}, oldRoot, newRoot), ErrInvalidProof)
+}
+
+func TestProveNamespaceTransition_Rewritten(t *testing.T) {
+	oldTree := New(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, oldTree.PushAll([][]byte{{1, 'a'}, {1, 'b'}}))
+	for _, leaves := range [][][]byte{
+		{{1, 'a'}},
+		{{1, 'b'}, {1, 'a'}},
+		{{0, 'a'}},
+	} {
+		newTree := New(sha256.New(), NamespaceIDSize(1))
+		require.NoError(t, newTree.PushAll(leaves))
+		_, err := ProveNamespaceTransition(oldTree, newTree, namespace.ID{1})
+		assert.ErrorIs(t, err, ErrNamespaceRewritten)
+	}
+}
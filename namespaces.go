@@ -0,0 +1,39 @@
+package nmt
+
+import (
+	"bytes"
+	"iter"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// Namespaces returns an iterator over the distinct namespace IDs present in
+// the tree together with the range of leaves [Start, End) carrying them, in
+// ascending namespace order. The yielded IDs are copies that the caller may
+// retain and modify. If ForceAddLeaf added leaves out of order, the iterator
+// instead yields the maximal runs of consecutive leaves sharing a namespace in
+// the order of the leaves, so that a namespace may be yielded several times.
+// The tree must not be modified while iterating. Frontier-only trees, which
+// keep no leaves, yield no namespaces.
+func (n *NamespacedMerkleTree) Namespaces() iter.Seq2[namespace.ID, LeafRange] {
+	return func(yield func(namespace.ID, LeafRange) bool) {
+		nidSize := n.NamespaceSize()
+		for i := 0; i < len(n.leafHashes); {
+			nID := namespace.ID(n.leafHashes[i][:nidSize])
+			rng := LeafRange{Start: i, End: i + 1}
+			if n.unordered {
+				// the namespace ranges span all leaves of a namespace, which
+				// are not necessarily consecutive
+				for rng.End < len(n.leafHashes) && bytes.Equal(n.leafHashes[rng.End][:nidSize], nID) {
+					rng.End++
+				}
+			} else {
+				rng.End = n.namespaceRanges[string(nID)].End
+			}
+			if !yield(bytes.Clone(nID), rng) {
+				return
+			}
+			i = rng.End
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestNamespaces(t *testing.T) {
+	type entry struct {
+		nID namespace.ID
+		rng LeafRange
+	}
+	tests := []struct {
+		name string
+		tree *NamespacedMerkleTree
+		want []entry
+	}{
+		{"empty tree", New(sha256.New(), NamespaceIDSize(1)), nil},
+		{"single leaf", exampleNMT(1, true, 3), []entry{{namespace.ID{3}, LeafRange{0, 1}}}},
+		{
+			"mixed namespaces", exampleNMT(2, true, 0, 0, 1, 3, 3, 3, 7),
+			[]entry{
+				{namespace.ID{0, 0}, LeafRange{0, 2}},
+				{namespace.ID{1, 1}, LeafRange{2, 3}},
+				{namespace.ID{3, 3}, LeafRange{3, 6}},
+				{namespace.ID{7, 7}, LeafRange{6, 7}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []entry
+			for nID, rng := range tt.tree.Namespaces() {
+				got = append(got, entry{nID, rng})
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNamespaces_Break(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 2, 3)
+	count := 0
+	for range tree.Namespaces() {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 2, count)
+}
+
+func TestNamespaces_Unordered(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 3, 3)
+	require.NoError(t, tree.ForceAddLeaf([]byte{2, 'a'}))
+	require.NoError(t, tree.ForceAddLeaf([]byte{3, 'b'}))
+
+	var nIDs []namespace.ID
+	var ranges []LeafRange
+	for nID, rng := range tree.Namespaces() {
+		nIDs = append(nIDs, nID)
+		ranges = append(ranges, rng)
+	}
+	assert.Equal(t, []namespace.ID{{1}, {3}, {2}, {3}}, nIDs)
+	assert.Equal(t, []LeafRange{{0, 1}, {1, 3}, {3, 4}, {4, 5}}, ranges)
+}
+
+func TestNamespaces_CopiesIDs(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2)
+	for nID := range tree.Namespaces() {
+		nID[0] = 0xFF
+	}
+	assert.Equal(t, []byte{1}, tree.leafHashes[0][:1])
+	assert.Equal(t, []byte{2}, tree.leafHashes[1][:1])
+}
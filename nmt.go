@@ -4,10 +4,13 @@ package nmt
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"hash"
+	"log/slog"
 	"math/bits"
+	"time"
 
 	"github.com/celestiaorg/nmt/namespace"
 )
@@ -18,9 +21,27 @@ const (
 )
 
 var (
-	ErrInvalidRange     = errors.New("invalid proof range")
+	// ErrInvalidRange indicates that a supplied leaf range is out of bounds or
+	// otherwise malformed.
+	ErrInvalidRange = errors.New("invalid proof range")
+	// ErrInvalidPushOrder indicates that a pushed leaf has a smaller namespace
+	// ID than the previously pushed leaf.
 	ErrInvalidPushOrder = errors.New("pushed data has to be lexicographically ordered by namespace IDs")
-	noOp                = func(_ []byte, _ ...[]byte) {}
+	// ErrMismatchedNamespaceSize indicates that a namespace ID does not match
+	// the namespace size the tree or hasher was configured with.
+	ErrMismatchedNamespaceSize = errors.New("mismatched namespace size")
+	// ErrInvalidSubtreeRange indicates that a leaf range does not correspond to
+	// a single inner node of the tree.
+	ErrInvalidSubtreeRange = errors.New("invalid subtree range")
+	// ErrNamespaceNotFound indicates that the tree does not contain any leaf
+	// of a queried namespace.
+	ErrNamespaceNotFound = errors.New("namespace not found")
+	// ErrReservedNamespace indicates that a pushed leaf carries the maximum
+	// namespace ID although the tree was configured to reject it.
+	ErrReservedNamespace = errors.New("leaf uses the reserved maximum namespace")
+	// ErrTreeFull indicates that a leaf was pushed to a tree of fixed height
+	// that has no room left.
+	ErrTreeFull = errors.New("tree of fixed height is full")
 )
 
 type NodeVisitorFn = func(hash []byte, children ...[]byte)
@@ -38,8 +59,48 @@ type Options struct {
 	// more in-depth understanding of this field, refer to the "HashNode" method
 	// in the "Hasher.
 	IgnoreMaxNamespace bool
-	NodeVisitor        NodeVisitorFn
-	Hasher             Hasher
+	// RejectMaxNamespace makes Push refuse leaves carrying the maximum
+	// namespace ID, which is reserved for parity data.
+	RejectMaxNamespace bool
+	// NamespaceVersions, if set, makes Push validate the namespace IDs of
+	// leaves as versioned namespace IDs.
+	NamespaceVersions namespace.VersionRules
+	// Codec encodes and decodes the leaves of PushPayload and GetPayloads.
+	// Defaults to a RawCodec.
+	Codec       LeafCodec
+	NodeVisitor NodeVisitorFn
+	Hasher      Hasher
+	// ProgressFn, if set, is invoked every ProgressInterval processed nodes
+	// during Root().
+	ProgressFn       ProgressFn
+	ProgressInterval int
+	// Metrics receives instrumentation events of the tree. Defaults to a
+	// no-op implementation.
+	Metrics Metrics
+	// Logger, if set, receives log records of significant events of the
+	// tree, see Logger.
+	Logger *slog.Logger
+	// FilterNamespaces and FilterFalsePositiveRate size the bloom filter over
+	// pushed namespace IDs. The filter is disabled if FilterNamespaces is 0.
+	FilterNamespaces        int
+	FilterFalsePositiveRate float64
+	// Padding determines the shape of trees whose number of leaves is not a
+	// power of two. Defaults to NoPadding.
+	Padding PaddingStrategy
+	// FixedSize, if positive, is the number of leaves of a tree of fixed
+	// height, see FixedHeight.
+	FixedSize int
+	// History enables the recording of checkpoints, see History.
+	History bool
+	// ProofCacheSize is the number of namespace proofs kept in the proof
+	// cache. The cache is disabled if ProofCacheSize is 0.
+	ProofCacheSize int
+	// LazyLeafHashing defers leaf hashing, see LazyLeafHashing.
+	LazyLeafHashing bool
+	// DropLeafData discards leaf data once it is hashed, see DropLeafData.
+	DropLeafData bool
+	// FrontierOnly makes the tree only keep its frontier, see FrontierOnly.
+	FrontierOnly bool
 }
 
 type Option func(*Options)
@@ -77,12 +138,81 @@ func IgnoreMaxNamespace(ignore bool) Option {
 	}
 }
 
+// RejectMaxNamespace makes the tree refuse leaves carrying the maximum possible
+// namespace ID (i.e., NamespaceIDSize bytes of 0xFF) with an
+// ErrReservedNamespace error. This namespace is reserved for the parity data
+// produced by the erasure coding layer, which should add such leaves through
+// ForceAddLeaf or a tree without this option. Defaults to false.
+func RejectMaxNamespace(reject bool) Option {
+	return func(opts *Options) {
+		opts.RejectMaxNamespace = reject
+	}
+}
+
+// NamespaceVersions makes the tree treat the first byte of namespace IDs as a
+// version and refuse leaves whose namespace ID violates the given rules, see
+// namespace.VersionRules.Validate. Defaults to nil, i.e., namespace IDs are not
+// versioned.
+func NamespaceVersions(rules namespace.VersionRules) Option {
+	return func(opts *Options) {
+		opts.NamespaceVersions = rules
+	}
+}
+
+// ValidateNamespace checks nID against the restrictions the options impose on
+// the namespace IDs of pushed leaves. It returns an ErrReservedNamespace error
+// if nID is the maximum namespace ID and RejectMaxNamespace is set, and a
+// namespace.ErrUnsupportedVersion or namespace.ErrInvalidVersionedID error if
+// nID violates the NamespaceVersions rules. It allows code building or
+// verifying trees outside of NamespacedMerkleTree to enforce the same rules.
+func (opts *Options) ValidateNamespace(nID namespace.ID) error {
+	return validateNamespaceRules(nID, opts.RejectMaxNamespace, opts.NamespaceVersions)
+}
+
+// validateNamespaceRules implements Options.ValidateNamespace.
+func validateNamespaceRules(nID namespace.ID, rejectMax bool, versions namespace.VersionRules) error {
+	if rejectMax && len(nID) > 0 && bytes.Count(nID, []byte{0xFF}) == len(nID) {
+		return fmt.Errorf("%w: %x", ErrReservedNamespace, nID)
+	}
+	if versions != nil {
+		return versions.Validate(nID)
+	}
+	return nil
+}
+
+// Codec sets the LeafCodec used by PushPayload and GetPayloads to encode and
+// decode leaves. Defaults to a RawCodec for the configured namespace size.
+func Codec(c LeafCodec) Option {
+	return func(opts *Options) {
+		opts.Codec = c
+	}
+}
+
 func NodeVisitor(nodeVisitorFn NodeVisitorFn) Option {
 	return func(opts *Options) {
 		opts.NodeVisitor = nodeVisitorFn
 	}
 }
 
+// ProgressCallback registers fn to be invoked during Root() every interval
+// processed nodes (leaves and inner nodes), and once more when the root has
+// been computed. A non-positive interval defaults to DefaultProgressInterval.
+// This allows long-running root computations on big trees to report progress.
+func ProgressCallback(fn ProgressFn, interval int) Option {
+	return func(opts *Options) {
+		opts.ProgressFn = fn
+		opts.ProgressInterval = interval
+	}
+}
+
+// CustomMetrics registers m to receive instrumentation events (pushes, hash
+// operations, root and proof computations) of the tree.
+func CustomMetrics(m Metrics) Option {
+	return func(opts *Options) {
+		opts.Metrics = m
+	}
+}
+
 // CustomHasher replaces the default hasher.
 func CustomHasher(h Hasher) Option {
 	return func(o *Options) {
@@ -93,6 +223,16 @@ func CustomHasher(h Hasher) Option {
 type NamespacedMerkleTree struct {
 	treeHasher Hasher
 	visit      NodeVisitorFn
+	progress   *progressTracker
+	metrics    Metrics
+	logger     *slog.Logger
+	// rejectMaxNamespace indicates whether leaves with the maximum namespace
+	// ID are refused by Push.
+	rejectMaxNamespace bool
+	// namespaceVersions holds the rules pushed versioned namespace IDs are
+	// validated against, if any.
+	namespaceVersions namespace.VersionRules
+	codec             LeafCodec
 
 	// just cache stuff until we pass in a store and keep all nodes in there
 	// currently, only leaves and leafHashes are stored:
@@ -104,12 +244,35 @@ type NamespacedMerkleTree struct {
 	//  leafHashes stores the namespace hash of the leaves, calculated either
 	//  through the Root() or the computeLeafHashesIfNecessary methods.
 	leafHashes [][]byte
+	// leafArena holds the leaf hashes in chunks of contiguous memory rather
+	// than in one allocation per leaf, which saves the garbage collector from
+	// tracking millions of small objects in long-lived trees.
+	leafArena nodeArena
+	// lazyHashing indicates whether Push defers leaf hashing, and pending is
+	// the number of leaves at the end of the tree that have not been hashed
+	// yet. Until they are hashed by hashPendingLeaves, their leafHashes only
+	// hold their namespace ID, and they are not part of leafIndices.
+	lazyHashing bool
+	pending     int
+	// dropLeafData indicates whether leaves are replaced by nil once they
+	// are hashed, see DropLeafData.
+	dropLeafData bool
+	// unordered indicates that ForceAddLeaf added a leaf with a smaller
+	// namespace ID than its predecessor, which disables the invariant checks
+	// relying on the order of the leaves until the tree is reset.
+	unordered bool
 
 	// namespaceRanges can be used to efficiently look up the range for an
 	// existing namespace without iterating through the leaves. The map key is
 	// the string representation of a namespace.ID  and the LeafRange indicates
 	// the range of the leaves matching that namespace ID in the tree
 	namespaceRanges map[string]LeafRange
+	// leafIndices maps the string representation of a leaf hash to the index
+	// of the first leaf with that hash. It is nil until IndexOf builds it.
+	leafIndices map[string]int
+	// filter, if enabled, contains the namespace IDs of namespaceRanges and
+	// possibly those of namespaces removed by truncate.
+	filter *bloomFilter
 	// minNID is the minimum namespace ID of the leaves
 	minNID namespace.ID
 	// maxNID is the maximum namespace ID of the leaves
@@ -119,18 +282,91 @@ type NamespacedMerkleTree struct {
 	// invoked. It's important to note that rawRoot may become outdated and may
 	// not accurately reflect the current state of the leaves.
 	rawRoot []byte
+	// innerNodes memoizes the inner nodes computed by Root() keyed by the leaf
+	// range they cover so that subsequent proof generation does not need to
+	// re-hash them. It is reset whenever a leaf is added.
+	innerNodes map[LeafRange][]byte
+	// padding is the PaddingStrategy of the tree, and padNodes memoizes the
+	// roots of padding subtrees by height. padNodes is reset whenever a leaf
+	// is added.
+	padding  PaddingStrategy
+	padNodes [][]byte
+	// shared, if not nil, coordinates appends to the backing arrays of leaves
+	// and leafHashes shared with snapshots, and sharedRanges indicates that
+	// namespaceRanges is shared with snapshots. See Snapshot.
+	shared       *sharedLeaves
+	sharedRanges bool
+	// fixedSize is the number of leaves of a tree of fixed height including
+	// padding, or 0 if the height of the tree is not fixed.
+	fixedSize int
+	// keepHistory indicates whether the tree records checkpoints in history,
+	// ordered by version.
+	keepHistory bool
+	history     []historyEntry
+	// batches holds the open batches of the tree, see Begin.
+	batches []*Batch
+	// proofs, if enabled, caches namespace proofs. It is reset whenever a
+	// leaf is added or removed.
+	proofs *proofCache
+	// frontier, if not nil, holds the frontier of a frontier-only tree, which
+	// keeps no leaves, see FrontierOnly.
+	frontier *RootComputer
 }
 
 // New initializes a namespaced Merkle tree using the given base hash function
 // and for the given namespace size (number of bytes). If the namespace size is
 // 0 this corresponds to a regular non-namespaced Merkle tree.
 func New(h hash.Hash, setters ...Option) *NamespacedMerkleTree {
+	opts := newOptions(h, setters...)
+	var filter *bloomFilter
+	if opts.FilterNamespaces > 0 {
+		filter = newBloomFilter(opts.FilterNamespaces, opts.FilterFalsePositiveRate)
+	}
+	var frontier *RootComputer
+	if opts.FrontierOnly {
+		if opts.History {
+			panic("Got History and FrontierOnly. Expected at most one of them.")
+		}
+		frontier = &RootComputer{treeHasher: opts.Hasher, padding: opts.Padding, fixedSize: opts.FixedSize}
+		// no leaves are kept
+		opts.InitialCapacity = 0
+	}
+	return &NamespacedMerkleTree{
+		treeHasher:         opts.Hasher,
+		visit:              opts.NodeVisitor,
+		progress:           newProgressTracker(opts.ProgressFn, opts.ProgressInterval),
+		metrics:            opts.Metrics,
+		logger:             opts.Logger,
+		rejectMaxNamespace: opts.RejectMaxNamespace,
+		namespaceVersions:  opts.NamespaceVersions,
+		codec:              opts.Codec,
+		leaves:             make([][]byte, 0, opts.InitialCapacity),
+		leafHashes:         make([][]byte, 0, opts.InitialCapacity),
+		leafArena:          nodeArena{nodeSize: len(opts.Hasher.EmptyRoot())},
+		namespaceRanges:    make(map[string]LeafRange),
+		filter:             filter,
+		padding:            opts.Padding,
+		fixedSize:          opts.FixedSize,
+		keepHistory:        opts.History,
+		proofs:             newProofCache(opts.ProofCacheSize),
+		lazyHashing:        opts.LazyLeafHashing,
+		dropLeafData:       opts.DropLeafData,
+		frontier:           frontier,
+		minNID:             bytes.Repeat([]byte{0xFF}, int(opts.NamespaceIDSize)),
+		maxNID:             bytes.Repeat([]byte{0x00}, int(opts.NamespaceIDSize)),
+	}
+}
+
+// newOptions returns the Options resulting from applying setters to the
+// defaults, with the default hasher built from h unless replaced by
+// CustomHasher. It panics if the hasher and the namespace size disagree.
+func newOptions(h hash.Hash, setters ...Option) *Options {
 	// default options:
 	opts := &Options{
 		InitialCapacity:    DefaultCapacity,
 		NamespaceIDSize:    DefaultNamespaceIDLen,
 		IgnoreMaxNamespace: true,
-		NodeVisitor:        noOp,
+		Metrics:            NoopMetrics{},
 	}
 
 	for _, setter := range setters {
@@ -145,16 +381,21 @@ func New(h hash.Hash, setters ...Option) *NamespacedMerkleTree {
 	for _, setter := range setters {
 		setter(opts)
 	}
-
-	return &NamespacedMerkleTree{
-		treeHasher:      opts.Hasher,
-		visit:           opts.NodeVisitor,
-		leaves:          make([][]byte, 0, opts.InitialCapacity),
-		leafHashes:      make([][]byte, 0, opts.InitialCapacity),
-		namespaceRanges: make(map[string]LeafRange),
-		minNID:          bytes.Repeat([]byte{0xFF}, int(opts.NamespaceIDSize)),
-		maxNID:          bytes.Repeat([]byte{0x00}, int(opts.NamespaceIDSize)),
+	if opts.Metrics == nil {
+		opts.Metrics = NoopMetrics{}
+	}
+	if opts.Codec == nil {
+		opts.Codec = RawCodec{NamespaceSize: opts.NamespaceIDSize}
+	}
+	if opts.FixedSize > 0 {
+		// missing leaves of trees of fixed height are empty
+		opts.Padding = PadWithEmptyLeaves
 	}
+	if opts.Hasher.NamespaceSize() != opts.NamespaceIDSize {
+		panic(fmt.Sprintf("Got hasher with namespace size %d. Expected the configured namespace size %d.", opts.Hasher.NamespaceSize(), opts.NamespaceIDSize))
+	}
+
+	return opts
 }
 
 // Prove returns a NMT inclusion proof for the leaf at the supplied index. Note
@@ -184,6 +425,16 @@ func (n *NamespacedMerkleTree) Prove(index int) (Proof, error) {
 // If the supplied (start, end) range is invalid i.e., if start < 0 or end > n.Size() or start >= end,
 // then ProveRange returns an ErrInvalidRange error. Any errors rather than ErrInvalidRange are irrecoverable and indicate an illegal state of the tree (n).
 func (n *NamespacedMerkleTree) ProveRange(start, end int) (Proof, error) {
+	begin := time.Now()
+	proof, err := n.proveRange(start, end)
+	d := time.Since(begin)
+	n.metrics.ProofGenerated(d, len(proof.nodes), err)
+	n.logProof("ProveRange", d, proof, err)
+	n.checkInvariants("ProveRange")
+	return proof, err
+}
+
+func (n *NamespacedMerkleTree) proveRange(start, end int) (Proof, error) {
 	isMaxNsIgnored := n.treeHasher.IsMaxNamespaceIDIgnored()
 	// TODO: store nodes and re-use the hashes instead recomputing parts of the
 	// tree here
@@ -226,17 +477,47 @@ func (n *NamespacedMerkleTree) ProveRange(start, end int) (Proof, error) {
 // generated using a modified version of the namespace hash with a custom
 // namespace ID range calculation. For more information on this, please refer to
 // the HashNode method in the Hasher.
-// Any error returned by this method is irrecoverable and indicates an illegal state of the tree (n).
+// If the size of nID does not match the tree's namespace size, ProveNamespace
+// returns an ErrMismatchedNamespaceSize error. Any other error returned by this method is irrecoverable and indicates an illegal state of the tree (n).
 func (n *NamespacedMerkleTree) ProveNamespace(nID namespace.ID) (Proof, error) {
+	return n.ProveNamespaceCtx(context.Background(), nID)
+}
+
+// ProveNamespaceCtx is like ProveNamespace but checks ctx for cancellation
+// between subtree computations. If ctx is done before the proof is complete,
+// the returned error wraps ctx.Err().
+func (n *NamespacedMerkleTree) ProveNamespaceCtx(ctx context.Context, nID namespace.ID) (Proof, error) {
+	begin := time.Now()
+	var proof Proof
+	var err error
+	switch {
+	case n.frontier != nil:
+		err = ErrFrontierOnly
+	case n.proofs != nil:
+		proof, err = n.proveNamespaceCached(ctx, nID)
+	default:
+		proof, err = n.proveNamespace(ctx, nID)
+	}
+	d := time.Since(begin)
+	n.metrics.ProofGenerated(d, len(proof.nodes), err)
+	n.logProof("ProveNamespace", d, proof, err)
+	n.checkInvariants("ProveNamespace")
+	return proof, err
+}
+
+func (n *NamespacedMerkleTree) proveNamespace(ctx context.Context, nID namespace.ID) (Proof, error) {
 	isMaxNsIgnored := n.treeHasher.IsMaxNamespaceIDIgnored()
+	if err := n.validateNamespaceSize(nID); err != nil {
+		return Proof{}, err
+	}
 
 	// check if the tree is empty
-	if n.Size() == 0 {
+	if n.sizeWithPadding() == 0 {
 		return NewEmptyRangeProof(isMaxNsIgnored), nil
 	}
 
 	// compute the root of the tree
-	root, err := n.Root()
+	root, err := n.RootCtx(ctx)
 	if err != nil {
 		return Proof{}, fmt.Errorf("failed to get root: %w", err)
 	}
@@ -265,7 +546,7 @@ func (n *NamespacedMerkleTree) ProveNamespace(nID namespace.ID) (Proof, error) {
 	// the tree or calculated the range it would be in (to generate a proof of
 	// absence and to return the corresponding leaf hashes).
 
-	proof, err := n.buildRangeProof(proofStart, proofEnd)
+	proof, err := n.buildRangeProofCtx(ctx, proofStart, proofEnd)
 	if err != nil {
 		return Proof{}, err
 	}
@@ -274,7 +555,11 @@ func (n *NamespacedMerkleTree) ProveNamespace(nID namespace.ID) (Proof, error) {
 		return NewInclusionProof(proofStart, proofEnd, proof, isMaxNsIgnored), nil
 	}
 
-	return NewAbsenceProof(proofStart, proofEnd, proof, n.leafHashes[proofStart], isMaxNsIgnored), nil
+	leafHash, err := n.subtreeRoot(proofStart, proofEnd)
+	if err != nil {
+		return Proof{}, err
+	}
+	return NewAbsenceProof(proofStart, proofEnd, proof, leafHash, isMaxNsIgnored), nil
 }
 
 // validateRange validates the range [start, end) against the size of the tree.
@@ -291,12 +576,22 @@ func (n *NamespacedMerkleTree) validateRange(start, end int) error {
 // The nodes are ordered according to in order traversal of the namespaced tree.
 // Any errors returned by this method are irrecoverable and indicate an illegal state of the tree (n).
 func (n *NamespacedMerkleTree) buildRangeProof(proofStart, proofEnd int) ([][]byte, error) {
+	return n.buildRangeProofCtx(context.Background(), proofStart, proofEnd)
+}
+
+// buildRangeProofCtx is like buildRangeProof but aborts with ctx.Err() once ctx
+// is done.
+func (n *NamespacedMerkleTree) buildRangeProofCtx(ctx context.Context, proofStart, proofEnd int) ([][]byte, error) {
+	if err := n.hashPendingLeaves(); err != nil {
+		return nil, err
+	}
 	proof := [][]byte{} // it is the list of nodes hashes (as byte slices) with no index
 	var recurse func(start, end int, includeNode bool) ([]byte, error)
 
-	// validate the range
-	if err := n.validateRange(proofStart, proofEnd); err != nil {
-		return nil, err
+	// validate the range, which may cover padding leaves in case of absence
+	// proofs
+	if proofStart < 0 || proofStart >= proofEnd || proofEnd > n.sizeWithPadding() {
+		return nil, ErrInvalidRange
 	}
 
 	// start, end are indices of leaves in the tree hence they should be within
@@ -306,7 +601,22 @@ func (n *NamespacedMerkleTree) buildRangeProof(proofStart, proofEnd int) ([][]by
 	// should be part of the proof
 	recurse = func(start, end int, includeNode bool) ([]byte, error) {
 		if start >= n.Size() {
-			return nil, nil
+			if n.padding == NoPadding {
+				return nil, nil
+			}
+			// a subtree of padding leaves only needs to be traversed if it
+			// covers the padding leaf of an absence proof
+			overlaps := start < proofEnd && proofStart < end
+			if !overlaps || end-start == 1 {
+				pad, err := n.padSubtreeRoot(end - start)
+				if err != nil {
+					return nil, err
+				}
+				if includeNode && !overlaps {
+					proof = append(proof, pad)
+				}
+				return pad, nil
+			}
 		}
 
 		// reached a leaf
@@ -340,6 +650,25 @@ func (n *NamespacedMerkleTree) buildRangeProof(proofStart, proofEnd int) ([][]by
 			newIncludeNode = false
 		}
 
+		// the hash of the current subtree may have been memoized by Root(), in
+		// which case its subtrees only need to be traversed if they are part
+		// of the proof
+		key := LeafRange{Start: start, End: end}
+		if n.padding == NoPadding {
+			key.End = minInt(end, n.Size())
+		}
+		cached, isCached := n.innerNodes[key]
+		if isCached && !newIncludeNode {
+			if includeNode {
+				proof = append(proof, cached)
+			}
+			return cached, nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		// recursively get left and right subtree
 		k := getSplitPoint(end - start)
 
@@ -354,14 +683,18 @@ func (n *NamespacedMerkleTree) buildRangeProof(proofStart, proofEnd int) ([][]by
 
 		// only right leaf/subtree can be non-existent
 		var hash []byte
-		if right == nil {
+		switch {
+		case right == nil:
 			hash = left
-		} else {
+		case isCached:
+			hash = cached
+		default:
 			var err error
 			hash, err = n.treeHasher.HashNode(left, right)
 			if err != nil { // if HashNode returns an error, it is a bug
 				return nil, err // this should never happen if the Push method is used to add leaves to the tree
 			}
+			n.metrics.NodeHashed()
 		}
 
 		// if the hash of the subtree representing [start, end) should be part
@@ -377,54 +710,61 @@ func (n *NamespacedMerkleTree) buildRangeProof(proofStart, proofEnd int) ([][]by
 	if fullTreeSize < 1 {
 		fullTreeSize = 1
 	}
+	if n.fixedSize > 0 {
+		fullTreeSize = n.fixedSize
+	}
 	if _, err := recurse(0, fullTreeSize, true); err != nil {
 		return nil, err
 	}
 	return proof, nil
 }
 
-// Get returns leaves for the given namespace.ID.
+// Get returns leaves for the given namespace.ID. If the tree drops leaf data
+// (see DropLeafData), the returned leaves are nil.
 func (n *NamespacedMerkleTree) Get(nID namespace.ID) [][]byte {
 	_, start, end := n.foundInRange(nID)
 	return n.leaves[start:end]
 }
 
+// Leaf returns the namespace-prefixed leaf at the given index. It returns an
+// ErrInvalidRange error if index is not in [0, n.Size()) and an
+// ErrLeafDataDropped error if the tree drops leaf data.
+func (n *NamespacedMerkleTree) Leaf(index int) ([]byte, error) {
+	if index < 0 || index >= n.Size() {
+		return nil, fmt.Errorf("leaf index %d is out of the tree range [0, %d): %w", index, n.Size(), ErrInvalidRange)
+	}
+	if err := n.checkLeafData(); err != nil {
+		return nil, err
+	}
+	return n.leaves[index], nil
+}
+
 // GetWithProof is a convenience method returns leaves for the given
 // namespace.ID together with the proof for that namespace. It returns the same
-// result as calling the combination of Get(nid) and ProveNamespace(nid).
+// result as calling the combination of Get(nid) and ProveNamespace(nid). See
+// GetNamespaceData for a variant returning a single struct.
 func (n *NamespacedMerkleTree) GetWithProof(nID namespace.ID) ([][]byte, Proof, error) {
-	data := n.Get(nID)
-	proof, err := n.ProveNamespace(nID)
-	return data, proof, err
+	d, err := n.GetNamespaceData(nID)
+	if err != nil {
+		return n.Get(nID), Proof{}, err
+	}
+	return d.Leaves, d.Proof, nil
 }
 
 // calculateAbsenceIndex returns the index of a leaf of the tree that 1) its
 // namespace ID is the smallest namespace ID larger than nID and 2) the
-// namespace ID of the leaf to the left of it is smaller than the nID.
+// namespace ID of the leaf to the left of it is smaller than the nID. For trees
+// padded with empty leaves, this may be the index of the first padding leaf.
 func (n *NamespacedMerkleTree) calculateAbsenceIndex(nID namespace.ID) int {
-	nidSize := n.treeHasher.NamespaceSize()
-	var prevLeaf []byte
-
-	for index, curLeaf := range n.leaves {
-		if index == 0 {
-			prevLeaf = curLeaf
-			continue
-		}
-		prevNs := namespace.ID(prevLeaf[:nidSize])
-		currentNs := curLeaf[:nidSize]
-		// Note that here we would also care for the case current < nId < prevNs
-		// but we only allow pushing leaves with ascending namespaces; i.e.
-		// prevNs <= currentNs is always true. Also we only check for strictly
-		// smaller: prev < nid < current because if we either side was equal, we
-		// would have found the namespace before.
-		if prevNs.Less(nID) && nID.Less(currentNs) {
-			return index
-		}
-		prevLeaf = curLeaf
+	// leaves are pushed in ascending namespace order, hence the first leaf
+	// with a namespace ID larger than nID can be found by a binary search
+	index, _ := n.namespaceRangeBounds(nID, nID)
+	if (index == 0 && n.Size() > 0) || index == n.sizeWithPadding() {
+		// the case (nID < minNID) or (maxNID < nID) should be handled before
+		// calling this private helper!
+		panic("calculateAbsenceIndex() called although (nID < minNID) or (maxNID < nID) for provided nID")
 	}
-	// the case (nID < minNID) or (maxNID < nID) should be handled before
-	// calling this private helper!
-	panic("calculateAbsenceIndex() called although (nID < minNID) or (maxNID < nID) for provided nID")
+	return index
 }
 
 // foundInRange returns a range of leaves in the namespace tree with the
@@ -435,6 +775,9 @@ func (n *NamespacedMerkleTree) calculateAbsenceIndex(nID namespace.ID) int {
 func (n *NamespacedMerkleTree) foundInRange(nID namespace.ID) (found bool, startIndex int, endIndex int) {
 	// This is a faster version of this code snippet:
 	// https://github.com/celestiaorg/celestiaorg-prototype/blob/2aeca6f55ad389b9d68034a0a7038f80a8d2982e/simpleblock.go#L106-L117
+	if n.filter != nil && !n.filter.mayContain(nID) {
+		return false, 0, 0
+	}
 	foundRng, found := n.namespaceRanges[string(nID)]
 	return found, foundRng.Start, foundRng.End
 }
@@ -452,43 +795,112 @@ func (n *NamespacedMerkleTree) NamespaceSize() namespace.IDSize {
 // the namespace ID compared to the previously inserted data (i.e., it is not
 // lexicographically sorted by namespace ID).
 func (n *NamespacedMerkleTree) Push(namespacedData namespace.PrefixedData) error {
+	err := n.push(namespacedData)
+	n.metrics.Pushed(err)
+	n.logPush("Push", err)
+	n.checkInvariants("Push")
+	return err
+}
+
+func (n *NamespacedMerkleTree) push(namespacedData namespace.PrefixedData) error {
 	nID, err := n.validateAndExtractNamespace(namespacedData)
 	if err != nil {
 		return err
 	}
+	if n.frontier != nil {
+		leafHash, err := n.treeHasher.HashLeaf(namespacedData)
+		if err != nil {
+			return err
+		}
+		n.metrics.LeafHashed()
+		return n.pushFrontier(leafHash, nID)
+	}
+	if n.lazyHashing {
+		// the namespace ID stands in for the leaf hash until it is computed
+		n.pending++
+		n.addLeaf(namespacedData, nID, nID)
+		return nil
+	}
 
 	// compute the leaf hash
-	res, err := n.treeHasher.HashLeaf(namespacedData)
+	res, err := n.hashLeaf(namespacedData, cap(n.leafHashes)-n.Size())
 	if err != nil {
 		return err
 	}
+	n.metrics.LeafHashed()
 
-	// update relevant "caches":
-	n.leaves = append(n.leaves, namespacedData)
-	n.leafHashes = append(n.leafHashes, res)
-	n.updateNamespaceRanges()
-	n.updateMinMaxID(nID)
-	n.rawRoot = nil
+	if n.dropLeafData {
+		namespacedData = nil
+	}
+	n.addLeaf(namespacedData, res, nID)
 	return nil
 }
 
+// hashLeaf returns the hash of the namespace-prefixed leaf data allocated from
+// the leaf arena of the tree. upcoming is the number of leaves expected to be
+// hashed next, which determines the size of newly allocated chunks.
+func (n *NamespacedMerkleTree) hashLeaf(ndata []byte, upcoming int) ([]byte, error) {
+	if nth, ok := n.treeHasher.(*NmtHasher); ok {
+		if err := nth.ValidateLeaf(ndata); err != nil {
+			return nil, err
+		}
+		return nth.hashLeafInto(n.nextLeafHash(upcoming), ndata), nil
+	}
+	leafHash, err := n.treeHasher.HashLeaf(ndata)
+	if err != nil {
+		return nil, err
+	}
+	return append(n.nextLeafHash(upcoming), leafHash...), nil
+}
+
+// nextLeafHash returns an empty buffer for a leaf hash from the leaf arena,
+// see hashLeaf.
+func (n *NamespacedMerkleTree) nextLeafHash(upcoming int) []byte {
+	if len(n.leafArena.buf) < n.leafArena.nodeSize {
+		n.leafArena.chunkNodes = minInt(maxInt(upcoming, 1), maxArenaChunkNodes)
+	}
+	return n.leafArena.next()
+}
+
 // Root calculates the namespaced Merkle Tree's root based on the data that has
 // been added through the use of the Push method. the returned byte slice is of
 // size 2* n.NamespaceSize + the underlying hash output size, and should be
 // parsed as minND || maxNID || hash
 // Any error returned by this method is irrecoverable and indicate an illegal state of the tree (n).
 func (n *NamespacedMerkleTree) Root() ([]byte, error) {
+	return n.RootCtx(context.Background())
+}
+
+// RootCtx is like Root but checks ctx for cancellation between subtree
+// computations. If ctx is done before the root is computed, the returned error
+// wraps ctx.Err() and the tree's cached root is left untouched.
+func (n *NamespacedMerkleTree) RootCtx(ctx context.Context) ([]byte, error) {
 	if n.rawRoot == nil {
-		res, err := n.computeRoot(0, n.Size())
+		begin := time.Now()
+		var res []byte
+		var err error
+		if n.frontier != nil {
+			res, err = n.frontier.Root()
+		} else {
+			n.progress.start(n.Size())
+			res, err = n.computeRootCtx(ctx, 0, n.sizeWithPadding())
+			n.progress.stop(err == nil)
+		}
+		d := time.Since(begin)
+		n.metrics.RootComputed(d, err)
+		n.logRoot(d, res, err)
 		if err != nil {
-			return nil, err // this should never happen since leaves are validated in the Push method
+			return nil, err // apart from ctx being done, this should never happen since leaves are validated in the Push method
 		}
 		n.rawRoot = res
+		n.checkInvariants("Root")
 	}
 	return n.rawRoot, nil
 }
 
 // MinNamespace returns the minimum namespace ID in this Namespaced Merkle Tree.
+// It computes the root of the tree, see MinLeafNamespace for a cheaper
+// alternative.
 // Any errors returned by this method are irrecoverable and indicate an illegal state of the tree (n).
 func (n *NamespacedMerkleTree) MinNamespace() (namespace.ID, error) {
 	r, err := n.Root()
@@ -499,6 +911,8 @@ func (n *NamespacedMerkleTree) MinNamespace() (namespace.ID, error) {
 }
 
 // MaxNamespace returns the maximum namespace ID in this Namespaced Merkle Tree.
+// It computes the root of the tree and therefore follows the IgnoreMaxNamespace
+// setting, see MaxLeafNamespace for a cheaper alternative.
 // Any errors returned by this method are irrecoverable and indicate an illegal state of the tree (n).
 func (n *NamespacedMerkleTree) MaxNamespace() (namespace.ID, error) {
 	r, err := n.Root()
@@ -508,63 +922,293 @@ func (n *NamespacedMerkleTree) MaxNamespace() (namespace.ID, error) {
 	return MaxNamespace(r, n.NamespaceSize()), nil
 }
 
+// MinLeafNamespace returns the minimum namespace ID of the leaves pushed so
+// far without computing the root, or nil if the tree is empty.
+func (n *NamespacedMerkleTree) MinLeafNamespace() namespace.ID {
+	if n.Size() == 0 {
+		return nil
+	}
+	return append(namespace.ID(nil), n.minNID...)
+}
+
+// MaxLeafNamespace returns the maximum namespace ID of the leaves pushed so
+// far without computing the root, or nil if the tree is empty. Unlike
+// MaxNamespace, it does not ignore the maximum possible namespace ID.
+func (n *NamespacedMerkleTree) MaxLeafNamespace() namespace.ID {
+	if n.Size() == 0 {
+		return nil
+	}
+	return append(namespace.ID(nil), n.maxNID...)
+}
+
 // ForceAddLeaf adds a namespaced data to the tree without validating its
 // namespace ID. This method should only be used by tests that are attempting to
 // create out of order trees. The default hasher will fail for trees that are
 // out of order.
 func (n *NamespacedMerkleTree) ForceAddLeaf(leaf namespace.PrefixedData) error {
-	nID := namespace.ID(leaf[:n.NamespaceSize()])
-	// compute the leaf hash
-	res, err := n.treeHasher.HashLeaf(leaf)
+	if err := n.validateCapacity(); err != nil {
+		return err
+	}
+	if err := n.hashPendingLeaves(); err != nil {
+		return err
+	}
+	// compute the leaf hash, which validates the size of the leaf
+	res, err := n.hashLeaf(leaf, 1)
 	if err != nil {
 		return err
 	}
+	n.metrics.LeafHashed()
+	nID := leaf.NamespaceID(n.NamespaceSize())
 
-	// update relevant "caches":
+	if n.Size() > 0 && nID.Less(n.leafHashes[n.Size()-1][:n.NamespaceSize()]) {
+		n.unordered = true
+	}
+	if n.dropLeafData {
+		leaf = nil
+	}
+	n.addLeaf(leaf, res, nID)
+	n.checkInvariants("ForceAddLeaf")
+	return nil
+}
+
+// addLeaf appends leaf with its leafHash and namespace ID nID to the tree and
+// updates all relevant "caches".
+func (n *NamespacedMerkleTree) addLeaf(leaf, leafHash []byte, nID namespace.ID) {
+	n.ownLeaves()
 	n.leaves = append(n.leaves, leaf)
-	n.leafHashes = append(n.leafHashes, res)
+	n.leafHashes = append(n.leafHashes, leafHash)
+	// the leaf indices are only built on demand, and pending leaves are
+	// indexed once they are hashed
+	if n.leafIndices != nil && n.pending == 0 {
+		if _, found := n.leafIndices[string(leafHash)]; !found {
+			n.leafIndices[string(leafHash)] = n.Size() - 1
+		}
+	}
 	n.updateNamespaceRanges()
 	n.updateMinMaxID(nID)
 	n.rawRoot = nil
-	return nil
+	n.innerNodes = nil
+	n.padNodes = nil
+	n.invalidateProofs()
+}
+
+// Reset removes all leaves from the tree while retaining its configuration
+// and the capacity of its internal buffers, so that the tree can be reused
+// instead of allocating a new one. Slices previously returned by the tree,
+// e.g. by Get, must not be used after calling Reset.
+func (n *NamespacedMerkleTree) Reset() {
+	if n.shared != nil {
+		// the leaves are shared with snapshots
+		n.leaves = make([][]byte, 0, cap(n.leaves))
+		n.leafHashes = make([][]byte, 0, cap(n.leafHashes))
+		n.shared = nil
+	}
+	clear(n.leaves)
+	n.leaves = n.leaves[:0]
+	clear(n.leafHashes)
+	n.leafHashes = n.leafHashes[:0]
+	n.pending = 0
+	// the chunks may still be referenced by proofs or returned leaf hashes
+	n.leafArena.buf = nil
+	if n.sharedRanges {
+		n.namespaceRanges = make(map[string]LeafRange)
+		n.sharedRanges = false
+	}
+	clear(n.namespaceRanges)
+	clear(n.leafIndices)
+	if n.filter != nil {
+		n.filter.reset()
+	}
+	n.innerNodes = nil
+	n.padNodes = nil
+	n.invalidateProofs()
+	n.truncateHistory(0)
+	// minNID and maxNID may alias pushed leaves, hence they are replaced
+	// rather than overwritten
+	n.minNID = bytes.Repeat([]byte{0xFF}, int(n.NamespaceSize()))
+	n.maxNID = bytes.Repeat([]byte{0x00}, int(n.NamespaceSize()))
+	n.rawRoot = nil
+	n.unordered = false
+	if n.frontier != nil {
+		n.frontier.Reset()
+	}
+	n.checkInvariants("Reset")
+}
+
+// Pop removes the most recently pushed leaf from the tree and returns it,
+// invalidating the cached root and the inner nodes covering the leaf. The
+// returned leaf is nil if it was added using PushLeafHash or its data was
+// dropped, see DropLeafData. Pop returns an ErrInvalidRange error if the tree
+// is empty and an ErrBatchOpen error if the leaf was pushed before an open
+// Batch began.
+func (n *NamespacedMerkleTree) Pop() ([]byte, error) {
+	if n.Size() == 0 {
+		return nil, fmt.Errorf("cannot pop from an empty tree: %w", ErrInvalidRange)
+	}
+	if n.frontier != nil {
+		return nil, ErrFrontierOnly
+	}
+	if err := n.validatePop(); err != nil {
+		return nil, err
+	}
+	leaf := n.leaves[n.Size()-1]
+	n.truncate(n.Size() - 1)
+	n.checkInvariants("Pop")
+	return leaf, nil
+}
+
+// IndexOf returns the index of the leaf with the supplied namespaced leaf hash,
+// e.g., as found in a proof. If several leaves have the same hash, the index
+// of the first one is returned. The second return value is false if no leaf
+// with the given hash exists in the tree.
+func (n *NamespacedMerkleTree) IndexOf(leafHash []byte) (int, bool) {
+	if err := n.hashPendingLeaves(); err != nil {
+		return 0, false
+	}
+	if n.leafIndices == nil {
+		n.buildLeafIndices()
+	}
+	index, found := n.leafIndices[string(leafHash)]
+	n.checkInvariants("IndexOf")
+	return index, found
+}
+
+// buildLeafIndices builds the leaf indices on the first call to IndexOf.
+func (n *NamespacedMerkleTree) buildLeafIndices() {
+	n.leafIndices = make(map[string]int, n.Size())
+	for i, leafHash := range n.leafHashes {
+		if _, found := n.leafIndices[string(leafHash)]; !found {
+			n.leafIndices[string(leafHash)] = i
+		}
+	}
 }
 
 // computeRoot calculates the namespace Merkle root for a tree/sub-tree that
 // encompasses the leaves within the range of [start, end).
 // Any errors returned by this method are irrecoverable and indicate an illegal state of the tree (n).
 func (n *NamespacedMerkleTree) computeRoot(start, end int) ([]byte, error) {
+	return n.computeRootCtx(context.Background(), start, end)
+}
+
+// computeRootCtx is like computeRoot but aborts with ctx.Err() once ctx is
+// done.
+func (n *NamespacedMerkleTree) computeRootCtx(ctx context.Context, start, end int) ([]byte, error) {
+	if err := n.hashPendingLeaves(); err != nil {
+		return nil, err
+	}
 	// in computeRoot, start may be equal to end which indicates an empty tree hence empty root.
 	// Due to this, we need to perform custom range check instead of using validateRange() in which start=end is considered invalid.
-	if start < 0 || start > end || end > n.Size() {
+	if start < 0 || start > end || end > n.sizeWithPadding() {
 		return nil, fmt.Errorf("failed to compute root [%d, %d): %w", start, end, ErrInvalidRange)
 	}
+	if start >= n.Size() && start < end {
+		// only padding leaves are beyond the size of the tree
+		return n.padSubtreeRoot(end - start)
+	}
 	switch end - start {
 	case 0:
 		rootHash := n.treeHasher.EmptyRoot()
-		n.visit(rootHash)
+		n.visitNode(rootHash)
 		return rootHash, nil
 	case 1:
 		leafHash := make([]byte, len(n.leafHashes[start]))
 		copy(leafHash, n.leafHashes[start])
-		n.visit(leafHash, n.leaves[start])
+		n.visitLeaf(leafHash, n.leaves[start])
 		return leafHash, nil
 	default:
-		k := getSplitPoint(end - start)
-		left, err := n.computeRoot(start, start+k)
-		if err != nil { // this should never happen since leaves are added through the Push method, during which leaves formats are validated and their namespace IDs are checked to be sequential.
-			return nil, fmt.Errorf("failed to compute subtree root [%d, %d): %w", start, start+k, err)
-		}
-		right, err := n.computeRoot(start+k, end)
-		if err != nil { // this should never happen since leaves are added through the Push method, during which leaves formats are validated and their namespace IDs are checked to be sequential.
-			return nil, fmt.Errorf("failed to compute subtree root [%d, %d): %w", start+k, end, err)
+		// the number of inner nodes hashed is at most the number of leaves
+		// (not counting padding) plus the height of the subtree
+		estimate := minInt(end, n.Size()) - start + bits.Len(uint(end-start))
+		if n.innerNodes == nil {
+			n.innerNodes = make(map[LeafRange][]byte, estimate)
 		}
-		hash, err := n.treeHasher.HashNode(left, right)
-		if err != nil { // this error should never happen since leaves are added through the Push method, during which leaves formats are validated and their namespace IDs are checked to be sequential.
-			return nil, fmt.Errorf("failed to compute subtree root [%d, %d): %w", left, right, err)
+		arena := nodeArena{nodeSize: len(n.treeHasher.EmptyRoot()), chunkNodes: minInt(estimate, maxArenaChunkNodes)}
+		return n.hashSubtree(ctx, &arena, start, end)
+	}
+}
+
+// hashSubtree computes the root of the subtree covering the leaves [start,
+// end), which spans at least two leaves. Leaf hashes are used without copying
+// them and inner nodes are allocated from arena.
+func (n *NamespacedMerkleTree) hashSubtree(ctx context.Context, arena *nodeArena, start, end int) ([]byte, error) {
+	if start >= n.Size() {
+		// only padding leaves are beyond the size of the tree
+		return n.padSubtreeRoot(end - start)
+	}
+	if end-start == 1 {
+		n.visitLeaf(n.leafHashes[start], n.leaves[start])
+		return n.leafHashes[start], nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	k := getSplitPoint(end - start)
+	left, err := n.hashSubtree(ctx, arena, start, start+k)
+	if err != nil { // this should never happen since leaves are added through the Push method, during which leaves formats are validated and their namespace IDs are checked to be sequential.
+		return nil, fmt.Errorf("failed to compute subtree root [%d, %d): %w", start, start+k, err)
+	}
+	right, err := n.hashSubtree(ctx, arena, start+k, end)
+	if err != nil { // this should never happen since leaves are added through the Push method, during which leaves formats are validated and their namespace IDs are checked to be sequential.
+		return nil, fmt.Errorf("failed to compute subtree root [%d, %d): %w", start+k, end, err)
+	}
+	var hash []byte
+	if nth, ok := n.treeHasher.(*NmtHasher); ok {
+		// hash into the arena rather than allocating each node separately
+		if err = nth.ValidateNodes(left, right); err == nil {
+			hash = nth.hashNodeInto(arena.next(), left, right)
 		}
+	} else {
+		hash, err = n.treeHasher.HashNode(left, right)
+	}
+	if err != nil { // this error should never happen since leaves are added through the Push method, during which leaves formats are validated and their namespace IDs are checked to be sequential.
+		return nil, fmt.Errorf("failed to compute subtree root [%d, %d): %w", start, end, err)
+	}
+	n.metrics.NodeHashed()
+	if n.visit != nil {
 		n.visit(hash, left, right)
-		return hash, nil
 	}
+	n.progress.visited(2)
+	n.innerNodes[LeafRange{Start: start, End: end}] = hash
+	return hash, nil
+}
+
+// visitNode passes a computed node to the configured NodeVisitorFn and records
+// it for progress reporting.
+func (n *NamespacedMerkleTree) visitNode(hash []byte, children ...[]byte) {
+	if n.visit != nil {
+		n.visit(hash, children...)
+	}
+	n.progress.visited(len(children))
+}
+
+// visitLeaf is like visitNode for leaves but does not allocate if there is no
+// NodeVisitorFn.
+func (n *NamespacedMerkleTree) visitLeaf(leafHash, leaf []byte) {
+	if n.visit != nil {
+		n.visit(leafHash, leaf)
+	}
+	n.progress.visited(1)
+}
+
+// maxArenaChunkNodes bounds the number of nodes allocated at once by a
+// nodeArena, and hence the memory retained by a single node.
+const maxArenaChunkNodes = 1024
+
+// nodeArena hands out buffers for nodes of nodeSize bytes carved from chunks
+// of chunkNodes nodes, saving an allocation per node.
+type nodeArena struct {
+	nodeSize, chunkNodes int
+	buf                  []byte
+}
+
+// next returns an empty buffer with a capacity of nodeSize bytes.
+func (a *nodeArena) next() []byte {
+	if len(a.buf) < a.nodeSize {
+		a.buf = make([]byte, a.nodeSize*a.chunkNodes)
+	}
+	node := a.buf[:0:a.nodeSize]
+	a.buf = a.buf[a.nodeSize:]
+	return node
 }
 
 // getSplitPoint returns the largest power of 2 less than the length.
@@ -584,9 +1228,10 @@ func getSplitPoint(length int) int {
 }
 
 func (n *NamespacedMerkleTree) updateNamespaceRanges() {
+	n.ownNamespaceRanges()
 	if n.Size() > 0 {
 		lastIndex := n.Size() - 1
-		lastPushed := n.leaves[lastIndex]
+		lastPushed := n.leafHashes[lastIndex]
 		lastNsStr := string(lastPushed[:n.treeHasher.NamespaceSize()])
 		lastRange, found := n.namespaceRanges[lastNsStr]
 		if !found {
@@ -594,6 +1239,9 @@ func (n *NamespacedMerkleTree) updateNamespaceRanges() {
 				Start: lastIndex,
 				End:   lastIndex + 1,
 			}
+			if n.filter != nil {
+				n.filter.add(lastPushed[:n.treeHasher.NamespaceSize()])
+			}
 		} else {
 			n.namespaceRanges[lastNsStr] = LeafRange{
 				Start: lastRange.Start,
@@ -613,26 +1261,65 @@ func (n *NamespacedMerkleTree) updateNamespaceRanges() {
 // validateAndExtractNamespace returns one of the following errors:
 // - ErrInvalidLeafLen: indicates the length of the ndata is smaller than the tree's NamespaceSize.
 // - ErrInvalidPushOrder: indicates the namespace ID of the ndata is smaller than the last leaf data in the tree.
+// - ErrReservedNamespace: indicates the ndata uses the maximum namespace ID which the tree rejects.
 func (n *NamespacedMerkleTree) validateAndExtractNamespace(ndata namespace.PrefixedData) (namespace.ID, error) {
 	nidSize := int(n.NamespaceSize())
 	if len(ndata) < nidSize {
 		return nil, fmt.Errorf("%w: got: %v, want >= %v", ErrInvalidLeafLen, len(ndata), nidSize)
 	}
-	nID := namespace.ID(ndata[:n.NamespaceSize()])
+	nID := ndata.NamespaceID(n.NamespaceSize())
+	if err := n.validatePushedNamespace(nID); err != nil {
+		return nil, err
+	}
+	return nID, nil
+}
+
+// validatePushedNamespace returns an ErrInvalidPushOrder error if nID is
+// smaller than the namespace ID of the last leaf in the tree, an
+// ErrReservedNamespace error if nID is the maximum namespace ID and the tree
+// rejects it, and a namespace.ErrUnsupportedVersion or
+// namespace.ErrInvalidVersionedID error if nID violates the tree's namespace
+// version rules. It returns an ErrTreeFull error if the tree has a fixed height
+// and no room left.
+func (n *NamespacedMerkleTree) validatePushedNamespace(nID namespace.ID) error {
+	if err := n.validateCapacity(); err != nil {
+		return err
+	}
+	if err := validateNamespaceRules(nID, n.rejectMaxNamespace, n.namespaceVersions); err != nil {
+		return err
+	}
+	nidSize := int(n.NamespaceSize())
 	// ensure pushed data doesn't have a smaller namespace than the previous
 	// one:
 	curSize := n.Size()
 	if curSize > 0 {
-		if nID.Less(n.leaves[curSize-1][:nidSize]) {
-			return nil, fmt.Errorf(
+		var last namespace.ID
+		if n.frontier != nil {
+			last = n.frontier.lastNID
+		} else {
+			last = n.leafHashes[curSize-1][:nidSize]
+		}
+		if nID.Less(last) {
+			return fmt.Errorf(
 				"%w: last namespace: %x, pushed: %x",
 				ErrInvalidPushOrder,
-				n.leaves[curSize-1][:nidSize],
+				last,
 				nID,
 			)
 		}
 	}
-	return nID, nil
+	return nil
+}
+
+// validateNamespaceSize returns an ErrMismatchedNamespaceSize error if the size
+// of nID does not match the tree's namespace size. The length of nID is
+// compared rather than its Size, which wraps around for IDs longer than
+// namespace.IDMaxSize.
+func (n *NamespacedMerkleTree) validateNamespaceSize(nID namespace.ID) error {
+	if len(nID) != int(n.NamespaceSize()) {
+		return fmt.Errorf("%w: got: %v, want: %v", ErrMismatchedNamespaceSize, len(nID), n.NamespaceSize())
+	}
+	return nil
 }
 
 func (n *NamespacedMerkleTree) updateMinMaxID(id namespace.ID) {
@@ -649,10 +1336,10 @@ func (n *NamespacedMerkleTree) updateMinMaxID(id namespace.ID) {
 // The provided range, defined by start and end, is end-exclusive.
 func (n *NamespacedMerkleTree) ComputeSubtreeRoot(start, end int) ([]byte, error) {
 	if start < 0 {
-		return nil, fmt.Errorf("start %d shouldn't be strictly negative", start)
+		return nil, fmt.Errorf("start %d shouldn't be strictly negative: %w", start, ErrInvalidRange)
 	}
 	if end <= start {
-		return nil, fmt.Errorf("end %d should be stricly bigger than start %d", end, start)
+		return nil, fmt.Errorf("end %d should be stricly bigger than start %d: %w", end, start, ErrInvalidRange)
 	}
 	uStart, err := safeIntToUint(start)
 	if err != nil {
@@ -665,7 +1352,35 @@ func (n *NamespacedMerkleTree) ComputeSubtreeRoot(start, end int) ([]byte, error
 	// check if the provided range correctly references an inner node.
 	// calculates the ideal tree from the provided range, and verifies if it is the same as the range
 	if idealTreeRange := nextSubtreeSize(uint64(uStart), uint64(uEnd)); end-start != idealTreeRange {
-		return nil, fmt.Errorf("the provided range [%d, %d) does not construct a valid subtree root range", start, end)
+		return nil, fmt.Errorf("the provided range [%d, %d) does not construct a valid subtree root range: %w", start, end, ErrInvalidSubtreeRange)
+	}
+	return n.computeRoot(start, end)
+}
+
+// SubtreeRoot returns the namespaced root of the subtree covering the leaves
+// [start, end). If the range corresponds to an inner node of the tree (see
+// ComputeSubtreeRoot), the node is returned, preferably from the nodes
+// memoized by Root(). Otherwise, the root of a tree consisting of only the
+// leaves in the range is computed. SubtreeRoot returns an ErrInvalidRange error
+// if the range is empty or not within [0, n.Size()).
+func (n *NamespacedMerkleTree) SubtreeRoot(start, end int) ([]byte, error) {
+	if err := n.validateRange(start, end); err != nil {
+		return nil, err
+	}
+	return n.subtreeRoot(start, end)
+}
+
+// subtreeRoot returns the root of the subtree covering the leaves [start,
+// end), taking it from the inner nodes memoized by Root() if possible.
+func (n *NamespacedMerkleTree) subtreeRoot(start, end int) ([]byte, error) {
+	if err := n.hashPendingLeaves(); err != nil {
+		return nil, err
+	}
+	if end-start == 1 && start < n.Size() {
+		return n.leafHashes[start], nil
+	}
+	if hash, found := n.innerNodes[LeafRange{Start: start, End: end}]; found {
+		return hash, nil
 	}
 	return n.computeRoot(start, end)
 }
@@ -696,5 +1411,8 @@ func MaxNamespace(hash []byte, size namespace.IDSize) []byte {
 
 // Size returns the number of leaves in the tree.
 func (n *NamespacedMerkleTree) Size() int {
+	if n.frontier != nil {
+		return n.frontier.Size()
+	}
 	return len(n.leaves)
 }
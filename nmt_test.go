@@ -2,6 +2,7 @@ package nmt
 
 import (
 	"bytes"
+	"context"
 	"crypto"
 	"crypto/rand"
 	"crypto/sha256"
@@ -724,6 +725,71 @@ func BenchmarkComputeRoot(b *testing.B) {
 	}
 }
 
+func BenchmarkRoot(b *testing.B) {
+	data, err := generateRandNamespacedRawData(1<<16, 8, 64)
+	require.NoError(b, err)
+	tree := New(sha256.New())
+	for _, d := range data {
+		require.NoError(b, tree.Push(d))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.rawRoot, tree.innerNodes = nil, nil
+		if _, err := tree.Root(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRootAllocs(t *testing.T) {
+	skipInDebugMode(t)
+	data, err := generateRandNamespacedRawData(1<<12, 8, 64)
+	require.NoError(t, err)
+	tree := New(sha256.New())
+	for _, d := range data {
+		require.NoError(t, tree.Push(d))
+	}
+	want, err := tree.Root()
+	require.NoError(t, err)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		tree.rawRoot, tree.innerNodes = nil, nil
+		got, err := tree.Root()
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	})
+	// the inner nodes are allocated in chunks rather than one by one
+	assert.Less(t, allocs, float64(64))
+}
+
+func TestPushAllocs(t *testing.T) {
+	skipInDebugMode(t)
+	const size = 1 << 10
+	leaf := []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0}
+	allocs := testing.AllocsPerRun(10, func() {
+		tree := New(sha256.New(), InitialCapacity(size))
+		for i := 0; i < size; i++ {
+			leaf[8], leaf[9] = byte(i>>8), byte(i)
+			require.NoError(t, tree.Push(leaf))
+		}
+	})
+	// the leaf hashes are allocated in chunks rather than one by one, which
+	// saves one of the three allocations per leaf
+	assert.Less(t, allocs/size, 2.5)
+}
+
+func TestLeafArena_Snapshot(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2)
+	snapshot := tree.Snapshot(sha256.New())
+	// both trees allocate the hashes of their next leaves from separate
+	// chunks
+	require.NoError(t, tree.Push([]byte{3, 'a'}))
+	require.NoError(t, snapshot.Push([]byte{4, 'b'}))
+	assert.Equal(t, []byte{3, 3}, tree.leafHashes[2][:2])
+	assert.Equal(t, []byte{4, 4}, snapshot.leafHashes[2][:2])
+}
+
 func Test_Root_RaceCondition(t *testing.T) {
 	// this is very similar to: https://github.com/HuobiRDCenter/huobi_Golang/pull/9
 	tree := New(sha256.New())
@@ -884,6 +950,7 @@ func swap(slice [][]byte, i int, j int) {
 
 // Test_buildRangeProof_Err tests that buildRangeProof returns an error when the underlying tree has an invalid state e.g., leaves are not ordered by namespace ID or a leaf hash is corrupted.
 func Test_buildRangeProof_Err(t *testing.T) {
+	skipInDebugMode(t)
 	nIDList := []byte{1, 2, 3, 4, 5, 6, 7, 8}
 	nIDSize := 2
 
@@ -931,6 +998,7 @@ func Test_buildRangeProof_Err(t *testing.T) {
 
 // Test_ProveRange_Err tests that ProveRange returns an error when the underlying tree has an invalid state e.g., leaves are not ordered by namespace ID or a leaf hash is corrupted.
 func Test_ProveRange_Err(t *testing.T) {
+	skipInDebugMode(t)
 	// create an NMT with 8 sequentially namespaced leaves, numbered from 1 to 8.
 	treeWithCorruptLeafHash := exampleNMT(2, true, 1, 2, 3, 4, 5, 6, 7, 8)
 	// corrupt a leaf hash
@@ -969,6 +1037,7 @@ func Test_ProveRange_Err(t *testing.T) {
 
 // The Test_ProveNamespace_Err function tests that ProveNamespace returns an error when the underlying tree is in an invalid state, such as when the leaves are not ordered by namespace ID or when a leaf hash is corrupt.
 func Test_ProveNamespace_Err(t *testing.T) {
+	skipInDebugMode(t)
 	// create an NMT with 8 sequentially namespaced leaves, numbered from 1 to 8.
 	treeWithCorruptLeafHash := exampleNMT(2, true, 1, 2, 3, 4, 5, 6, 7, 8)
 	// corrupt a leaf hash
@@ -1007,6 +1076,7 @@ func Test_ProveNamespace_Err(t *testing.T) {
 
 // Test_Root_Error tests that the Root method returns an error when the underlying tree is in an invalid state, such as when the leaves are not ordered by namespace ID or when a leaf is corrupt.
 func Test_Root_Error(t *testing.T) {
+	skipInDebugMode(t)
 	// create an NMT with 8 sequentially namespaced leaves, numbered from 1 to 8.
 	treeWithCorruptLeafHash := exampleNMT(2, true, 1, 2, 3, 4, 5, 6, 7, 8)
 	// corrupt a leaf hash
@@ -1040,6 +1110,7 @@ func Test_Root_Error(t *testing.T) {
 
 // Test_computeRoot_Error tests that the computeRoot method returns an error when the underlying tree is in an invalid state, such as when the leaves are not ordered by namespace ID or when a leaf is corrupt.
 func Test_computeRoot_Error(t *testing.T) {
+	skipInDebugMode(t)
 	nIDSize := 2
 	nIDList := []byte{1, 2, 3, 4, 5, 6, 7, 8}
 
@@ -1087,6 +1158,7 @@ func Test_computeRoot_Error(t *testing.T) {
 
 // Test_MinMaxNamespace_Err tests that the MinNamespace and MaxNamespace methods return an error when the underlying tree is in an invalid state, such as when the leaves are not ordered by namespace ID or when a leaf is corrupt.
 func Test_MinMaxNamespace_Err(t *testing.T) {
+	skipInDebugMode(t)
 	// create an NMT with 8 sequentially namespaced leaves, numbered from 1 to 8.
 	treeWithCorruptLeafHash := exampleNMT(2, true, 1, 2, 3, 4, 5, 6, 7, 8)
 	// corrupt a leaf hash
@@ -1188,6 +1260,11 @@ func TestForcedOutOfOrderNamespacedMerkleTree(t *testing.T) {
 		err := tree.ForceAddLeaf(d)
 		assert.NoError(t, err)
 	}
+
+	// leaves shorter than the namespace size are rejected
+	tree = New(sha256.New(), NamespaceIDSize(2))
+	assert.ErrorIs(t, tree.ForceAddLeaf([]byte{1}), ErrInvalidLeafLen)
+	assert.Zero(t, tree.Size())
 }
 
 func TestComputeSubtreeRoot(t *testing.T) {
@@ -1312,3 +1389,366 @@ func TestComputeSubtreeRoot(t *testing.T) {
 		})
 	}
 }
+
+// TestErrorsIs verifies that errors returned by the tree can be matched
+// against the exported sentinel errors using errors.Is.
+func TestErrorsIs(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 3, 4)
+
+	err := tree.Push([]byte{0, 'a'})
+	assert.True(t, errors.Is(err, ErrInvalidPushOrder))
+
+	err = tree.Push([]byte{})
+	assert.True(t, errors.Is(err, ErrInvalidLeafLen))
+
+	_, err = tree.ProveRange(2, 1)
+	assert.True(t, errors.Is(err, ErrInvalidRange))
+
+	_, err = tree.ComputeSubtreeRoot(1, 3)
+	assert.True(t, errors.Is(err, ErrInvalidSubtreeRange))
+
+	_, err = tree.ComputeSubtreeRoot(-1, 3)
+	assert.True(t, errors.Is(err, ErrInvalidRange))
+
+	_, err = ToLeafRanges(0, 4, 0)
+	assert.True(t, errors.Is(err, ErrInvalidSubtreeWidth))
+
+	proof, err := tree.ProveNamespace(namespace.ID{1})
+	require.NoError(t, err)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	nth := NewNmtHasher(sha256.New(), 2, true)
+	_, err = proof.VerifyLeafHashes(nth, false, namespace.ID{1}, [][]byte{tree.leafHashes[0]}, root)
+	assert.True(t, errors.Is(err, ErrMismatchedNamespaceSize))
+
+	nth = NewNmtHasher(sha256.New(), 1, true)
+	_, err = proof.VerifyLeafHashes(nth, false, namespace.ID{1}, [][]byte{tree.leafHashes[1]}, root)
+	assert.True(t, errors.Is(err, ErrInvalidProof))
+}
+
+// TestRootCtx_Canceled verifies that RootCtx and ProveNamespaceCtx stop with
+// the context's error once the context is canceled.
+func TestRootCtx_Canceled(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 3, 4, 5, 6, 7, 8)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := tree.RootCtx(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, tree.rawRoot)
+
+	_, err = tree.ProveNamespaceCtx(ctx, namespace.ID{3})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// a live context yields the same results as the context-free variants
+	gotRoot, err := tree.RootCtx(context.Background())
+	require.NoError(t, err)
+	wantRoot, err := tree.Root()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoot, gotRoot)
+
+	gotProof, err := tree.ProveNamespaceCtx(context.Background(), namespace.ID{3})
+	require.NoError(t, err)
+	wantProof, err := tree.ProveNamespace(namespace.ID{3})
+	require.NoError(t, err)
+	assert.Equal(t, wantProof, gotProof)
+}
+
+func TestIndexOf(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 1, 2)
+	// the index is built on the first call
+	assert.Nil(t, tree.leafIndices)
+	for i, leafHash := range tree.leafHashes {
+		index, found := tree.IndexOf(leafHash)
+		assert.True(t, found)
+		assert.Equal(t, i, index)
+	}
+
+	// a leaf hash taken from an absence proof resolves to its position
+	require.NoError(t, tree.Push([]byte{4, 'x'}))
+	absenceProof, err := tree.ProveNamespace(namespace.ID{3})
+	require.NoError(t, err)
+	index, found := tree.IndexOf(absenceProof.LeafHash())
+	assert.True(t, found)
+	assert.Equal(t, absenceProof.Start(), index)
+
+	// duplicate leaves resolve to the first occurrence
+	require.NoError(t, tree.Push([]byte{4, 'x'}))
+	index, found = tree.IndexOf(tree.leafHashes[5])
+	assert.True(t, found)
+	assert.Equal(t, 4, index)
+
+	_, found = tree.IndexOf([]byte("unknown"))
+	assert.False(t, found)
+}
+
+// TestNamespaceSizes verifies building, proving and verifying trees for a
+// variety of namespace sizes, and that IDs of a mismatching size are rejected.
+func TestNamespaceSizes(t *testing.T) {
+	for _, nidSize := range []int{1, 8, 16, 29, 32} {
+		t.Run(fmt.Sprintf("nidSize=%d", nidSize), func(t *testing.T) {
+			tree := exampleNMT(nidSize, true, 1, 2, 2, 4, 0xFF)
+			root, err := tree.Root()
+			require.NoError(t, err)
+			assert.Len(t, root, 2*nidSize+sha256.Size)
+
+			for _, nid := range []byte{0, 1, 2, 3, 4} {
+				nID := namespace.ID(bytes.Repeat([]byte{nid}, nidSize))
+				proof, err := tree.ProveNamespace(nID)
+				require.NoError(t, err)
+				assert.True(t, proof.VerifyNamespace(sha256.New(), nID, tree.Get(nID), root))
+			}
+
+			// namespace IDs of a different size are rejected everywhere
+			shortID := namespace.ID(bytes.Repeat([]byte{2}, nidSize-1))
+			longID := namespace.ID(bytes.Repeat([]byte{2}, nidSize+1))
+			for _, nID := range []namespace.ID{shortID, longID} {
+				_, err = tree.ProveNamespace(nID)
+				assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+				_, err = tree.ProveNamespaceRange(nID, nID)
+				assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+				_, err = tree.ProveLeafInNamespace(nID, 0)
+				assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+			}
+			assert.ErrorIs(t, tree.Push(namespace.PrefixedData(shortID)), ErrInvalidLeafLen)
+
+			proof, err := tree.ProveNamespace(namespace.ID(bytes.Repeat([]byte{2}, nidSize)))
+			require.NoError(t, err)
+			leaves := tree.Get(namespace.ID(bytes.Repeat([]byte{2}, nidSize)))
+			assert.False(t, proof.VerifyNamespace(sha256.New(), longID, leaves, root))
+			nth := NewNmtHasher(sha256.New(), namespace.IDSize(nidSize), true)
+			_, err = proof.VerifyLeafHashes(nth, true, longID, tree.leafHashes[1:3], root)
+			assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+		})
+	}
+}
+
+func TestNew_MismatchingHasherNamespaceSize(t *testing.T) {
+	shouldPanic(t, func() {
+		_ = New(sha256.New(), NamespaceIDSize(8), CustomHasher(NewNmtHasher(sha256.New(), 29, true)))
+	})
+}
+
+func TestLeaf(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 2)
+	for i := 0; i < tree.Size(); i++ {
+		leaf, err := tree.Leaf(i)
+		require.NoError(t, err)
+		assert.Equal(t, tree.leaves[i], leaf)
+	}
+	_, err := tree.Leaf(-1)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+	_, err = tree.Leaf(3)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+}
+
+func TestProveNamespace_ReusesInnerNodes(t *testing.T) {
+	m := &countingMetrics{}
+	tree := New(sha256.New(), NamespaceIDSize(1), CustomMetrics(m))
+	for i := 0; i < 11; i++ {
+		require.NoError(t, tree.Push(append([]byte{byte(i / 2)}, []byte(fmt.Sprintf("leaf_%d", i))...)))
+	}
+	fresh := exampleNMT(1, true)
+	for _, leaf := range tree.leaves {
+		require.NoError(t, fresh.Push(leaf))
+	}
+
+	root, err := tree.Root()
+	require.NoError(t, err)
+	for nID := byte(0); nID < 7; nID++ {
+		m.nodeHashes = 0
+		proof, err := tree.ProveNamespace(namespace.ID{nID})
+		require.NoError(t, err)
+		assert.Zero(t, m.nodeHashes, "namespace %d", nID)
+
+		// proofs served from memoized nodes equal freshly computed ones
+		want, err := fresh.buildRangeProof(proof.Start(), proof.End())
+		if proof.IsOfAbsence() || proof.IsEmptyProof() {
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, want, proof.Nodes())
+		assert.True(t, proof.VerifyNamespace(sha256.New(), namespace.ID{nID}, tree.Get(namespace.ID{nID}), root))
+	}
+
+	// adding a leaf invalidates the memoized nodes
+	require.NoError(t, tree.Push(append([]byte{7}, []byte("leaf_11")...)))
+	assert.Nil(t, tree.innerNodes)
+	root, err = tree.Root()
+	require.NoError(t, err)
+	proof, err := tree.ProveNamespace(namespace.ID{7})
+	require.NoError(t, err)
+	assert.True(t, proof.VerifyNamespace(sha256.New(), namespace.ID{7}, tree.Get(namespace.ID{7}), root))
+}
+
+func TestRejectMaxNamespace(t *testing.T) {
+	parity := append(bytes.Repeat([]byte{0xFF}, 2), []byte("parity")...)
+
+	tree := New(sha256.New(), NamespaceIDSize(2), RejectMaxNamespace(true))
+	require.NoError(t, tree.Push(append([]byte{0xFF, 0xFE}, []byte("data")...)))
+	assert.ErrorIs(t, tree.Push(parity), ErrReservedNamespace)
+	assert.Equal(t, 1, tree.Size())
+
+	// leaf hashes of the reserved namespace are rejected as well
+	other := New(sha256.New(), NamespaceIDSize(2), IgnoreMaxNamespace(true))
+	require.NoError(t, other.Push(parity))
+	assert.ErrorIs(t, tree.PushLeafHash(other.leafHashes[0]), ErrReservedNamespace)
+
+	// the erasure coding layer may still add parity leaves explicitly
+	require.NoError(t, tree.ForceAddLeaf(parity))
+	assert.Equal(t, 2, tree.Size())
+
+	// by default, the reserved namespace is accepted
+	assert.NoError(t, New(sha256.New(), NamespaceIDSize(2)).Push(parity))
+}
+
+func TestNamespaceVersions(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(4), NamespaceVersions(namespace.VersionRules{
+		0:    namespace.RequireLeadingZeros(2),
+		0xFF: nil,
+	}))
+	require.NoError(t, tree.Push([]byte{0, 0, 0, 1, 'a'}))
+	assert.ErrorIs(t, tree.Push([]byte{0, 0, 1, 1, 'b'}), namespace.ErrInvalidVersionedID)
+	assert.ErrorIs(t, tree.Push([]byte{1, 0, 0, 1, 'c'}), namespace.ErrUnsupportedVersion)
+	require.NoError(t, tree.Push([]byte{0xFF, 0xFF, 0xFF, 0xFF, 'd'}))
+	assert.Equal(t, 2, tree.Size())
+}
+
+func TestSubtreeRoot(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 2, 3, 4, 5, 6)
+	root, err := tree.Root()
+	require.NoError(t, err)
+
+	got, err := tree.SubtreeRoot(0, 7)
+	require.NoError(t, err)
+	assert.Equal(t, root, got)
+
+	// aligned ranges equal the inner nodes of the tree
+	for _, rng := range []LeafRange{{0, 4}, {4, 6}, {2, 4}, {6, 7}} {
+		want, err := tree.ComputeSubtreeRoot(rng.Start, rng.End)
+		require.NoError(t, err)
+		got, err := tree.SubtreeRoot(rng.Start, rng.End)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "range %v", rng)
+	}
+
+	// arbitrary ranges result in the root of a tree of these leaves
+	got, err = tree.SubtreeRoot(1, 4)
+	require.NoError(t, err)
+	sub := exampleNMT(1, true)
+	for _, leaf := range tree.leaves[1:4] {
+		require.NoError(t, sub.Push(leaf))
+	}
+	want, err := sub.Root()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	for _, rng := range []LeafRange{{-1, 2}, {3, 3}, {4, 8}} {
+		_, err := tree.SubtreeRoot(rng.Start, rng.End)
+		assert.ErrorIs(t, err, ErrInvalidRange)
+	}
+}
+
+func TestReset(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 1, 2)
+	_, err := tree.Root()
+	require.NoError(t, err)
+	leafCap := cap(tree.leaves)
+
+	tree.Reset()
+	assert.Equal(t, 0, tree.Size())
+	assert.Equal(t, leafCap, cap(tree.leaves))
+	assert.Empty(t, tree.Get(namespace.ID{1}))
+	root, err := tree.Root()
+	require.NoError(t, err)
+	assert.Equal(t, tree.treeHasher.EmptyRoot(), root)
+
+	// the reset tree behaves like a fresh one
+	fresh := exampleNMT(1, true, 3, 5)
+	for _, leaf := range fresh.leaves {
+		require.NoError(t, tree.Push(leaf))
+	}
+	want, err := fresh.Root()
+	require.NoError(t, err)
+	got, err := tree.Root()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	for _, nID := range []namespace.ID{{1}, {3}, {4}} {
+		wantProof, err := fresh.ProveNamespace(nID)
+		require.NoError(t, err)
+		gotProof, err := tree.ProveNamespace(nID)
+		require.NoError(t, err)
+		assert.Equal(t, wantProof, gotProof)
+	}
+	_, found := tree.IndexOf(fresh.leafHashes[0])
+	assert.True(t, found)
+}
+
+func TestPop(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 1, 2)
+	want := exampleNMT(1, true, 0, 1, 1)
+	_, err := tree.Root()
+	require.NoError(t, err)
+
+	leaf, err := tree.Pop()
+	require.NoError(t, err)
+	assert.Equal(t, append([]byte{2}, []byte("leaf_3")...), leaf)
+	// the inner nodes of the remaining leaves are kept
+	assert.Contains(t, tree.innerNodes, LeafRange{Start: 0, End: 2})
+	assert.NotContains(t, tree.innerNodes, LeafRange{Start: 2, End: 4})
+	assert.NotContains(t, tree.innerNodes, LeafRange{Start: 0, End: 4})
+	requireSameState(t, want, tree)
+	assert.Empty(t, tree.Get(namespace.ID{2}))
+	assert.Equal(t, namespace.ID{1}, tree.MaxLeafNamespace())
+
+	// popping and pushing again restores the tree
+	require.NoError(t, tree.Push(leaf))
+	require.NoError(t, want.Push(leaf))
+	requireSameState(t, want, tree)
+
+	leafHash := tree.leafHashes[3]
+	_, err = tree.Pop()
+	require.NoError(t, err)
+	require.NoError(t, tree.PushLeafHash(leafHash))
+	leaf, err = tree.Pop()
+	require.NoError(t, err)
+	assert.Nil(t, leaf)
+
+	for tree.Size() > 0 {
+		_, err = tree.Pop()
+		require.NoError(t, err)
+	}
+	requireSameState(t, New(sha256.New(), NamespaceIDSize(1)), tree)
+	_, err = tree.Pop()
+	assert.ErrorIs(t, err, ErrInvalidRange)
+}
+
+func TestMinMaxLeafNamespace(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1))
+	assert.Nil(t, tree.MinLeafNamespace())
+	assert.Nil(t, tree.MaxLeafNamespace())
+
+	require.NoError(t, tree.Push([]byte{2, 'a'}))
+	assert.Equal(t, namespace.ID{2}, tree.MinLeafNamespace())
+	assert.Equal(t, namespace.ID{2}, tree.MaxLeafNamespace())
+
+	require.NoError(t, tree.Push([]byte{4, 'b'}))
+	require.NoError(t, tree.Push([]byte{0xFF, 'c'}))
+	assert.Equal(t, namespace.ID{2}, tree.MinLeafNamespace())
+	// the maximum namespace is not ignored
+	assert.Equal(t, namespace.ID{0xFF}, tree.MaxLeafNamespace())
+	maxNs, err := tree.MaxNamespace()
+	require.NoError(t, err)
+	assert.Equal(t, namespace.ID{4}, maxNs)
+	minNs, err := tree.MinNamespace()
+	require.NoError(t, err)
+	assert.Equal(t, tree.MinLeafNamespace(), minNs)
+
+	// the returned IDs are copies
+	tree.MinLeafNamespace()[0] = 9
+	assert.Equal(t, namespace.ID{2}, tree.MinLeafNamespace())
+
+	tree.Reset()
+	assert.Nil(t, tree.MinLeafNamespace())
+}
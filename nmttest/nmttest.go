@@ -0,0 +1,250 @@
+// Package nmttest generates namespaced test data deterministically, e.g., for
+// property tests of code built on namespaced Merkle trees. A Generator seeded
+// with the same value yields the same data on every run and platform, so that
+// failures can be reproduced from the seed alone:
+//
+//	gen := nmttest.New(seed, nmttest.NamespaceSize(8), nmttest.DuplicationRate(0.75))
+//	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(8))
+//	for _, leaf := range gen.Leaves(128) {
+//		if err := tree.Push(leaf); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+package nmttest
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// Options configures a Generator.
+type Options struct {
+	// NamespaceSize is the size of the generated namespace IDs in bytes.
+	// Defaults to 8 bytes.
+	NamespaceSize namespace.IDSize
+	// DuplicationRate is the probability that a leaf generated by Leaves
+	// shares the namespace ID of its predecessor. Defaults to 0.5.
+	DuplicationRate float64
+	// MinLeafSize and MaxLeafSize bound the size of the leaf data in bytes,
+	// excluding the namespace ID. Sizes are distributed uniformly within the
+	// bounds. Default to 1 and 64 bytes.
+	MinLeafSize int
+	MaxLeafSize int
+	// MaxNamespace allows generating the maximum namespace ID, which trees
+	// treat specially if IgnoreMaxNamespace or RejectMaxNamespace is set.
+	// Defaults to false.
+	MaxNamespace bool
+}
+
+type Option func(*Options)
+
+// NamespaceSize sets the size of the generated namespace IDs in bytes.
+func NamespaceSize(size int) Option {
+	if size < 0 || size > namespace.IDMaxSize {
+		panic("Got invalid namespace size. Expected 0 <= size <= namespace.IDMaxSize.")
+	}
+	return func(opts *Options) {
+		opts.NamespaceSize = namespace.IDSize(size)
+	}
+}
+
+// DuplicationRate sets the probability that a leaf shares the namespace ID of
+// its predecessor. A rate of 0 makes the namespace IDs of all leaves distinct
+// if possible, a rate of 1 puts all leaves into a single namespace.
+func DuplicationRate(rate float64) Option {
+	if rate < 0 || rate > 1 || math.IsNaN(rate) {
+		panic("Got invalid duplication rate. Expected 0 <= rate <= 1.")
+	}
+	return func(opts *Options) {
+		opts.DuplicationRate = rate
+	}
+}
+
+// LeafSize sets the bounds (both inclusive) of the size of the leaf data in
+// bytes, excluding the namespace ID.
+func LeafSize(minSize, maxSize int) Option {
+	if minSize < 0 || maxSize < minSize {
+		panic("Got invalid leaf size bounds. Expected 0 <= min <= max.")
+	}
+	return func(opts *Options) {
+		opts.MinLeafSize = minSize
+		opts.MaxLeafSize = maxSize
+	}
+}
+
+// MaxNamespace sets whether the maximum namespace ID may be generated.
+func MaxNamespace(allow bool) Option {
+	return func(opts *Options) {
+		opts.MaxNamespace = allow
+	}
+}
+
+// Generator generates namespace IDs and namespaced leaves from a seeded source
+// of randomness. It is not safe for concurrent use.
+type Generator struct {
+	rnd  *rand.Rand
+	opts Options
+	// maxNID is the maximum namespace ID of the configured size.
+	maxNID namespace.ID
+}
+
+// New creates a Generator seeded with seed.
+func New(seed int64, setters ...Option) *Generator {
+	opts := Options{
+		NamespaceSize:   8,
+		DuplicationRate: 0.5,
+		MinLeafSize:     1,
+		MaxLeafSize:     64,
+	}
+	for _, setter := range setters {
+		setter(&opts)
+	}
+	return &Generator{
+		rnd:    rand.New(rand.NewSource(seed)), //nolint:gosec
+		opts:   opts,
+		maxNID: bytes.Repeat([]byte{0xFF}, int(opts.NamespaceSize)),
+	}
+}
+
+// Options returns the configuration of the generator.
+func (g *Generator) Options() Options {
+	return g.opts
+}
+
+// NamespaceID returns a random namespace ID. It is never the maximum namespace
+// ID unless MaxNamespace is set.
+func (g *Generator) NamespaceID() namespace.ID {
+	nID := make(namespace.ID, g.opts.NamespaceSize)
+	for {
+		_, _ = g.rnd.Read(nID)
+		// the empty namespace ID of size 0 is the only one of its size
+		if g.opts.MaxNamespace || len(nID) == 0 || !nID.Equal(g.maxNID) {
+			return nID
+		}
+	}
+}
+
+// NamespaceIDs returns n distinct random namespace IDs in ascending order. It
+// panics if n exceeds the number of namespace IDs that can be generated, see
+// NamespaceCount.
+func (g *Generator) NamespaceIDs(n int) []namespace.ID {
+	if n < 0 || uint64(n) > g.NamespaceCount() {
+		panic("Got invalid number of namespace IDs. Expected 0 <= n <= NamespaceCount().")
+	}
+	var ids []namespace.ID
+	if count := g.NamespaceCount(); count <= 1<<16 {
+		// sample without replacement from all namespace IDs, which would
+		// otherwise take long if n is close to count
+		ids = make([]namespace.ID, n)
+		for i, v := range g.rnd.Perm(int(count))[:n] {
+			ids[i] = fromUint64(uint64(v), g.opts.NamespaceSize)
+		}
+	} else {
+		seen := make(map[string]struct{}, n)
+		ids = make([]namespace.ID, 0, n)
+		for len(ids) < n {
+			nID := g.NamespaceID()
+			if _, found := seen[string(nID)]; found {
+				continue
+			}
+			seen[string(nID)] = struct{}{}
+			ids = append(ids, nID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Less(ids[j]) })
+	return ids
+}
+
+// NamespaceCount returns the number of distinct namespace IDs the generator
+// can generate, capped at math.MaxUint64.
+func (g *Generator) NamespaceCount() uint64 {
+	size := g.opts.NamespaceSize
+	switch {
+	case size == 0:
+		return 1
+	case size >= 8:
+		return math.MaxUint64
+	}
+	count := uint64(1) << (8 * uint(size))
+	if !g.opts.MaxNamespace {
+		count--
+	}
+	return count
+}
+
+// Leaf returns a leaf of namespace nID, i.e., nID followed by random data of a
+// random size within the configured bounds.
+func (g *Generator) Leaf(nID namespace.ID) []byte {
+	size := g.opts.MinLeafSize + g.rnd.Intn(g.opts.MaxLeafSize-g.opts.MinLeafSize+1)
+	leaf := make([]byte, len(nID)+size)
+	copy(leaf, nID)
+	_, _ = g.rnd.Read(leaf[len(nID):])
+	return leaf
+}
+
+// Leaves returns n namespaced leaves ordered by namespace ID, as required by
+// NamespacedMerkleTree.Push. Each leaf but the first shares the namespace ID
+// of its predecessor with probability DuplicationRate. If the namespace size
+// is too small to provide a distinct namespace ID for each of the remaining
+// leaves, namespace IDs are duplicated more often.
+func (g *Generator) Leaves(n int) [][]byte {
+	if n <= 0 {
+		return [][]byte{}
+	}
+	// runs[i] is the number of consecutive leaves of the i-th namespace
+	runs := []int{1}
+	for i := 1; i < n; i++ {
+		if g.rnd.Float64() < g.opts.DuplicationRate {
+			runs[len(runs)-1]++
+		} else {
+			runs = append(runs, 1)
+		}
+	}
+	for uint64(len(runs)) > g.NamespaceCount() {
+		last := len(runs) - 1
+		runs[last-1] += runs[last]
+		runs = runs[:last]
+	}
+
+	leaves := make([][]byte, 0, n)
+	for i, nID := range g.NamespaceIDs(len(runs)) {
+		for j := 0; j < runs[i]; j++ {
+			leaves = append(leaves, g.Leaf(nID))
+		}
+	}
+	return leaves
+}
+
+// AbsentNamespaceID returns a random namespace ID that lies strictly between
+// the namespace IDs of the sorted leaves but is not used by any of them, e.g.,
+// to test proofs of absence. It returns false if there is no such namespace
+// ID.
+func (g *Generator) AbsentNamespaceID(leaves [][]byte) (namespace.ID, bool) {
+	size := int(g.opts.NamespaceSize)
+	var gaps []namespace.ID
+	for i := 1; i < len(leaves); i++ {
+		prev, cur := namespace.ID(leaves[i-1][:size]), namespace.ID(leaves[i][:size])
+		next, err := prev.Next()
+		if err == nil && next.Less(cur) {
+			gaps = append(gaps, next)
+		}
+	}
+	if len(gaps) == 0 {
+		return nil, false
+	}
+	return gaps[g.rnd.Intn(len(gaps))], true
+}
+
+// fromUint64 is like namespace.FromUint64 for values known to fit into size
+// bytes.
+func fromUint64(v uint64, size namespace.IDSize) namespace.ID {
+	nID, err := namespace.FromUint64(v, size)
+	if err != nil {
+		panic(err)
+	}
+	return nID
+}
@@ -0,0 +1,146 @@
+package nmttest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestLeaves_Reproducible(t *testing.T) {
+	leaves := New(1).Leaves(100)
+	assert.Equal(t, leaves, New(1).Leaves(100))
+	assert.NotEqual(t, leaves, New(2).Leaves(100))
+
+	// the generated data must not change across releases, or seeds reported
+	// by failing tests could not be reproduced
+	h := sha256.New()
+	for _, leaf := range leaves {
+		h.Write(leaf)
+	}
+	assert.Equal(t, "6a1c8ba14db76f64732b8dc3c3e4f24458ee8038f5e803714c943b59707e7a96", hex.EncodeToString(h.Sum(nil)))
+}
+
+func TestLeaves(t *testing.T) {
+	for _, opts := range [][]Option{
+		nil,
+		{NamespaceSize(1), DuplicationRate(0)},
+		{NamespaceSize(2), DuplicationRate(0.9), LeafSize(0, 0)},
+		{NamespaceSize(29), LeafSize(100, 200), MaxNamespace(true)},
+	} {
+		gen := New(7, opts...)
+		o := gen.Options()
+		leaves := gen.Leaves(500)
+		require.Len(t, leaves, 500)
+		size := int(o.NamespaceSize)
+		for i, leaf := range leaves {
+			require.GreaterOrEqual(t, len(leaf), size+o.MinLeafSize)
+			require.LessOrEqual(t, len(leaf), size+o.MaxLeafSize)
+			if i > 0 {
+				require.LessOrEqual(t, bytes.Compare(leaves[i-1][:size], leaf[:size]), 0)
+			}
+		}
+	}
+	assert.Empty(t, New(1).Leaves(0))
+}
+
+func TestLeaves_DuplicationRate(t *testing.T) {
+	for _, rate := range []float64{0, 0.25, 0.5, 0.9, 1} {
+		leaves := New(3, DuplicationRate(rate)).Leaves(2000)
+		duplicates := 0
+		for i := 1; i < len(leaves); i++ {
+			if bytes.Equal(leaves[i-1][:8], leaves[i][:8]) {
+				duplicates++
+			}
+		}
+		assert.InDelta(t, rate, float64(duplicates)/float64(len(leaves)-1), 0.05, "rate %v", rate)
+	}
+
+	// namespace IDs of a single byte cannot be distinct for 1000 leaves
+	leaves := New(3, NamespaceSize(1), DuplicationRate(0)).Leaves(1000)
+	distinct := make(map[byte]struct{})
+	for _, leaf := range leaves {
+		distinct[leaf[0]] = struct{}{}
+	}
+	assert.Len(t, distinct, 255)
+	_, found := distinct[0xFF]
+	assert.False(t, found)
+
+	leaves = New(3, NamespaceSize(0)).Leaves(10)
+	assert.Len(t, leaves, 10)
+}
+
+func TestNamespaceIDs(t *testing.T) {
+	for _, size := range []int{1, 2, 3, 8} {
+		gen := New(5, NamespaceSize(size))
+		n := 200
+		if size == 1 {
+			n = int(gen.NamespaceCount())
+		}
+		ids := gen.NamespaceIDs(n)
+		require.Len(t, ids, n)
+		for i, id := range ids {
+			require.Len(t, id, size)
+			require.False(t, id.Equal(bytes.Repeat([]byte{0xFF}, size)))
+			if i > 0 {
+				require.True(t, ids[i-1].Less(id))
+			}
+		}
+	}
+	assert.Equal(t, uint64(255), New(1, NamespaceSize(1)).NamespaceCount())
+	assert.Equal(t, uint64(256), New(1, NamespaceSize(1), MaxNamespace(true)).NamespaceCount())
+	assert.Panics(t, func() { New(1, NamespaceSize(1)).NamespaceIDs(256) })
+}
+
+func TestAbsentNamespaceID(t *testing.T) {
+	gen := New(9, NamespaceSize(1))
+	leaves := [][]byte{{1}, {2}, {2}, {5}, {6}}
+	for i := 0; i < 10; i++ {
+		nID, found := gen.AbsentNamespaceID(leaves)
+		require.True(t, found)
+		assert.Equal(t, namespace.ID{3}, nID)
+	}
+	_, found := gen.AbsentNamespaceID([][]byte{{1}, {2}, {3}})
+	assert.False(t, found)
+	_, found = gen.AbsentNamespaceID(nil)
+	assert.False(t, found)
+}
+
+func TestOptions_Invalid(t *testing.T) {
+	assert.Panics(t, func() { NamespaceSize(-1) })
+	assert.Panics(t, func() { NamespaceSize(namespace.IDMaxSize + 1) })
+	assert.Panics(t, func() { DuplicationRate(1.5) })
+	assert.Panics(t, func() { LeafSize(2, 1) })
+	assert.Panics(t, func() { LeafSize(-1, 1) })
+}
+
+// TestProperty_NamespaceProofs demonstrates a property test based on the
+// generators: the proof of every present or absent namespace verifies.
+func TestProperty_NamespaceProofs(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		gen := New(seed, NamespaceSize(2), DuplicationRate(0.6))
+		leaves := gen.Leaves(1 + int(seed)*7)
+		tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(2))
+		for _, leaf := range leaves {
+			require.NoError(t, tree.Push(leaf))
+		}
+		root, err := tree.Root()
+		require.NoError(t, err)
+
+		nIDs := []namespace.ID{leaves[0][:2], gen.NamespaceID()}
+		if nID, found := gen.AbsentNamespaceID(leaves); found {
+			nIDs = append(nIDs, nID)
+		}
+		for _, nID := range nIDs {
+			proof, err := tree.ProveNamespace(nID)
+			require.NoError(t, err)
+			assert.True(t, proof.VerifyNamespace(sha256.New(), nID, tree.Get(nID), root), "seed %d, namespace %s", seed, nID)
+		}
+	}
+}
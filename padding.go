@@ -0,0 +1,130 @@
+package nmt
+
+import (
+	"bytes"
+	"fmt"
+	"math/bits"
+)
+
+// PaddingStrategy determines the shape of trees whose number of leaves is not
+// a power of two.
+type PaddingStrategy int
+
+const (
+	// NoPadding splits every subtree at the largest power of two smaller
+	// than its number of leaves, as in RFC 6962. A tree of n leaves has the
+	// same root as before any padding support existed. This is the default.
+	NoPadding PaddingStrategy = iota
+	// PadWithEmptyLeaves pads the tree to the next power of two number of
+	// leaves with empty leaves. An empty leaf consists of the maximum
+	// namespace ID (NamespaceIDSize bytes of 0xFF) and no data, i.e., its
+	// hash is HashLeaf(maxNID). Since the padding carries the maximum
+	// namespace, it is excluded from the root's namespace range if
+	// IgnoreMaxNamespace is set, and proofs of the maximum namespace do not
+	// pass the completeness check.
+	PadWithEmptyLeaves
+	// PadWithLastLeaf pads the tree to the next power of two number of leaves
+	// by repeating the hash of the last leaf. Since the padding carries the
+	// namespace of the last leaf, namespace proofs of that namespace do not
+	// pass the completeness check; inclusion proofs are unaffected.
+	PadWithLastLeaf
+)
+
+// Padding sets the PaddingStrategy of the tree. The padding leaves are not
+// part of the tree, i.e., they are not counted by Size and cannot be
+// retrieved or proven, but they determine the root and appear as regular
+// nodes in proofs, which can hence be verified as usual. An empty tree is not
+// padded and has the root EmptyRoot. Defaults to NoPadding.
+func Padding(p PaddingStrategy) Option {
+	if p < NoPadding || p > PadWithLastLeaf {
+		panic(fmt.Sprintf("Got invalid padding strategy %d.", p))
+	}
+	return func(opts *Options) {
+		opts.Padding = p
+	}
+}
+
+// FixedHeight makes the tree a tree of fixed height k, i.e., of 2^k leaves.
+// Leaves that have not been pushed are treated as empty leaves as with
+// PadWithEmptyLeaves, regardless of the Padding option, and Push returns an
+// ErrTreeFull error once the tree holds 2^k leaves. In particular, the root of
+// an empty tree of fixed height is the root of 2^k empty leaves rather than
+// EmptyRoot. FixedHeight panics if 2^k does not fit into an int.
+func FixedHeight(k int) Option {
+	if k < 0 || k > bits.UintSize-2 {
+		panic(fmt.Sprintf("Got invalid height %d. Expected 0 <= height <= %d.", k, bits.UintSize-2))
+	}
+	return func(opts *Options) {
+		opts.FixedSize = 1 << k
+	}
+}
+
+// Capacity returns the number of leaves of a tree of fixed height (see
+// FixedHeight), or 0 if the height of the tree is not fixed.
+func (n *NamespacedMerkleTree) Capacity() int {
+	return n.fixedSize
+}
+
+// validateCapacity returns an ErrTreeFull error if the tree has a fixed height
+// and no room for another leaf.
+func (n *NamespacedMerkleTree) validateCapacity() error {
+	if n.fixedSize > 0 && n.Size() >= n.fixedSize {
+		return fmt.Errorf("%w: capacity %d", ErrTreeFull, n.fixedSize)
+	}
+	return nil
+}
+
+// sizeWithPadding returns the number of leaves of the tree including padding.
+func (n *NamespacedMerkleTree) sizeWithPadding() int {
+	if n.fixedSize > 0 {
+		return n.fixedSize
+	}
+	if n.padding == NoPadding || n.Size() <= 1 {
+		return n.Size()
+	}
+	return paddedSize(n.Size())
+}
+
+// padSubtreeRoot returns the root of a subtree consisting of width padding
+// leaves, width being a power of two.
+func (n *NamespacedMerkleTree) padSubtreeRoot(width int) ([]byte, error) {
+	if len(n.padNodes) == 0 {
+		var lastLeafHash []byte
+		if n.Size() > 0 {
+			lastLeafHash = n.leafHashes[n.Size()-1]
+		}
+		leaf, err := paddingLeafHash(n.treeHasher, n.padding, lastLeafHash)
+		if err != nil {
+			return nil, err
+		}
+		n.padNodes = [][]byte{leaf}
+	}
+	return padSubtree(n.treeHasher, &n.padNodes, bits.TrailingZeros(uint(width)))
+}
+
+// paddingLeafHash returns the hash of the padding leaves of the given
+// strategy for a tree whose last leaf has the hash lastLeafHash.
+func paddingLeafHash(h Hasher, p PaddingStrategy, lastLeafHash []byte) ([]byte, error) {
+	switch p {
+	case PadWithEmptyLeaves:
+		return h.HashLeaf(bytes.Repeat([]byte{0xFF}, int(h.NamespaceSize())))
+	case PadWithLastLeaf:
+		return lastLeafHash, nil
+	default:
+		return nil, fmt.Errorf("no padding leaf for padding strategy %d", p)
+	}
+}
+
+// padSubtree returns the root of a perfect subtree of the given height whose
+// leaves all equal nodes[0]. nodes memoizes the roots by height.
+func padSubtree(h Hasher, nodes *[][]byte, height int) ([]byte, error) {
+	for len(*nodes) <= height {
+		last := (*nodes)[len(*nodes)-1]
+		node, err := h.HashNode(last, last)
+		if err != nil {
+			return nil, err
+		}
+		*nodes = append(*nodes, node)
+	}
+	return (*nodes)[height], nil
+}
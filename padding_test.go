@@ -0,0 +1,315 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestPadding_Root(t *testing.T) {
+	for _, padding := range []PaddingStrategy{PadWithEmptyLeaves, PadWithLastLeaf} {
+		for size := 0; size <= 17; size++ {
+			t.Run(fmt.Sprintf("padding %d size %d", padding, size), func(t *testing.T) {
+				tree := New(sha256.New(), NamespaceIDSize(1), Padding(padding))
+				computer := NewRootComputer(sha256.New(), NamespaceIDSize(1), Padding(padding))
+				// the padded tree has the same root as an unpadded tree with
+				// explicit padding leaves
+				explicit := New(sha256.New(), NamespaceIDSize(1))
+				var leaf []byte
+				for i := 0; i < size; i++ {
+					leaf = []byte{byte(i), 'd', byte(i)}
+					require.NoError(t, tree.Push(leaf))
+					require.NoError(t, computer.Push(leaf))
+					require.NoError(t, explicit.Push(leaf))
+				}
+				for size > 0 && explicit.Size() < paddedSize(size) {
+					if padding == PadWithEmptyLeaves {
+						require.NoError(t, explicit.Push([]byte{0xFF}))
+					} else {
+						require.NoError(t, explicit.Push(leaf))
+					}
+				}
+				want, err := explicit.Root()
+				require.NoError(t, err)
+
+				got, err := tree.Root()
+				require.NoError(t, err)
+				assert.Equal(t, want, got)
+				assert.Equal(t, size, tree.Size())
+				got, err = computer.Root()
+				require.NoError(t, err)
+				assert.Equal(t, want, got)
+				if size > 0 {
+					got, err = tree.NodeByGIndex(1)
+					require.NoError(t, err)
+					assert.Equal(t, want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestPadding_Proofs(t *testing.T) {
+	for _, padding := range []PaddingStrategy{PadWithEmptyLeaves, PadWithLastLeaf} {
+		tree := New(sha256.New(), NamespaceIDSize(1), Padding(padding))
+		for i := 0; i < 11; i++ {
+			require.NoError(t, tree.Push([]byte{byte(i / 2), 'd', byte(i)}))
+		}
+		root, err := tree.Root()
+		require.NoError(t, err)
+		for i := 0; i < tree.Size(); i++ {
+			proof, err := tree.Prove(i)
+			require.NoError(t, err)
+			leaf := tree.leaves[i]
+			assert.True(t, proof.VerifyInclusion(sha256.New(), leaf[:1], [][]byte{leaf[1:]}, root), "padding %d leaf %d", padding, i)
+		}
+		for nID := byte(0); nID < 5; nID++ {
+			proof, err := tree.ProveNamespace(namespace.ID{nID})
+			require.NoError(t, err)
+			assert.True(t, proof.VerifyNamespace(sha256.New(), namespace.ID{nID}, tree.Get(namespace.ID{nID}), root), "padding %d namespace %d", padding, nID)
+		}
+		// the padding shares the namespace of the last leaf
+		proof, err := tree.ProveNamespace(namespace.ID{5})
+		require.NoError(t, err)
+		assert.Equal(t, padding == PadWithEmptyLeaves, proof.VerifyNamespace(sha256.New(), namespace.ID{5}, tree.Get(namespace.ID{5}), root))
+	}
+}
+
+func TestPadding_ResetsWithLeaves(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), Padding(PadWithLastLeaf))
+	require.NoError(t, tree.Push([]byte{1, 'a'}))
+	require.NoError(t, tree.Push([]byte{1, 'b'}))
+	require.NoError(t, tree.Push([]byte{1, 'c'}))
+	first, err := tree.Root()
+	require.NoError(t, err)
+	require.NoError(t, tree.Push([]byte{1, 'd'}))
+	second, err := tree.Root()
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	tree.Reset()
+	require.NoError(t, tree.Push([]byte{2, 'a'}))
+	require.NoError(t, tree.Push([]byte{2, 'b'}))
+	require.NoError(t, tree.Push([]byte{2, 'c'}))
+	explicit := New(sha256.New(), NamespaceIDSize(1))
+	for _, leaf := range [][]byte{{2, 'a'}, {2, 'b'}, {2, 'c'}, {2, 'c'}} {
+		require.NoError(t, explicit.Push(leaf))
+	}
+	want, err := explicit.Root()
+	require.NoError(t, err)
+	got, err := tree.Root()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestPadding_Invalid(t *testing.T) {
+	assert.Panics(t, func() { Padding(PaddingStrategy(3)) })
+	assert.Panics(t, func() { Padding(PaddingStrategy(-1)) })
+}
+
+func TestFixedHeight_Root(t *testing.T) {
+	for height := 0; height <= 4; height++ {
+		capacity := 1 << height
+		for size := 0; size <= capacity; size++ {
+			t.Run(fmt.Sprintf("height %d size %d", height, size), func(t *testing.T) {
+				tree := New(sha256.New(), NamespaceIDSize(1), FixedHeight(height))
+				computer := NewRootComputer(sha256.New(), NamespaceIDSize(1), FixedHeight(height))
+				explicit := New(sha256.New(), NamespaceIDSize(1))
+				for i := 0; i < size; i++ {
+					leaf := []byte{byte(i), 'd'}
+					require.NoError(t, tree.Push(leaf))
+					require.NoError(t, computer.Push(leaf))
+					require.NoError(t, explicit.Push(leaf))
+				}
+				for explicit.Size() < capacity {
+					require.NoError(t, explicit.Push([]byte{0xFF}))
+				}
+				want, err := explicit.Root()
+				require.NoError(t, err)
+
+				assert.Equal(t, capacity, tree.Capacity())
+				assert.Equal(t, size, tree.Size())
+				got, err := tree.Root()
+				require.NoError(t, err)
+				assert.Equal(t, want, got)
+				got, err = computer.Root()
+				require.NoError(t, err)
+				assert.Equal(t, want, got)
+			})
+		}
+	}
+}
+
+func TestFixedHeight_Full(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), FixedHeight(1))
+	computer := NewRootComputer(sha256.New(), NamespaceIDSize(1), FixedHeight(1))
+	for i := 0; i < 2; i++ {
+		require.NoError(t, tree.Push([]byte{1, byte(i)}))
+		require.NoError(t, computer.Push([]byte{1, byte(i)}))
+	}
+	assert.ErrorIs(t, tree.Push([]byte{1, 2}), ErrTreeFull)
+	assert.ErrorIs(t, tree.ForceAddLeaf([]byte{1, 2}), ErrTreeFull)
+	assert.ErrorIs(t, tree.PushLeafHash(tree.leafHashes[1]), ErrTreeFull)
+	assert.ErrorIs(t, computer.Push([]byte{1, 2}), ErrTreeFull)
+	assert.Equal(t, 2, tree.Size())
+
+	tree.Reset()
+	assert.NoError(t, tree.Push([]byte{1, 2}))
+	assert.Equal(t, 0, New(sha256.New()).Capacity())
+}
+
+func TestFixedHeight_Proofs(t *testing.T) {
+	for _, ignoreMax := range []bool{true, false} {
+		tree := New(sha256.New(), NamespaceIDSize(1), FixedHeight(4), IgnoreMaxNamespace(ignoreMax))
+		for i := 0; i < 5; i++ {
+			require.NoError(t, tree.Push([]byte{byte(2 * i), 'd', byte(i)}))
+		}
+		root, err := tree.Root()
+		require.NoError(t, err)
+		for i := 0; i < tree.Size(); i++ {
+			proof, err := tree.Prove(i)
+			require.NoError(t, err)
+			leaf := tree.leaves[i]
+			assert.True(t, proof.VerifyInclusion(sha256.New(), leaf[:1], [][]byte{leaf[1:]}, root))
+		}
+		// namespaces beyond the last leaf are proven absent by the first
+		// empty leaf if the maximum namespace is not ignored
+		for nID := byte(0); nID < 12; nID++ {
+			proof, err := tree.ProveNamespace(namespace.ID{nID})
+			require.NoError(t, err)
+			leaves := tree.Get(namespace.ID{nID})
+			assert.True(t, proof.VerifyNamespace(sha256.New(), namespace.ID{nID}, leaves, root), "ignoreMax %t namespace %d", ignoreMax, nID)
+			if nID > 8 && !ignoreMax {
+				assert.True(t, proof.IsOfAbsence())
+				assert.Equal(t, tree.Size(), proof.Start())
+			}
+		}
+	}
+}
+
+func TestFixedHeight_Empty(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), FixedHeight(2))
+	root, err := tree.Root()
+	require.NoError(t, err)
+	assert.NotEqual(t, tree.treeHasher.EmptyRoot(), root)
+
+	proof, err := tree.ProveNamespace(namespace.ID{1})
+	require.NoError(t, err)
+	assert.True(t, proof.VerifyNamespace(sha256.New(), namespace.ID{1}, nil, root))
+}
+
+func TestFixedHeight_Invalid(t *testing.T) {
+	assert.Panics(t, func() { FixedHeight(-1) })
+	assert.Panics(t, func() { FixedHeight(64) })
+}
+
+func TestPadding_AllProofs(t *testing.T) {
+	setters := map[string]Option{
+		"empty leaves": Padding(PadWithEmptyLeaves),
+		"last leaf":    Padding(PadWithLastLeaf),
+		"fixed height": FixedHeight(3),
+	}
+	for name, setter := range setters {
+		for _, size := range []int{3, 5, 6, 7} {
+			t.Run(fmt.Sprintf("%s size %d", name, size), func(t *testing.T) {
+				// leaf i has namespace 2*(i/2), the odd namespaces are absent
+				newTree := func(size int) *NamespacedMerkleTree {
+					tree := New(sha256.New(), NamespaceIDSize(1), setter)
+					for i := 0; i < size; i++ {
+						require.NoError(t, tree.Push([]byte{byte(2 * (i / 2)), 'd', byte(i)}))
+					}
+					return tree
+				}
+				tree := newTree(size)
+				root, err := tree.Root()
+				require.NoError(t, err)
+				// the padding shares the namespace of the last leaf if the
+				// tree is padded with it, which fails the completeness check
+				lastNID := namespace.ID{byte(2 * ((size - 1) / 2))}
+				complete := func(nID namespace.ID) bool {
+					return name != "last leaf" || !nID.Equal(lastNID)
+				}
+
+				for i := 0; i < size; i++ {
+					proof, err := tree.Prove(i)
+					require.NoError(t, err)
+					leaf := tree.leaves[i]
+					assert.True(t, proof.VerifyInclusion(sha256.New(), leaf[:1], [][]byte{leaf[1:]}, root), "Prove(%d)", i)
+
+					// the leaves 2k and 2k+1 share a namespace
+					start, end := i&^1, minInt(i&^1+2, size)
+					proof, err = tree.ProveRange(start, end)
+					require.NoError(t, err)
+					var leaves [][]byte
+					for _, leaf := range tree.leaves[start:end] {
+						leaves = append(leaves, leaf[1:])
+					}
+					assert.True(t, proof.VerifyInclusion(sha256.New(), leaf[:1], leaves, root), "ProveRange(%d, %d)", start, end)
+				}
+
+				indices := make([]int, size)
+				for i := range indices {
+					indices[i] = i
+				}
+				for i := 0; i < size; i++ {
+					multiProof, err := tree.ProveMulti(indices[i:])
+					require.NoError(t, err)
+					assert.True(t, multiProof.VerifyInclusion(sha256.New(), 1, tree.leaves[i:], root), "ProveMulti(%v)", indices[i:])
+				}
+
+				var absent []namespace.ID
+				for nID := byte(0); nID <= lastNID[0]+1; nID++ {
+					leaves := tree.Get(namespace.ID{nID})
+					if leaves == nil && nID < lastNID[0] {
+						absent = append(absent, namespace.ID{nID})
+					}
+					proof, err := tree.ProveNamespace(namespace.ID{nID})
+					require.NoError(t, err)
+					assert.Equal(t, complete(namespace.ID{nID}), proof.VerifyNamespace(sha256.New(), namespace.ID{nID}, leaves, root), "ProveNamespace(%d)", nID)
+
+					for offset := range leaves {
+						proof, err := tree.ProveLeafInNamespace(namespace.ID{nID}, offset)
+						require.NoError(t, err)
+						assert.True(t, proof.VerifyLeafInNamespace(sha256.New(), namespace.ID{nID}, offset, leaves[offset], root), "ProveLeafInNamespace(%d, %d)", nID, offset)
+					}
+				}
+
+				proof, err := tree.ProveNamespaceRange(namespace.ID{0}, lastNID)
+				require.NoError(t, err)
+				assert.Equal(t, complete(lastNID), proof.VerifyNamespaceRange(sha256.New(), namespace.ID{0}, lastNID, tree.leaves, root), "ProveNamespaceRange")
+
+				proofs, err := tree.ProveAllNamespaces()
+				require.NoError(t, err)
+				for key, proof := range proofs {
+					nID := namespace.ID(key)
+					assert.Equal(t, complete(nID), proof.VerifyNamespace(sha256.New(), nID, tree.Get(nID), root), "ProveAllNamespaces(%d)", nID)
+				}
+
+				batch, err := tree.ProveAbsence(absent)
+				require.NoError(t, err)
+				assert.NoError(t, batch.Verify(sha256.New(), absent, root), "ProveAbsence(%v)", absent)
+
+				sizeProof, err := tree.ProveSize()
+				require.NoError(t, err)
+				assert.NoError(t, sizeProof.Verify(sha256.New(), root, NamespaceIDSize(1), setter), "ProveSize")
+				sizeProof, err = tree.ProveLastLeaf()
+				require.NoError(t, err)
+				_, err = sizeProof.VerifyLastLeaf(sha256.New(), root, NamespaceIDSize(1), setter)
+				assert.NoError(t, err, "ProveLastLeaf")
+
+				oldTree := newTree(1)
+				oldRoot, err := oldTree.Root()
+				require.NoError(t, err)
+				transition, err := ProveNamespaceTransition(oldTree, tree, namespace.ID{0})
+				require.NoError(t, err)
+				err = transition.Verify(sha256.New(), namespace.ID{0}, tree.leaves[1:2], oldRoot, root)
+				assert.Equal(t, complete(namespace.ID{0}), err == nil, "ProveNamespaceTransition: %v", err)
+			})
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package nmt
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// PartialTree is a partially known NMT with a trusted root. It is assembled
+// from verified range proofs: every node a proof reveals or allows to
+// recompute is cached, keyed by the range of leaves it covers. Further queries
+// (roots of covered ranges, membership of collected leaves) are answered from
+// that cache without any additional proof.
+//
+// The size of the tree, i.e., its number of leaves, must be known upfront so
+// that the range of every node can be determined exactly. Padded trees are
+// supported as well: their padding leaves are part of the shape of the tree,
+// but only the nodes covering pushed leaves are answered for.
+type PartialTree struct {
+	nth  *NmtHasher
+	size int
+	// width is the number of leaves of the tree including its padding, i.e.,
+	// size for trees without padding.
+	width int
+	root  []byte
+	// nodes holds the namespaced hashes of all known nodes keyed by the range
+	// of leaves they cover. Leaves are stored with a range of size 1.
+	nodes map[LeafRange][]byte
+}
+
+// NewPartialTree creates an empty PartialTree for a tree of size leaves with
+// the given trusted root. `h`, nIDSize and ignoreMaxNamespace MUST match the
+// configuration of the tree the root was computed for, and so must the Padding
+// and FixedHeight options passed as setters, which determine the shape of
+// padded trees; all other options are ignored. It returns an ErrInvalidRange
+// error if size is negative or exceeds the capacity of a tree of fixed height.
+func NewPartialTree(h hash.Hash, nIDSize namespace.IDSize, ignoreMaxNamespace bool, size int, root []byte, setters ...Option) (*PartialTree, error) {
+	nth := NewNmtHasher(h, nIDSize, ignoreMaxNamespace)
+	var opts Options
+	for _, setter := range setters {
+		setter(&opts)
+	}
+	if size < 0 {
+		return nil, fmt.Errorf("tree size %d shouldn't be strictly negative: %w", size, ErrInvalidRange)
+	}
+	if opts.FixedSize > 0 && size > opts.FixedSize {
+		return nil, fmt.Errorf("tree size %d exceeds the capacity %d: %w", size, opts.FixedSize, ErrInvalidRange)
+	}
+	if err := nth.ValidateNodeFormat(root); err != nil {
+		return nil, fmt.Errorf("root does not match the NMT hasher's hash format: %w", err)
+	}
+	// mirrors NamespacedMerkleTree.sizeWithPadding
+	width := size
+	switch {
+	case opts.FixedSize > 0:
+		width = opts.FixedSize
+	case opts.Padding != NoPadding && size > 1:
+		width = paddedSize(size)
+	}
+	pt := &PartialTree{
+		nth:   nth,
+		size:  size,
+		width: width,
+		root:  root,
+		nodes: make(map[LeafRange][]byte),
+	}
+	if width > 0 {
+		pt.nodes[LeafRange{Start: 0, End: width}] = root
+	}
+	return pt, nil
+}
+
+// Root returns the trusted root of the tree.
+func (pt *PartialTree) Root() []byte {
+	return pt.root
+}
+
+// Size returns the number of leaves of the tree.
+func (pt *PartialTree) Size() int {
+	return pt.size
+}
+
+// AddProof verifies proof for the supplied leaf hashes against the trusted
+// root and, if valid, caches all nodes it reveals. For inclusion proofs,
+// leafHashes holds the hashes of the leaves in [proof.Start(), proof.End());
+// for absence proofs it holds proof.LeafHash(). Nothing is cached if the
+// verification fails, in which case an error wrapping ErrInvalidProof (or the
+// root cause of the failure) is returned.
+func (pt *PartialTree) AddProof(proof Proof, leafHashes [][]byte) error {
+	if proof.Start() < 0 || proof.Start() >= proof.End() || proof.End() > pt.size {
+		return fmt.Errorf("proof range [%d, %d) is not valid for a tree of size %d: %w", proof.Start(), proof.End(), pt.size, ErrInvalidRange)
+	}
+	if len(leafHashes) != proof.End()-proof.Start() {
+		return fmt.Errorf("supplied leafHashes size %d, expected size %d: %w", len(leafHashes), proof.End()-proof.Start(), ErrWrongLeafHashesSize)
+	}
+	if err := validateLeafHashes(pt.nth, leafHashes); err != nil {
+		return err
+	}
+	if err := proof.validateFormat(pt.nth, pt.root); err != nil {
+		return err
+	}
+
+	nodes := proof.Nodes()
+	discovered := make(map[LeafRange][]byte)
+	order := namespaceOrder{nth: pt.nth}
+	var recurse func(start, end int) ([]byte, error)
+	recurse = func(start, end int) ([]byte, error) {
+		if start >= pt.width {
+			return nil, nil
+		}
+		rng := LeafRange{Start: start, End: minInt(end, pt.width)}
+		var hash []byte
+		switch {
+		case end <= proof.Start() || start >= proof.End():
+			// no overlap with the proof range, hence the proof contains
+			// the root of this subtree
+			hash = popIfNonEmpty(&nodes)
+			if hash == nil {
+				return nil, fmt.Errorf("missing proof node for range [%d, %d): %w", rng.Start, rng.End, ErrInvalidProof)
+			}
+			if err := order.next(hash); err != nil {
+				return nil, err
+			}
+		case end-start == 1:
+			hash = leafHashes[start-proof.Start()]
+			if err := order.next(hash); err != nil {
+				return nil, err
+			}
+		default:
+			k := getSplitPoint(end - start)
+			left, err := recurse(start, start+k)
+			if err != nil {
+				return nil, err
+			}
+			right, err := recurse(start+k, end)
+			if err != nil {
+				return nil, err
+			}
+			if right == nil {
+				hash = left
+			} else if hash, err = pt.nth.HashNode(left, right); err != nil {
+				return nil, fmt.Errorf("failed to hash node: %w", err)
+			}
+		}
+		discovered[rng] = hash
+		return hash, nil
+	}
+
+	fullTreeSize := getSplitPoint(pt.width) * 2
+	if fullTreeSize < 1 {
+		fullTreeSize = 1
+	}
+	rootHash, err := recurse(0, fullTreeSize)
+	if err != nil {
+		return err
+	}
+	if len(nodes) != 0 {
+		return fmt.Errorf("%d unused proof nodes: %w", len(nodes), ErrInvalidProof)
+	}
+	if !bytes.Equal(rootHash, pt.root) {
+		return fmt.Errorf("proof does not match the trusted root: %w", ErrInvalidProof)
+	}
+	for rng, hash := range discovered {
+		pt.nodes[rng] = hash
+	}
+	return nil
+}
+
+// AddNamespaceProof verifies a namespace proof, as returned by
+// NamespacedMerkleTree.ProveNamespace, for the namespace-prefixed leaves of
+// namespace nID and caches all nodes it reveals. It fails if the proof does
+// not pass Proof.VerifyNamespace.
+func (pt *PartialTree) AddNamespaceProof(proof Proof, nID namespace.ID, leaves [][]byte) error {
+	if !proof.VerifyNamespace(pt.nth.baseHasher, nID, leaves, pt.root) {
+		return fmt.Errorf("namespace proof for %x does not verify: %w", nID, ErrInvalidProof)
+	}
+	if proof.IsEmptyProof() {
+		return nil
+	}
+	if proof.IsOfAbsence() {
+		return pt.AddProof(proof, [][]byte{proof.LeafHash()})
+	}
+	leafHashes := make([][]byte, 0, len(leaves))
+	for _, leaf := range leaves {
+		leafHash, err := pt.nth.HashLeaf(leaf)
+		if err != nil {
+			return err
+		}
+		leafHashes = append(leafHashes, leafHash)
+	}
+	return pt.AddProof(proof, leafHashes)
+}
+
+// LeafHash returns the leaf hash at the given index if it has been collected
+// through a previously added proof.
+func (pt *PartialTree) LeafHash(index int) ([]byte, bool) {
+	hash, found := pt.nodes[LeafRange{Start: index, End: index + 1}]
+	return hash, found
+}
+
+// HasLeaf returns true if a leaf with the given leaf hash has been collected
+// at the given index.
+func (pt *PartialTree) HasLeaf(index int, leafHash []byte) bool {
+	hash, found := pt.LeafHash(index)
+	return found && bytes.Equal(hash, leafHash)
+}
+
+// SubtreeRoot returns the root of the subtree covering the leaves
+// [start, end), provided that [start, end) corresponds to a node of the tree
+// (see NamespacedMerkleTree.ComputeSubtreeRoot) and that the node is either
+// cached or can be computed from cached nodes. The second return value is false
+// otherwise. For padded trees, only nodes covering no padding leaves are
+// answered for.
+func (pt *PartialTree) SubtreeRoot(start, end int) ([]byte, bool) {
+	if start < 0 || end <= start || end > pt.size {
+		return nil, false
+	}
+	if !isNodeRange(start, end, pt.width) {
+		return nil, false
+	}
+	return pt.subtreeRoot(start, end)
+}
+
+// isNodeRange returns true if [start, end) is the range of leaves covered by a
+// node of a tree with size leaves.
+func isNodeRange(start, end, size int) bool {
+	nodeStart, nodeEnd := 0, getSplitPoint(size)*2
+	for nodeStart < size {
+		if nodeStart == start && minInt(nodeEnd, size) == end {
+			return true
+		}
+		if nodeEnd-nodeStart <= 1 {
+			return false
+		}
+		k := (nodeEnd - nodeStart) / 2
+		if start < nodeStart+k {
+			nodeEnd = nodeStart + k
+		} else {
+			nodeStart += k
+		}
+	}
+	return false
+}
+
+func (pt *PartialTree) subtreeRoot(start, end int) ([]byte, bool) {
+	if hash, found := pt.nodes[LeafRange{Start: start, End: end}]; found {
+		return hash, true
+	}
+	if end-start <= 1 {
+		return nil, false
+	}
+	k := getSplitPoint(end - start)
+	left, found := pt.subtreeRoot(start, start+k)
+	if !found {
+		return nil, false
+	}
+	right, found := pt.subtreeRoot(start+k, end)
+	if !found {
+		return nil, false
+	}
+	hash, err := pt.nth.HashNode(left, right)
+	if err != nil {
+		return nil, false
+	}
+	pt.nodes[LeafRange{Start: start, End: end}] = hash
+	return hash, true
+}
@@ -0,0 +1,130 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestPartialTree(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 2, 3, 4, 5, 6)
+	root, err := tree.Root()
+	require.NoError(t, err)
+
+	pt, err := NewPartialTree(sha256.New(), 1, true, tree.Size(), root)
+	require.NoError(t, err)
+	gotRoot, found := pt.SubtreeRoot(0, tree.Size())
+	require.True(t, found)
+	assert.Equal(t, root, gotRoot)
+
+	// nothing is known about the leaves yet
+	_, found = pt.LeafHash(1)
+	assert.False(t, found)
+	_, found = pt.SubtreeRoot(0, 2)
+	assert.False(t, found)
+
+	proof, err := tree.ProveNamespace(namespace.ID{1})
+	require.NoError(t, err)
+	require.NoError(t, pt.AddNamespaceProof(proof, namespace.ID{1}, tree.Get(namespace.ID{1})))
+	assert.True(t, pt.HasLeaf(1, tree.leafHashes[1]))
+	assert.False(t, pt.HasLeaf(1, tree.leafHashes[2]))
+	// leaf 0 is revealed as a sibling in the proof
+	assert.True(t, pt.HasLeaf(0, tree.leafHashes[0]))
+
+	proof, err = tree.Prove(5)
+	require.NoError(t, err)
+	require.NoError(t, pt.AddProof(proof, tree.leafHashes[5:6]))
+
+	for _, rng := range []LeafRange{{0, 2}, {0, 4}, {2, 4}, {4, 6}, {4, 7}, {6, 7}, {0, 7}} {
+		want, err := tree.computeRoot(rng.Start, rng.End)
+		require.NoError(t, err)
+		got, found := pt.SubtreeRoot(rng.Start, rng.End)
+		require.True(t, found, "range %v", rng)
+		assert.Equal(t, want, got, "range %v", rng)
+	}
+
+	// ranges that are not nodes of the tree are rejected
+	for _, rng := range []LeafRange{{1, 3}, {5, 7}, {0, 3}, {0, 8}, {-1, 1}} {
+		_, found = pt.SubtreeRoot(rng.Start, rng.End)
+		assert.False(t, found, "range %v", rng)
+	}
+}
+
+func TestPartialTree_InvalidProofs(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 2, 3)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	pt, err := NewPartialTree(sha256.New(), 1, true, tree.Size(), root)
+	require.NoError(t, err)
+
+	proof, err := tree.Prove(1)
+	require.NoError(t, err)
+	assert.ErrorIs(t, pt.AddProof(proof, tree.leafHashes[2:3]), ErrInvalidProof)
+	assert.ErrorIs(t, pt.AddProof(proof, tree.leafHashes[1:3]), ErrWrongLeafHashesSize)
+	_, found := pt.LeafHash(0)
+	assert.False(t, found)
+
+	truncated := NewInclusionProof(1, 2, proof.Nodes()[:1], true)
+	assert.ErrorIs(t, pt.AddProof(truncated, tree.leafHashes[1:2]), ErrInvalidProof)
+
+	outOfRange := NewInclusionProof(4, 5, proof.Nodes(), true)
+	assert.ErrorIs(t, pt.AddProof(outOfRange, tree.leafHashes[1:2]), ErrInvalidRange)
+
+	_, err = NewPartialTree(sha256.New(), 1, true, 4, []byte{1})
+	assert.ErrorIs(t, err, ErrInvalidNodeLen)
+}
+
+func TestPartialTree_UnorderedNodes(t *testing.T) {
+	leafHashes, root := misorderedTree(t)
+	pt, err := NewPartialTree(sha256.New(), 1, true, 4, root)
+	require.NoError(t, err)
+	nth := NewNmtHasher(sha256.New(), 1, true)
+	right, err := nth.HashNode(leafHashes[2], leafHashes[3])
+	require.NoError(t, err)
+	proof := NewInclusionProof(0, 1, [][]byte{leafHashes[1], right}, true)
+	assert.ErrorIs(t, pt.AddProof(proof, leafHashes[:1]), ErrUnorderedSiblings)
+}
+
+func TestPartialTree_Padded(t *testing.T) {
+	for _, setter := range []Option{Padding(PadWithEmptyLeaves), Padding(PadWithLastLeaf), FixedHeight(4)} {
+		tree := New(sha256.New(), NamespaceIDSize(1), setter)
+		for i := 0; i < 5; i++ {
+			require.NoError(t, tree.Push([]byte{byte(i), 'a'}))
+		}
+		root, err := tree.Root()
+		require.NoError(t, err)
+
+		pt, err := NewPartialTree(sha256.New(), 1, true, tree.Size(), root, setter)
+		require.NoError(t, err)
+		for i := 0; i < tree.Size(); i++ {
+			proof, err := tree.Prove(i)
+			require.NoError(t, err)
+			require.NoError(t, pt.AddProof(proof, tree.leafHashes[i:i+1]), "leaf %d", i)
+			assert.True(t, pt.HasLeaf(i, tree.leafHashes[i]))
+		}
+		for _, rng := range []LeafRange{{0, 2}, {0, 4}, {4, 5}} {
+			want, err := tree.computeRoot(rng.Start, rng.End)
+			require.NoError(t, err)
+			got, found := pt.SubtreeRoot(rng.Start, rng.End)
+			require.True(t, found, "range %v", rng)
+			assert.Equal(t, want, got, "range %v", rng)
+		}
+		// nodes covering padding leaves are not answered for
+		_, found := pt.SubtreeRoot(0, 5)
+		assert.False(t, found)
+
+		// the shape of an unpadded tree does not match the root
+		unpadded, err := NewPartialTree(sha256.New(), 1, true, tree.Size(), root)
+		require.NoError(t, err)
+		proof, err := tree.Prove(4)
+		require.NoError(t, err)
+		assert.Error(t, unpadded.AddProof(proof, tree.leafHashes[4:5]))
+	}
+
+	_, err := NewPartialTree(sha256.New(), 1, true, 5, make([]byte, 34), FixedHeight(2))
+	assert.ErrorIs(t, err, ErrInvalidRange)
+}
@@ -0,0 +1,225 @@
+package nmt
+
+import (
+	"context"
+	"fmt"
+	"hash"
+	"runtime"
+	"sync"
+)
+
+// pipelineBatchSize is the maximum number of leaves hashed by a worker at
+// once, which amortizes the synchronization between the stages of
+// BuildFromChannel.
+const pipelineBatchSize = 64
+
+// pipelineBatch is a sequence of consecutive leaves hashed by a single
+// worker. done is closed once the leaves are hashed.
+type pipelineBatch struct {
+	leaves [][]byte
+	hashes [][]byte
+	// err is the error of the first leaf that could not be hashed, at index
+	// errIndex of the batch.
+	err      error
+	errIndex int
+	done     chan struct{}
+}
+
+func newPipelineBatch() *pipelineBatch {
+	return &pipelineBatch{leaves: make([][]byte, 0, pipelineBatchSize), done: make(chan struct{})}
+}
+
+// BuildFromChannel builds a tree from the namespace-prefixed leaves received
+// from leaves, which must be ordered by namespace ID as for Push, and returns
+// the tree together with its root once leaves is closed. The leaves are hashed
+// by the given number of worker goroutines, each using its own instance of
+// newHash, while the tree and its root are assembled concurrently with the
+// ingestion of further leaves. If workers is not positive, GOMAXPROCS workers
+// are used. The tree is configured by setters as for New; since hashers cannot
+// be shared by workers, BuildFromChannel panics if setters include a
+// CustomHasher.
+//
+// As for Push, the tree retains the received leaves, which must not be
+// modified afterwards. BuildFromChannel returns the first error Push would
+// return for the leaves, or an error wrapping ctx.Err() if ctx is done before
+// leaves is closed. It stops receiving from leaves on error, so producers
+// should stop sending once ctx is done or the result is returned.
+func BuildFromChannel(ctx context.Context, newHash func() hash.Hash, leaves <-chan []byte, workers int, setters ...Option) (*NamespacedMerkleTree, []byte, error) {
+	tree := New(newHash(), setters...)
+	nth, ok := tree.treeHasher.(*NmtHasher)
+	if !ok {
+		panic("Got CustomHasher. Expected the default hasher, which can be instantiated per worker.")
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	// the root is computed incrementally while the leaves are assembled
+	computer := NewRootComputer(newHash(), setters...)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// order holds the batches in the order of their leaves, bounding the
+	// number of batches in flight
+	order := make(chan *pipelineBatch, 2*workers)
+	jobs := make(chan *pipelineBatch, workers)
+	var wg sync.WaitGroup
+	// cancel before waiting for the goroutines to stop
+	defer wg.Wait()
+	defer cancel()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		defer close(order)
+		dispatchPipelineBatches(ctx, leaves, order, jobs)
+	}()
+	for i := 0; i < workers; i++ {
+		hasher := NewNmtHasher(newHash(), nth.NamespaceSize(), nth.IsMaxNamespaceIDIgnored())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range jobs {
+				hashPipelineBatch(hasher, batch)
+			}
+		}()
+	}
+
+	index := 0
+	for batch := range order {
+		select {
+		case <-batch.done:
+		case <-ctx.Done():
+			return nil, nil, fmt.Errorf("building tree aborted after %d leaves: %w", index, ctx.Err())
+		}
+		for i, leaf := range batch.leaves {
+			if batch.err != nil && i == batch.errIndex {
+				return nil, nil, fmt.Errorf("leaf %d: %w", index, batch.err)
+			}
+			if err := tree.pushHashed(leaf, batch.hashes[i]); err != nil {
+				return nil, nil, fmt.Errorf("leaf %d: %w", index, err)
+			}
+			if err := computer.pushLeafHash(batch.hashes[i]); err != nil {
+				return nil, nil, fmt.Errorf("leaf %d: %w", index, err)
+			}
+			index++
+		}
+	}
+	// the dispatcher also stops once ctx is done
+	if err := ctx.Err(); err != nil {
+		return nil, nil, fmt.Errorf("building tree aborted after %d leaves: %w", index, err)
+	}
+
+	root, err := computer.Root()
+	if err != nil {
+		return nil, nil, err
+	}
+	tree.rawRoot = root
+	tree.checkInvariants("BuildFromChannel")
+	return tree, root, nil
+}
+
+// dispatchPipelineBatches groups the received leaves into batches and hands
+// them to the workers and, in order, to the assembler. Partial batches are
+// dispatched whenever no further leaf is ready, so that slow producers do not
+// delay the hashing.
+func dispatchPipelineBatches(ctx context.Context, leaves <-chan []byte, order, jobs chan<- *pipelineBatch) {
+	batch := newPipelineBatch()
+	dispatch := func() bool {
+		for _, ch := range []chan<- *pipelineBatch{order, jobs} {
+			select {
+			case ch <- batch:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		batch = newPipelineBatch()
+		return true
+	}
+	for {
+		var leaf []byte
+		var ok bool
+		if len(batch.leaves) == 0 {
+			select {
+			case leaf, ok = <-leaves:
+			case <-ctx.Done():
+				return
+			}
+		} else {
+			select {
+			case leaf, ok = <-leaves:
+			case <-ctx.Done():
+				return
+			default:
+				if !dispatch() {
+					return
+				}
+				continue
+			}
+		}
+		if !ok {
+			if len(batch.leaves) > 0 {
+				dispatch()
+			}
+			return
+		}
+		batch.leaves = append(batch.leaves, leaf)
+		if len(batch.leaves) == pipelineBatchSize && !dispatch() {
+			return
+		}
+	}
+}
+
+// hashPipelineBatch hashes the leaves of batch up to the first invalid one.
+func hashPipelineBatch(hasher *NmtHasher, batch *pipelineBatch) {
+	defer close(batch.done)
+	batch.hashes = make([][]byte, len(batch.leaves))
+	for i, leaf := range batch.leaves {
+		leafHash, err := hasher.HashLeaf(leaf)
+		if err != nil {
+			batch.err, batch.errIndex = err, i
+			return
+		}
+		batch.hashes[i] = leafHash
+	}
+}
+
+// pushHashed adds the namespace-prefixed leaf with the precomputed leafHash
+// to the tree, applying the same checks as Push.
+func (n *NamespacedMerkleTree) pushHashed(leaf, leafHash []byte) error {
+	nID, err := n.validateAndExtractNamespace(leaf)
+	if err == nil && n.frontier != nil {
+		n.metrics.LeafHashed()
+		err = n.pushFrontier(leafHash, nID)
+		n.metrics.Pushed(err)
+		n.logPush("BuildFromChannel", err)
+		return err
+	}
+	if err == nil {
+		err = n.hashPendingLeaves()
+	}
+	n.metrics.Pushed(err)
+	n.logPush("BuildFromChannel", err)
+	if err != nil {
+		return err
+	}
+	n.metrics.LeafHashed()
+	if n.dropLeafData {
+		leaf = nil
+	}
+	n.addLeaf(leaf, leafHash, nID)
+	return nil
+}
+
+// pushLeafHash adds the precomputed hash of the next leaf. The leaf must have
+// been validated, e.g., by NamespacedMerkleTree.Push.
+func (c *RootComputer) pushLeafHash(leafHash []byte) error {
+	var err error
+	c.lastLeafHash = leafHash
+	c.peaks, err = c.merge(c.peaks, c.size, 0, leafHash)
+	if err != nil {
+		return err
+	}
+	c.size++
+	return nil
+}
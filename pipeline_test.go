@@ -0,0 +1,126 @@
+package nmt
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// sendLeaves returns a channel that yields leaves and is closed afterwards.
+func sendLeaves(leaves [][]byte) <-chan []byte {
+	ch := make(chan []byte, 256)
+	go func() {
+		defer close(ch)
+		for _, leaf := range leaves {
+			ch <- leaf
+		}
+	}()
+	return ch
+}
+
+func TestBuildFromChannel(t *testing.T) {
+	for _, opts := range [][]Option{
+		{NamespaceIDSize(2)},
+		{NamespaceIDSize(2), IgnoreMaxNamespace(false)},
+		{NamespaceIDSize(2), Padding(PadWithLastLeaf)},
+		{NamespaceIDSize(2), FixedHeight(10), DropLeafData(true)},
+	} {
+		for _, size := range []int{0, 1, 63, 64, 65, 1000} {
+			leaves := make([][]byte, size)
+			want := New(sha256.New(), opts...)
+			for i := range leaves {
+				leaves[i] = []byte{byte(i >> 8), byte(i / 3), byte(i)}
+				require.NoError(t, want.Push(leaves[i]))
+			}
+			wantRoot, err := want.Root()
+			require.NoError(t, err)
+
+			for _, workers := range []int{0, 1, 3} {
+				tree, root, err := BuildFromChannel(context.Background(), sha256.New, sendLeaves(leaves), workers, opts...)
+				require.NoError(t, err)
+				assert.Equal(t, wantRoot, root, "size %d, workers %d", size, workers)
+				assert.Equal(t, size, tree.Size())
+				require.NoError(t, tree.verifyInvariants())
+				if size > 0 {
+					nID := namespace.ID(leaves[size/2][:2])
+					proof, err := tree.ProveNamespace(nID)
+					require.NoError(t, err)
+					var data [][]byte
+					for _, leaf := range leaves {
+						if nID.Equal(leaf[:2]) {
+							data = append(data, leaf)
+						}
+					}
+					assert.True(t, proof.VerifyNamespace(sha256.New(), nID, data, root))
+				}
+			}
+		}
+	}
+}
+
+func TestBuildFromChannel_InvalidLeaves(t *testing.T) {
+	leaves := make([][]byte, 200)
+	for i := range leaves {
+		leaves[i] = []byte{byte(i), 'x'}
+	}
+	unordered := append(append([][]byte(nil), leaves[:150]...), []byte{1, 'y'})
+	_, _, err := BuildFromChannel(context.Background(), sha256.New, sendLeaves(unordered), 2, NamespaceIDSize(1))
+	assert.ErrorIs(t, err, ErrInvalidPushOrder)
+	assert.ErrorContains(t, err, "leaf 150")
+
+	short := append(append([][]byte(nil), leaves[:70]...), []byte{})
+	_, _, err = BuildFromChannel(context.Background(), sha256.New, sendLeaves(short), 2, NamespaceIDSize(1))
+	assert.ErrorIs(t, err, ErrInvalidLeafLen)
+	assert.ErrorContains(t, err, "leaf 70")
+
+	_, _, err = BuildFromChannel(context.Background(), sha256.New, sendLeaves(leaves[:3]), 2, NamespaceIDSize(1), FixedHeight(1))
+	assert.ErrorIs(t, err, ErrTreeFull)
+
+	assert.Panics(t, func() {
+		hasher := struct{ Hasher }{NewNmtHasher(sha256.New(), 8, true)}
+		_, _, _ = BuildFromChannel(context.Background(), sha256.New, sendLeaves(nil), 1, CustomHasher(hasher))
+	})
+}
+
+func TestBuildFromChannel_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	leaves := make(chan []byte)
+	go func() {
+		leaves <- []byte{1, 'x'}
+		cancel()
+	}()
+	_, _, err := BuildFromChannel(ctx, sha256.New, leaves, 2, NamespaceIDSize(1))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func BenchmarkBuildFromChannel(b *testing.B) {
+	leaves := make([][]byte, 1<<12)
+	for i := range leaves {
+		leaves[i] = append([]byte{byte(i >> 8), byte(i)}, make([]byte, 510)...)
+	}
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tree := New(sha256.New(), NamespaceIDSize(2))
+			for _, leaf := range leaves {
+				if err := tree.Push(leaf); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if _, err := tree.Root(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("pipeline", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, _, err := BuildFromChannel(context.Background(), sha256.New, sendLeaves(leaves), 0, NamespaceIDSize(2)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
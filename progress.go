@@ -0,0 +1,93 @@
+package nmt
+
+// DefaultProgressInterval is the number of processed nodes between two
+// invocations of a ProgressFn if no positive interval is supplied.
+const DefaultProgressInterval = 1 << 16
+
+// Progress describes how far a root computation has advanced.
+type Progress struct {
+	// LeavesHashed is the number of leaf hashes consumed so far.
+	LeavesHashed int
+	// NodesComputed is the number of inner nodes hashed so far.
+	NodesComputed int
+	// TotalLeaves is the number of leaves in the tree the root is computed
+	// for.
+	TotalLeaves int
+}
+
+// Done returns true if all leaves of the tree have been processed.
+func (p Progress) Done() bool {
+	return p.LeavesHashed == p.TotalLeaves
+}
+
+// ProgressFn is invoked periodically during Root() with the current progress.
+// It is called synchronously from the goroutine computing the root and should
+// therefore return quickly.
+type ProgressFn = func(Progress)
+
+// progressTracker counts the nodes processed during a single Root()
+// computation and invokes fn every interval processed nodes.
+type progressTracker struct {
+	fn       ProgressFn
+	interval int
+
+	// active is true while a Root() computation is in progress. Nodes
+	// visited outside of Root() (e.g., via ComputeSubtreeRoot) are not
+	// reported.
+	active  bool
+	current Progress
+	pending int
+}
+
+func newProgressTracker(fn ProgressFn, interval int) *progressTracker {
+	if fn == nil {
+		return nil
+	}
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	return &progressTracker{fn: fn, interval: interval}
+}
+
+// start resets the tracker for a new root computation over totalLeaves leaves.
+func (p *progressTracker) start(totalLeaves int) {
+	if p == nil {
+		return
+	}
+	p.active = true
+	p.current = Progress{TotalLeaves: totalLeaves}
+	p.pending = 0
+}
+
+// stop deactivates the tracker. If final is true, the last progress is
+// reported regardless of the interval.
+func (p *progressTracker) stop(final bool) {
+	if p == nil || !p.active {
+		return
+	}
+	p.active = false
+	if final {
+		p.fn(p.current)
+	}
+}
+
+// visited records a processed node. children is the number of children passed
+// to the NodeVisitorFn, i.e., 1 for leaves and 2 for inner nodes.
+func (p *progressTracker) visited(children int) {
+	if p == nil || !p.active {
+		return
+	}
+	switch children {
+	case 1:
+		p.current.LeavesHashed++
+	case 2:
+		p.current.NodesComputed++
+	default:
+		return
+	}
+	p.pending++
+	if p.pending >= p.interval {
+		p.pending = 0
+		p.fn(p.current)
+	}
+}
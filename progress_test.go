@@ -0,0 +1,51 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressCallback(t *testing.T) {
+	var reports []Progress
+	tree := New(sha256.New(), NamespaceIDSize(1), ProgressCallback(func(p Progress) {
+		reports = append(reports, p)
+	}, 4))
+	for i := 0; i < 8; i++ {
+		require.NoError(t, tree.Push([]byte{byte(i), 'd'}))
+	}
+
+	_, err := tree.Root()
+	require.NoError(t, err)
+
+	// 8 leaves and 7 inner nodes are processed, i.e., 15 nodes in total,
+	// resulting in 3 periodic reports and one final report
+	require.Len(t, reports, 4)
+	final := reports[len(reports)-1]
+	assert.Equal(t, Progress{LeavesHashed: 8, NodesComputed: 7, TotalLeaves: 8}, final)
+	assert.True(t, final.Done())
+	for i := 1; i < len(reports); i++ {
+		prev, cur := reports[i-1], reports[i]
+		assert.LessOrEqual(t, prev.LeavesHashed+prev.NodesComputed, cur.LeavesHashed+cur.NodesComputed)
+	}
+
+	// the root is cached, hence no further progress is reported
+	_, err = tree.Root()
+	require.NoError(t, err)
+	assert.Len(t, reports, 4)
+
+	// nodes computed outside of Root() are not reported
+	_, err = tree.ComputeSubtreeRoot(0, 4)
+	require.NoError(t, err)
+	assert.Len(t, reports, 4)
+}
+
+func TestProgressCallback_Nil(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), ProgressCallback(nil, 1))
+	require.NoError(t, tree.Push([]byte{0, 'd'}))
+	_, err := tree.Root()
+	require.NoError(t, err)
+	assert.Nil(t, tree.progress)
+}
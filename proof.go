@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"hash"
+	"math"
 	"math/bits"
 
 	"github.com/celestiaorg/nmt/namespace"
@@ -15,12 +16,29 @@ import (
 var (
 	// ErrFailedCompletenessCheck indicates that the verification of a namespace proof failed due to the lack of completeness property.
 	ErrFailedCompletenessCheck = errors.New("failed completeness check")
-	ErrWrongLeafHashesSize     = errors.New("wrong leafHashes size")
+	// ErrWrongLeafHashesSize indicates that the number of supplied leaf hashes
+	// does not match the proof range.
+	ErrWrongLeafHashesSize = errors.New("wrong leafHashes size")
+	// ErrInvalidProof indicates that a proof is structurally inconsistent with
+	// the data it is being verified against.
+	ErrInvalidProof = errors.New("invalid proof")
+	// ErrMissingAdjacentNode indicates that an absence proof does not prove
+	// the nodes adjacent to the queried namespace, i.e., the last node before
+	// and the first node after the position the namespace would occupy.
+	ErrMissingAdjacentNode = errors.New("absence proof lacks an adjacent node")
+	// ErrInvalidSubtreeWidth indicates that a subtree root width is not usable
+	// to split a proof range into subtree roots.
+	ErrInvalidSubtreeWidth = errors.New("invalid subtree width")
 )
 
 // Proof represents a namespace proof of a namespace.ID in an NMT. In case this
 // proof proves the absence of a namespace.ID in a tree it also contains the
 // leaf hashes of the range where that namespace would be.
+//
+// The range of a proof is held in ints. Proofs of trees of more than 2^31
+// leaves therefore require a 64-bit platform; on 32-bit platforms, decoding a
+// proof whose range does not fit into an int fails with an ErrInvalidRange
+// error.
 type Proof struct {
 	// start index of the leaves that match the queried namespace.ID.
 	start int
@@ -53,14 +71,7 @@ type Proof struct {
 }
 
 func (proof Proof) MarshalJSON() ([]byte, error) {
-	pbProofObj := pb.Proof{
-		Start:                 int64(proof.start),
-		End:                   int64(proof.end),
-		Nodes:                 proof.nodes,
-		LeafHash:              proof.leafHash,
-		IsMaxNamespaceIgnored: proof.isMaxNamespaceIDIgnored,
-	}
-	return json.Marshal(pbProofObj)
+	return json.Marshal(proof.ToProto())
 }
 
 func (proof *Proof) UnmarshalJSON(data []byte) error {
@@ -69,6 +80,9 @@ func (proof *Proof) UnmarshalJSON(data []byte) error {
 	if err != nil {
 		return err
 	}
+	if err := checkProtoRange(pbProof); err != nil {
+		return err
+	}
 	proof.start = int(pbProof.Start)
 	proof.end = int(pbProof.End)
 	proof.nodes = pbProof.Nodes
@@ -161,27 +175,61 @@ func (proof Proof) IsEmptyProof() bool {
 //
 // `root` is the root of the NMT against which the `proof` is verified.
 func (proof Proof) VerifyNamespace(h hash.Hash, nID namespace.ID, leaves [][]byte, root []byte) bool {
-	nIDLen := nID.Size()
-	nth := NewNmtHasher(h, nIDLen, proof.isMaxNamespaceIDIgnored)
+	return proof.verifyNamespaceRange(h, nID, nID, leaves, root)
+}
 
-	// perform some consistency checks:
-	// check that the root is valid w.r.t the NMT hasher
-	if err := nth.ValidateNodeFormat(root); err != nil {
+// VerifyNamespaceRange is the counterpart of VerifyNamespace for proofs
+// generated by ProveNamespaceRange. It verifies that `leaves` are exactly the
+// leaves of the tree with root `root` whose namespace falls within
+// [nIDStart, nIDEnd] (both inclusive), i.e., that all of them are included in
+// the tree and no leaf within that namespace range was left out.
+func (proof Proof) VerifyNamespaceRange(h hash.Hash, nIDStart, nIDEnd namespace.ID, leaves [][]byte, root []byte) bool {
+	if nIDStart.Size() != nIDEnd.Size() || nIDEnd.Less(nIDStart) {
 		return false
 	}
-	// check that all the proof.nodes are valid w.r.t the NMT hasher
-	for _, node := range proof.nodes {
-		if err := nth.ValidateNodeFormat(node); err != nil {
-			return false
-		}
+	return proof.verifyNamespaceRange(h, nIDStart, nIDEnd, leaves, root)
+}
+
+// VerifyNamespaceWithOptions is like VerifyNamespace but first checks nID
+// against the restrictions the given tree options impose on namespace IDs, see
+// Options.ValidateNamespace, so that proofs for namespaces the tree would never
+// accept are rejected with the corresponding error. Only the
+// RejectMaxNamespace and NamespaceVersions options are taken into account.
+func (proof Proof) VerifyNamespaceWithOptions(h hash.Hash, nID namespace.ID, leaves [][]byte, root []byte, setters ...Option) (bool, error) {
+	var opts Options
+	for _, setter := range setters {
+		setter(&opts)
+	}
+	if err := opts.ValidateNamespace(nID); err != nil {
+		return false, err
 	}
+	return proof.VerifyNamespace(h, nID, leaves, root), nil
+}
 
-	// if the proof is an absence proof, the leafHash must be valid w.r.t the NMT hasher
-	if proof.IsOfAbsence() {
-		if err := nth.ValidateNodeFormat(proof.leafHash); err != nil {
-			return false
+// verifyNamespaceRange implements VerifyNamespace for the namespace range
+// [nIDStart, nIDEnd]. It is equivalent to VerifyNamespace if nIDStart equals
+// nIDEnd.
+func (proof Proof) verifyNamespaceRange(h hash.Hash, nIDStart, nIDEnd namespace.ID, leaves [][]byte, root []byte) bool {
+	return proof.checkNamespaceRange(h, nIDStart, nIDEnd, leaves, root) == nil
+}
+
+// checkNamespaceRange is like verifyNamespaceRange but returns the reason the
+// verification failed, or nil if it succeeded.
+func (proof Proof) checkNamespaceRange(h hash.Hash, nIDStart, nIDEnd namespace.ID, leaves [][]byte, root []byte) error {
+	nIDLen := nIDStart.Size()
+	nth := NewNmtHasher(h, nIDLen, proof.isMaxNamespaceIDIgnored)
+
+	// perform some consistency checks: the namespace IDs, the root, the
+	// proof.nodes and the leafHash of an absence proof must be valid w.r.t the
+	// NMT hasher
+	for _, nID := range []namespace.ID{nIDStart, nIDEnd} {
+		if err := nth.validateNamespaceID(nID); err != nil {
+			return err
 		}
 	}
+	if err := proof.validateFormat(nth, root); err != nil {
+		return err
+	}
 
 	isEmptyRange := proof.start == proof.end
 	if isEmptyRange {
@@ -192,44 +240,38 @@ func (proof Proof) VerifyNamespace(h hash.Hash, nID namespace.ID, leaves [][]byt
 			// namespaces covered by the root 2) the root represents an empty tree, since
 			// it purports to cover the zero namespace but does not actually include
 			// any such nodes
-			if nID.Less(rootMin) || rootMax.Less(nID) {
-				return true
+			if nIDEnd.Less(rootMin) || rootMax.Less(nIDStart) {
+				return nil
 			}
 			if bytes.Equal(root, nth.EmptyRoot()) {
-				return true
+				return nil
 			}
-			return false
+			return fmt.Errorf("%w: empty proof for namespace range [%s, %s] within the range [%s, %s] of the root", ErrInvalidProof, nIDStart, nIDEnd, rootMin, rootMax)
 		}
 		// the proof range is empty, and invalid
-		return false
+		return fmt.Errorf("proof range [%d, %d) is empty but the proof is not: %w", proof.start, proof.end, ErrInvalidRange)
 	}
 
 	gotLeafHashes := make([][]byte, 0, len(leaves))
 	if proof.IsOfAbsence() {
+		// the adjacency of the leaf hash is checked by verifyLeafHashes
 		gotLeafHashes = append(gotLeafHashes, proof.leafHash)
-		// conduct some sanity checks:
-		leafMinNID := namespace.ID(proof.leafHash[:nIDLen])
-		if !nID.Less(leafMinNID) {
-			// leafHash.minNID  must be greater than nID
-			return false
-		}
-
 	} else {
 		// collect leaf hashes from provided data and do some sanity checks:
 		hashLeafFunc := nth.HashLeaf
-		for _, gotLeaf := range leaves {
-			if nth.ValidateLeaf(gotLeaf) != nil {
-				return false
+		for i, gotLeaf := range leaves {
+			if err := nth.ValidateLeaf(gotLeaf); err != nil {
+				return fmt.Errorf("leaf %d: %w", i, err)
 			}
 			// check whether the namespace ID of the data matches the queried nID
-			if gotLeafNid := namespace.ID(gotLeaf[:nIDLen]); !gotLeafNid.Equal(nID) {
+			if gotLeafNid := namespace.ID(gotLeaf[:nIDLen]); gotLeafNid.Less(nIDStart) || nIDEnd.Less(gotLeafNid) {
 				// conflicting namespace IDs in data
-				return false
+				return fmt.Errorf("%w: leaf %d has namespace %s outside the namespace range [%s, %s]", ErrInvalidProof, i, gotLeafNid, nIDStart, nIDEnd)
 			}
 			// hash the leaf data
 			leafHash, err := hashLeafFunc(gotLeaf)
 			if err != nil { // this can never happen due to the initial validation of the leaf at the beginning of the loop
-				return false
+				return err
 			}
 			gotLeafHashes = append(gotLeafHashes, leafHash)
 		}
@@ -238,14 +280,17 @@ func (proof Proof) VerifyNamespace(h hash.Hash, nID namespace.ID, leaves [][]byt
 	// If not, make an early return.
 	expectedLeafCount := proof.End() - proof.Start()
 	if !proof.IsOfAbsence() && len(gotLeafHashes) != expectedLeafCount {
-		return false
+		return fmt.Errorf("supplied %d leaves, expected %d: %w", len(gotLeafHashes), expectedLeafCount, ErrWrongLeafHashesSize)
 	}
 	// with verifyCompleteness set to true:
-	res, err := proof.VerifyLeafHashes(nth, true, nID, gotLeafHashes, root)
+	res, err := proof.verifyLeafHashes(nth, true, nIDStart, nIDEnd, gotLeafHashes, root)
 	if err != nil {
-		return false
+		return err
 	}
-	return res
+	if !res {
+		return fmt.Errorf("%w: computed root does not match the root %x", ErrInvalidProof, root)
+	}
+	return nil
 }
 
 // The VerifyLeafHashes function checks whether the given proof is a valid Merkle
@@ -258,7 +303,23 @@ func (proof Proof) VerifyNamespace(h hash.Hash, nID namespace.ID, leaves [][]byt
 // the completeness of the proof by verifying that there is no leaf in the
 // tree represented by the root parameter that matches the namespace ID nID
 // outside the leafHashes list.
+// The nodes of the proof and the leaf hashes must be ordered by namespace ID in
+// the order of the leaves they cover, otherwise an ErrUnorderedSiblings error
+// is returned, and each leaf hash must cover a single namespace, otherwise an
+// ErrInvalidLeafHash error is returned.
+// For absence proofs, leafHashes must hold the leaf hash of the proof, and the
+// nodes adjacent to nID, i.e., the leaf hash and the node before it, are
+// checked to lie after and before nID even if verifyCompleteness is false;
+// otherwise an ErrMissingAdjacentNode error is returned.
 func (proof Proof) VerifyLeafHashes(nth *NmtHasher, verifyCompleteness bool, nID namespace.ID, leafHashes [][]byte, root []byte) (bool, error) {
+	return proof.verifyLeafHashes(nth, verifyCompleteness, nID, nID, leafHashes, root)
+}
+
+// verifyLeafHashes generalizes VerifyLeafHashes to the namespace range
+// [nIDStart, nIDEnd] (both inclusive). Every leaf hash of an inclusion proof
+// must fall within that range, and if verifyCompleteness is set, no leaf
+// outside leafHashes may carry a namespace within that range.
+func (proof Proof) verifyLeafHashes(nth *NmtHasher, verifyCompleteness bool, nIDStart, nIDEnd namespace.ID, leafHashes [][]byte, root []byte) (bool, error) {
 	// check that the proof range is valid
 	if proof.Start() < 0 || proof.Start() >= proof.End() {
 		return false, fmt.Errorf("proof range [proof.start=%d, proof.end=%d) is not valid: %w", proof.Start(), proof.End(), ErrInvalidRange)
@@ -274,34 +335,34 @@ func (proof Proof) VerifyLeafHashes(nth *NmtHasher, verifyCompleteness bool, nID
 	}
 
 	// perform some consistency checks:
-	if nID.Size() != nth.NamespaceSize() {
-		return false, fmt.Errorf("namespace ID size (%d) does not match the namespace size of the NMT hasher (%d)", nID.Size(), nth.NamespaceSize())
-	}
-	// check that the root is valid w.r.t the NMT hasher
-	if err := nth.ValidateNodeFormat(root); err != nil {
-		return false, fmt.Errorf("root does not match the NMT hasher's hash format: %w", err)
-	}
-	// check that all the proof.nodes are valid w.r.t the NMT hasher
-	for _, node := range proof.nodes {
-		if err := nth.ValidateNodeFormat(node); err != nil {
-			return false, fmt.Errorf("proof nodes do not match the NMT hasher's hash format: %w", err)
+	for _, nID := range []namespace.ID{nIDStart, nIDEnd} {
+		if err := nth.validateNamespaceID(nID); err != nil {
+			return false, err
 		}
 	}
+	if err := proof.validateFormat(nth, root); err != nil {
+		return false, err
+	}
 	// check that all the leafHashes are valid w.r.t the NMT hasher
-	for _, leafHash := range leafHashes {
-		if err := nth.ValidateNodeFormat(leafHash); err != nil {
-			return false, fmt.Errorf("leaf hash does not match the NMT hasher's hash format: %w", err)
-		}
+	if err := validateLeafHashes(nth, leafHashes); err != nil {
+		return false, err
 	}
 
 	// check that the namespace of leafHashes is the same as the queried namespace, except for the case of absence proof
 	if !proof.IsOfAbsence() { // in case of absence proof, the leafHash is the hash of a leaf next to the queried namespace, hence its namespace ID is not the same as the queried namespace ID
-		// check the namespace of all the leaf hashes to be the same as the queried namespace
+		// check the namespace of all the leaf hashes to be within the queried namespace range
 		for _, leafHash := range leafHashes {
-			minNsID := MinNamespace(leafHash, nth.NamespaceSize())
-			maxNsID := MaxNamespace(leafHash, nth.NamespaceSize())
-			if !nID.Equal(minNsID) || !nID.Equal(maxNsID) {
-				return false, fmt.Errorf("leaf hash %x does not belong to namespace %x", leafHash, nID)
+			minNsID := minNamespaceView(leafHash, nth.NamespaceSize())
+			maxNsID := maxNamespaceView(leafHash, nth.NamespaceSize())
+			// the hash of a single leaf covers a single namespace
+			if !minNsID.Equal(maxNsID) {
+				return false, fmt.Errorf("%w: leaf hash %x covers more than one namespace", ErrInvalidLeafHash, leafHash)
+			}
+			if nIDStart.Equal(nIDEnd) && (!nIDStart.Equal(minNsID) || !nIDStart.Equal(maxNsID)) {
+				return false, fmt.Errorf("leaf hash %x does not belong to namespace %x: %w", leafHash, nIDStart, ErrInvalidProof)
+			}
+			if namespace.ID(minNsID).Less(nIDStart) || nIDEnd.Less(maxNsID) {
+				return false, fmt.Errorf("leaf hash %x does not belong to namespace range [%x, %x]: %w", leafHash, nIDStart, nIDEnd, ErrInvalidProof)
 			}
 		}
 	}
@@ -320,49 +381,66 @@ func (proof Proof) VerifyLeafHashes(nth *NmtHasher, verifyCompleteness bool, nID
 	// rightSubtrees only contains the subtrees after r.End
 	rightSubtrees := nodes
 
+	// the nodes adjacent to the namespace range of an absence proof are
+	// checked regardless of verifyCompleteness, so that no leaves of the
+	// range can hide between them
+	if proof.IsOfAbsence() {
+		if leafIndex != uint64(proof.Start()) {
+			return false, fmt.Errorf("%w: the proof nodes cover %d of the %d leaves before the leaf hash", ErrMissingAdjacentNode, leafIndex, proof.Start())
+		}
+		if err := proof.checkAdjacentNodes(nth, nIDStart, nIDEnd, leftSubtrees, leafHashes[0]); err != nil {
+			return false, err
+		}
+	}
+
 	if verifyCompleteness {
 		// leftSubtrees contains the subtree roots upto [0, r.Start)
 		for _, subtree := range leftSubtrees {
-			leftSubTreeMax := MaxNamespace(subtree, nth.NamespaceSize())
-			if nID.LessOrEqual(namespace.ID(leftSubTreeMax)) {
+			leftSubTreeMax := maxNamespaceView(subtree, nth.NamespaceSize())
+			if nIDStart.LessOrEqual(leftSubTreeMax) {
 				return false, ErrFailedCompletenessCheck
 			}
 		}
 		for _, subtree := range rightSubtrees {
-			rightSubTreeMin := MinNamespace(subtree, nth.NamespaceSize())
-			if namespace.ID(rightSubTreeMin).LessOrEqual(nID) {
+			rightSubTreeMin := minNamespaceView(subtree, nth.NamespaceSize())
+			if rightSubTreeMin.LessOrEqual(nIDEnd) {
 				return false, ErrFailedCompletenessCheck
 			}
 		}
 	}
 
-	var computeRoot func(start, end int) ([]byte, error)
+	// leaf indices are handled as uint64 so that the full tree size computed
+	// below cannot overflow, even for proofs of more than 2^31 leaves on
+	// 32-bit platforms
+	proofStart, proofEnd := uint64(proof.Start()), uint64(proof.End())
+	order := namespaceOrder{nth: nth}
+	var computeRoot func(start, end uint64) ([]byte, error)
 	// computeRoot can return error iff the HashNode function fails while calculating the root
-	computeRoot = func(start, end int) ([]byte, error) {
+	computeRoot = func(start, end uint64) ([]byte, error) {
 		// reached a leaf
 		if end-start == 1 {
 			// if the leaf index falls within the proof range, pop and return a
 			// leaf
-			if proof.Start() <= start && start < proof.End() {
+			if proofStart <= start && start < proofEnd {
 				// advance leafHashes
-				return popIfNonEmpty(&leafHashes), nil
+				return order.pop(&leafHashes)
 			}
 
 			// if the leaf index  is outside the proof range, pop and return a
 			// proof node (which in this case is a leaf) if present, else return
 			// nil because leaf doesn't exist
-			return popIfNonEmpty(&proof.nodes), nil
+			return order.pop(&proof.nodes)
 		}
 
 		// if current range does not overlap with the proof range, pop and
 		// return a proof node if present, else return nil because subtree
 		// doesn't exist
-		if end <= proof.Start() || start >= proof.End() {
-			return popIfNonEmpty(&proof.nodes), nil
+		if end <= proofStart || start >= proofEnd {
+			return order.pop(&proof.nodes)
 		}
 
 		// Recursively get left and right subtree
-		k := getSplitPoint(end - start)
+		k := (end - start) / 2
 		left, err := computeRoot(start, start+k)
 		if err != nil {
 			return nil, fmt.Errorf("failed to compute subtree root [%d, %d): %w", start, start+k, err)
@@ -384,15 +462,15 @@ func (proof Proof) VerifyLeafHashes(nth *NmtHasher, verifyCompleteness bool, nID
 	}
 
 	// estimate the leaf size of the subtree containing the proof range
-	proofRangeSubtreeEstimate := getSplitPoint(proof.end) * 2
-	if proofRangeSubtreeEstimate < 1 {
-		proofRangeSubtreeEstimate = 1
-	}
+	proofRangeSubtreeEstimate := fullTreeSize(proofEnd)
 	rootHash, err := computeRoot(0, proofRangeSubtreeEstimate)
 	if err != nil {
 		return false, fmt.Errorf("failed to compute root [%d, %d): %w", 0, proofRangeSubtreeEstimate, err)
 	}
 	for i := 0; i < len(proof.nodes); i++ {
+		if err := order.next(proof.nodes[i]); err != nil {
+			return false, err
+		}
 		rootHash, err = nth.HashNode(rootHash, proof.nodes[i])
 		if err != nil {
 			return false, fmt.Errorf("failed to hash node: %w", err)
@@ -402,6 +480,26 @@ func (proof Proof) VerifyLeafHashes(nth *NmtHasher, verifyCompleteness bool, nID
 	return bytes.Equal(rootHash, root), nil
 }
 
+// checkAdjacentNodes checks the nodes adjacent to the namespace range
+// [nIDStart, nIDEnd] of an absence proof: leafHash, which must be the leaf
+// hash of the proof, is the first node after the range, and the last of the
+// leftSubtrees, if any, is the last node before the range. Since nodes are
+// ordered by namespace, no leaf of the range can be outside of them.
+func (proof Proof) checkAdjacentNodes(nth *NmtHasher, nIDStart, nIDEnd namespace.ID, leftSubtrees [][]byte, leafHash []byte) error {
+	if !bytes.Equal(leafHash, proof.leafHash) {
+		return fmt.Errorf("%w: supplied leaf hash %x differs from the leaf hash %x of the absence proof", ErrInvalidProof, leafHash, proof.leafHash)
+	}
+	if leafMinNID := minNamespaceView(leafHash, nth.NamespaceSize()); !nIDEnd.Less(leafMinNID) {
+		return fmt.Errorf("%w: leaf hash of the absence proof has namespace %s, want greater than %s", ErrMissingAdjacentNode, leafMinNID, nIDEnd)
+	}
+	if len(leftSubtrees) > 0 {
+		if leftMaxNID := maxNamespaceView(leftSubtrees[len(leftSubtrees)-1], nth.NamespaceSize()); !leftMaxNID.Less(nIDStart) {
+			return fmt.Errorf("%w: node before the leaf hash has namespace %s, want less than %s", ErrMissingAdjacentNode, leftMaxNID, nIDStart)
+		}
+	}
+	return nil
+}
+
 // VerifyInclusion checks that the inclusion proof is valid by using leaf data
 // and the provided proof to regenerate and compare the root. Note that the leavesWithoutNamespace data should not contain the prefixed namespace, unlike the tree.Push method,
 // which takes prefixed data. All leaves implicitly have the same namespace ID:
@@ -432,25 +530,22 @@ func (proof Proof) VerifyInclusion(h hash.Hash, nid namespace.ID, leavesWithoutN
 
 	nth := NewNmtHasher(h, nid.Size(), proof.isMaxNamespaceIDIgnored)
 
-	// perform some consistency checks:
-	// check that the root is valid w.r.t the NMT hasher
-	if err := nth.ValidateNodeFormat(root); err != nil {
+	// perform some consistency checks: the namespace ID, the root and the
+	// proof.nodes must be valid w.r.t the NMT hasher
+	if err := nth.validateNamespaceID(nid); err != nil {
 		return false
 	}
-	// check that all the proof.nodes are valid w.r.t the NMT hasher
-	for _, node := range proof.nodes {
-		if err := nth.ValidateNodeFormat(node); err != nil {
-			return false
-		}
+	if err := proof.validateFormat(nth, root); err != nil {
+		return false
 	}
 
 	// add namespace to all the leaves
 	hashes := make([][]byte, len(leavesWithoutNamespace))
+	// leafData is reused for all leaves since HashLeaf does not retain it
+	var leafData []byte
 	for i, d := range leavesWithoutNamespace {
 		// prepend the namespace to the leaf data
-		leafData := append(
-			append(make([]byte, 0, len(d)+len(nid)), nid...), d...,
-		)
+		leafData = append(append(leafData[:0], nid...), d...)
 		res, err := nth.HashLeaf(leafData)
 		if err != nil {
 			return false // this never can happen since the leafData is guaranteed to be namespaced
@@ -480,20 +575,14 @@ func (proof Proof) VerifySubtreeRootInclusion(nth *NmtHasher, subtreeRoots [][]b
 		return false, fmt.Errorf("proof range [proof.start=%d, proof.end=%d) is not valid: %w", proof.Start(), proof.End(), ErrInvalidRange)
 	}
 
-	// check that the root is valid w.r.t the NMT hasher
-	if err := nth.ValidateNodeFormat(root); err != nil {
-		return false, fmt.Errorf("root does not match the NMT hasher's hash format: %w", err)
-	}
-	// check that all the proof.Notes() are valid w.r.t the NMT hasher
-	for _, node := range proof.Nodes() {
-		if err := nth.ValidateNodeFormat(node); err != nil {
-			return false, fmt.Errorf("proof nodes do not match the NMT hasher's hash format: %w", err)
-		}
+	// check that the root and the proof nodes are valid w.r.t the NMT hasher
+	if err := proof.validateFormat(nth, root); err != nil {
+		return false, err
 	}
 	// check that all the subtree roots are valid w.r.t the NMT hasher
-	for _, subtreeRoot := range subtreeRoots {
+	for i, subtreeRoot := range subtreeRoots {
 		if err := nth.ValidateNodeFormat(subtreeRoot); err != nil {
-			return false, fmt.Errorf("inner nodes does not match the NMT hasher's hash format: %w", err)
+			return false, fmt.Errorf("subtree root %d does not match the NMT hasher's hash format: %w", i, err)
 		}
 	}
 
@@ -506,9 +595,10 @@ func (proof Proof) VerifySubtreeRootInclusion(nth *NmtHasher, subtreeRoots [][]b
 	// check whether the number of ranges matches the number of subtree roots.
 	// if not, make an early return.
 	if len(subtreeRoots) != len(ranges) {
-		return false, fmt.Errorf("number of subtree roots %d is different than the number of the expected leaf ranges %d", len(subtreeRoots), len(ranges))
+		return false, fmt.Errorf("number of subtree roots %d is different than the number of the expected leaf ranges %d: %w", len(subtreeRoots), len(ranges), ErrInvalidProof)
 	}
 
+	order := namespaceOrder{nth: nth}
 	var computeRoot func(start, end int) ([]byte, error)
 	// computeRoot can return error iff the HashNode function fails while calculating the root
 	computeRoot = func(start, end int) ([]byte, error) {
@@ -516,23 +606,23 @@ func (proof Proof) VerifySubtreeRootInclusion(nth *NmtHasher, subtreeRoots [][]b
 		// return a proof node if present, else return nil because subtree
 		// doesn't exist
 		if end <= proof.Start() || start >= proof.End() {
-			return popIfNonEmpty(&proof.nodes), nil
+			return order.pop(&proof.nodes)
 		}
 
 		if len(ranges) == 0 {
-			return nil, fmt.Errorf("expected to have a subtree root for range [%d, %d)", start, end)
+			return nil, fmt.Errorf("expected to have a subtree root for range [%d, %d): %w", start, end, ErrInvalidProof)
 		}
 
 		if ranges[0].Start == start && ranges[0].End == end {
 			ranges = ranges[1:]
-			return popIfNonEmpty(&subtreeRoots), nil
+			return order.pop(&subtreeRoots)
 		}
 
 		if end-start == 1 {
 			// At this level, we reached a leaf, but we couldn't find any range corresponding
 			// to needed leaf [start, end).
 			// This means that the initial provided [start, end) range was invalid.
-			return nil, fmt.Errorf("the provided range [%d, %d) does not reference a valid inner node", proof.start, proof.end)
+			return nil, fmt.Errorf("the provided range [%d, %d) does not reference a valid inner node: %w", proof.start, proof.end, ErrInvalidSubtreeRange)
 		}
 
 		// Recursively get left and right subtree
@@ -558,15 +648,19 @@ func (proof Proof) VerifySubtreeRootInclusion(nth *NmtHasher, subtreeRoots [][]b
 	}
 
 	// estimate the leaf size of the subtree containing the proof range
-	proofRangeSubtreeEstimate := getSplitPoint(proof.End()) * 2
-	if proofRangeSubtreeEstimate < 1 {
-		proofRangeSubtreeEstimate = 1
+	estimate := fullTreeSize(uint64(proof.End()))
+	if estimate > math.MaxInt {
+		return false, fmt.Errorf("proof range [%d, %d) exceeds the platform's int range: %w", proof.Start(), proof.End(), ErrInvalidRange)
 	}
+	proofRangeSubtreeEstimate := int(estimate)
 	rootHash, err := computeRoot(0, proofRangeSubtreeEstimate)
 	if err != nil {
 		return false, fmt.Errorf("failed to compute root [%d, %d): %w", 0, proofRangeSubtreeEstimate, err)
 	}
 	for i := 0; i < len(proof.Nodes()); i++ {
+		if err := order.next(proof.Nodes()[i]); err != nil {
+			return false, err
+		}
 		rootHash, err = nth.HashNode(rootHash, proof.Nodes()[i])
 		if err != nil {
 			return false, fmt.Errorf("failed to hash node: %w", err)
@@ -598,13 +692,13 @@ func (proof Proof) VerifySubtreeRootInclusion(nth *NmtHasher, subtreeRoots [][]b
 // Note: This method is Celestia specific.
 func ToLeafRanges(proofStart, proofEnd, subtreeWidth int) ([]LeafRange, error) {
 	if proofStart < 0 {
-		return nil, fmt.Errorf("proof start %d shouldn't be strictly negative", proofStart)
+		return nil, fmt.Errorf("proof start %d shouldn't be strictly negative: %w", proofStart, ErrInvalidRange)
 	}
 	if proofEnd <= proofStart {
-		return nil, fmt.Errorf("proof end %d should be stricly bigger than proof start %d", proofEnd, proofStart)
+		return nil, fmt.Errorf("proof end %d should be stricly bigger than proof start %d: %w", proofEnd, proofStart, ErrInvalidRange)
 	}
 	if subtreeWidth <= 0 {
-		return nil, fmt.Errorf("subtree root width cannot be negative %d", subtreeWidth)
+		return nil, fmt.Errorf("subtree root width cannot be negative %d: %w", subtreeWidth, ErrInvalidSubtreeWidth)
 	}
 	currentStart := proofStart
 	currentLeafRange := proofEnd - proofStart
@@ -645,7 +739,7 @@ func nextLeafRange(currentStart, currentEnd, subtreeWidth int) (LeafRange, error
 	idealTreeSize := nextSubtreeSize(uint64(currentStart), uint64(rangeEnd))
 	if currentStart+idealTreeSize != rangeEnd {
 		// this will happen if the calculated range does not correctly reference an inner node in the tree.
-		return LeafRange{}, fmt.Errorf("provided subtree width %d doesn't allow creating a valid leaf range [%d, %d)", subtreeWidth, currentStart, rangeEnd)
+		return LeafRange{}, fmt.Errorf("provided subtree width %d doesn't allow creating a valid leaf range [%d, %d): %w", subtreeWidth, currentStart, rangeEnd, ErrInvalidSubtreeWidth)
 	}
 	return LeafRange{Start: currentStart, End: rangeEnd}, nil
 }
@@ -659,7 +753,51 @@ func largestPowerOfTwo(bound uint) (int, error) {
 	return 1 << (bits.Len(bound) - 1), nil
 }
 
-// ProtoToProof creates a proof from its proto representation.
+// validateFormat checks that the root, every proof node and, for absence
+// proofs, the leaf hash conform to the namespaced hash format of nth. Proofs
+// are untrusted input, so verifiers call validateFormat before slicing or
+// hashing any of their fields. The returned error names the offending field
+// and wraps ErrInvalidNodeLen or ErrInvalidNodeNamespaceOrder.
+func (proof Proof) validateFormat(nth *NmtHasher, root []byte) error {
+	if err := nth.ValidateNodeFormat(root); err != nil {
+		return fmt.Errorf("root does not match the NMT hasher's hash format: %w", err)
+	}
+	for i, node := range proof.nodes {
+		if err := nth.ValidateNodeFormat(node); err != nil {
+			return fmt.Errorf("proof node %d does not match the NMT hasher's hash format: %w", i, err)
+		}
+	}
+	if proof.IsOfAbsence() {
+		if err := nth.ValidateNodeFormat(proof.leafHash); err != nil {
+			return fmt.Errorf("leaf hash of the absence proof does not match the NMT hasher's hash format: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateLeafHashes checks that the leaf hashes supplied to a verifier
+// conform to the namespaced hash format of nth, see validateFormat.
+func validateLeafHashes(nth *NmtHasher, leafHashes [][]byte) error {
+	for i, leafHash := range leafHashes {
+		if err := nth.ValidateNodeFormat(leafHash); err != nil {
+			return fmt.Errorf("leaf hash %d does not match the NMT hasher's hash format: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// checkProtoRange returns an ErrInvalidRange error if the range of the proto
+// proof cannot be represented by an int, e.g., for proofs of trees with more
+// than 2^31 leaves on 32-bit platforms.
+func checkProtoRange(protoProof pb.Proof) error {
+	if int64(int(protoProof.Start)) != protoProof.Start || int64(int(protoProof.End)) != protoProof.End {
+		return fmt.Errorf("proof range [%d, %d) exceeds the platform's int range: %w", protoProof.Start, protoProof.End, ErrInvalidRange)
+	}
+	return nil
+}
+
+// ProtoToProof creates a proof from its proto representation. The range of
+// protoProof must fit into an int.
 func ProtoToProof(protoProof pb.Proof) Proof {
 	if protoProof.Start == 0 && protoProof.End == 0 {
 		return NewEmptyRangeProof(protoProof.IsMaxNamespaceIgnored)
@@ -696,6 +834,16 @@ func nextSubtreeSize(start, end uint64) int {
 	return 1 << uint(ideal)
 }
 
+// fullTreeSize returns the number of leaves of the smallest perfect tree
+// covering the leaves [0, end), i.e., the smallest power of two that is
+// greater than or equal to end, and 1 for end <= 1.
+func fullTreeSize(end uint64) uint64 {
+	if end <= 1 {
+		return 1
+	}
+	return 1 << bits.Len64(end-1)
+}
+
 // popIfNonEmpty pops the first element off of a slice only if the slice is
 // non-empty, else returns a nil slice
 func popIfNonEmpty(s *[][]byte) []byte {
@@ -0,0 +1,101 @@
+package nmt
+
+import (
+	"container/list"
+	"context"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ProofCache enables an LRU cache of up to size namespace proofs generated by
+// ProveNamespace, keyed by the root of the tree and the namespace ID. Serving a
+// cached proof requires neither hashing nor traversing the tree. The cache is
+// cleared whenever leaves are added or removed. A size of 0 disables the cache,
+// which is the default.
+func ProofCache(size int) Option {
+	if size < 0 {
+		panic("Got invalid proof cache size. Expected a value greater or equal to 0.")
+	}
+	return func(opts *Options) {
+		opts.ProofCacheSize = size
+	}
+}
+
+// proofCache is an LRU cache of namespace proofs.
+type proofCache struct {
+	size int
+	// order holds the cached entries from the most to the least recently
+	// used one, and entries indexes them by key.
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type proofCacheEntry struct {
+	key   string
+	proof Proof
+}
+
+// newProofCache returns a proofCache of the given size, or nil if size is 0.
+func newProofCache(size int) *proofCache {
+	if size == 0 {
+		return nil
+	}
+	return &proofCache{size: size, order: list.New(), entries: make(map[string]*list.Element, size)}
+}
+
+func (c *proofCache) get(key string) (Proof, bool) {
+	elem, found := c.entries[key]
+	if !found {
+		return Proof{}, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*proofCacheEntry).proof, true
+}
+
+func (c *proofCache) add(key string, proof Proof) {
+	if elem, found := c.entries[key]; found {
+		elem.Value.(*proofCacheEntry).proof = proof
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&proofCacheEntry{key: key, proof: proof})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*proofCacheEntry).key)
+	}
+}
+
+func (c *proofCache) reset() {
+	c.order.Init()
+	clear(c.entries)
+}
+
+// proveNamespaceCached is like proveNamespace but serves proofs from the proof
+// cache if possible.
+func (n *NamespacedMerkleTree) proveNamespaceCached(ctx context.Context, nID namespace.ID) (Proof, error) {
+	if err := n.validateNamespaceSize(nID); err != nil {
+		return Proof{}, err
+	}
+	root, err := n.RootCtx(ctx)
+	if err != nil {
+		return Proof{}, err
+	}
+	key := string(root) + string(nID)
+	if proof, found := n.proofs.get(key); found {
+		return proof, nil
+	}
+	proof, err := n.proveNamespace(ctx, nID)
+	if err != nil {
+		return Proof{}, err
+	}
+	n.proofs.add(key, proof)
+	return proof, nil
+}
+
+// invalidateProofs clears the proof cache, if any.
+func (n *NamespacedMerkleTree) invalidateProofs() {
+	if n.proofs != nil {
+		n.proofs.reset()
+	}
+}
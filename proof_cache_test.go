@@ -0,0 +1,105 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestProofCache(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), ProofCache(4))
+	uncached := New(sha256.New(), NamespaceIDSize(1))
+	for _, leaf := range [][]byte{{1, 'a'}, {2, 'b'}, {2, 'c'}, {4, 'd'}} {
+		require.NoError(t, tree.Push(leaf))
+		require.NoError(t, uncached.Push(leaf))
+	}
+
+	for _, nID := range []namespace.ID{{1}, {2}, {3}, {5}} {
+		want, err := uncached.ProveNamespace(nID)
+		require.NoError(t, err)
+		proof, err := tree.ProveNamespace(nID)
+		require.NoError(t, err)
+		assert.Equal(t, want, proof)
+		cached, err := tree.ProveNamespace(nID)
+		require.NoError(t, err)
+		assert.Equal(t, want, cached)
+	}
+	assert.Len(t, tree.proofs.entries, 4)
+
+	// a cache hit does not traverse the tree
+	tree.innerNodes = nil
+	_, err := tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	assert.Nil(t, tree.innerNodes)
+
+	_, err = tree.ProveNamespace(namespace.ID{1, 2})
+	assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+	assert.Len(t, tree.proofs.entries, 4)
+}
+
+func TestProofCache_Invalidation(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), ProofCache(4))
+	require.NoError(t, tree.Push([]byte{1, 'a'}))
+	before, err := tree.ProveNamespace(namespace.ID{1})
+	require.NoError(t, err)
+
+	require.NoError(t, tree.Push([]byte{1, 'b'}))
+	assert.Empty(t, tree.proofs.entries)
+	after, err := tree.ProveNamespace(namespace.ID{1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, after.End())
+
+	_, err = tree.Pop()
+	require.NoError(t, err)
+	assert.Empty(t, tree.proofs.entries)
+	popped, err := tree.ProveNamespace(namespace.ID{1})
+	require.NoError(t, err)
+	assert.Equal(t, before, popped)
+
+	batch := tree.Begin()
+	require.NoError(t, batch.Push([]byte{2, 'c'}))
+	_, err = tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	require.NoError(t, batch.Rollback())
+	assert.Empty(t, tree.proofs.entries)
+
+	tree.Reset()
+	assert.Empty(t, tree.proofs.entries)
+}
+
+func TestProofCache_Eviction(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), ProofCache(2))
+	require.NoError(t, tree.Push([]byte{1, 'a'}))
+	root, err := tree.Root()
+	require.NoError(t, err)
+	key := func(nID byte) string { return string(root) + string([]byte{nID}) }
+
+	for _, nID := range []byte{1, 2, 1, 3} {
+		_, err := tree.ProveNamespace(namespace.ID{nID})
+		require.NoError(t, err)
+	}
+	// 2 is the least recently used namespace
+	assert.Len(t, tree.proofs.entries, 2)
+	assert.Contains(t, tree.proofs.entries, key(1))
+	assert.Contains(t, tree.proofs.entries, key(3))
+	assert.NotContains(t, tree.proofs.entries, key(2))
+}
+
+func TestProofCache_Options(t *testing.T) {
+	assert.Nil(t, New(sha256.New()).proofs)
+	assert.Nil(t, New(sha256.New(), ProofCache(0)).proofs)
+	assert.Panics(t, func() { ProofCache(-1) })
+
+	tree := New(sha256.New(), NamespaceIDSize(1), ProofCache(2))
+	require.NoError(t, tree.Push([]byte{1, 'a'}))
+	_, err := tree.ProveNamespace(namespace.ID{1})
+	require.NoError(t, err)
+	snapshot := tree.Snapshot(sha256.New())
+	require.NotNil(t, snapshot.proofs)
+	assert.NotSame(t, tree.proofs, snapshot.proofs)
+	assert.Empty(t, snapshot.proofs.entries)
+}
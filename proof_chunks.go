@@ -0,0 +1,160 @@
+package nmt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+)
+
+// ErrInvalidProofChunk indicates that the chunks of a proof are malformed,
+// corrupted, incomplete, or belong to different proofs.
+var ErrInvalidProofChunk = errors.New("invalid proof chunk")
+
+// proofChunkMagic and proofChunkVersion identify the encoding of proof
+// chunks.
+const (
+	proofChunkMagic   = "NMTP"
+	proofChunkVersion = 1
+)
+
+// ProofChunkOverhead is the number of bytes each chunk returned by SplitProof
+// adds to its share of the serialized proof: a header holding the chunk's
+// index, the number of chunks and the checksum of the serialized proof, and a
+// trailing CRC-32 checksum of the chunk.
+const ProofChunkOverhead = len(proofChunkMagic) + 1 + 3*4 + crc32.Size
+
+// SplitProof splits the serialized proof data into chunks of at most
+// maxChunkSize bytes each, e.g., to send proofs exceeding the maximum message
+// size of a transport in several messages. Each chunk is checksummed so that
+// corrupted chunks are detected individually, and records its position so
+// that the chunks can be reassembled by ReassembleProof in any order.
+// SplitProof panics if maxChunkSize does not exceed ProofChunkOverhead or
+// the proof requires more than 2^32-1 chunks.
+func SplitProof(data []byte, maxChunkSize int) [][]byte {
+	if maxChunkSize <= ProofChunkOverhead {
+		panic(fmt.Sprintf("Got invalid chunk size %d. Expected a size greater than %d.", maxChunkSize, ProofChunkOverhead))
+	}
+	payloadSize := maxChunkSize - ProofChunkOverhead
+	count := (len(data) + payloadSize - 1) / payloadSize
+	if count == 0 {
+		// an empty proof is sent as a single empty chunk
+		count = 1
+	}
+	if uint64(count) > math.MaxUint32 {
+		panic(fmt.Sprintf("Got %d chunks. Expected at most %d chunks.", count, uint32(math.MaxUint32)))
+	}
+	sum := crc32.ChecksumIEEE(data)
+	chunks := make([][]byte, count)
+	for i := range chunks {
+		payload := data[i*payloadSize : minInt((i+1)*payloadSize, len(data))]
+		chunk := make([]byte, 0, len(payload)+ProofChunkOverhead)
+		chunk = append(chunk, proofChunkMagic...)
+		chunk = append(chunk, proofChunkVersion)
+		chunk = binary.BigEndian.AppendUint32(chunk, uint32(i))
+		chunk = binary.BigEndian.AppendUint32(chunk, uint32(count))
+		chunk = binary.BigEndian.AppendUint32(chunk, sum)
+		chunk = append(chunk, payload...)
+		chunks[i] = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk))
+	}
+	return chunks
+}
+
+// proofChunk is a decoded chunk of a serialized proof.
+type proofChunk struct {
+	index, count, sum uint32
+	payload           []byte
+}
+
+// parseProofChunk decodes and checks a chunk returned by SplitProof.
+func parseProofChunk(chunk []byte) (proofChunk, error) {
+	if len(chunk) < ProofChunkOverhead || string(chunk[:len(proofChunkMagic)]) != proofChunkMagic {
+		return proofChunk{}, fmt.Errorf("%w: missing header", ErrInvalidProofChunk)
+	}
+	body, sum := chunk[:len(chunk)-crc32.Size], binary.BigEndian.Uint32(chunk[len(chunk)-crc32.Size:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return proofChunk{}, fmt.Errorf("%w: checksum mismatch", ErrInvalidProofChunk)
+	}
+	if version := body[len(proofChunkMagic)]; version != proofChunkVersion {
+		return proofChunk{}, fmt.Errorf("%w: unsupported version %d", ErrInvalidProofChunk, version)
+	}
+	header := body[len(proofChunkMagic)+1:]
+	c := proofChunk{
+		index:   binary.BigEndian.Uint32(header),
+		count:   binary.BigEndian.Uint32(header[4:]),
+		sum:     binary.BigEndian.Uint32(header[8:]),
+		payload: header[12:],
+	}
+	if c.index >= c.count {
+		return proofChunk{}, fmt.Errorf("%w: index %d out of range for %d chunks", ErrInvalidProofChunk, c.index, c.count)
+	}
+	return c, nil
+}
+
+// ReassembleProof reassembles the serialized proof split by SplitProof from
+// all of its chunks, given in any order. It returns an ErrInvalidProofChunk
+// error if a chunk is corrupted, chunks are missing or duplicated, or the
+// chunks belong to different proofs.
+func ReassembleProof(chunks [][]byte) ([]byte, error) {
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("%w: no chunks", ErrInvalidProofChunk)
+	}
+	parsed := make([]proofChunk, len(chunks))
+	size := 0
+	for i, chunk := range chunks {
+		c, err := parseProofChunk(chunk)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", i, err)
+		}
+		if i > 0 && (c.count != parsed[0].count || c.sum != parsed[0].sum) {
+			return nil, fmt.Errorf("%w: chunk %d belongs to a different proof", ErrInvalidProofChunk, i)
+		}
+		parsed[i] = c
+		size += len(c.payload)
+	}
+	if uint64(parsed[0].count) != uint64(len(chunks)) {
+		return nil, fmt.Errorf("%w: got %d chunks, want %d", ErrInvalidProofChunk, len(chunks), parsed[0].count)
+	}
+
+	ordered := make([][]byte, len(chunks))
+	for _, c := range parsed {
+		if ordered[c.index] != nil {
+			return nil, fmt.Errorf("%w: duplicate chunk %d", ErrInvalidProofChunk, c.index)
+		}
+		ordered[c.index] = c.payload
+	}
+	data := make([]byte, 0, size)
+	for _, payload := range ordered {
+		data = append(data, payload...)
+	}
+	if crc32.ChecksumIEEE(data) != parsed[0].sum {
+		return nil, fmt.Errorf("%w: checksum mismatch of the reassembled proof", ErrInvalidProofChunk)
+	}
+	return data, nil
+}
+
+// Chunks serializes the proof as GobEncode does and splits it into chunks of
+// at most maxChunkSize bytes, see SplitProof.
+func (proof Proof) Chunks(maxChunkSize int) ([][]byte, error) {
+	data, err := proof.GobEncode()
+	if err != nil {
+		return nil, err
+	}
+	return SplitProof(data, maxChunkSize), nil
+}
+
+// ProofFromChunks reassembles a proof from the chunks returned by
+// Proof.Chunks, given in any order, see ReassembleProof. The proof still needs
+// to be verified against a root.
+func ProofFromChunks(chunks [][]byte) (Proof, error) {
+	data, err := ReassembleProof(chunks)
+	if err != nil {
+		return Proof{}, err
+	}
+	var proof Proof
+	if err := proof.GobDecode(data); err != nil {
+		return Proof{}, err
+	}
+	return proof, nil
+}
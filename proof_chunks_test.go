@@ -0,0 +1,96 @@
+package nmt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestSplitProof(t *testing.T) {
+	data := bytes.Repeat([]byte{1, 2, 3, 4, 5, 6, 7}, 20)
+	for _, maxChunkSize := range []int{ProofChunkOverhead + 1, ProofChunkOverhead + 10, 100, len(data) + ProofChunkOverhead, 1000} {
+		chunks := SplitProof(data, maxChunkSize)
+		payloadSize := maxChunkSize - ProofChunkOverhead
+		assert.Len(t, chunks, (len(data)+payloadSize-1)/payloadSize)
+		for _, chunk := range chunks {
+			assert.LessOrEqual(t, len(chunk), maxChunkSize)
+		}
+
+		// chunks may arrive in any order
+		reversed := make([][]byte, len(chunks))
+		for i, chunk := range chunks {
+			reversed[len(chunks)-1-i] = chunk
+		}
+		got, err := ReassembleProof(reversed)
+		require.NoError(t, err)
+		assert.Equal(t, data, got, "chunk size %d", maxChunkSize)
+	}
+
+	chunks := SplitProof(nil, 100)
+	assert.Len(t, chunks, 1)
+	got, err := ReassembleProof(chunks)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	assert.Panics(t, func() { SplitProof(data, ProofChunkOverhead) })
+}
+
+func TestReassembleProof_Invalid(t *testing.T) {
+	data := bytes.Repeat([]byte{1, 2, 3}, 30)
+	chunks := SplitProof(data, 40)
+	require.Len(t, chunks, 5)
+	other := SplitProof(bytes.Repeat([]byte{4, 5, 6}, 30), 40)
+
+	corrupted := bytes.Clone(chunks[2])
+	corrupted[len(corrupted)/2] ^= 1
+	tests := []struct {
+		name   string
+		chunks [][]byte
+	}{
+		{"no chunks", nil},
+		{"missing chunk", chunks[:4]},
+		{"duplicate chunk", [][]byte{chunks[0], chunks[1], chunks[1], chunks[3], chunks[4]}},
+		{"corrupted chunk", [][]byte{chunks[0], chunks[1], corrupted, chunks[3], chunks[4]}},
+		{"truncated chunk", [][]byte{chunks[0], chunks[1], chunks[2][:10], chunks[3], chunks[4]}},
+		{"different proof", [][]byte{chunks[0], chunks[1], other[2], chunks[3], chunks[4]}},
+		{"extra chunk", append(append([][]byte{}, chunks...), chunks[0])},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReassembleProof(tt.chunks)
+			assert.ErrorIs(t, err, ErrInvalidProofChunk)
+		})
+	}
+}
+
+func TestProof_Chunks(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1))
+	for i := 0; i < 100; i++ {
+		require.NoError(t, tree.Push([]byte{byte(i / 10), byte(i)}))
+	}
+	root, err := tree.Root()
+	require.NoError(t, err)
+
+	for _, nID := range []namespace.ID{{3}, {20}} {
+		proof, err := tree.ProveNamespace(nID)
+		require.NoError(t, err)
+		chunks, err := proof.Chunks(64)
+		require.NoError(t, err)
+		if !proof.IsEmptyProof() {
+			assert.Greater(t, len(chunks), 1)
+		}
+
+		got, err := ProofFromChunks(chunks)
+		require.NoError(t, err)
+		assert.True(t, proof.Equal(got))
+		assert.True(t, got.VerifyNamespace(sha256.New(), nID, tree.Get(nID), root))
+	}
+
+	_, err = ProofFromChunks(nil)
+	assert.ErrorIs(t, err, ErrInvalidProofChunk)
+}
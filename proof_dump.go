@@ -0,0 +1,103 @@
+package nmt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// Dump writes a human-readable description of the proof to w: its range and
+// flags, and one line per node with the node's position, namespace range and
+// a truncated digest. Positions are the leaf range [start, end) covered by a
+// node and its generalized index (see GIndexRange) in a tree of treeSize
+// leaves; they are omitted if treeSize is not positive or does not match the
+// proof. Dump is meant as a debugging aid, e.g., to compare the proofs of two
+// implementations line by line, and does not verify the proof.
+func (proof Proof) Dump(w io.Writer, nidSize namespace.IDSize, treeSize int) error {
+	d := &proofDumper{w: w, nidSize: int(nidSize)}
+	d.printf("proof: range [%d, %d), %d nodes, ignore max namespace: %t\n",
+		proof.start, proof.end, len(proof.nodes), proof.isMaxNamespaceIDIgnored)
+	switch {
+	case proof.IsEmptyProof():
+		d.printf("empty proof: the namespace is outside the range of the tree\n")
+		return d.err
+	case proof.IsOfAbsence():
+		d.printf("proof of absence: leaf %d holds the next larger namespace\n", proof.start)
+	default:
+		d.printf("proof of inclusion of leaves [%d, %d)\n", proof.start, proof.end)
+	}
+
+	var gindices []uint64
+	switch {
+	case treeSize <= 0:
+		d.printf("tree size unknown, node positions omitted\n")
+	case proof.start < 0 || proof.end > treeSize || proof.start >= proof.end:
+		d.printf("range does not fit a tree of %d leaves, node positions omitted\n", treeSize)
+	default:
+		gindices = proofGIndices(proof.start, proof.end, treeSize)
+		if len(gindices) != len(proof.nodes) {
+			d.printf("a tree of %d leaves requires %d nodes, node positions omitted\n", treeSize, len(gindices))
+			gindices = nil
+		} else {
+			d.printf("tree size: %d\n", treeSize)
+		}
+	}
+	for i, node := range proof.nodes {
+		d.printf("node %d:", i)
+		if gindices != nil {
+			// the gindices are valid for the tree size
+			rng, _ := GIndexRange(treeSize, gindices[i])
+			d.printf(" leaves [%d, %d), gindex %d,", rng.Start, rng.End, gindices[i])
+		}
+		d.node(node)
+	}
+	if proof.IsOfAbsence() {
+		d.printf("leaf hash:")
+		if gindices != nil {
+			d.printf(" leaves [%d, %d),", proof.start, proof.start+1)
+		}
+		d.node(proof.leafHash)
+	}
+	return d.err
+}
+
+// DumpString returns the output of Dump as a string.
+func (proof Proof) DumpString(nidSize namespace.IDSize, treeSize int) string {
+	var buf bytes.Buffer
+	// writing to a bytes.Buffer does not fail
+	_ = proof.Dump(&buf, nidSize, treeSize)
+	return buf.String()
+}
+
+type proofDumper struct {
+	w       io.Writer
+	nidSize int
+	// err holds the first write error; subsequent writes are skipped.
+	err error
+}
+
+func (d *proofDumper) printf(format string, args ...interface{}) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = fmt.Fprintf(d.w, format, args...)
+}
+
+// node completes the line of a node with its namespace range and truncated
+// digest.
+func (d *proofDumper) node(node []byte) {
+	if len(node) < 2*d.nidSize {
+		d.printf(" malformed node of %d bytes %x\n", len(node), node)
+		return
+	}
+	minNs, maxNs := node[:d.nidSize], node[d.nidSize:2*d.nidSize]
+	digest := node[2*d.nidSize:]
+	suffix := ""
+	if len(digest) > dotDigestPrefixLen {
+		digest, suffix = digest[:dotDigestPrefixLen], "..."
+	}
+	d.printf(" min %s, max %s, digest %s%s\n", hex.EncodeToString(minNs), hex.EncodeToString(maxNs), hex.EncodeToString(digest), suffix)
+}
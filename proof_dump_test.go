@@ -0,0 +1,48 @@
+package nmt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestProof_Dump(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 2, 4, 5)
+	proof, err := tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(proof.DumpString(1, tree.Size())), "\n")
+	require.Len(t, lines, 6)
+	assert.Equal(t, "proof: range [1, 3), 3 nodes, ignore max namespace: true", lines[0])
+	assert.Equal(t, "proof of inclusion of leaves [1, 3)", lines[1])
+	assert.Equal(t, "tree size: 5", lines[2])
+	assert.Regexp(t, `^node 0: leaves \[0, 1\), gindex 8, min 01, max 01, digest [0-9a-f]{8}\.\.\.$`, lines[3])
+	assert.Regexp(t, `^node 1: leaves \[3, 4\), gindex 11, min 04, max 04, digest`, lines[4])
+	assert.Regexp(t, `^node 2: leaves \[4, 5\), gindex 3, min 05, max 05, digest`, lines[5])
+
+	// without the tree size, positions are omitted
+	lines = strings.Split(strings.TrimSpace(proof.DumpString(1, 0)), "\n")
+	require.Len(t, lines, 6)
+	assert.Equal(t, "tree size unknown, node positions omitted", lines[2])
+	assert.Regexp(t, `^node 0: min 01, max 01, digest`, lines[3])
+	assert.Contains(t, proof.DumpString(1, 16), "a tree of 16 leaves requires 4 nodes")
+
+	proof, err = tree.ProveNamespace(namespace.ID{3})
+	require.NoError(t, err)
+	dump := proof.DumpString(1, tree.Size())
+	assert.Contains(t, dump, "proof of absence: leaf 3 holds the next larger namespace")
+	assert.Regexp(t, `leaf hash: leaves \[3, 4\), min 04, max 04, digest`, dump)
+
+	assert.Contains(t, NewEmptyRangeProof(true).DumpString(1, 5), "empty proof")
+	assert.Contains(t, NewInclusionProof(0, 1, [][]byte{{1}}, true).DumpString(1, 0), "node 0: malformed node of 1 bytes 01")
+}
+
+func TestProof_Dump_WriteError(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2)
+	proof, err := tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	assert.Error(t, proof.Dump(failingWriter{}, 1, tree.Size()))
+}
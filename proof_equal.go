@@ -0,0 +1,55 @@
+package nmt
+
+import "bytes"
+
+// Normalize returns the canonical representation of the proof. The result
+// shares no memory with proof and
+//   - represents empty node lists and the leafHash of inclusion proofs as nil,
+//   - places all empty proofs at the range [0, 0) since verification ignores
+//     the position of an empty range.
+//
+// Normalization does not change the outcome of any verification.
+func (proof Proof) Normalize() Proof {
+	if proof.IsEmptyProof() {
+		return NewEmptyRangeProof(proof.isMaxNamespaceIDIgnored)
+	}
+	var nodes [][]byte
+	if len(proof.nodes) > 0 {
+		nodes = make([][]byte, len(proof.nodes))
+		for i, node := range proof.nodes {
+			nodes[i] = append([]byte(nil), node...)
+		}
+	}
+	var leafHash []byte
+	if len(proof.leafHash) > 0 {
+		leafHash = append([]byte(nil), proof.leafHash...)
+	}
+	return Proof{
+		start:                   proof.start,
+		end:                     proof.end,
+		nodes:                   nodes,
+		leafHash:                leafHash,
+		isMaxNamespaceIDIgnored: proof.isMaxNamespaceIDIgnored,
+	}
+}
+
+// Equal reports whether proof and other are structurally equal, i.e., their
+// normalized representations are identical.
+func (proof Proof) Equal(other Proof) bool {
+	if proof.IsEmptyProof() || other.IsEmptyProof() {
+		return proof.IsEmptyProof() && other.IsEmptyProof() &&
+			proof.isMaxNamespaceIDIgnored == other.isMaxNamespaceIDIgnored
+	}
+	if proof.start != other.start || proof.end != other.end ||
+		proof.isMaxNamespaceIDIgnored != other.isMaxNamespaceIDIgnored ||
+		!bytes.Equal(proof.leafHash, other.leafHash) ||
+		len(proof.nodes) != len(other.nodes) {
+		return false
+	}
+	for i, node := range proof.nodes {
+		if !bytes.Equal(node, other.nodes[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestProof_Equal(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 1, 3, 4)
+	inclusion, err := tree.ProveNamespace(namespace.ID{1})
+	require.NoError(t, err)
+	absence, err := tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	require.True(t, absence.IsOfAbsence())
+
+	copied := func(p Proof) Proof {
+		nodes := make([][]byte, len(p.nodes))
+		for i, node := range p.nodes {
+			nodes[i] = append([]byte(nil), node...)
+		}
+		return Proof{p.start, p.end, nodes, append([]byte(nil), p.leafHash...), p.isMaxNamespaceIDIgnored}
+	}
+
+	tests := []struct {
+		name string
+		a, b Proof
+		want bool
+	}{
+		{"inclusion copy", inclusion, copied(inclusion), true},
+		{"absence copy", absence, copied(absence), true},
+		{"inclusion vs absence", inclusion, absence, false},
+		{"different range", inclusion, NewInclusionProof(inclusion.start, inclusion.end+1, inclusion.nodes, true), false},
+		{"different nodes", inclusion, NewInclusionProof(inclusion.start, inclusion.end, inclusion.nodes[1:], true), false},
+		{"different ignore max", inclusion, NewInclusionProof(inclusion.start, inclusion.end, inclusion.nodes, false), false},
+		{"empty leaf hash vs nil", NewInclusionProof(1, 2, [][]byte{{1}}, true), NewAbsenceProof(1, 2, [][]byte{{1}}, []byte{}, true), true},
+		{"empty nodes vs nil", NewInclusionProof(1, 2, nil, true), NewInclusionProof(1, 2, [][]byte{}, true), true},
+		{"empty proofs at different positions", NewEmptyRangeProof(true), Proof{start: 3, end: 3, isMaxNamespaceIDIgnored: true}, true},
+		{"empty proofs with different ignore max", NewEmptyRangeProof(true), NewEmptyRangeProof(false), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.a.Equal(tt.b))
+			assert.Equal(t, tt.want, tt.b.Equal(tt.a))
+			assert.Equal(t, tt.want, tt.a.Normalize().Equal(tt.b.Normalize()))
+			// normalized representations of equal proofs are identical
+			if tt.want {
+				assert.Equal(t, tt.a.Normalize(), tt.b.Normalize())
+			}
+		})
+	}
+}
+
+func TestProof_Normalize(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 1, 3, 4)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	proof, err := tree.ProveNamespace(namespace.ID{1})
+	require.NoError(t, err)
+
+	normalized := proof.Normalize()
+	assert.True(t, normalized.VerifyNamespace(sha256.New(), namespace.ID{1}, tree.Get(namespace.ID{1}), root))
+	// the normalized proof does not share memory with the original one
+	normalized.nodes[0][0] ^= 0xFF
+	assert.False(t, proof.Equal(normalized))
+
+	empty := Proof{start: 2, end: 2, nodes: [][]byte{}, isMaxNamespaceIDIgnored: true}.Normalize()
+	assert.Equal(t, NewEmptyRangeProof(true), empty)
+}
@@ -0,0 +1,39 @@
+package nmt
+
+import (
+	"fmt"
+
+	"github.com/celestiaorg/nmt/pb"
+)
+
+// GobEncode implements gob.GobEncoder. The proof is encoded using its protobuf
+// representation. Roots are plain byte slices and need no special handling.
+func (proof Proof) GobEncode() ([]byte, error) {
+	pbProof := proof.ToProto()
+	return pbProof.Marshal()
+}
+
+// GobDecode implements gob.GobDecoder.
+func (proof *Proof) GobDecode(data []byte) error {
+	var pbProof pb.Proof
+	if err := pbProof.Unmarshal(data); err != nil {
+		return fmt.Errorf("failed to decode proof: %w", err)
+	}
+	if err := checkProtoRange(pbProof); err != nil {
+		return err
+	}
+	*proof = ProtoToProof(pbProof)
+	return nil
+}
+
+// ToProto returns the protobuf representation of the proof, see ProtoToProof
+// for the inverse.
+func (proof Proof) ToProto() pb.Proof {
+	return pb.Proof{
+		Start:                 int64(proof.start),
+		End:                   int64(proof.end),
+		Nodes:                 proof.nodes,
+		LeafHash:              proof.leafHash,
+		IsMaxNamespaceIgnored: proof.isMaxNamespaceIDIgnored,
+	}
+}
@@ -0,0 +1,75 @@
+package nmt
+
+import (
+	"bytes"
+	"encoding/gob"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+	pb "github.com/celestiaorg/nmt/pb"
+)
+
+func TestProof_Gob(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 1, 3)
+	root, err := tree.Root()
+	require.NoError(t, err)
+
+	type stored struct {
+		Root   []byte
+		Proofs []Proof
+	}
+	want := stored{Root: root}
+	for _, nID := range []namespace.ID{{1}, {2}, {9}} {
+		proof, err := tree.ProveNamespace(nID)
+		require.NoError(t, err)
+		want.Proofs = append(want.Proofs, proof)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(want))
+	var got stored
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&got))
+
+	assert.Equal(t, want.Root, got.Root)
+	require.Len(t, got.Proofs, len(want.Proofs))
+	for i := range want.Proofs {
+		assert.True(t, want.Proofs[i].Equal(got.Proofs[i]), "proof %d", i)
+	}
+	assert.False(t, got.Proofs[0].IsOfAbsence())
+	assert.True(t, got.Proofs[1].IsOfAbsence())
+	assert.True(t, got.Proofs[2].IsEmptyProof())
+
+	var proof Proof
+	assert.Error(t, proof.GobDecode([]byte{0xFF}))
+}
+
+func TestProof_ToProto(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 1, 3)
+	for _, nID := range []namespace.ID{{1}, {2}, {9}} {
+		proof, err := tree.ProveNamespace(nID)
+		require.NoError(t, err)
+		assert.True(t, proof.Equal(ProtoToProof(proof.ToProto())), "namespace %x", nID)
+	}
+}
+
+func TestProof_GobDecodeLargeRange(t *testing.T) {
+	// a proof of leaf 2^32, which only exists in trees of more than 2^32
+	// leaves
+	pbProof := pb.Proof{Start: 1 << 32, End: 1<<32 + 1, Nodes: [][]byte{make([]byte, 34)}}
+	data, err := pbProof.Marshal()
+	require.NoError(t, err)
+
+	var proof Proof
+	err = proof.GobDecode(data)
+	if strconv.IntSize < 64 {
+		// the range cannot be represented on 32-bit platforms
+		assert.ErrorIs(t, err, ErrInvalidRange)
+		return
+	}
+	require.NoError(t, err)
+	assert.Equal(t, int64(1<<32), int64(proof.Start()))
+}
@@ -0,0 +1,82 @@
+package nmt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ErrMalleableProof indicates that a proof is not in its canonical form, i.e.,
+// it might verify although it differs from the proof the tree would generate.
+var ErrMalleableProof = errors.New("non-canonical proof")
+
+// ValidateStrict checks that the proof is in the unique form generated by
+// NamespacedMerkleTree so that each valid proof has exactly one byte
+// representation. It returns an error wrapping ErrMalleableProof if
+//   - an empty proof is not located at the range [0, 0) or carries nodes or a
+//     leaf hash,
+//   - the proof range is negative or reversed,
+//   - the leaf hash of an inclusion proof is empty but not nil,
+//   - an absence proof covers more than the single leaf it carries,
+//   - any proof node is empty, or
+//   - there are fewer nodes than subtrees to the left of the proof range.
+func (proof Proof) ValidateStrict() error {
+	if proof.start == proof.end {
+		if proof.start != 0 || len(proof.nodes) != 0 || proof.leafHash != nil {
+			return fmt.Errorf("%w: empty proof must cover the range [0, 0) without nodes and leaf hash", ErrMalleableProof)
+		}
+		return nil
+	}
+	if proof.start < 0 || proof.end < proof.start {
+		return fmt.Errorf("%w: proof range [%d, %d) is invalid", ErrMalleableProof, proof.start, proof.end)
+	}
+	if proof.leafHash != nil && len(proof.leafHash) == 0 {
+		return fmt.Errorf("%w: empty leaf hash", ErrMalleableProof)
+	}
+	if proof.IsOfAbsence() && proof.end-proof.start != 1 {
+		return fmt.Errorf("%w: absence proof range [%d, %d) must cover exactly one leaf", ErrMalleableProof, proof.start, proof.end)
+	}
+	for i, node := range proof.nodes {
+		if len(node) == 0 {
+			return fmt.Errorf("%w: proof node %d is empty", ErrMalleableProof, i)
+		}
+	}
+	leftSubtrees := leftSubtreeCount(proof.start)
+	if len(proof.nodes) < leftSubtrees {
+		return fmt.Errorf("%w: got %d nodes for %d subtrees left of the proof range", ErrMalleableProof, len(proof.nodes), leftSubtrees)
+	}
+	return nil
+}
+
+// VerifyNamespaceStrict is like VerifyNamespace but additionally rejects
+// proofs that fail ValidateStrict or whose IgnoreMaxNamespace setting differs
+// from the one of nth. Such proofs result in an error wrapping
+// ErrMalleableProof.
+func (proof Proof) VerifyNamespaceStrict(nth *NmtHasher, nID namespace.ID, leaves [][]byte, root []byte) (bool, error) {
+	if err := proof.validateStrict(nth); err != nil {
+		return false, err
+	}
+	return proof.VerifyNamespace(nth.baseHasher, nID, leaves, root), nil
+}
+
+// VerifyInclusionStrict is like VerifyInclusion but additionally rejects
+// proofs that fail ValidateStrict or whose IgnoreMaxNamespace setting differs
+// from the one of nth. Such proofs result in an error wrapping
+// ErrMalleableProof.
+func (proof Proof) VerifyInclusionStrict(nth *NmtHasher, nID namespace.ID, leavesWithoutNamespace [][]byte, root []byte) (bool, error) {
+	if err := proof.validateStrict(nth); err != nil {
+		return false, err
+	}
+	if proof.IsOfAbsence() {
+		return false, fmt.Errorf("%w: absence proof used as inclusion proof", ErrMalleableProof)
+	}
+	return proof.VerifyInclusion(nth.baseHasher, nID, leavesWithoutNamespace, root), nil
+}
+
+func (proof Proof) validateStrict(nth *NmtHasher) error {
+	if proof.isMaxNamespaceIDIgnored != nth.IsMaxNamespaceIDIgnored() {
+		return fmt.Errorf("%w: proof IgnoreMaxNamespace is %v, expected %v", ErrMalleableProof, proof.isMaxNamespaceIDIgnored, nth.IsMaxNamespaceIDIgnored())
+	}
+	return proof.ValidateStrict()
+}
@@ -0,0 +1,114 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestVerifyNamespaceStrict(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 3)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	nth := NewNmtHasher(sha256.New(), 1, true)
+
+	// canonical proofs are accepted
+	for _, nID := range []byte{0, 1, 2, 3, 4} {
+		proof, err := tree.ProveNamespace(namespace.ID{nID})
+		require.NoError(t, err)
+		require.NoError(t, proof.ValidateStrict())
+		ok, err := proof.VerifyNamespaceStrict(nth, namespace.ID{nID}, tree.Get(namespace.ID{nID}), root)
+		require.NoError(t, err)
+		assert.True(t, ok, "namespace %d", nID)
+	}
+
+	absence, err := tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	require.True(t, absence.IsOfAbsence())
+	inclusion, err := tree.ProveNamespace(namespace.ID{1})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		proof  Proof
+		nID    byte
+		leaves [][]byte
+		// lenient indicates whether VerifyNamespace accepts the proof
+		lenient bool
+	}{
+		{
+			"absence proof with widened range",
+			NewAbsenceProof(absence.start, absence.end+1, absence.nodes, absence.leafHash, true),
+			2, nil, false,
+		},
+		{
+			"flipped IgnoreMaxNamespace",
+			NewInclusionProof(inclusion.start, inclusion.end, inclusion.nodes, false),
+			1, tree.Get(namespace.ID{1}), true,
+		},
+		{
+			"misplaced empty proof",
+			Proof{start: 3, end: 3, isMaxNamespaceIDIgnored: true},
+			4, nil, true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.lenient, tt.proof.VerifyNamespace(sha256.New(), namespace.ID{tt.nID}, tt.leaves, root))
+			ok, err := tt.proof.VerifyNamespaceStrict(nth, namespace.ID{tt.nID}, tt.leaves, root)
+			assert.ErrorIs(t, err, ErrMalleableProof)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestValidateStrict(t *testing.T) {
+	node := make([]byte, 34)
+	tests := []struct {
+		name    string
+		proof   Proof
+		wantErr bool
+	}{
+		{"empty proof", NewEmptyRangeProof(true), false},
+		{"empty proof with nodes", NewInclusionProof(0, 0, [][]byte{node}, true), true},
+		{"reversed range", NewInclusionProof(2, 1, [][]byte{node}, true), true},
+		{"negative range", NewInclusionProof(-1, 1, [][]byte{node}, true), true},
+		{"empty leaf hash", NewAbsenceProof(0, 1, [][]byte{node}, []byte{}, true), true},
+		{"empty node", NewInclusionProof(0, 1, [][]byte{{}}, true), true},
+		{"missing left nodes", NewInclusionProof(3, 4, [][]byte{node}, true), true},
+		{"all left nodes", NewInclusionProof(3, 4, [][]byte{node, node}, true), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.proof.ValidateStrict()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrMalleableProof)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestVerifyInclusionStrict(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 3)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	nth := NewNmtHasher(sha256.New(), 1, true)
+
+	proof, err := tree.Prove(1)
+	require.NoError(t, err)
+	leaf := tree.leaves[1][1:]
+	ok, err := proof.VerifyInclusionStrict(nth, namespace.ID{1}, [][]byte{leaf}, root)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	absence, err := tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	_, err = absence.VerifyInclusionStrict(nth, namespace.ID{3}, [][]byte{tree.leaves[2][1:]}, root)
+	assert.ErrorIs(t, err, ErrMalleableProof)
+}
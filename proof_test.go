@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"hash"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -1867,3 +1868,282 @@ func TestVerifySubtreeRootInclusion_infiniteRecursion(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func BenchmarkVerifyNamespace(b *testing.B) {
+	tests := []struct {
+		name      string
+		numLeaves int
+	}{
+		{"64-leaves", 64},
+		{"256-leaves", 256},
+	}
+
+	for _, tt := range tests {
+		data, err := generateRandNamespacedRawData(tt.numLeaves, 8, 256)
+		require.NoError(b, err)
+		tree := New(sha256.New())
+		for _, d := range data {
+			require.NoError(b, tree.Push(d))
+		}
+		root, err := tree.Root()
+		require.NoError(b, err)
+		nID := namespace.ID(data[tt.numLeaves/2][:8])
+		proof, err := tree.ProveNamespace(nID)
+		require.NoError(b, err)
+		leaves := tree.Get(nID)
+		h := sha256.New()
+		b.ResetTimer()
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if !proof.VerifyNamespace(h, nID, leaves, root) {
+					b.Fatal("verification failed")
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyInclusion_LargeIndices(t *testing.T) {
+	// a proof for the last leaf of a tree of 2^62+1 leaves (2^30+1 on 32-bit
+	// platforms), whose full tree size overflows an int
+	nth := NewNmtHasher(sha256.New(), 1, true)
+	left, err := nth.HashLeaf([]byte{0, 'x'})
+	require.NoError(t, err)
+	leafHash, err := nth.HashLeaf([]byte{1, 'd'})
+	require.NoError(t, err)
+	root, err := nth.HashNode(left, leafHash)
+	require.NoError(t, err)
+
+	start := math.MaxInt/2 + 1
+	proof := NewInclusionProof(start, start+1, [][]byte{left}, true)
+	assert.True(t, proof.VerifyInclusion(sha256.New(), namespace.ID{1}, [][]byte{{'d'}}, root))
+	assert.False(t, proof.VerifyInclusion(sha256.New(), namespace.ID{1}, [][]byte{{'e'}}, root))
+}
+
+func TestFullTreeSize(t *testing.T) {
+	tests := []struct {
+		end  uint64
+		want uint64
+	}{
+		{0, 1},
+		{1, 1},
+		{2, 2},
+		{3, 4},
+		{4, 4},
+		{5, 8},
+		{1<<62 + 1, 1 << 63},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, fullTreeSize(tt.end), "end %d", tt.end)
+	}
+}
+
+// misorderedTree returns the leaf hashes of a tree whose second leaf carries
+// the maximum namespace ID, which is hidden by its parent since the maximum
+// namespace ID is ignored, followed by leaves of smaller namespaces, and the
+// root of that tree. Such a tree cannot be built using Push.
+func misorderedTree(t *testing.T) ([][]byte, []byte) {
+	nth := NewNmtHasher(sha256.New(), 1, true)
+	var leafHashes [][]byte
+	for _, leaf := range [][]byte{{1, 'a'}, {0xFF, 'b'}, {2, 'c'}, {3, 'd'}} {
+		leafHashes = append(leafHashes, nth.MustHashLeaf(leaf))
+	}
+	left, err := nth.HashNode(leafHashes[0], leafHashes[1])
+	require.NoError(t, err)
+	// the hidden maximum namespace ID is not reflected by the left subtree
+	require.Equal(t, []byte{1, 1}, left[:2])
+	right, err := nth.HashNode(leafHashes[2], leafHashes[3])
+	require.NoError(t, err)
+	root, err := nth.HashNode(left, right)
+	require.NoError(t, err)
+	return leafHashes, root
+}
+
+func TestVerifyLeafHashes_UnorderedNodes(t *testing.T) {
+	leafHashes, root := misorderedTree(t)
+	nth := NewNmtHasher(sha256.New(), 1, true)
+	right, err := nth.HashNode(leafHashes[2], leafHashes[3])
+	require.NoError(t, err)
+
+	proof := NewInclusionProof(0, 1, [][]byte{leafHashes[1], right}, true)
+	ok, err := proof.VerifyLeafHashes(nth, false, namespace.ID{1}, leafHashes[:1], root)
+	assert.ErrorIs(t, err, ErrUnorderedSiblings)
+	assert.False(t, ok)
+	assert.False(t, proof.VerifyInclusion(sha256.New(), namespace.ID{1}, [][]byte{{'a'}}, root))
+}
+
+func TestVerifyLeafHashes_NamespaceRangeLeafHash(t *testing.T) {
+	nth := NewNmtHasher(sha256.New(), 1, true)
+	first, second := nth.MustHashLeaf([]byte{1, 'a'}), nth.MustHashLeaf([]byte{2, 'b'})
+	root, err := nth.HashNode(first, second)
+	require.NoError(t, err)
+
+	// an inner node passed off as the hash of a single leaf is rejected
+	proof := NewInclusionProof(0, 1, nil, true)
+	ok, err := proof.verifyLeafHashes(nth, false, namespace.ID{1}, namespace.ID{2}, [][]byte{root}, root)
+	assert.ErrorIs(t, err, ErrInvalidLeafHash)
+	assert.False(t, ok)
+}
+
+func TestVerify_TruncatedProof(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 4, 5)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	nth := NewNmtHasher(sha256.New(), 1, true)
+	inclusion, err := tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	absence, err := tree.ProveNamespace(namespace.ID{3})
+	require.NoError(t, err)
+	require.True(t, absence.IsOfAbsence())
+
+	truncated := func(b []byte) []byte { return b[:len(b)-1] }
+	tests := []struct {
+		name    string
+		proof   Proof
+		nID     namespace.ID
+		root    []byte
+		wantErr string
+	}{
+		{"root", inclusion, namespace.ID{2}, truncated(root), "root"},
+		{
+			"proof node",
+			NewInclusionProof(inclusion.Start(), inclusion.End(), [][]byte{inclusion.Nodes()[0], truncated(inclusion.Nodes()[1])}, true),
+			namespace.ID{2}, root, "proof node 1",
+		},
+		{
+			"leaf hash of absence proof",
+			NewAbsenceProof(absence.Start(), absence.End(), absence.Nodes(), truncated(absence.LeafHash()), true),
+			namespace.ID{3}, root, "leaf hash of the absence proof",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaves := tree.Get(tt.nID)
+			assert.False(t, tt.proof.VerifyNamespace(sha256.New(), tt.nID, leaves, root[:len(tt.root)]))
+
+			leafHashes := [][]byte{tt.proof.LeafHash()}
+			if !tt.proof.IsOfAbsence() {
+				leafHashes = [][]byte{nth.MustHashLeaf(leaves[0])}
+			}
+			ok, err := tt.proof.VerifyLeafHashes(nth, true, tt.nID, leafHashes, tt.root)
+			assert.False(t, ok)
+			assert.ErrorIs(t, err, ErrInvalidNodeLen)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	// truncated leaf hashes and leaves
+	ok, err := inclusion.VerifyLeafHashes(nth, true, namespace.ID{2}, [][]byte{truncated(nth.MustHashLeaf([]byte{2}))}, root)
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, ErrInvalidNodeLen)
+	assert.ErrorContains(t, err, "leaf hash 0")
+	assert.False(t, inclusion.VerifyNamespace(sha256.New(), namespace.ID{2}, [][]byte{{}}, root))
+}
+
+func TestVerify_OversizedNamespaceID(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	proof, err := tree.ProveNamespace(namespace.ID{0})
+	require.NoError(t, err)
+	require.True(t, proof.IsEmptyProof())
+	require.True(t, proof.VerifyNamespace(sha256.New(), namespace.ID{0}, nil, root))
+
+	// the size of a namespace ID of 257 bytes wraps around to 1, which must
+	// not be mistaken for a namespace ID of the tree
+	oversized := make(namespace.ID, namespace.IDMaxSize+2)
+	require.Equal(t, namespace.IDSize(1), oversized.Size())
+	assert.False(t, proof.VerifyNamespace(sha256.New(), oversized, nil, root))
+	assert.False(t, proof.VerifyNamespaceRange(sha256.New(), oversized, oversized, nil, root))
+
+	inclusion, err := tree.Prove(0)
+	require.NoError(t, err)
+	nth := NewNmtHasher(sha256.New(), 1, true)
+	ok, err := inclusion.VerifyLeafHashes(nth, false, oversized, [][]byte{tree.leafHashes[0]}, root)
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+	assert.False(t, inclusion.VerifyInclusion(sha256.New(), oversized, [][]byte{{}}, root))
+
+	_, err = tree.ProveNamespace(oversized)
+	assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+}
+
+func TestVerifyLeafHashes_AbsenceAdjacency(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 3, 4, 6, 7, 8, 9)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	nth := NewNmtHasher(sha256.New(), 1, true)
+	absence, err := tree.ProveNamespace(namespace.ID{5})
+	require.NoError(t, err)
+	require.True(t, absence.IsOfAbsence())
+	for _, verifyCompleteness := range []bool{false, true} {
+		ok, err := absence.VerifyLeafHashes(nth, verifyCompleteness, namespace.ID{5}, [][]byte{absence.LeafHash()}, root)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	// the leaf 4 (namespace 6) proven as if it was adjacent to namespace 4,
+	// which hides the leaf 3 (namespace 4) in the node before it
+	nodes, err := tree.buildRangeProof(4, 5)
+	require.NoError(t, err)
+	leafHash := tree.leafHashes[4]
+	hiding := NewAbsenceProof(4, 5, nodes, leafHash, true)
+	tests := []struct {
+		name  string
+		proof Proof
+		nID   namespace.ID
+	}{
+		{"hidden leaf before", hiding, namespace.ID{4}},
+		{"hidden leaf after", hiding, namespace.ID{6}},
+		{"missing nodes", NewAbsenceProof(4, 5, nil, leafHash, true), namespace.ID{5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the adjacent nodes are checked even without the completeness check
+			for _, verifyCompleteness := range []bool{false, true} {
+				ok, err := tt.proof.VerifyLeafHashes(nth, verifyCompleteness, tt.nID, [][]byte{leafHash}, root)
+				assert.False(t, ok)
+				assert.ErrorIs(t, err, ErrMissingAdjacentNode)
+			}
+			assert.False(t, tt.proof.VerifyNamespace(sha256.New(), tt.nID, nil, root))
+		})
+	}
+
+	// the supplied leaf hash must be the one of the proof
+	ok, err := absence.VerifyLeafHashes(nth, false, namespace.ID{5}, [][]byte{tree.leafHashes[5]}, root)
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+}
+
+func TestVerifyNamespaceWithOptions(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(2), IgnoreMaxNamespace(false))
+	for _, leaf := range [][]byte{{0, 0, 'a'}, {1, 0, 'b'}, {0xFF, 0xFF, 'c'}} {
+		require.NoError(t, tree.Push(leaf))
+	}
+	root, err := tree.Root()
+	require.NoError(t, err)
+	setters := []Option{RejectMaxNamespace(true), NamespaceVersions(namespace.VersionRules{0: nil, 0xFF: nil})}
+
+	nID := namespace.ID{0, 0}
+	proof, err := tree.ProveNamespace(nID)
+	require.NoError(t, err)
+	ok, err := proof.VerifyNamespaceWithOptions(sha256.New(), nID, tree.Get(nID), root, setters...)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// proofs of namespaces the options forbid are rejected although they are
+	// valid otherwise
+	for nID, wantErr := range map[string]error{
+		string([]byte{1, 0}):       namespace.ErrUnsupportedVersion,
+		string([]byte{0xFF, 0xFF}): ErrReservedNamespace,
+	} {
+		nID := namespace.ID(nID)
+		proof, err := tree.ProveNamespace(nID)
+		require.NoError(t, err)
+		require.True(t, proof.VerifyNamespace(sha256.New(), nID, tree.Get(nID), root))
+		ok, err := proof.VerifyNamespaceWithOptions(sha256.New(), nID, tree.Get(nID), root, setters...)
+		assert.ErrorIs(t, err, wantErr)
+		assert.False(t, ok)
+	}
+}
@@ -0,0 +1,30 @@
+package nmt
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// MarshalText implements encoding.TextMarshaler. The proof is represented as
+// the hexadecimal encoding of its protobuf representation, which frames the
+// range, nodes, leaf hash and IgnoreMaxNamespace flag. This is convenient for
+// CLIs, JSON-RPC parameters and test fixtures.
+func (proof Proof) MarshalText() ([]byte, error) {
+	data, err := proof.GobEncode()
+	if err != nil {
+		return nil, err
+	}
+	text := make([]byte, hex.EncodedLen(len(data)))
+	hex.Encode(text, data)
+	return text, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for the representation
+// produced by MarshalText.
+func (proof *Proof) UnmarshalText(text []byte) error {
+	data := make([]byte, hex.DecodedLen(len(text)))
+	if _, err := hex.Decode(data, text); err != nil {
+		return fmt.Errorf("failed to decode proof: %w", err)
+	}
+	return proof.GobDecode(data)
+}
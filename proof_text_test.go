@@ -0,0 +1,40 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestProof_MarshalText(t *testing.T) {
+	tree := exampleNMT(1, false, 0, 1, 1, 3, 255)
+	root, err := tree.Root()
+	require.NoError(t, err)
+
+	for _, nID := range []namespace.ID{{1}, {2}, {255}, {4}} {
+		proof, err := tree.ProveNamespace(nID)
+		require.NoError(t, err)
+		text, err := proof.MarshalText()
+		require.NoError(t, err)
+
+		var got Proof
+		require.NoError(t, got.UnmarshalText(text))
+		assert.True(t, proof.Equal(got), "namespace %x", nID)
+		assert.True(t, got.VerifyNamespace(sha256.New(), nID, tree.Get(nID), root))
+	}
+
+	var proof Proof
+	assert.Error(t, proof.UnmarshalText([]byte("not hex")))
+
+	// the JSON representation is unaffected
+	inclusion, err := tree.ProveNamespace(namespace.ID{1})
+	require.NoError(t, err)
+	data, err := json.Marshal(inclusion)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"nodes"`)
+}
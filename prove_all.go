@@ -0,0 +1,36 @@
+package nmt
+
+import (
+	"context"
+	"fmt"
+)
+
+// ProveAllNamespaces returns a namespace proof for every namespace present in
+// the tree, keyed by the string representation of the namespace ID. Each
+// proof equals the one returned by ProveNamespace. The root is computed once
+// and all proofs are assembled from its memoized inner nodes, so the total
+// cost is roughly that of a single Root() call plus the total size of the
+// proofs rather than one tree traversal per namespace.
+func (n *NamespacedMerkleTree) ProveAllNamespaces() (map[string]Proof, error) {
+	return n.ProveAllNamespacesCtx(context.Background())
+}
+
+// ProveAllNamespacesCtx is like ProveAllNamespaces but checks ctx for
+// cancellation between subtree computations.
+func (n *NamespacedMerkleTree) ProveAllNamespacesCtx(ctx context.Context) (map[string]Proof, error) {
+	if n.frontier != nil {
+		return nil, ErrFrontierOnly
+	}
+	if _, err := n.RootCtx(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get root: %w", err)
+	}
+	proofs := make(map[string]Proof, len(n.namespaceRanges))
+	for nID := range n.Namespaces() {
+		proof, err := n.ProveNamespaceCtx(ctx, nID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prove namespace %x: %w", nID, err)
+		}
+		proofs[string(nID)] = proof
+	}
+	return proofs, nil
+}
@@ -0,0 +1,63 @@
+package nmt
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveAllNamespaces(t *testing.T) {
+	for _, ignoreMax := range []bool{true, false} {
+		tree := exampleNMT(1, ignoreMax, 1, 1, 2, 4, 4, 4, 7, 0xFF)
+		proofs, err := tree.ProveAllNamespaces()
+		require.NoError(t, err)
+		assert.Len(t, proofs, 5)
+
+		root, err := tree.Root()
+		require.NoError(t, err)
+		for nID := range tree.Namespaces() {
+			proof, ok := proofs[string(nID)]
+			require.True(t, ok, "namespace %x", nID)
+			want, err := tree.ProveNamespace(nID)
+			require.NoError(t, err)
+			assert.Equal(t, want, proof)
+			if proof.IsEmptyProof() {
+				// the maximum namespace is excluded from the root
+				continue
+			}
+			assert.True(t, proof.VerifyNamespace(sha256.New(), nID, tree.Get(nID), root))
+		}
+	}
+}
+
+func TestProveAllNamespaces_HashesOnce(t *testing.T) {
+	m := &countingMetrics{}
+	tree := New(sha256.New(), NamespaceIDSize(1), CustomMetrics(m))
+	for i := 0; i < 16; i++ {
+		require.NoError(t, tree.Push([]byte{byte(i / 2), 'd'}))
+	}
+	proofs, err := tree.ProveAllNamespaces()
+	require.NoError(t, err)
+	assert.Len(t, proofs, 8)
+	// only the inner nodes of the root computation are hashed
+	assert.Equal(t, 15, m.nodeHashes)
+	assert.Equal(t, 8, m.proofs)
+}
+
+func TestProveAllNamespaces_Empty(t *testing.T) {
+	tree := New(sha256.New())
+	proofs, err := tree.ProveAllNamespaces()
+	require.NoError(t, err)
+	assert.Empty(t, proofs)
+}
+
+func TestProveAllNamespacesCtx_Canceled(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := tree.ProveAllNamespacesCtx(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,171 @@
+package nmt
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// ErrNamespacePruned indicates that the leaves of a namespace, or the nodes
+// needed to prove it, have been pruned from the tree.
+var ErrNamespacePruned = errors.New("namespace has been pruned")
+
+// PruneNamespaces computes the root of the tree and then drops the data of
+// the leaves of all namespaces for which keep returns false, e.g., so that
+// archival nodes only retain the namespaces they are interested in. The leaf
+// hashes and the memoized inner nodes are retained, hence roots and proofs of
+// all namespaces are unaffected, but Get returns nil leaves for the pruned
+// namespaces, just like for leaves added using PushLeafHash. Snapshots of the
+// tree are not affected. See Prune for dropping the leaf hashes as well.
+func (n *NamespacedMerkleTree) PruneNamespaces(keep func(nID namespace.ID) bool) error {
+	if _, err := n.Root(); err != nil {
+		return err
+	}
+	if n.shared != nil {
+		// the leaves are shared with snapshots
+		n.leaves = slices.Clone(n.leaves)
+		n.leafHashes = slices.Clip(n.leafHashes)
+		n.shared = nil
+	}
+	for nsStr, rng := range n.namespaceRanges {
+		if !keep(namespace.ID(nsStr)) {
+			clear(n.leaves[rng.Start:rng.End])
+		}
+	}
+	n.checkInvariants("PruneNamespaces")
+	return nil
+}
+
+// PrunedTree is a read-only tree retaining the leaves of selected namespaces
+// of a NamespacedMerkleTree and only the nodes needed to prove them, see
+// NamespacedMerkleTree.Prune. Its proofs are identical to those of the
+// original tree.
+type PrunedTree struct {
+	nidSize   namespace.IDSize
+	ignoreMax bool
+	// size is the number of leaves of the original tree, and treeSize
+	// additionally counts its padding leaves, if any.
+	size     int
+	treeSize int
+	root     []byte
+	// namespaces holds the ranges of the retained namespaces, and leaves
+	// their leaves, keyed by the string representation of their namespace
+	// IDs.
+	namespaces map[string]LeafRange
+	leaves     map[string][][]byte
+	// nodes holds the nodes of the proofs of the retained namespaces keyed by
+	// their gindex, see GIndexRange.
+	nodes map[uint64][]byte
+}
+
+// Prune returns a PrunedTree retaining the leaves of the namespaces for which
+// keep returns true together with the nodes needed to prove them, which
+// allows dropping the tree and thereby the leaves and leaf hashes of all
+// other namespaces. The root of the tree is computed first. Prune leaves the
+// tree unchanged; see PruneNamespaces for only dropping leaf data in place.
+func (n *NamespacedMerkleTree) Prune(keep func(nID namespace.ID) bool) (*PrunedTree, error) {
+	if n.frontier != nil {
+		return nil, ErrFrontierOnly
+	}
+	root, err := n.Root()
+	if err != nil {
+		return nil, err
+	}
+	p := &PrunedTree{
+		nidSize:    n.NamespaceSize(),
+		ignoreMax:  n.treeHasher.IsMaxNamespaceIDIgnored(),
+		size:       n.Size(),
+		treeSize:   n.sizeWithPadding(),
+		root:       root,
+		namespaces: make(map[string]LeafRange),
+		leaves:     make(map[string][][]byte),
+		nodes:      make(map[uint64][]byte),
+	}
+	for nsStr, rng := range n.namespaceRanges {
+		if !keep(namespace.ID(nsStr)) {
+			continue
+		}
+		proof, err := n.proveRange(rng.Start, rng.End)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prove namespace %s: %w", namespace.ID(nsStr), err)
+		}
+		nodes, err := proof.GIndexNodes(p.treeSize)
+		if err != nil {
+			return nil, err
+		}
+		for gindex, node := range nodes {
+			p.nodes[gindex] = node
+		}
+		p.namespaces[nsStr] = rng
+		p.leaves[nsStr] = slices.Clone(n.leaves[rng.Start:rng.End])
+	}
+	return p, nil
+}
+
+// Root returns the root of the original tree.
+func (p *PrunedTree) Root() []byte {
+	return p.root
+}
+
+// Size returns the number of leaves of the original tree.
+func (p *PrunedTree) Size() int {
+	return p.size
+}
+
+// NamespaceSize returns the namespace size of the original tree.
+func (p *PrunedTree) NamespaceSize() namespace.IDSize {
+	return p.nidSize
+}
+
+// Namespaces returns the IDs of the retained namespaces in ascending order.
+func (p *PrunedTree) Namespaces() []namespace.ID {
+	nIDs := make([]namespace.ID, 0, len(p.namespaces))
+	for nsStr := range p.namespaces {
+		nIDs = append(nIDs, namespace.ID(nsStr))
+	}
+	sort.Slice(nIDs, func(i, j int) bool { return nIDs[i].Less(nIDs[j]) })
+	return nIDs
+}
+
+// Get returns the leaves of the namespace nID as NamespacedMerkleTree.Get. It
+// returns no leaves if nID is outside the namespace range of the tree, and an
+// ErrNamespacePruned error if the namespace was not retained.
+func (p *PrunedTree) Get(nID namespace.ID) ([][]byte, error) {
+	if leaves, found := p.leaves[string(nID)]; found {
+		return leaves, nil
+	}
+	if p.outOfRange(nID) {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNamespacePruned, nID)
+}
+
+// ProveNamespace returns the proof NamespacedMerkleTree.ProveNamespace returns
+// for the namespace nID if the namespace was retained or nID is outside the
+// namespace range of the tree. Otherwise, it returns an ErrNamespacePruned
+// error, since proofs of absence of pruned namespaces may require pruned
+// nodes.
+func (p *PrunedTree) ProveNamespace(nID namespace.ID) (Proof, error) {
+	if len(nID) != int(p.nidSize) {
+		return Proof{}, fmt.Errorf("%w: got: %v, want: %v", ErrMismatchedNamespaceSize, len(nID), p.nidSize)
+	}
+	if rng, found := p.namespaces[string(nID)]; found {
+		return NewInclusionProofFromGIndices(rng.Start, rng.End, p.treeSize, p.nodes, p.ignoreMax)
+	}
+	if p.outOfRange(nID) {
+		return NewEmptyRangeProof(p.ignoreMax), nil
+	}
+	return Proof{}, fmt.Errorf("%w: %s", ErrNamespacePruned, nID)
+}
+
+// outOfRange returns true if nID is outside the namespace range of the root,
+// in which case the original tree proves it with an empty proof.
+func (p *PrunedTree) outOfRange(nID namespace.ID) bool {
+	if p.treeSize == 0 {
+		return true
+	}
+	return nID.Less(MinNamespace(p.root, p.nidSize)) || namespace.ID(MaxNamespace(p.root, p.nidSize)).Less(nID)
+}
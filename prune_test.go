@@ -0,0 +1,122 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func keepNamespaces(nIDs ...byte) func(namespace.ID) bool {
+	return func(nID namespace.ID) bool {
+		for _, keep := range nIDs {
+			if nID[0] == keep {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestNamespacedMerkleTree_PruneNamespaces(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 2, 4, 4, 4, 6)
+	want := exampleNMT(1, true, 1, 2, 2, 4, 4, 4, 6)
+	snapshot := tree.Snapshot(sha256.New())
+	require.NoError(t, tree.PruneNamespaces(keepNamespaces(2, 6)))
+
+	assert.Equal(t, want.Get(namespace.ID{2}), tree.Get(namespace.ID{2}))
+	assert.Equal(t, want.Get(namespace.ID{6}), tree.Get(namespace.ID{6}))
+	assert.Equal(t, [][]byte{nil, nil, nil}, tree.Get(namespace.ID{4}))
+	assert.Equal(t, want.Get(namespace.ID{4}), snapshot.Get(namespace.ID{4}))
+
+	root, err := tree.Root()
+	require.NoError(t, err)
+	wantRoot, err := want.Root()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoot, root)
+	for _, nID := range []namespace.ID{{1}, {3}, {4}} {
+		proof, err := tree.ProveNamespace(nID)
+		require.NoError(t, err)
+		wantProof, err := want.ProveNamespace(nID)
+		require.NoError(t, err)
+		assert.True(t, wantProof.Equal(proof))
+	}
+
+	// the tree can still be extended
+	require.NoError(t, tree.Push([]byte{7, 'x'}))
+	require.NoError(t, want.Push([]byte{7, 'x'}))
+	requireSameRoot(t, want, tree)
+}
+
+func requireSameRoot(t *testing.T, want, got *NamespacedMerkleTree) {
+	t.Helper()
+	wantRoot, err := want.Root()
+	require.NoError(t, err)
+	gotRoot, err := got.Root()
+	require.NoError(t, err)
+	require.Equal(t, wantRoot, gotRoot)
+}
+
+func TestNamespacedMerkleTree_Prune(t *testing.T) {
+	for _, opts := range [][]Option{
+		{NamespaceIDSize(1)},
+		{NamespaceIDSize(1), Padding(PadWithEmptyLeaves)},
+	} {
+		tree := New(sha256.New(), opts...)
+		for i := 0; i < 37; i++ {
+			require.NoError(t, tree.Push([]byte{byte(2 * (i / 4)), byte(i)}))
+		}
+		pruned, err := tree.Prune(keepNamespaces(0, 8, 18))
+		require.NoError(t, err)
+		root, err := tree.Root()
+		require.NoError(t, err)
+		assert.Equal(t, root, pruned.Root())
+		assert.Equal(t, 37, pruned.Size())
+		assert.Equal(t, namespace.IDSize(1), pruned.NamespaceSize())
+		assert.Equal(t, []namespace.ID{{0}, {8}, {18}}, pruned.Namespaces())
+		// far fewer nodes than leaves are retained
+		assert.Less(t, len(pruned.nodes), 20)
+
+		for _, nID := range []namespace.ID{{0}, {8}, {18}} {
+			leaves, err := pruned.Get(nID)
+			require.NoError(t, err)
+			assert.Equal(t, tree.Get(nID), leaves)
+			proof, err := pruned.ProveNamespace(nID)
+			require.NoError(t, err)
+			wantProof, err := tree.ProveNamespace(nID)
+			require.NoError(t, err)
+			assert.True(t, wantProof.Equal(proof), "namespace %s", nID)
+			assert.True(t, proof.VerifyNamespace(sha256.New(), nID, leaves, root))
+		}
+
+		for _, nID := range []namespace.ID{{2}, {3}} {
+			_, err = pruned.Get(nID)
+			assert.ErrorIs(t, err, ErrNamespacePruned)
+			_, err = pruned.ProveNamespace(nID)
+			assert.ErrorIs(t, err, ErrNamespacePruned)
+		}
+
+		leaves, err := pruned.Get(namespace.ID{20})
+		require.NoError(t, err)
+		assert.Empty(t, leaves)
+		proof, err := pruned.ProveNamespace(namespace.ID{20})
+		require.NoError(t, err)
+		assert.True(t, proof.IsEmptyProof())
+		assert.True(t, proof.VerifyNamespace(sha256.New(), namespace.ID{20}, nil, root))
+
+		_, err = pruned.ProveNamespace(namespace.ID{0, 0})
+		assert.ErrorIs(t, err, ErrMismatchedNamespaceSize)
+	}
+}
+
+func TestNamespacedMerkleTree_PruneEmpty(t *testing.T) {
+	pruned, err := New(sha256.New(), NamespaceIDSize(1)).Prune(keepNamespaces(1))
+	require.NoError(t, err)
+	assert.Empty(t, pruned.Namespaces())
+	proof, err := pruned.ProveNamespace(namespace.ID{1})
+	require.NoError(t, err)
+	assert.True(t, proof.IsEmptyProof())
+}
@@ -0,0 +1,435 @@
+// Package rfc9162 expresses NMT proofs in the structures of RFC 9162
+// (Certificate Transparency Version 2.0), so that tooling speaking CT formats
+// can consume them.
+//
+// The node hashes carried by the structures are namespaced hashes, i.e.,
+// minimum namespace ID || maximum namespace ID || digest, and must be combined
+// using the NMT node hash (see nmt.Hasher.HashNode) rather than the plain
+// RFC 9162 one. Only inclusion proofs of a single leaf have an RFC 9162
+// counterpart; namespace and absence proofs carry information the structures
+// cannot express. Consistency proofs, which NMT proofs do not provide, can be
+// generated with ProveConsistency.
+package rfc9162
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/celestiaorg/nmt"
+)
+
+// The VersionedTransType values of the RFC 9162 TransItem structures
+// supported by this package.
+const (
+	ConsistencyProofV2 uint16 = 0x0006
+	InclusionProofV2   uint16 = 0x0007
+)
+
+var (
+	// ErrInvalidEncoding indicates that data does not encode a valid RFC 9162
+	// structure.
+	ErrInvalidEncoding = errors.New("invalid RFC 9162 encoding")
+	// ErrUnsupportedProof indicates that an NMT proof has no RFC 9162
+	// counterpart.
+	ErrUnsupportedProof = errors.New("proof cannot be expressed in RFC 9162")
+	// ErrVerificationFailed indicates that a proof does not match the roots it
+	// is verified against.
+	ErrVerificationFailed = errors.New("proof verification failed")
+)
+
+// InclusionProof is the InclusionProofDataV2 structure of RFC 9162. The
+// InclusionPath is ordered from the leaf towards the root.
+type InclusionProof struct {
+	LogID         []byte
+	TreeSize      uint64
+	LeafIndex     uint64
+	InclusionPath [][]byte
+}
+
+// ConsistencyProof is the ConsistencyProofDataV2 structure of RFC 9162.
+type ConsistencyProof struct {
+	LogID           []byte
+	TreeSize1       uint64
+	TreeSize2       uint64
+	ConsistencyPath [][]byte
+}
+
+// FromProof converts an NMT inclusion proof of a single leaf of a tree of
+// treeSize leaves to an InclusionProof of the log identified by logID. It
+// returns an ErrUnsupportedProof error for any other kind of proof.
+func FromProof(logID []byte, proof nmt.Proof, treeSize int) (InclusionProof, error) {
+	if proof.IsEmptyProof() || proof.IsOfAbsence() || proof.End()-proof.Start() != 1 {
+		return InclusionProof{}, fmt.Errorf("%w: only inclusion proofs of a single leaf are supported", ErrUnsupportedProof)
+	}
+	nodes, err := proof.GIndexNodes(treeSize)
+	if err != nil {
+		return InclusionProof{}, err
+	}
+	gindices, err := pathGIndices(treeSize, proof.Start())
+	if err != nil {
+		return InclusionProof{}, err
+	}
+	var path [][]byte
+	for _, gindex := range gindices {
+		path = append(path, nodes[gindex])
+	}
+	return InclusionProof{
+		LogID:         logID,
+		TreeSize:      uint64(treeSize),
+		LeafIndex:     uint64(proof.Start()),
+		InclusionPath: path,
+	}, nil
+}
+
+// ToProof converts p to an NMT inclusion proof, which can be verified using
+// nmt.Proof.VerifyInclusion.
+func (p InclusionProof) ToProof(ignoreMaxNamespace bool) (nmt.Proof, error) {
+	treeSize, index, err := toInts(p.TreeSize, p.LeafIndex)
+	if err != nil {
+		return nmt.Proof{}, err
+	}
+	gindices, err := pathGIndices(treeSize, index)
+	if err != nil {
+		return nmt.Proof{}, err
+	}
+	if len(gindices) != len(p.InclusionPath) {
+		return nmt.Proof{}, fmt.Errorf("%w: inclusion path has %d nodes, want %d", ErrInvalidEncoding, len(p.InclusionPath), len(gindices))
+	}
+	nodes := make(map[uint64][]byte, len(gindices))
+	for i, gindex := range gindices {
+		nodes[gindex] = p.InclusionPath[i]
+	}
+	return nmt.NewInclusionProofFromGIndices(index, index+1, treeSize, nodes, ignoreMaxNamespace)
+}
+
+// pathGIndices returns the gindices of the siblings of the nodes on the path
+// from the leaf at index to the root of a tree of treeSize leaves, starting at
+// the leaf. Siblings that do not exist are skipped.
+func pathGIndices(treeSize, index int) ([]uint64, error) {
+	gindex, err := nmt.LeafGIndex(treeSize, index)
+	if err != nil {
+		return nil, err
+	}
+	var gindices []uint64
+	for ; gindex > 1; gindex >>= 1 {
+		if _, err := nmt.GIndexRange(treeSize, gindex^1); err == nil {
+			gindices = append(gindices, gindex^1)
+		}
+	}
+	return gindices, nil
+}
+
+// ProveConsistency returns the proof that the tree consisting of the first
+// size1 leaves of tree is a prefix of tree, as defined in RFC 9162.
+func ProveConsistency(logID []byte, tree *nmt.NamespacedMerkleTree, size1 int) (ConsistencyProof, error) {
+	size2 := tree.Size()
+	if size1 <= 0 || size1 > size2 {
+		return ConsistencyProof{}, fmt.Errorf("tree size %d is out of the range [1, %d]: %w", size1, size2, nmt.ErrInvalidRange)
+	}
+	path, err := subproof(tree, size1, 0, size2, true)
+	if err != nil {
+		return ConsistencyProof{}, err
+	}
+	return ConsistencyProof{
+		LogID:           logID,
+		TreeSize1:       uint64(size1),
+		TreeSize2:       uint64(size2),
+		ConsistencyPath: path,
+	}, nil
+}
+
+// subproof implements SUBPROOF(m, D[start:end], b) of RFC 9162 with m
+// relative to start.
+func subproof(tree *nmt.NamespacedMerkleTree, m, start, end int, b bool) ([][]byte, error) {
+	if m == end-start {
+		if b {
+			return nil, nil
+		}
+		root, err := tree.SubtreeRoot(start, end)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{root}, nil
+	}
+	k := largestPowerOfTwoBelow(end - start)
+	var (
+		path    [][]byte
+		sibling []byte
+		err     error
+	)
+	if m <= k {
+		path, err = subproof(tree, m, start, start+k, b)
+		if err == nil {
+			sibling, err = tree.SubtreeRoot(start+k, end)
+		}
+	} else {
+		path, err = subproof(tree, m-k, start+k, end, false)
+		if err == nil {
+			sibling, err = tree.SubtreeRoot(start, start+k)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(path, sibling), nil
+}
+
+// Verify verifies that root1 is the root of a prefix of TreeSize1 leaves of
+// the tree with root root2 and TreeSize2 leaves, following the algorithm of
+// RFC 9162 Section 2.1.4.2. nth must be the hasher of the trees.
+func (p ConsistencyProof) Verify(nth nmt.Hasher, root1, root2 []byte) error {
+	first, second := p.TreeSize1, p.TreeSize2
+	if first == 0 || second < first {
+		return fmt.Errorf("%w: invalid tree sizes %d and %d", ErrVerificationFailed, first, second)
+	}
+	if first == second {
+		if len(p.ConsistencyPath) != 0 || !bytes.Equal(root1, root2) {
+			return fmt.Errorf("%w: roots of equal tree sizes differ", ErrVerificationFailed)
+		}
+		return nil
+	}
+	path := p.ConsistencyPath
+	if first&(first-1) == 0 {
+		path = append([][]byte{root1}, path...)
+	}
+	if len(path) == 0 {
+		return fmt.Errorf("%w: empty consistency path", ErrVerificationFailed)
+	}
+	fn, sn := first-1, second-1
+	for fn&1 == 1 {
+		fn, sn = fn>>1, sn>>1
+	}
+	fr, sr := path[0], path[0]
+	for _, c := range path[1:] {
+		if sn == 0 {
+			return fmt.Errorf("%w: consistency path is too long", ErrVerificationFailed)
+		}
+		var err error
+		if fn&1 == 1 || fn == sn {
+			if fr, err = nth.HashNode(c, fr); err != nil {
+				return fmt.Errorf("%w: %w", ErrVerificationFailed, err)
+			}
+			if sr, err = nth.HashNode(c, sr); err != nil {
+				return fmt.Errorf("%w: %w", ErrVerificationFailed, err)
+			}
+			for fn&1 == 0 && fn != 0 {
+				fn, sn = fn>>1, sn>>1
+			}
+		} else if sr, err = nth.HashNode(sr, c); err != nil {
+			return fmt.Errorf("%w: %w", ErrVerificationFailed, err)
+		}
+		fn, sn = fn>>1, sn>>1
+	}
+	if sn != 0 || !bytes.Equal(fr, root1) || !bytes.Equal(sr, root2) {
+		return fmt.Errorf("%w: consistency path does not match the roots", ErrVerificationFailed)
+	}
+	return nil
+}
+
+// MarshalBinary encodes p as a TransItem of type inclusion_proof_v2.
+func (p InclusionProof) MarshalBinary() ([]byte, error) {
+	w := &writer{}
+	w.uint16(InclusionProofV2)
+	w.logID(p.LogID)
+	w.uint64(p.TreeSize)
+	w.uint64(p.LeafIndex)
+	w.nodeHashes(p.InclusionPath)
+	return w.buf, w.err
+}
+
+// UnmarshalBinary decodes a TransItem of type inclusion_proof_v2 into p.
+func (p *InclusionProof) UnmarshalBinary(data []byte) error {
+	r := &reader{buf: data}
+	r.transType(InclusionProofV2)
+	res := InclusionProof{
+		LogID:     r.logID(),
+		TreeSize:  r.uint64(),
+		LeafIndex: r.uint64(),
+	}
+	res.InclusionPath = r.nodeHashes()
+	if err := r.finish(); err != nil {
+		return err
+	}
+	if res.LeafIndex >= res.TreeSize {
+		return fmt.Errorf("%w: leaf index %d is out of the tree range [0, %d)", ErrInvalidEncoding, res.LeafIndex, res.TreeSize)
+	}
+	*p = res
+	return nil
+}
+
+// MarshalBinary encodes p as a TransItem of type consistency_proof_v2.
+func (p ConsistencyProof) MarshalBinary() ([]byte, error) {
+	w := &writer{}
+	w.uint16(ConsistencyProofV2)
+	w.logID(p.LogID)
+	w.uint64(p.TreeSize1)
+	w.uint64(p.TreeSize2)
+	w.nodeHashes(p.ConsistencyPath)
+	return w.buf, w.err
+}
+
+// UnmarshalBinary decodes a TransItem of type consistency_proof_v2 into p.
+func (p *ConsistencyProof) UnmarshalBinary(data []byte) error {
+	r := &reader{buf: data}
+	r.transType(ConsistencyProofV2)
+	res := ConsistencyProof{
+		LogID:     r.logID(),
+		TreeSize1: r.uint64(),
+		TreeSize2: r.uint64(),
+	}
+	res.ConsistencyPath = r.nodeHashes()
+	if err := r.finish(); err != nil {
+		return err
+	}
+	*p = res
+	return nil
+}
+
+// The bounds of the variable-length vectors of RFC 9162.
+const (
+	minLogIDLen    = 2
+	maxLogIDLen    = 127
+	minNodeHashLen = 32
+	maxNodeHashLen = 1<<8 - 1
+	maxPathLen     = 1<<16 - 1
+)
+
+// writer encodes TLS presentation language structures, recording the first
+// error encountered.
+type writer struct {
+	buf []byte
+	err error
+}
+
+func (w *writer) uint16(v uint16) { w.buf = binary.BigEndian.AppendUint16(w.buf, v) }
+
+func (w *writer) uint64(v uint64) { w.buf = binary.BigEndian.AppendUint64(w.buf, v) }
+
+func (w *writer) logID(id []byte) {
+	if len(id) < minLogIDLen || len(id) > maxLogIDLen {
+		w.fail(fmt.Errorf("%w: log ID length %d is out of the range [%d, %d]", ErrInvalidEncoding, len(id), minLogIDLen, maxLogIDLen))
+		return
+	}
+	w.buf = append(append(w.buf, byte(len(id))), id...)
+}
+
+func (w *writer) nodeHashes(nodes [][]byte) {
+	total := 0
+	for _, node := range nodes {
+		if len(node) < minNodeHashLen || len(node) > maxNodeHashLen {
+			w.fail(fmt.Errorf("%w: node hash length %d is out of the range [%d, %d]", ErrInvalidEncoding, len(node), minNodeHashLen, maxNodeHashLen))
+			return
+		}
+		total += 1 + len(node)
+	}
+	if total > maxPathLen {
+		w.fail(fmt.Errorf("%w: path of %d bytes is too long", ErrInvalidEncoding, total))
+		return
+	}
+	w.uint16(uint16(total))
+	for _, node := range nodes {
+		w.buf = append(append(w.buf, byte(len(node))), node...)
+	}
+}
+
+func (w *writer) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// reader decodes TLS presentation language structures, recording the first
+// error encountered.
+type reader struct {
+	buf []byte
+	err error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.buf) < n {
+		r.err = fmt.Errorf("%w: unexpected end of data", ErrInvalidEncoding)
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *reader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *reader) uint64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *reader) transType(want uint16) {
+	if got := r.uint16(); r.err == nil && got != want {
+		r.err = fmt.Errorf("%w: got TransItem type %d, want %d", ErrInvalidEncoding, got, want)
+	}
+}
+
+func (r *reader) opaque8(minLen, maxLen int) []byte {
+	l := r.next(1)
+	if l == nil {
+		return nil
+	}
+	if int(l[0]) < minLen || int(l[0]) > maxLen {
+		r.err = fmt.Errorf("%w: length %d is out of the range [%d, %d]", ErrInvalidEncoding, l[0], minLen, maxLen)
+		return nil
+	}
+	return append([]byte(nil), r.next(int(l[0]))...)
+}
+
+func (r *reader) logID() []byte {
+	return r.opaque8(minLogIDLen, maxLogIDLen)
+}
+
+func (r *reader) nodeHashes() [][]byte {
+	total := r.uint16()
+	vec := &reader{buf: r.next(int(total))}
+	if r.err != nil {
+		return nil
+	}
+	var nodes [][]byte
+	for len(vec.buf) > 0 && vec.err == nil {
+		nodes = append(nodes, vec.opaque8(minNodeHashLen, maxNodeHashLen))
+	}
+	r.err = vec.err
+	return nodes
+}
+
+func (r *reader) finish() error {
+	if r.err == nil && len(r.buf) != 0 {
+		r.err = fmt.Errorf("%w: %d trailing bytes", ErrInvalidEncoding, len(r.buf))
+	}
+	return r.err
+}
+
+// toInts converts a tree size and leaf index to ints, checking that the index
+// is within the tree.
+func toInts(treeSize, index uint64) (int, int, error) {
+	if treeSize > uint64(maxInt) || index >= treeSize {
+		return 0, 0, fmt.Errorf("%w: leaf index %d is out of the tree range [0, %d)", ErrInvalidEncoding, index, treeSize)
+	}
+	return int(treeSize), int(index), nil
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+// largestPowerOfTwoBelow returns the largest power of two smaller than n > 1.
+func largestPowerOfTwoBelow(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
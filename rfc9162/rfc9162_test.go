@@ -0,0 +1,145 @@
+package rfc9162
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+var logID = []byte{0x06, 0x03, 0x2b, 0x06, 0x01}
+
+func buildTree(t *testing.T, size int) *nmt.NamespacedMerkleTree {
+	tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(1))
+	for i := 0; i < size; i++ {
+		require.NoError(t, tree.Push([]byte{byte(i), 'd', byte(i)}))
+	}
+	return tree
+}
+
+func TestInclusionProof(t *testing.T) {
+	for size := 1; size <= 17; size++ {
+		tree := buildTree(t, size)
+		root, err := tree.Root()
+		require.NoError(t, err)
+		for index := 0; index < size; index++ {
+			t.Run(fmt.Sprintf("size %d index %d", size, index), func(t *testing.T) {
+				proof, err := tree.Prove(index)
+				require.NoError(t, err)
+				ct, err := FromProof(logID, proof, size)
+				require.NoError(t, err)
+				assert.Equal(t, uint64(size), ct.TreeSize)
+				assert.Equal(t, uint64(index), ct.LeafIndex)
+				assert.Len(t, ct.InclusionPath, len(proof.Nodes()))
+
+				data, err := ct.MarshalBinary()
+				require.NoError(t, err)
+				var decoded InclusionProof
+				require.NoError(t, decoded.UnmarshalBinary(data))
+				assert.Equal(t, ct, decoded)
+
+				got, err := decoded.ToProof(true)
+				require.NoError(t, err)
+				assert.Equal(t, proof, got)
+				leaf := []byte{byte(index), 'd', byte(index)}
+				assert.True(t, got.VerifyInclusion(sha256.New(), namespace.ID(leaf[:1]), [][]byte{leaf[1:]}, root))
+			})
+		}
+	}
+}
+
+func TestInclusionProof_PathOrder(t *testing.T) {
+	// the path of leaf 0 of a tree of 4 leaves is leaf 1 followed by the
+	// root of leaves [2, 4)
+	tree := buildTree(t, 4)
+	proof, err := tree.Prove(0)
+	require.NoError(t, err)
+	ct, err := FromProof(logID, proof, 4)
+	require.NoError(t, err)
+	leaf1, err := tree.SubtreeRoot(1, 2)
+	require.NoError(t, err)
+	right, err := tree.SubtreeRoot(2, 4)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{leaf1, right}, ct.InclusionPath)
+}
+
+func TestFromProof_Unsupported(t *testing.T) {
+	tree := buildTree(t, 4)
+	proof, err := tree.ProveRange(0, 2)
+	require.NoError(t, err)
+	_, err = FromProof(logID, proof, 4)
+	assert.ErrorIs(t, err, ErrUnsupportedProof)
+	_, err = FromProof(logID, nmt.NewEmptyRangeProof(true), 4)
+	assert.ErrorIs(t, err, ErrUnsupportedProof)
+}
+
+func TestConsistencyProof(t *testing.T) {
+	nth := nmt.NewNmtHasher(sha256.New(), 1, true)
+	for size2 := 1; size2 <= 17; size2++ {
+		tree := buildTree(t, size2)
+		root2, err := tree.Root()
+		require.NoError(t, err)
+		for size1 := 1; size1 <= size2; size1++ {
+			t.Run(fmt.Sprintf("%d to %d", size1, size2), func(t *testing.T) {
+				root1, err := buildTree(t, size1).Root()
+				require.NoError(t, err)
+				proof, err := ProveConsistency(logID, tree, size1)
+				require.NoError(t, err)
+				require.NoError(t, proof.Verify(nth, root1, root2))
+
+				data, err := proof.MarshalBinary()
+				require.NoError(t, err)
+				var decoded ConsistencyProof
+				require.NoError(t, decoded.UnmarshalBinary(data))
+				assert.Equal(t, proof, decoded)
+
+				// a different old root must be rejected
+				other, err := buildTree(t, size1+1).Root()
+				require.NoError(t, err)
+				assert.ErrorIs(t, proof.Verify(nth, other, root2), ErrVerificationFailed)
+				if len(proof.ConsistencyPath) > 0 {
+					tampered := proof
+					tampered.ConsistencyPath = append([][]byte{root2}, proof.ConsistencyPath[1:]...)
+					assert.ErrorIs(t, tampered.Verify(nth, root1, root2), ErrVerificationFailed)
+				}
+			})
+		}
+	}
+}
+
+func TestProveConsistency_InvalidSize(t *testing.T) {
+	tree := buildTree(t, 4)
+	_, err := ProveConsistency(logID, tree, 0)
+	assert.ErrorIs(t, err, nmt.ErrInvalidRange)
+	_, err = ProveConsistency(logID, tree, 5)
+	assert.ErrorIs(t, err, nmt.ErrInvalidRange)
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	tree := buildTree(t, 4)
+	proof, err := tree.Prove(1)
+	require.NoError(t, err)
+	ct, err := FromProof(logID, proof, 4)
+	require.NoError(t, err)
+	data, err := ct.MarshalBinary()
+	require.NoError(t, err)
+
+	var p InclusionProof
+	assert.ErrorIs(t, p.UnmarshalBinary(data[:len(data)-1]), ErrInvalidEncoding)
+	assert.ErrorIs(t, p.UnmarshalBinary(append(data, 0)), ErrInvalidEncoding)
+	var c ConsistencyProof
+	assert.ErrorIs(t, c.UnmarshalBinary(data), ErrInvalidEncoding)
+
+	ct.LogID = []byte{1}
+	_, err = ct.MarshalBinary()
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+	ct.LogID = logID
+	ct.InclusionPath = [][]byte{{1, 2, 3}}
+	_, err = ct.MarshalBinary()
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+}
@@ -0,0 +1,174 @@
+package nmt
+
+import (
+	"fmt"
+	"hash"
+	"math/bits"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// RootComputer computes the root of a namespaced Merkle tree from a stream of
+// leaves without holding them in memory. It consumes leaves in order and only
+// keeps the roots of the O(log n) perfect subtrees on the right frontier of
+// the tree. The resulting root is identical to the one of a
+// NamespacedMerkleTree with the same options and leaves. Long computations can
+// be checkpointed and resumed, see SaveCheckpoint.
+type RootComputer struct {
+	treeHasher Hasher
+	// peaks holds the roots of the perfect subtrees covering all leaves
+	// pushed so far, ordered from the largest (left-most) to the smallest
+	// (right-most) subtree. The binary representation of size determines
+	// their heights.
+	peaks [][]byte
+	size  int
+	// lastNID is the namespace ID of the last pushed leaf.
+	lastNID namespace.ID
+	// lastLeafHash is the hash of the last pushed leaf.
+	lastLeafHash []byte
+	padding      PaddingStrategy
+	// fixedSize is the number of leaves of a tree of fixed height, or 0.
+	fixedSize int
+	// rejectMaxNamespace and namespaceVersions restrict the namespace IDs of
+	// pushed leaves as for NamespacedMerkleTree.
+	rejectMaxNamespace bool
+	namespaceVersions  namespace.VersionRules
+}
+
+// NewRootComputer returns a RootComputer for the given base hash function.
+// It accepts the same options as New; options that only concern the stored
+// tree, such as InitialCapacity or NodeVisitor, are ignored.
+func NewRootComputer(h hash.Hash, setters ...Option) *RootComputer {
+	opts := newOptions(h, setters...)
+	return &RootComputer{
+		treeHasher:         opts.Hasher,
+		padding:            opts.Padding,
+		fixedSize:          opts.FixedSize,
+		rejectMaxNamespace: opts.RejectMaxNamespace,
+		namespaceVersions:  opts.NamespaceVersions,
+	}
+}
+
+// Push adds the namespace-prefixed data as the next leaf. The same rules as
+// for NamespacedMerkleTree.Push apply, i.e., it returns an ErrInvalidLeafLen
+// error if the data is shorter than the namespace size, an ErrInvalidPushOrder
+// error if its namespace ID is smaller than the one of the previous leaf, and
+// the errors of Options.ValidateNamespace if its namespace ID violates the
+// RejectMaxNamespace or NamespaceVersions options. If the tree has a fixed height, it returns an ErrTreeFull
+// error once the tree is full.
+func (c *RootComputer) Push(namespacedData namespace.PrefixedData) error {
+	if c.fixedSize > 0 && c.size >= c.fixedSize {
+		return fmt.Errorf("%w: capacity %d", ErrTreeFull, c.fixedSize)
+	}
+	nidSize := int(c.treeHasher.NamespaceSize())
+	if len(namespacedData) < nidSize {
+		return fmt.Errorf("%w: got: %v, want >= %v", ErrInvalidLeafLen, len(namespacedData), nidSize)
+	}
+	nID := namespace.ID(namespacedData[:nidSize])
+	if err := validateNamespaceRules(nID, c.rejectMaxNamespace, c.namespaceVersions); err != nil {
+		return err
+	}
+	if c.size > 0 && nID.Less(c.lastNID) {
+		return fmt.Errorf("%w: last namespace: %x, pushed: %x", ErrInvalidPushOrder, c.lastNID, nID)
+	}
+
+	node, err := c.treeHasher.HashLeaf(namespacedData)
+	if err != nil {
+		return err
+	}
+	c.lastLeafHash = node
+	c.peaks, err = c.merge(c.peaks, c.size, 0, node)
+	if err != nil {
+		return err
+	}
+	c.size++
+	c.lastNID = append(c.lastNID[:0], nID...)
+	return nil
+}
+
+// merge adds node, the root of a perfect subtree of the given height, as the
+// right-most subtree of a tree of size leaves with the given peaks and returns
+// the resulting peaks. size must be a multiple of the subtree's size.
+func (c *RootComputer) merge(peaks [][]byte, size, height int, node []byte) ([][]byte, error) {
+	// merge the new subtree with all peaks of the same height, just like
+	// carrying when incrementing a binary counter
+	for s := size >> height; s&1 == 1; s >>= 1 {
+		last := len(peaks) - 1
+		var err error
+		node, err = c.treeHasher.HashNode(peaks[last], node)
+		if err != nil {
+			return nil, err
+		}
+		peaks = peaks[:last]
+	}
+	return append(peaks, node), nil
+}
+
+// Size returns the number of leaves pushed so far.
+func (c *RootComputer) Size() int {
+	return c.size
+}
+
+// Root returns the root of the tree consisting of all leaves pushed so far.
+// More leaves may be pushed afterwards.
+func (c *RootComputer) Root() ([]byte, error) {
+	if c.size == 0 && c.fixedSize == 0 {
+		return c.treeHasher.EmptyRoot(), nil
+	}
+	if c.padding != NoPadding {
+		return c.paddedRoot()
+	}
+	// the right-most peaks form the right subtrees of the remaining ones,
+	// hence they are folded from right to left
+	root := c.peaks[len(c.peaks)-1]
+	for i := len(c.peaks) - 2; i >= 0; i-- {
+		var err error
+		root, err = c.treeHasher.HashNode(c.peaks[i], root)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// Reset removes all leaves so that the RootComputer can be reused.
+func (c *RootComputer) Reset() {
+	clear(c.peaks)
+	c.peaks = c.peaks[:0]
+	c.size = 0
+	c.lastNID = c.lastNID[:0]
+	c.lastLeafHash = nil
+}
+
+// paddedRoot returns the root of the tree padded according to the padding
+// strategy. The padding is added as perfect subtrees of padding leaves
+// filling up the tree to the next power of two, or to its capacity if it has a
+// fixed height, leaving the peaks untouched.
+func (c *RootComputer) paddedRoot() ([]byte, error) {
+	padLeaf, err := paddingLeafHash(c.treeHasher, c.padding, c.lastLeafHash)
+	if err != nil {
+		return nil, err
+	}
+	padNodes := [][]byte{padLeaf}
+	peaks := append([][]byte(nil), c.peaks...)
+	target := paddedSize(c.size)
+	if c.fixedSize > 0 {
+		target = c.fixedSize
+	}
+	for size := c.size; size < target; {
+		// the next padding subtree is the largest one aligned at size
+		height := bits.TrailingZeros(uint(size))
+		if size == 0 {
+			height = bits.TrailingZeros(uint(target))
+		}
+		pad, err := padSubtree(c.treeHasher, &padNodes, height)
+		if err != nil {
+			return nil, err
+		}
+		if peaks, err = c.merge(peaks, size, height, pad); err != nil {
+			return nil, err
+		}
+		size += 1 << height
+	}
+	return peaks[0], nil
+}
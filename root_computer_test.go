@@ -0,0 +1,69 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestRootComputer(t *testing.T) {
+	for _, ignoreMax := range []bool{true, false} {
+		tree := New(sha256.New(), NamespaceIDSize(2), IgnoreMaxNamespace(ignoreMax))
+		computer := NewRootComputer(sha256.New(), NamespaceIDSize(2), IgnoreMaxNamespace(ignoreMax))
+		for i := 0; i <= 70; i++ {
+			want, err := tree.Root()
+			require.NoError(t, err)
+			got, err := computer.Root()
+			require.NoError(t, err)
+			require.Equal(t, want, got, "size %d, ignoreMax %v", i, ignoreMax)
+			assert.Equal(t, tree.Size(), computer.Size())
+
+			nID := byte(i / 4)
+			if i >= 64 {
+				nID = 0xFF // parity namespace
+			}
+			leaf := append([]byte{nID, nID}, []byte(fmt.Sprintf("leaf_%d", i))...)
+			require.NoError(t, tree.Push(leaf))
+			require.NoError(t, computer.Push(leaf))
+		}
+	}
+}
+
+func TestRootComputer_Errors(t *testing.T) {
+	computer := NewRootComputer(sha256.New(), NamespaceIDSize(2))
+	assert.ErrorIs(t, computer.Push([]byte{1}), ErrInvalidLeafLen)
+	require.NoError(t, computer.Push([]byte{0, 2, 'a'}))
+	assert.ErrorIs(t, computer.Push([]byte{0, 1, 'b'}), ErrInvalidPushOrder)
+	assert.Equal(t, 1, computer.Size())
+}
+
+func TestRootComputer_NamespaceRules(t *testing.T) {
+	computer := NewRootComputer(sha256.New(), NamespaceIDSize(2), RejectMaxNamespace(true),
+		NamespaceVersions(namespace.VersionRules{0: namespace.RequireLeadingZeros(1)}))
+	require.NoError(t, computer.Push([]byte{0, 0, 'a'}))
+	assert.ErrorIs(t, computer.Push([]byte{0, 1, 'b'}), namespace.ErrInvalidVersionedID)
+	assert.ErrorIs(t, computer.Push([]byte{1, 0, 'c'}), namespace.ErrUnsupportedVersion)
+	assert.ErrorIs(t, computer.Push([]byte{0xFF, 0xFF, 'd'}), ErrReservedNamespace)
+	assert.Equal(t, 1, computer.Size())
+}
+
+func TestRootComputer_Reset(t *testing.T) {
+	c := NewRootComputer(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, c.Push([]byte{5, 'a'}))
+	c.Reset()
+	assert.Zero(t, c.Size())
+	// the push order is reset as well
+	require.NoError(t, c.Push([]byte{1, 'a'}))
+	got, err := c.Root()
+	require.NoError(t, err)
+	tree := New(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, tree.Push([]byte{1, 'a'}))
+	want, err := tree.Root()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,88 @@
+package nmt
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/celestiaorg/nmt/internal/bech32"
+	"github.com/celestiaorg/nmt/namespace"
+	"github.com/celestiaorg/nmt/pb"
+)
+
+// ErrInvalidRootEncoding indicates that a string is not a valid encoding of a
+// namespaced root.
+var ErrInvalidRootEncoding = errors.New("invalid root encoding")
+
+// FormatRoot returns the hexadecimal encoding of a namespaced root, e.g. for
+// RPC responses and logs.
+func FormatRoot(root []byte) string {
+	return hex.EncodeToString(root)
+}
+
+// FormatRootBech32 returns the bech32 encoding of a namespaced root with the
+// human readable part hrp. The result exceeds the 90 characters limit of BIP
+// 173 for typical root sizes.
+func FormatRootBech32(hrp string, root []byte) (string, error) {
+	return bech32.Encode(hrp, root)
+}
+
+// ParseRoot parses a hex encoded root as returned by FormatRoot and checks
+// that it conforms to the namespaced hash format of nth.
+func ParseRoot(nth *NmtHasher, s string) ([]byte, error) {
+	root, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRootEncoding, err)
+	}
+	return validateParsedRoot(nth, root)
+}
+
+// ParseRootBech32 parses a bech32 encoded root as returned by
+// FormatRootBech32, and checks its human readable part and that it conforms to
+// the namespaced hash format of nth.
+func ParseRootBech32(nth *NmtHasher, hrp, s string) ([]byte, error) {
+	gotHRP, root, err := bech32.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRootEncoding, err)
+	}
+	if gotHRP != hrp {
+		return nil, fmt.Errorf("%w: human readable part %q, expected %q", ErrInvalidRootEncoding, gotHRP, hrp)
+	}
+	return validateParsedRoot(nth, root)
+}
+
+// RootToProto returns the protobuf representation of a namespaced root of a
+// tree with namespace IDs of nidSize bytes. It returns an
+// ErrInvalidRootEncoding error if the root is shorter than its namespace
+// range.
+func RootToProto(root []byte, nidSize namespace.IDSize) (pb.Root, error) {
+	if len(root) < 2*int(nidSize) {
+		return pb.Root{}, fmt.Errorf("%w: got %d bytes, want at least %d", ErrInvalidRootEncoding, len(root), 2*int(nidSize))
+	}
+	return pb.Root{
+		MinNamespaceId: MinNamespace(root, nidSize),
+		MaxNamespaceId: MaxNamespace(root, nidSize),
+		Digest:         root[2*nidSize:],
+	}, nil
+}
+
+// ProtoToRoot creates a namespaced root from its protobuf representation and
+// checks that it conforms to the namespaced hash format of nth.
+func ProtoToRoot(nth *NmtHasher, protoRoot pb.Root) ([]byte, error) {
+	if len(protoRoot.MinNamespaceId) != int(nth.NamespaceSize()) || len(protoRoot.MaxNamespaceId) != int(nth.NamespaceSize()) {
+		return nil, fmt.Errorf("%w: namespace IDs of %d and %d bytes, want %d", ErrInvalidRootEncoding,
+			len(protoRoot.MinNamespaceId), len(protoRoot.MaxNamespaceId), nth.NamespaceSize())
+	}
+	root := make([]byte, 0, len(protoRoot.MinNamespaceId)+len(protoRoot.MaxNamespaceId)+len(protoRoot.Digest))
+	root = append(root, protoRoot.MinNamespaceId...)
+	root = append(root, protoRoot.MaxNamespaceId...)
+	root = append(root, protoRoot.Digest...)
+	return validateParsedRoot(nth, root)
+}
+
+func validateParsedRoot(nth *NmtHasher, root []byte) ([]byte, error) {
+	if err := nth.ValidateNodeFormat(root); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRootEncoding, err)
+	}
+	return root, nil
+}
@@ -0,0 +1,67 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/pb"
+)
+
+func TestRootEncoding(t *testing.T) {
+	tree := exampleNMT(2, true, 1, 2, 3)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	nth := NewNmtHasher(sha256.New(), 2, true)
+
+	got, err := ParseRoot(nth, FormatRoot(root))
+	require.NoError(t, err)
+	assert.Equal(t, root, got)
+
+	s, err := FormatRootBech32("nmtroot", root)
+	require.NoError(t, err)
+	got, err = ParseRootBech32(nth, "nmtroot", s)
+	require.NoError(t, err)
+	assert.Equal(t, root, got)
+
+	// the length is validated against the namespace size
+	_, err = ParseRoot(NewNmtHasher(sha256.New(), 3, true), FormatRoot(root))
+	assert.ErrorIs(t, err, ErrInvalidRootEncoding)
+	assert.ErrorIs(t, err, ErrInvalidNodeLen)
+	_, err = ParseRootBech32(NewNmtHasher(sha256.New(), 1, true), "nmtroot", s)
+	assert.ErrorIs(t, err, ErrInvalidNodeLen)
+
+	_, err = ParseRoot(nth, "xyz")
+	assert.ErrorIs(t, err, ErrInvalidRootEncoding)
+	_, err = ParseRootBech32(nth, "other", s)
+	assert.ErrorIs(t, err, ErrInvalidRootEncoding)
+}
+
+func TestRootProto(t *testing.T) {
+	tree := exampleNMT(2, true, 1, 2, 3)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	nth := NewNmtHasher(sha256.New(), 2, true)
+
+	protoRoot, err := RootToProto(root, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 1}, protoRoot.MinNamespaceId)
+	assert.Equal(t, []byte{3, 3}, protoRoot.MaxNamespaceId)
+	data, err := protoRoot.Marshal()
+	require.NoError(t, err)
+	var decoded pb.Root
+	require.NoError(t, decoded.Unmarshal(data))
+	got, err := ProtoToRoot(nth, decoded)
+	require.NoError(t, err)
+	assert.Equal(t, root, got)
+
+	_, err = RootToProto(root[:3], 2)
+	assert.ErrorIs(t, err, ErrInvalidRootEncoding)
+	_, err = ProtoToRoot(NewNmtHasher(sha256.New(), 1, true), protoRoot)
+	assert.ErrorIs(t, err, ErrInvalidRootEncoding)
+	protoRoot.Digest = protoRoot.Digest[1:]
+	_, err = ProtoToRoot(nth, protoRoot)
+	assert.ErrorIs(t, err, ErrInvalidNodeLen)
+}
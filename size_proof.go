@@ -0,0 +1,141 @@
+package nmt
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+	"math/bits"
+)
+
+// SizeProof proves that a root commits to a tree of exactly Size leaves. It
+// consists of the path along the right edge of the tree, i.e., the roots of
+// the perfect subtrees to the left of the last leaf, and the last leaf itself.
+// Since the hash of a leaf cannot be told apart from the hash of an inner
+// node, the roots of the subtrees alone could be passed off as the roots of
+// smaller subtrees, proving a different size. Hence, the proof also opens the
+// left-most path of each subtree down to its first leaf, which proves its
+// height. A proof holds O(log² n) nodes and O(log n) leaves.
+type SizeProof struct {
+	Size int
+	// Nodes holds the roots of the perfect subtrees covering the first Size-1
+	// leaves from left to right, i.e., one root for each bit set in Size-1.
+	Nodes [][]byte
+	// Openings holds the opening of the left-most path of each subtree in
+	// Nodes.
+	Openings []SubtreeOpening
+	// LastLeaf is the namespace-prefixed data of the last leaf, or nil if the
+	// tree is empty.
+	LastLeaf []byte
+}
+
+// SubtreeOpening is the left-most path of a perfect subtree of a SizeProof.
+type SubtreeOpening struct {
+	// FirstLeaf is the namespace-prefixed data of the first leaf of the
+	// subtree.
+	FirstLeaf []byte
+	// Siblings holds the right siblings on the path from the first leaf to
+	// the root of the subtree, from the bottom up.
+	Siblings [][]byte
+}
+
+// ProveSize returns a SizeProof of the number of leaves of the tree. It
+// returns an ErrLeafDataDropped error if the data of the leaves opened by the
+// proof is not available, see DropLeafData and PushLeafHash.
+func (n *NamespacedMerkleTree) ProveSize() (SizeProof, error) {
+	size := n.Size()
+	if size == 0 {
+		return SizeProof{}, nil
+	}
+	// the nodes to the left of the last leaf are the first nodes of its proof
+	proof, err := n.ProveRange(size-1, size)
+	if err != nil {
+		return SizeProof{}, err
+	}
+	p := SizeProof{Nodes: proof.nodes[:bits.OnesCount(uint(size-1))], Size: size}
+	start := 0
+	for height := bits.Len(uint(size-1)) - 1; height >= 0; height-- {
+		if (size-1)&(1<<height) == 0 {
+			continue
+		}
+		leaf, err := n.sizeProofLeaf(start)
+		if err != nil {
+			return SizeProof{}, err
+		}
+		opening := SubtreeOpening{FirstLeaf: leaf, Siblings: make([][]byte, height)}
+		for h := range opening.Siblings {
+			if opening.Siblings[h], err = n.ComputeSubtreeRoot(start+1<<h, start+1<<(h+1)); err != nil {
+				return SizeProof{}, err
+			}
+		}
+		p.Openings = append(p.Openings, opening)
+		start += 1 << height
+	}
+	if p.LastLeaf, err = n.sizeProofLeaf(size - 1); err != nil {
+		return SizeProof{}, err
+	}
+	return p, nil
+}
+
+// sizeProofLeaf returns a copy of the leaf at the given index, or an
+// ErrLeafDataDropped error if its data is not available.
+func (n *NamespacedMerkleTree) sizeProofLeaf(index int) ([]byte, error) {
+	leaf, err := n.Leaf(index)
+	if err != nil {
+		return nil, err
+	}
+	if leaf == nil {
+		return nil, fmt.Errorf("%w: leaf %d was added by its hash", ErrLeafDataDropped, index)
+	}
+	return bytes.Clone(leaf), nil
+}
+
+// Verify checks that root commits to a tree of exactly p.Size leaves. h and
+// setters must match the base hash function and the options of the tree,
+// including its padding and fixed height. It returns an error wrapping
+// ErrInvalidProof if the verification fails.
+func (p SizeProof) Verify(h hash.Hash, root []byte, setters ...Option) error {
+	if p.Size < 0 || p.Size == 0 && (len(p.Nodes) != 0 || len(p.Openings) != 0 || p.LastLeaf != nil) {
+		return fmt.Errorf("%w: malformed proof of size %d", ErrInvalidProof, p.Size)
+	}
+	if p.Size == 0 {
+		got, err := NewRootComputer(h, setters...).Root()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidProof, err)
+		}
+		if !bytes.Equal(got, root) {
+			return fmt.Errorf("%w: root %x is not the root of an empty tree", ErrInvalidProof, root)
+		}
+		return nil
+	}
+	_, err := p.VerifyLastLeaf(h, root, setters...)
+	return err
+}
+
+// verify checks that the opening proves that node is the root of a perfect
+// subtree of the given height.
+func (o SubtreeOpening) verify(h Hasher, height int, node []byte) error {
+	if len(o.Siblings) != height {
+		return fmt.Errorf("got %d siblings, want %d", len(o.Siblings), height)
+	}
+	got, err := hashSizeProofLeaf(h, o.FirstLeaf)
+	if err != nil {
+		return err
+	}
+	for _, sibling := range o.Siblings {
+		if got, err = h.HashNode(got, sibling); err != nil {
+			return err
+		}
+	}
+	if !bytes.Equal(got, node) {
+		return fmt.Errorf("opening results in %x, want %x", got, node)
+	}
+	return nil
+}
+
+// hashSizeProofLeaf returns the leaf hash of a leaf of a SizeProof.
+func hashSizeProofLeaf(h Hasher, leaf []byte) ([]byte, error) {
+	if len(leaf) < int(h.NamespaceSize()) {
+		return nil, fmt.Errorf("%w: got: %v, want >= %v", ErrInvalidLeafLen, len(leaf), h.NamespaceSize())
+	}
+	return h.HashLeaf(leaf)
+}
@@ -0,0 +1,90 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveSize(t *testing.T) {
+	for _, opts := range [][]Option{
+		{NamespaceIDSize(2)},
+		{NamespaceIDSize(2), Padding(PadWithLastLeaf)},
+		{NamespaceIDSize(2), Padding(PadWithEmptyLeaves)},
+		{NamespaceIDSize(2), FixedHeight(5)},
+	} {
+		tree := New(sha256.New(), opts...)
+		for size := 0; size < 20; size++ {
+			root, err := tree.Root()
+			require.NoError(t, err)
+			proof, err := tree.ProveSize()
+			require.NoError(t, err)
+			assert.Equal(t, size, proof.Size)
+			require.NoError(t, proof.Verify(sha256.New(), root, opts...), "size %d", size)
+
+			// claims of other sizes are rejected
+			for _, other := range []int{size - 1, size + 1} {
+				if other < 0 {
+					continue
+				}
+				forged := proof
+				forged.Size = other
+				assert.ErrorIs(t, forged.Verify(sha256.New(), root, opts...), ErrInvalidProof, "size %d", other)
+			}
+			require.NoError(t, tree.Push(append([]byte{0, byte(size / 3)}, []byte(fmt.Sprintf("leaf_%d", size))...)))
+		}
+	}
+}
+
+func TestSizeProof_VerifyInvalid(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, tree.PushAll([][]byte{{1, 'a'}, {1, 'b'}, {2, 'c'}, {2, 'd'}}))
+	root, err := tree.Root()
+	require.NoError(t, err)
+	proof, err := tree.ProveSize()
+	require.NoError(t, err)
+	require.NoError(t, proof.Verify(sha256.New(), root, NamespaceIDSize(1)))
+
+	// the right-edge path of a tree of 3 leaves also results in the root when
+	// read as a tree of 2 leaves, which the openings rule out
+	small := New(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, small.PushAll([][]byte{{1, 'a'}, {1, 'b'}, {2, 'c'}}))
+	smallRoot, err := small.Root()
+	require.NoError(t, err)
+	smallProof, err := small.ProveSize()
+	require.NoError(t, err)
+	lastLeafHash, err := small.treeHasher.HashLeaf(smallProof.LastLeaf)
+	require.NoError(t, err)
+	c := NewRootComputer(sha256.New(), NamespaceIDSize(1))
+	require.NoError(t, c.verifyLastLeaf(2, smallProof.Nodes, lastLeafHash, smallRoot))
+	forged := smallProof
+	forged.Size = 2
+	forged.Openings = []SubtreeOpening{{FirstLeaf: smallProof.Openings[0].FirstLeaf}}
+	assert.ErrorIs(t, forged.Verify(sha256.New(), smallRoot, NamespaceIDSize(1)), ErrInvalidProof)
+
+	tests := []struct {
+		name  string
+		proof SizeProof
+	}{
+		{"negative size", SizeProof{Size: -1}},
+		{"empty", SizeProof{}},
+		{"missing leaf", SizeProof{Size: 4, Nodes: proof.Nodes, Openings: proof.Openings}},
+		{"missing node", SizeProof{Size: 4, Nodes: proof.Nodes[:1], Openings: proof.Openings[:1], LastLeaf: proof.LastLeaf}},
+		{"missing opening", SizeProof{Size: 4, Nodes: proof.Nodes, LastLeaf: proof.LastLeaf}},
+		{"wrong leaf", SizeProof{Size: 4, Nodes: proof.Nodes, Openings: proof.Openings, LastLeaf: []byte{2, 'c'}}},
+		{"wrong opening", SizeProof{Size: 4, Nodes: proof.Nodes, Openings: proof.Openings[1:], LastLeaf: proof.LastLeaf}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.proof.Verify(sha256.New(), root, NamespaceIDSize(1)), ErrInvalidProof)
+		})
+	}
+
+	dropped := New(sha256.New(), NamespaceIDSize(1), DropLeafData(true))
+	require.NoError(t, dropped.Push([]byte{1, 'a'}))
+	_, err = dropped.ProveSize()
+	assert.ErrorIs(t, err, ErrLeafDataDropped)
+}
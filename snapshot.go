@@ -0,0 +1,115 @@
+package nmt
+
+import (
+	"hash"
+	"maps"
+	"slices"
+	"sync/atomic"
+)
+
+// sharedLeaves coordinates appends to the backing arrays of leaves and
+// leafHashes shared by a tree and its snapshots. Only the tree whose leaves
+// end at size may append in place; all others copy their leaves first.
+type sharedLeaves struct {
+	// size is the number of elements of the backing arrays in use.
+	size atomic.Int64
+}
+
+// Snapshot returns an independent copy of the tree without copying its leaves
+// or its indices, i.e., in time independent of the number of leaves, apart
+// from hashing the leaves pending under LazyLeafHashing. The snapshot and the
+// tree share the backing arrays of their leaves and leaf hashes: whichever of
+// them pushes a leaf first keeps appending in place, while the other copies
+// the slices referencing its leaves (but not the leaves themselves) on its
+// first push, in time linear in the number of leaves, and appends in amortized
+// constant time afterwards. Leaf data is never copied. Likewise, the first of
+// them to modify the namespace index copies it, and the snapshot rebuilds the
+// index used by IndexOf on first use. The inner nodes memoized by Root are
+// not shared, so the snapshot computes its root afresh. This allows several
+// block candidates to be built concurrently from a shared prefix of leaves.
+//
+// The snapshot hashes with h, which must be a new instance of the tree's base
+// hash function, so that the tree and the snapshot can be used concurrently.
+// Trees using a CustomHasher share it with their snapshots, which may then
+// only be used concurrently if the hasher is safe for concurrent use. The
+// snapshot shares the NodeVisitorFn and Metrics of the tree as well.
+func (n *NamespacedMerkleTree) Snapshot(h hash.Hash) *NamespacedMerkleTree {
+	if n.pending > 0 {
+		// pending leaves are hashed in place, hence before the arrays are
+		// shared; errors are returned by the next method hashing them
+		_ = n.hashPendingLeaves()
+	}
+	snapshot := *n
+	if nth, ok := n.treeHasher.(*NmtHasher); ok {
+		snapshot.treeHasher = NewNmtHasher(h, nth.NamespaceSize(), nth.IsMaxNamespaceIDIgnored())
+	}
+	if n.progress != nil {
+		snapshot.progress = newProgressTracker(n.progress.fn, n.progress.interval)
+	}
+	if n.frontier != nil {
+		snapshot.frontier = n.frontier.clone()
+		snapshot.frontier.treeHasher = snapshot.treeHasher
+	}
+	if n.shared == nil {
+		n.shared = &sharedLeaves{}
+		n.shared.size.Store(int64(n.Size()))
+	}
+	snapshot.shared = n.shared
+	// the batches of the tree are not open on the snapshot
+	snapshot.batches = nil
+	// the remainder of the current chunk of the leaf arena is the tree's
+	snapshot.leafArena.buf = nil
+	if n.pending > 0 {
+		// the tree failed to hash its pending leaves, which it hashes in
+		// place
+		snapshot.leafHashes = slices.Clone(n.leafHashes)
+		if n.dropLeafData {
+			snapshot.leaves = slices.Clone(n.leaves)
+		}
+	}
+	n.sharedRanges = true
+	snapshot.sharedRanges = true
+	// the leaf indices are only rebuilt if the snapshot needs them
+	snapshot.leafIndices = nil
+	if n.filter != nil {
+		snapshot.filter = n.filter.clone()
+	}
+	snapshot.innerNodes = nil
+	if n.proofs != nil {
+		snapshot.proofs = newProofCache(n.proofs.size)
+	}
+	snapshot.padNodes = slices.Clip(n.padNodes)
+	snapshot.history = make([]historyEntry, len(n.history))
+	for i, entry := range n.history {
+		snapshot.history[i] = historyEntry{checkpoint: entry.checkpoint}
+	}
+	n.checkInvariants("Snapshot")
+	snapshot.checkInvariants("Snapshot")
+	return &snapshot
+}
+
+// ownLeaves ensures that the next leaf can be appended without affecting
+// other trees sharing the backing arrays of the leaves.
+func (n *NamespacedMerkleTree) ownLeaves() {
+	if n.shared == nil {
+		return
+	}
+	size := n.Size()
+	if size < cap(n.leaves) && size < cap(n.leafHashes) && n.shared.size.CompareAndSwap(int64(size), int64(size+1)) {
+		// the tree is the only one using the arrays beyond size
+		return
+	}
+	// appending will reallocate the arrays
+	n.leaves = slices.Clip(n.leaves)
+	n.leafHashes = slices.Clip(n.leafHashes)
+	n.shared = nil
+}
+
+// ownNamespaceRanges copies the namespace index if it is shared with a
+// snapshot.
+func (n *NamespacedMerkleTree) ownNamespaceRanges() {
+	if n.sharedRanges {
+		n.namespaceRanges = maps.Clone(n.namespaceRanges)
+		n.sharedRanges = false
+	}
+}
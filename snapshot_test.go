@@ -0,0 +1,131 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestSnapshot_Independent(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 2, 3)
+	_, err := tree.Root()
+	require.NoError(t, err)
+	snapshot := tree.Snapshot(sha256.New())
+
+	require.NoError(t, tree.Push([]byte{4, 'a'}))
+	require.NoError(t, snapshot.Push([]byte{3, 'b'}))
+	require.NoError(t, snapshot.Push([]byte{5, 'c'}))
+
+	want := exampleNMT(1, true, 1, 2, 2, 3)
+	wantSnapshot := exampleNMT(1, true, 1, 2, 2, 3)
+	require.NoError(t, want.Push([]byte{4, 'a'}))
+	require.NoError(t, wantSnapshot.Push([]byte{3, 'b'}))
+	require.NoError(t, wantSnapshot.Push([]byte{5, 'c'}))
+
+	index, found := snapshot.IndexOf(wantSnapshot.leafHashes[5])
+	assert.True(t, found)
+	assert.Equal(t, 5, index)
+	requireSameState(t, want, tree)
+	requireSameState(t, wantSnapshot, snapshot)
+	assert.Empty(t, tree.Get(namespace.ID{5}))
+	assert.Empty(t, snapshot.Get(namespace.ID{4}))
+}
+
+func TestSnapshot_AppendsInPlace(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), InitialCapacity(8))
+	require.NoError(t, tree.Push([]byte{1, 'a'}))
+	snapshot := tree.Snapshot(sha256.New())
+
+	// the tree pushing first keeps its backing arrays
+	require.NoError(t, tree.Push([]byte{2, 'b'}))
+	assert.Equal(t, 8, cap(tree.leaves))
+	// the snapshot copies its leaves instead of overwriting the tree's
+	require.NoError(t, snapshot.Push([]byte{3, 'c'}))
+	assert.Equal(t, []byte{2, 'b'}, tree.leaves[1])
+	assert.Equal(t, []byte{3, 'c'}, snapshot.leaves[1])
+	// only the slices referencing the leaves are copied, not the leaves
+	assert.Same(t, &tree.leaves[0][0], &snapshot.leaves[0][0])
+	assert.Same(t, &tree.leafHashes[0][0], &snapshot.leafHashes[0][0])
+}
+
+func TestSnapshot_PopAndReset(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 3)
+	snapshot := tree.Snapshot(sha256.New())
+	_, err := tree.Pop()
+	require.NoError(t, err)
+	tree.Reset()
+	require.NoError(t, tree.Push([]byte{7, 'x'}))
+
+	requireSameState(t, exampleNMT(1, true, 1, 2, 3), snapshot)
+	_, err = snapshot.Pop()
+	require.NoError(t, err)
+	requireSameState(t, exampleNMT(1, true, 1, 2), snapshot)
+}
+
+func TestSnapshot_Options(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1), NamespaceFilter(10, 0.01), History(true), Padding(PadWithLastLeaf))
+	require.NoError(t, tree.PushAll([][]byte{{1, 'a'}, {2, 'b'}, {2, 'c'}}))
+	snapshot := tree.Snapshot(sha256.New())
+
+	require.NoError(t, snapshot.PushAll([][]byte{{3, 'd'}}))
+	assert.Len(t, tree.Checkpoints(), 1)
+	assert.Len(t, snapshot.Checkpoints(), 2)
+	assert.True(t, snapshot.MayContainNamespace(namespace.ID{3}))
+	assert.NotContains(t, tree.namespaceRanges, string([]byte{3}))
+
+	proof, err := snapshot.ProveRangeAt(0, 2, 3)
+	require.NoError(t, err)
+	assert.True(t, proof.VerifyInclusion(sha256.New(), namespace.ID{2}, [][]byte{{'c'}}, tree.Checkpoints()[0].Root))
+}
+
+func TestSnapshot_Concurrent(t *testing.T) {
+	base := New(sha256.New(), NamespaceIDSize(1))
+	for i := 0; i < 64; i++ {
+		require.NoError(t, base.Push([]byte{1, byte(i)}))
+	}
+	_, err := base.Root()
+	require.NoError(t, err)
+
+	const candidates = 8
+	snapshots := make([]*NamespacedMerkleTree, candidates)
+	for i := range snapshots {
+		snapshots[i] = base.Snapshot(sha256.New())
+	}
+	roots := make([][]byte, candidates)
+	errs := make([]error, candidates)
+	var wg sync.WaitGroup
+	for i, snapshot := range snapshots {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j <= i; j++ {
+				if errs[i] = snapshot.Push([]byte{2, byte(i), byte(j)}); errs[i] != nil {
+					return
+				}
+			}
+			roots[i], errs[i] = snapshot.Root()
+		}()
+	}
+	wg.Wait()
+
+	for i := range snapshots {
+		require.NoError(t, errs[i])
+		want := New(sha256.New(), NamespaceIDSize(1))
+		for _, leaf := range base.leaves {
+			require.NoError(t, want.Push(leaf))
+		}
+		for j := 0; j <= i; j++ {
+			require.NoError(t, want.Push([]byte{2, byte(i), byte(j)}))
+		}
+		wantRoot, err := want.Root()
+		require.NoError(t, err)
+		assert.Equal(t, wantRoot, roots[i], fmt.Sprintf("candidate %d", i))
+	}
+	assert.Equal(t, 64, base.Size())
+}
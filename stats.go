@@ -0,0 +1,44 @@
+package nmt
+
+import "github.com/celestiaorg/nmt/namespace"
+
+// NamespaceStats describes the leaves of a single namespace in a tree.
+type NamespaceStats struct {
+	Namespace namespace.ID
+	// Leaves is the number of leaves of the namespace.
+	Leaves int
+	// Bytes is the total size of the namespace-prefixed leaves of the
+	// namespace.
+	Bytes int
+}
+
+// Stats describes the distribution of the leaves of a tree over namespaces.
+type Stats struct {
+	// Leaves is the total number of leaves of the tree.
+	Leaves int
+	// Bytes is the total size of all namespace-prefixed leaves of the tree.
+	Bytes int
+	// Namespaces holds the statistics of each namespace, in ascending
+	// namespace order.
+	Namespaces []NamespaceStats
+}
+
+// Stats returns per-namespace leaf counts and byte totals of the tree together
+// with tree-wide aggregates. Leaves added using PushLeafHash, whose data is
+// unknown to the tree, and leaves whose data was dropped (see DropLeafData)
+// are counted as leaves but do not contribute any bytes.
+func (n *NamespacedMerkleTree) Stats() Stats {
+	stats := Stats{Leaves: n.Size()}
+	for nID, rng := range n.Namespaces() {
+		nsStats := NamespaceStats{
+			Namespace: nID,
+			Leaves:    rng.End - rng.Start,
+		}
+		for _, leaf := range n.leaves[rng.Start:rng.End] {
+			nsStats.Bytes += len(leaf)
+		}
+		stats.Bytes += nsStats.Bytes
+		stats.Namespaces = append(stats.Namespaces, nsStats)
+	}
+	return stats
+}
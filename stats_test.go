@@ -0,0 +1,42 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestStats(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(1))
+	for _, leaf := range [][]byte{{1, 'a'}, {1, 'b', 'c'}, {3}, {5, 'd', 'e', 'f'}} {
+		require.NoError(t, tree.Push(leaf))
+	}
+	assert.Equal(t, Stats{
+		Leaves: 4,
+		Bytes:  10,
+		Namespaces: []NamespaceStats{
+			{Namespace: namespace.ID{1}, Leaves: 2, Bytes: 5},
+			{Namespace: namespace.ID{3}, Leaves: 1, Bytes: 1},
+			{Namespace: namespace.ID{5}, Leaves: 1, Bytes: 4},
+		},
+	}, tree.Stats())
+}
+
+func TestStats_Empty(t *testing.T) {
+	tree := New(sha256.New())
+	assert.Equal(t, Stats{}, tree.Stats())
+}
+
+func TestStats_LeafHashes(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2)
+	hashesOnly, err := NewFromLeafHashes(sha256.New(), tree.leafHashes, NamespaceIDSize(1))
+	require.NoError(t, err)
+	stats := hashesOnly.Stats()
+	assert.Equal(t, 2, stats.Leaves)
+	assert.Zero(t, stats.Bytes)
+	assert.Len(t, stats.Namespaces, 2)
+}
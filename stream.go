@@ -0,0 +1,276 @@
+package nmt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+const (
+	// streamVersion is the version of the proof stream format.
+	streamVersion = 1
+	// streamFlagIgnoreMaxNamespace marks proofs of trees ignoring the maximum
+	// namespace.
+	streamFlagIgnoreMaxNamespace = 1
+	// MaxStreamLeafSize is the maximum size of a single leaf accepted by
+	// VerifyNamespaceStream.
+	MaxStreamLeafSize = 64 << 20
+	// maxStreamRightNodes bounds the number of nodes right of the proven range,
+	// which is at most the height of the tree.
+	maxStreamRightNodes = 64
+)
+
+// ErrInvalidStream indicates that a proof stream is malformed.
+var ErrInvalidStream = errors.New("invalid proof stream")
+
+// WriteNamespaceStream serializes an inclusion proof together with the proven
+// namespace-prefixed leaves to w in the order they are consumed by
+// VerifyNamespaceStream, i.e., following an in-order traversal of the tree:
+//
+//	version || flags || uvarint(start) || uvarint(end) ||
+//	left nodes || (uvarint(len(leaf)) || leaf)* ||
+//	uvarint(#right nodes) || right nodes
+//
+// The number of nodes left of the range is implied by start. Absence and
+// empty proofs cannot be streamed.
+func WriteNamespaceStream(w io.Writer, proof Proof, leaves [][]byte) error {
+	if proof.IsOfAbsence() || proof.start < 0 || proof.start >= proof.end {
+		return fmt.Errorf("%w: only non-empty inclusion proofs can be streamed", ErrInvalidStream)
+	}
+	if len(leaves) != proof.end-proof.start {
+		return fmt.Errorf("%w: got %d leaves for proof range [%d, %d)", ErrWrongLeafHashesSize, len(leaves), proof.start, proof.end)
+	}
+	leftNodes := leftSubtreeCount(proof.start)
+	if len(proof.nodes) < leftNodes {
+		return fmt.Errorf("%w: got %d nodes, expected at least %d", ErrInvalidProof, len(proof.nodes), leftNodes)
+	}
+
+	bw := bufio.NewWriter(w)
+	var flags byte
+	if proof.isMaxNamespaceIDIgnored {
+		flags |= streamFlagIgnoreMaxNamespace
+	}
+	bw.Write([]byte{streamVersion, flags})
+	writeUvarint(bw, uint64(proof.start))
+	writeUvarint(bw, uint64(proof.end))
+	for _, node := range proof.nodes[:leftNodes] {
+		bw.Write(node)
+	}
+	for _, leaf := range leaves {
+		writeUvarint(bw, uint64(len(leaf)))
+		bw.Write(leaf)
+	}
+	writeUvarint(bw, uint64(len(proof.nodes)-leftNodes))
+	for _, node := range proof.nodes[leftNodes:] {
+		bw.Write(node)
+	}
+	return bw.Flush()
+}
+
+// VerifyNamespaceStream reads a proof stream written by WriteNamespaceStream
+// from r and verifies that it contains all leaves of namespace nID in the tree
+// with the given root, just like Proof.VerifyNamespace. The stream is verified
+// while being read, keeping only O(log n) nodes in memory. `h` MUST be the
+// same as the underlying hash function used to generate the proof.
+//
+// If fn is not nil, it is invoked with each leaf as it is read. Leaves passed
+// to fn are unverified until VerifyNamespaceStream returns without error and
+// must not be retained by fn. VerifyNamespaceStream returns the number of
+// leaves read and an error wrapping ErrInvalidStream, ErrInvalidProof or
+// ErrFailedCompletenessCheck if the stream does not verify.
+func VerifyNamespaceStream(r io.Reader, h hash.Hash, nID namespace.ID, root []byte, fn func(leaf []byte) error) (int, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		r = bufio.NewReader(r)
+		br = r.(io.ByteReader)
+	}
+
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, fmt.Errorf("%w: failed to read header: %w", ErrInvalidStream, err)
+	}
+	if header[0] != streamVersion {
+		return 0, fmt.Errorf("%w: unsupported version %d", ErrInvalidStream, header[0])
+	}
+	nth := NewNmtHasher(h, nID.Size(), header[1]&streamFlagIgnoreMaxNamespace != 0)
+	if err := nth.validateNamespaceID(nID); err != nil {
+		return 0, err
+	}
+	if err := nth.ValidateNodeFormat(root); err != nil {
+		return 0, fmt.Errorf("root does not match the NMT hasher's hash format: %w", err)
+	}
+	start, err := binary.ReadUvarint(br)
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to read start: %w", ErrInvalidStream, err)
+	}
+	end, err := binary.ReadUvarint(br)
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to read end: %w", ErrInvalidStream, err)
+	}
+	if start >= end || end > 1<<62 {
+		return 0, fmt.Errorf("%w: invalid range [%d, %d)", ErrInvalidStream, start, end)
+	}
+
+	f := streamFolder{nth: nth}
+	node := make([]byte, nth.Size())
+	readNode := func() ([]byte, error) {
+		if _, err := io.ReadFull(r, node); err != nil {
+			return nil, fmt.Errorf("%w: failed to read node: %w", ErrInvalidStream, err)
+		}
+		if err := nth.ValidateNodeFormat(node); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidProof, err)
+		}
+		return append([]byte(nil), node...), nil
+	}
+
+	// nodes left of the range
+	for f.pos < start {
+		n, err := readNode()
+		if err != nil {
+			return 0, err
+		}
+		if nID.LessOrEqual(maxNamespaceView(n, nID.Size())) {
+			return 0, ErrFailedCompletenessCheck
+		}
+		if err := f.push(n, uint64(nextSubtreeSize(f.pos, start))); err != nil {
+			return 0, err
+		}
+	}
+
+	// the leaves of the range
+	var leaf []byte
+	count := 0
+	for ; f.pos < end; count++ {
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			return count, fmt.Errorf("%w: failed to read leaf size: %w", ErrInvalidStream, err)
+		}
+		if size > MaxStreamLeafSize {
+			return count, fmt.Errorf("%w: leaf of %d bytes exceeds the maximum of %d", ErrInvalidStream, size, MaxStreamLeafSize)
+		}
+		if uint64(cap(leaf)) < size {
+			leaf = make([]byte, size)
+		}
+		leaf = leaf[:size]
+		if _, err := io.ReadFull(r, leaf); err != nil {
+			return count, fmt.Errorf("%w: failed to read leaf: %w", ErrInvalidStream, err)
+		}
+		if len(leaf) < int(nID.Size()) || !nID.Equal(leaf[:nID.Size()]) {
+			return count, fmt.Errorf("leaf %d does not belong to namespace %x: %w", count, nID, ErrInvalidProof)
+		}
+		leafHash, err := nth.HashLeaf(leaf)
+		if err != nil {
+			return count, fmt.Errorf("%w: %w", ErrInvalidProof, err)
+		}
+		if fn != nil {
+			if err := fn(leaf); err != nil {
+				return count, err
+			}
+		}
+		if err := f.push(leafHash, 1); err != nil {
+			return count, err
+		}
+	}
+
+	// nodes right of the range
+	rightNodes, err := binary.ReadUvarint(br)
+	if err != nil {
+		return count, fmt.Errorf("%w: failed to read the number of right nodes: %w", ErrInvalidStream, err)
+	}
+	if rightNodes > maxStreamRightNodes {
+		return count, fmt.Errorf("%w: %d right nodes exceed the maximum of %d", ErrInvalidStream, rightNodes, maxStreamRightNodes)
+	}
+	for i := uint64(0); i < rightNodes; i++ {
+		n, err := readNode()
+		if err != nil {
+			return count, err
+		}
+		if minNamespaceView(n, nID.Size()).LessOrEqual(nID) {
+			return count, ErrFailedCompletenessCheck
+		}
+		// right nodes cover the largest aligned subtree at their position
+		if err := f.push(n, f.pos&-f.pos); err != nil {
+			return count, err
+		}
+	}
+
+	computed, err := f.root()
+	if err != nil {
+		return count, err
+	}
+	if !bytes.Equal(computed, root) {
+		return count, fmt.Errorf("%w: computed root does not match", ErrInvalidProof)
+	}
+	return count, nil
+}
+
+// streamFolder computes a root from the subtrees of a tree given in order,
+// keeping only the right frontier of not yet merged subtrees.
+type streamFolder struct {
+	nth   *NmtHasher
+	stack []streamSubtree
+	// pos is the index of the first leaf after all pushed subtrees.
+	pos uint64
+}
+
+type streamSubtree struct {
+	hash      []byte
+	pos, size uint64
+}
+
+// push appends the subtree of the given (nominal) size and merges it with
+// its left siblings.
+func (f *streamFolder) push(hash []byte, size uint64) error {
+	s := streamSubtree{hash: hash, pos: f.pos, size: size}
+	f.pos += size
+	for len(f.stack) > 0 {
+		top := f.stack[len(f.stack)-1]
+		if top.size != s.size || top.pos%(2*s.size) != 0 {
+			break
+		}
+		merged, err := f.nth.HashNode(top.hash, s.hash)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidProof, err)
+		}
+		s = streamSubtree{hash: merged, pos: top.pos, size: 2 * s.size}
+		f.stack = f.stack[:len(f.stack)-1]
+	}
+	f.stack = append(f.stack, s)
+	return nil
+}
+
+// root folds the remaining subtrees from right to left; subtrees without a
+// right sibling are passed through as in a tree whose size is not a power of
+// two.
+func (f *streamFolder) root() ([]byte, error) {
+	root := f.stack[len(f.stack)-1].hash
+	for i := len(f.stack) - 2; i >= 0; i-- {
+		var err error
+		root, err = f.nth.HashNode(f.stack[i].hash, root)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidProof, err)
+		}
+	}
+	return root, nil
+}
+
+// leftSubtreeCount returns the number of proof nodes left of a proof range
+// starting at start.
+func leftSubtreeCount(start int) int {
+	count := 0
+	for leafIndex := uint64(0); leafIndex < uint64(start); count++ {
+		leafIndex += uint64(nextSubtreeSize(leafIndex, uint64(start)))
+	}
+	return count
+}
+
+func writeUvarint(w io.Writer, v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
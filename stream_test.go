@@ -0,0 +1,95 @@
+package nmt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestVerifyNamespaceStream(t *testing.T) {
+	for size := 1; size <= 20; size++ {
+		for _, ignoreMax := range []bool{true, false} {
+			nIDs := make([]byte, size)
+			for i := range nIDs {
+				nIDs[i] = byte(i / 3)
+			}
+			nIDs[size-1] = 0xFF
+			tree := exampleNMT(1, ignoreMax, nIDs...)
+			root, err := tree.Root()
+			require.NoError(t, err)
+
+			for ns := range tree.Namespaces() {
+				proof, err := tree.ProveNamespace(ns)
+				require.NoError(t, err)
+				if proof.IsEmptyProof() {
+					// the parity namespace is excluded from the root
+					continue
+				}
+				var buf bytes.Buffer
+				require.NoError(t, WriteNamespaceStream(&buf, proof, tree.Get(ns)))
+
+				var got [][]byte
+				count, err := VerifyNamespaceStream(iotest.OneByteReader(&buf), sha256.New(), ns, root, func(leaf []byte) error {
+					got = append(got, append([]byte(nil), leaf...))
+					return nil
+				})
+				require.NoError(t, err, "size %d, namespace %x, ignoreMax %v", size, ns, ignoreMax)
+				assert.Equal(t, len(tree.Get(ns)), count)
+				assert.Equal(t, tree.Get(ns), got)
+			}
+		}
+	}
+}
+
+func TestVerifyNamespaceStream_Invalid(t *testing.T) {
+	tree := exampleNMT(1, true, 0, 1, 1, 1, 2, 3)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	nID := namespace.ID{1}
+	proof, err := tree.ProveNamespace(nID)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, WriteNamespaceStream(&buf, proof, tree.Get(nID)))
+	stream := buf.Bytes()
+
+	verify := func(stream []byte, nID namespace.ID) error {
+		_, err := VerifyNamespaceStream(bytes.NewReader(stream), sha256.New(), nID, root, nil)
+		return err
+	}
+	require.NoError(t, verify(stream, nID))
+
+	// truncated streams
+	for i := 0; i < len(stream); i++ {
+		assert.Error(t, verify(stream[:i], nID), "truncated at %d", i)
+	}
+	// every flipped byte is detected, except for the IgnoreMaxNamespace flag
+	// which does not affect trees without parity leaves
+	for i := 0; i < len(stream); i++ {
+		if i == 1 {
+			continue
+		}
+		tampered := append([]byte(nil), stream...)
+		tampered[i] ^= 0x01
+		assert.Error(t, verify(tampered, nID), "flipped byte %d", i)
+	}
+
+	// dropping a leaf fails verification
+	var incomplete bytes.Buffer
+	partial := NewInclusionProof(proof.start, proof.end-1, proof.nodes, true)
+	require.NoError(t, WriteNamespaceStream(&incomplete, partial, tree.Get(nID)[:2]))
+	assert.Error(t, verify(incomplete.Bytes(), nID))
+
+	// wrong namespace
+	assert.ErrorIs(t, verify(stream, namespace.ID{2}), ErrInvalidProof)
+
+	absence, err := tree.ProveNamespace(namespace.ID{4})
+	require.NoError(t, err)
+	assert.ErrorIs(t, WriteNamespaceStream(&buf, absence, nil), ErrInvalidStream)
+	assert.ErrorIs(t, WriteNamespaceStream(&buf, proof, nil), ErrWrongLeafHashesSize)
+}
@@ -0,0 +1,49 @@
+package nmt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// Root is a namespaced root, or any other namespaced hash, as returned by
+// NamespacedMerkleTree.Root. Converting a root to a Root formats it readably,
+// e.g., log.Printf("root: %v", nmt.Root(root)).
+type Root []byte
+
+// String returns the hexadecimal encoding of the root abbreviated to its first
+// and last 4 bytes, followed by its size. Use FormatRoot for the full
+// encoding.
+func (r Root) String() string {
+	return fmt.Sprintf("%s (%d bytes)", namespace.ID(r).Abbrev(), len(r))
+}
+
+// String summarizes the tree: its size, the number of namespaces, the range
+// of namespace IDs and, if memoized, its root. Namespace IDs and the root are
+// abbreviated. String does not compute the root or hash pending leaves.
+func (n *NamespacedMerkleTree) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "NamespacedMerkleTree{size: %d, namespace size: %d, namespaces: %d", n.Size(), n.NamespaceSize(), len(n.namespaceRanges))
+	if n.Size() > 0 {
+		fmt.Fprintf(&b, ", namespace range: [%s, %s]", n.minNID.Abbrev(), n.maxNID.Abbrev())
+	}
+	if n.rawRoot != nil {
+		fmt.Fprintf(&b, ", root: %s", namespace.ID(n.rawRoot).Abbrev())
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// String summarizes the proof: its range, the number of nodes and, for proofs
+// of absence, the abbreviated leaf hash.
+func (proof Proof) String() string {
+	if proof.IsEmptyProof() {
+		return "Proof{empty}"
+	}
+	s := fmt.Sprintf("Proof{range: [%d, %d), nodes: %d", proof.start, proof.end, len(proof.nodes))
+	if proof.IsOfAbsence() {
+		s += ", absence leaf hash: " + namespace.ID(proof.leafHash).Abbrev()
+	}
+	return s + "}"
+}
@@ -0,0 +1,45 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestRoot_String(t *testing.T) {
+	root := EmptyRoot(sha256.New(), 8)
+	assert.Equal(t, "00000000...7852b855 (48 bytes)", fmt.Sprint(Root(root)))
+	assert.Equal(t, "0102 (2 bytes)", Root{1, 2}.String())
+}
+
+func TestNamespacedMerkleTree_String(t *testing.T) {
+	tree := New(sha256.New(), NamespaceIDSize(10))
+	assert.Equal(t, "NamespacedMerkleTree{size: 0, namespace size: 10, namespaces: 0}", tree.String())
+
+	for _, nID := range []byte{1, 1, 3} {
+		require.NoError(t, tree.Push(append(append(make([]byte, 9), nID), 'x')))
+	}
+	assert.Equal(t, "NamespacedMerkleTree{size: 3, namespace size: 10, namespaces: 2, namespace range: [00000000...00000001, 00000000...00000003]}", tree.String())
+
+	root, err := tree.Root()
+	require.NoError(t, err)
+	assert.Contains(t, fmt.Sprint(tree), ", root: "+namespace.ID(root).Abbrev()+"}")
+}
+
+func TestProof_String(t *testing.T) {
+	tree := exampleNMT(1, true, 1, 2, 2, 4)
+	proof, err := tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	assert.Equal(t, "Proof{range: [1, 3), nodes: 2}", proof.String())
+
+	proof, err = tree.ProveNamespace(namespace.ID{3})
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("Proof{range: [3, 4), nodes: 2, absence leaf hash: %s}", namespace.ID(proof.LeafHash()).Abbrev()), fmt.Sprint(proof))
+
+	assert.Equal(t, "Proof{empty}", NewEmptyRangeProof(true).String())
+}
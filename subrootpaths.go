@@ -6,10 +6,10 @@ import (
 )
 
 var (
-	errNotPowerOf2       = errors.New("GetSubrootPaths: Supplied square size is not a power of 2")
-	errInvalidShareCount = errors.New("GetSubrootPaths: Can't compute path for 0 share count slice")
-	errPastSquareSize    = errors.New("GetSubrootPaths: Share slice can't be past the square size")
-	errInvalidIdxEnd     = errors.New("GetSubrootPaths: idxEnd must be larger than idxStart and shareCount")
+	ErrNotPowerOf2       = errors.New("GetSubrootPaths: Supplied square size is not a power of 2")
+	ErrInvalidShareCount = errors.New("GetSubrootPaths: Can't compute path for 0 share count slice")
+	ErrPastSquareSize    = errors.New("GetSubrootPaths: Share slice can't be past the square size")
+	ErrInvalidIdxEnd     = errors.New("GetSubrootPaths: idxEnd must be larger than idxStart and shareCount")
 )
 
 // merkle path to a node is equivalent to the index's binary representation
@@ -126,24 +126,24 @@ func GetSubrootPaths(squareSize uint, idxStart uint, shareCount uint) ([][][]int
 	// check squareSize is at least 2 and that it's
 	// a power of 2 by checking that only 1 bit is on
 	if squareSize < 2 || bits.OnesCount(squareSize) != 1 {
-		return nil, errNotPowerOf2
+		return nil, ErrNotPowerOf2
 	}
 
 	// no path exists for 0 count slice
 	if shareCount == 0 {
-		return nil, errInvalidShareCount
+		return nil, ErrInvalidShareCount
 	}
 
 	idxEnd := idxStart + shareCount
 	if idxEnd < idxStart || idxEnd < shareCount {
-		return nil, errInvalidIdxEnd
+		return nil, ErrInvalidIdxEnd
 	}
 
 	shares := squareSize * squareSize
 
 	// sanity checking
 	if idxStart >= shares || idxEnd > shares {
-		return nil, errPastSquareSize
+		return nil, ErrPastSquareSize
 	}
 
 	startRow := idxStart / squareSize
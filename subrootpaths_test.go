@@ -1,6 +1,7 @@
 package nmt
 
 import (
+	"math"
 	"reflect"
 	"testing"
 )
@@ -20,12 +21,12 @@ func TestArgValidation(t *testing.T) {
 	}
 
 	tests := []test{
-		{input: pathSpan{squareSize: 0, startNode: 0, length: 0}, want: errNotPowerOf2},
-		{input: pathSpan{squareSize: 1, startNode: 0, length: 1}, want: errNotPowerOf2},
-		{input: pathSpan{squareSize: 20, startNode: 0, length: 1}, want: errNotPowerOf2},
-		{input: pathSpan{squareSize: 4, startNode: 0, length: 17}, want: errPastSquareSize},
-		{input: pathSpan{squareSize: 4, startNode: 0, length: 0}, want: errInvalidShareCount},
-		{input: pathSpan{squareSize: 128, startNode: 1, length: 18446744073709551615}, want: errInvalidIdxEnd},
+		{input: pathSpan{squareSize: 0, startNode: 0, length: 0}, want: ErrNotPowerOf2},
+		{input: pathSpan{squareSize: 1, startNode: 0, length: 1}, want: ErrNotPowerOf2},
+		{input: pathSpan{squareSize: 20, startNode: 0, length: 1}, want: ErrNotPowerOf2},
+		{input: pathSpan{squareSize: 4, startNode: 0, length: 17}, want: ErrPastSquareSize},
+		{input: pathSpan{squareSize: 4, startNode: 0, length: 0}, want: ErrInvalidShareCount},
+		{input: pathSpan{squareSize: 128, startNode: 1, length: math.MaxUint}, want: ErrInvalidIdxEnd},
 	}
 
 	for _, tc := range tests {
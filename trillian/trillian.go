@@ -0,0 +1,67 @@
+// Package trillian adapts the namespaced hasher to the hashers.LogHasher
+// interface of Trillian (github.com/google/trillian/merkle/hashers, now
+// github.com/transparency-dev/merkle) without depending on it. Logs built with
+// the adapter produce the same roots as NMTs over the same leaves, since both
+// use the RFC 6962 tree shape.
+package trillian
+
+import (
+	"hash"
+
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+// LogHasher mirrors Trillian's hashers.LogHasher interface.
+type LogHasher interface {
+	// EmptyRoot supports returning a special case for the root of an empty
+	// tree.
+	EmptyRoot() []byte
+	// HashLeaf computes the hash of a leaf that exists.
+	HashLeaf(leaf []byte) []byte
+	// HashChildren computes interior nodes.
+	HashChildren(l, r []byte) []byte
+	// Size returns the number of bytes the Hash* functions will return.
+	Size() int
+}
+
+// Hasher implements LogHasher using an nmt.NmtHasher. Leaves must be
+// namespace-prefixed. As the LogHasher interface does not allow returning
+// errors, HashLeaf and HashChildren panic on input the namespaced hasher
+// rejects, i.e., leaves shorter than the namespace size and children that are
+// not ordered by namespace. Hasher is not safe for concurrent use.
+type Hasher struct {
+	nth *nmt.NmtHasher
+}
+
+var _ LogHasher = (*Hasher)(nil)
+
+// NewHasher creates a Hasher using the base hash function h, namespace IDs of
+// nIDSize bytes and the given IgnoreMaxNamespace setting.
+func NewHasher(h hash.Hash, nIDSize namespace.IDSize, ignoreMaxNamespace bool) *Hasher {
+	return &Hasher{nth: nmt.NewNmtHasher(h, nIDSize, ignoreMaxNamespace)}
+}
+
+// EmptyRoot returns the root of an empty NMT.
+func (h *Hasher) EmptyRoot() []byte {
+	return h.nth.EmptyRoot()
+}
+
+// HashLeaf returns the namespaced hash of the namespace-prefixed leaf.
+func (h *Hasher) HashLeaf(leaf []byte) []byte {
+	return h.nth.MustHashLeaf(leaf)
+}
+
+// HashChildren returns the namespaced hash of the node with children l and r.
+func (h *Hasher) HashChildren(l, r []byte) []byte {
+	res, err := h.nth.HashNode(l, r)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// Size returns the size of namespaced hashes.
+func (h *Hasher) Size() int {
+	return h.nth.Size()
+}
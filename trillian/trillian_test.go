@@ -0,0 +1,59 @@
+package trillian
+
+import (
+	"crypto/sha256"
+	"math/bits"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt"
+)
+
+// rootFromLeaves computes the RFC 6962 root of leaves the way Trillian does,
+// using only the LogHasher interface.
+func rootFromLeaves(h LogHasher, leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		return h.EmptyRoot()
+	case 1:
+		return h.HashLeaf(leaves[0])
+	default:
+		k := 1 << (bits.Len(uint(len(leaves)-1)) - 1)
+		return h.HashChildren(rootFromLeaves(h, leaves[:k]), rootFromLeaves(h, leaves[k:]))
+	}
+}
+
+func TestHasher_MatchesNMTRoot(t *testing.T) {
+	for _, ignoreMax := range []bool{true, false} {
+		var leaves [][]byte
+		for size := 0; size <= 9; size++ {
+			tree := nmt.New(sha256.New(), nmt.NamespaceIDSize(1), nmt.IgnoreMaxNamespace(ignoreMax))
+			for _, leaf := range leaves {
+				require.NoError(t, tree.Push(leaf))
+			}
+			want, err := tree.Root()
+			require.NoError(t, err)
+
+			h := NewHasher(sha256.New(), 1, ignoreMax)
+			assert.Equal(t, want, rootFromLeaves(h, leaves), "size %d", size)
+			assert.Len(t, want, h.Size())
+
+			nID := byte(size)
+			if size == 9 {
+				nID = 0xFF
+			}
+			leaves = append(leaves, []byte{nID, 'd'})
+		}
+	}
+}
+
+func TestHasher_Panics(t *testing.T) {
+	h := NewHasher(sha256.New(), 2, true)
+	assert.Panics(t, func() { h.HashLeaf([]byte{1}) })
+	left := h.HashLeaf([]byte{0, 2})
+	right := h.HashLeaf([]byte{0, 1})
+	assert.Panics(t, func() { h.HashChildren(left, right) })
+	assert.NotPanics(t, func() { h.HashChildren(right, left) })
+}
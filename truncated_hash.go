@@ -0,0 +1,39 @@
+package nmt
+
+import (
+	"hash"
+)
+
+// TruncatedHash returns a constructor of instances of newHash whose digests
+// are truncated to their first size bytes. Like KeyedHash, the instances can
+// be used wherever a hash.Hash is expected. The node size of trees and hashers
+// using them shrinks accordingly, i.e., nodes are 2*NamespaceSize()+size bytes
+// long, and all node size checks adapt to it. This reduces the size of proofs
+// at the expense of collision resistance, which is roughly 2^(4*size) for
+// truncated digests. TruncatedHash panics if size is not within [1,
+// newHash().Size()].
+func TruncatedHash(newHash func() hash.Hash, size int) func() hash.Hash {
+	if size < 1 || size > newHash().Size() {
+		panic("Got invalid digest size. Expected a value within [1, newHash().Size()].")
+	}
+	return func() hash.Hash {
+		return &truncatedHash{Hash: newHash(), size: size}
+	}
+}
+
+// truncatedHash wraps a hash.Hash whose digests it truncates to size bytes.
+type truncatedHash struct {
+	hash.Hash
+	size int
+	// digest is reused across Sum calls to avoid allocations.
+	digest []byte
+}
+
+func (t *truncatedHash) Size() int {
+	return t.size
+}
+
+func (t *truncatedHash) Sum(b []byte) []byte {
+	t.digest = t.Hash.Sum(t.digest[:0])
+	return append(b, t.digest[:t.size]...)
+}
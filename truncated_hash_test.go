@@ -0,0 +1,56 @@
+package nmt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/nmt/namespace"
+)
+
+func TestTruncatedHash(t *testing.T) {
+	h := TruncatedHash(sha256.New, 20)()
+	assert.Equal(t, 20, h.Size())
+	h.Write([]byte("data"))
+	full := sha256.Sum256([]byte("data"))
+	assert.Equal(t, append([]byte{0xAB}, full[:20]...), h.Sum([]byte{0xAB}))
+	// Sum does not change the state
+	assert.Equal(t, full[:20], h.Sum(nil))
+
+	assert.Panics(t, func() { TruncatedHash(sha256.New, 0) })
+	assert.Panics(t, func() { TruncatedHash(sha256.New, sha256.Size+1) })
+}
+
+func TestTruncatedHash_Tree(t *testing.T) {
+	truncated := TruncatedHash(sha256.New, 20)
+	tree := New(truncated(), NamespaceIDSize(1))
+	leaves := [][]byte{{1, 'a'}, {2, 'b'}, {2, 'c'}, {4, 'd'}}
+	for _, leaf := range leaves {
+		require.NoError(t, tree.Push(leaf))
+	}
+	root, err := tree.Root()
+	require.NoError(t, err)
+	assert.Len(t, root, 2+20)
+	assert.Len(t, tree.treeHasher.EmptyRoot(), 2+20)
+
+	proof, err := tree.ProveNamespace(namespace.ID{2})
+	require.NoError(t, err)
+	for _, node := range proof.Nodes() {
+		assert.Len(t, node, 2+20)
+	}
+	assert.True(t, proof.VerifyNamespace(truncated(), namespace.ID{2}, leaves[1:3], root))
+	// full-size nodes are rejected by the size checks
+	assert.False(t, proof.VerifyNamespace(sha256.New(), namespace.ID{2}, leaves[1:3], root))
+
+	absence, err := tree.ProveNamespace(namespace.ID{3})
+	require.NoError(t, err)
+	assert.True(t, absence.VerifyNamespace(truncated(), namespace.ID{3}, nil, root))
+
+	nth := NewNmtHasher(truncated(), 1, true)
+	leafHash, err := nth.HashLeaf(leaves[0])
+	require.NoError(t, err)
+	assert.Error(t, nth.ValidateNodeFormat(append(leafHash, 0)))
+	assert.NoError(t, nth.ValidateNodeFormat(leafHash))
+}